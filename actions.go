@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+)
+
+// showCorporateActionDialog records a split or ticker rename against the portfolio.
+// onChange is called after the lots have been adjusted.
+func showCorporateActionDialog(win fyne.Window, pf *portfolio.Portfolio, onChange func()) {
+	kindSelect := widget.NewSelect([]string{"Split", "Rename"}, nil)
+	kindSelect.SetSelected("Split")
+	symbolEntry := widget.NewEntry()
+	symbolEntry.SetPlaceHolder("Ticker (blank for untagged lots)")
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	ratioEntry := widget.NewEntry()
+	ratioEntry.SetPlaceHolder("10:1")
+	newSymbolEntry := widget.NewEntry()
+
+	kindSelect.OnChanged = func(kind string) {
+		if kind == "Split" {
+			ratioEntry.Enable()
+			newSymbolEntry.Disable()
+		} else {
+			ratioEntry.Disable()
+			newSymbolEntry.Enable()
+		}
+	}
+	kindSelect.OnChanged(kindSelect.Selected)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Action", kindSelect),
+		widget.NewFormItem("Symbol", symbolEntry),
+		widget.NewFormItem("Effective", dateEntry),
+		widget.NewFormItem("Split Ratio", ratioEntry),
+		widget.NewFormItem("New Symbol", newSymbolEntry),
+	}
+
+	dialog.ShowForm("Corporate Action", "Apply", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		effective, err := time.ParseInLocation("2006-01-02", dateEntry.Text, time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Effective date must be YYYY-MM-DD"), win)
+			return
+		}
+
+		action := portfolio.CorporateAction{
+			Symbol:    strings.ToUpper(strings.TrimSpace(symbolEntry.Text)),
+			Effective: effective,
+		}
+		if kindSelect.Selected == "Split" {
+			action.Kind = portfolio.ActionSplit
+			action.Ratio, err = parseRatio(ratioEntry.Text)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+		} else {
+			action.Kind = portfolio.ActionRename
+			action.NewSymbol = strings.ToUpper(strings.TrimSpace(newSymbolEntry.Text))
+		}
+
+		if err := pf.ApplyAction(action); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		onChange()
+	}, win)
+}
+
+// parseRatio accepts split ratios as "10:1", "1:10", or a plain multiplier
+func parseRatio(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if newShares, oldShares, ok := strings.Cut(s, ":"); ok {
+		n, err1 := parseFloat(strings.TrimSpace(newShares))
+		o, err2 := parseFloat(strings.TrimSpace(oldShares))
+		if err1 != nil || err2 != nil || o == 0 {
+			return 0, fmt.Errorf("Split ratio must look like 10:1")
+		}
+		return n / o, nil
+	}
+	r, err := parseFloat(s)
+	if err != nil {
+		return 0, fmt.Errorf("Split ratio must look like 10:1")
+	}
+	return r, nil
+}
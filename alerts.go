@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/notify"
+	"fynance/portfolio"
+)
+
+const (
+	alertsKey     = "alerts.settings"
+	alertsSentKey = "alerts.sent" // Keys of alerts already sent, so each goes out once
+	maxAlertsSent = 200
+)
+
+// alertSettings are the notification routes and what triggers each alert.
+// They are kept in preferences, including any SMTP password, which is not
+// encrypted; the Notifications dialog says so.
+type alertSettings struct {
+	notify.Config
+	VestDays   int     `json:"vestDays"`             // Remind this many days before a vest
+	ExpiryDays int     `json:"expiryDays"`           // Warn this many days before options expire
+	PriceAbove float64 `json:"priceAbove,omitempty"` // Alert when the price reaches this; 0 is off
+	PriceBelow float64 `json:"priceBelow,omitempty"` // Alert when the price falls to this; 0 is off
+}
+
+// defaultAlertSettings send every alert to the desktop a week before a
+// vest and 90 days before options expire
+func defaultAlertSettings() alertSettings {
+	return alertSettings{Config: notify.DefaultConfig(), VestDays: 7, ExpiryDays: 90}
+}
+
+// alertKindLabels names each kind of alert in the Notifications dialog
+var alertKindLabels = map[notify.Kind]string{
+	notify.VestReminder: "Vest Reminders",
+	notify.PriceAlert:   "Price Alerts",
+	notify.OptionExpiry: "Expiring Options",
+	notify.FeeChange:    "Fee Changes",
+}
+
+// alertBackendLabels names each backend in the Notifications dialog
+var alertBackendLabels = map[notify.Backend]string{
+	notify.BackendDesktop: "Desktop",
+	notify.BackendEmail:   "Email",
+	notify.BackendWebhook: "Webhook",
+}
+
+// loadAlertSettings reads the saved settings, or the defaults
+func loadAlertSettings(a fyne.App) alertSettings {
+	s := defaultAlertSettings()
+	raw := a.Preferences().String(alertsKey)
+	if raw == "" {
+		return s
+	}
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return defaultAlertSettings()
+	}
+	return s
+}
+
+// saveAlertSettings persists the settings to preferences
+func saveAlertSettings(a fyne.App, s alertSettings) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	a.Preferences().SetString(alertsKey, string(data))
+}
+
+// sendAlerts delivers messages in the background through the saved routes.
+// Nothing is sent in demo mode.
+func sendAlerts(a fyne.App, msgs ...notify.Message) {
+	if demoMode || len(msgs) == 0 {
+		return
+	}
+	d := notify.NewDispatcher(loadAlertSettings(a).Config, &notify.Desktop{App: a})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		for _, m := range msgs {
+			if err := d.Notify(ctx, m); err != nil {
+				fyne.LogError("Failed to send alert", err)
+			}
+		}
+	}()
+}
+
+// sendAlertsOnce sends the messages whose keys have not been sent before
+// and remembers them
+func sendAlertsOnce(a fyne.App, keys []string, msgs []notify.Message) {
+	if demoMode {
+		return
+	}
+	sent := a.Preferences().StringList(alertsSentKey)
+	seen := make(map[string]bool, len(sent))
+	for _, k := range sent {
+		seen[k] = true
+	}
+	var fresh []notify.Message
+	for i, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			sent = append(sent, k)
+			fresh = append(fresh, msgs[i])
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	if len(sent) > maxAlertsSent {
+		sent = sent[len(sent)-maxAlertsSent:]
+	}
+	a.Preferences().SetStringList(alertsSentKey, sent)
+	sendAlerts(a, fresh...)
+}
+
+// checkPortfolioAlerts reminds of vests and option expirations coming up
+// within the configured number of days
+func checkPortfolioAlerts(a fyne.App, pf *portfolio.Portfolio, now time.Time) {
+	s := loadAlertSettings(a)
+	var keys []string
+	var msgs []notify.Message
+	if s.VestDays > 0 {
+		until := now.AddDate(0, 0, s.VestDays)
+		for _, g := range pf.Grants {
+			for _, v := range g.Unvested(now) {
+				if v.Date.After(until) {
+					continue
+				}
+				date := v.Date.In(taxHome()).Format("2006-01-02")
+				keys = append(keys, fmt.Sprintf("%s|%s|%s", notify.VestReminder, g.ID, date))
+				msgs = append(msgs, notify.Message{
+					Kind:  notify.VestReminder,
+					Title: fmt.Sprintf("%s %s vest on %s", g.Symbol, g.Kind, date),
+					Body:  fmt.Sprintf("%.0f shares release on %s. Check the withholding election and sale plan.", v.Shares, date),
+				})
+			}
+		}
+	}
+	if s.ExpiryDays > 0 {
+		until := now.AddDate(0, 0, s.ExpiryDays)
+		for _, g := range pf.Grants {
+			exp := g.Expiration()
+			if exp.IsZero() || exp.Before(now) || exp.After(until) {
+				continue
+			}
+			date := exp.In(taxHome()).Format("2006-01-02")
+			keys = append(keys, fmt.Sprintf("%s|%s|%s", notify.OptionExpiry, g.ID, date))
+			msgs = append(msgs, notify.Message{
+				Kind:  notify.OptionExpiry,
+				Title: fmt.Sprintf("%s %s options expire on %s", g.Symbol, g.Kind, date),
+				Body:  fmt.Sprintf("Vested options at $%.2f not exercised by %s are forfeited.", g.Strike, date),
+			})
+		}
+	}
+	sendAlertsOnce(a, keys, msgs)
+}
+
+// checkPriceAlert alerts, at most once a day for each threshold, when a
+// fetched price crosses the configured levels
+func checkPriceAlert(a fyne.App, p events.Price, now time.Time) {
+	s := loadAlertSettings(a)
+	symbol := p.Symbol
+	if symbol == "" {
+		symbol = "The share price"
+	}
+	day := now.In(taxHome()).Format("2006-01-02")
+	var keys []string
+	var msgs []notify.Message
+	if s.PriceAbove > 0 && p.Price >= s.PriceAbove {
+		keys = append(keys, fmt.Sprintf("%s|above|%s|%s", notify.PriceAlert, p.Symbol, day))
+		msgs = append(msgs, notify.Message{Kind: notify.PriceAlert, Title: "Price alert",
+			Body: fmt.Sprintf("%s is $%.2f, at or above $%.2f.", symbol, p.Price, s.PriceAbove)})
+	}
+	if s.PriceBelow > 0 && p.Price > 0 && p.Price <= s.PriceBelow {
+		keys = append(keys, fmt.Sprintf("%s|below|%s|%s", notify.PriceAlert, p.Symbol, day))
+		msgs = append(msgs, notify.Message{Kind: notify.PriceAlert, Title: "Price alert",
+			Body: fmt.Sprintf("%s is $%.2f, at or below $%.2f.", symbol, p.Price, s.PriceBelow)})
+	}
+	sendAlertsOnce(a, keys, msgs)
+}
+
+// showNotificationsDialog edits which backends each kind of alert goes to,
+// the backends' settings, and when alerts fire
+func showNotificationsDialog(a fyne.App, win fyne.Window) {
+	s := loadAlertSettings(a)
+
+	backendOptions := make([]string, 0, len(notify.Backends))
+	for _, b := range notify.Backends {
+		backendOptions = append(backendOptions, alertBackendLabels[b])
+	}
+	routeChecks := make(map[notify.Kind]*widget.CheckGroup, len(notify.Kinds))
+	var items []*widget.FormItem
+	for _, k := range notify.Kinds {
+		group := widget.NewCheckGroup(backendOptions, nil)
+		group.Horizontal = true
+		var selected []string
+		for _, b := range s.Routes[k] {
+			selected = append(selected, alertBackendLabels[b])
+		}
+		group.SetSelected(selected)
+		routeChecks[k] = group
+		items = append(items, widget.NewFormItem(alertKindLabels[k], group))
+	}
+
+	vestEntry := widget.NewEntry()
+	vestEntry.SetText(strconv.Itoa(s.VestDays))
+	expiryEntry := widget.NewEntry()
+	expiryEntry.SetText(strconv.Itoa(s.ExpiryDays))
+	aboveEntry := widget.NewEntry()
+	aboveEntry.SetPlaceHolder("Off")
+	belowEntry := widget.NewEntry()
+	belowEntry.SetPlaceHolder("Off")
+	if s.PriceAbove > 0 {
+		aboveEntry.SetText(fmt.Sprintf("%.2f", s.PriceAbove))
+	}
+	if s.PriceBelow > 0 {
+		belowEntry.SetText(fmt.Sprintf("%.2f", s.PriceBelow))
+	}
+
+	serverEntry := widget.NewEntry()
+	serverEntry.SetPlaceHolder("smtp.example.com:587")
+	serverEntry.SetText(s.Email.Server)
+	userEntry := widget.NewEntry()
+	userEntry.SetText(s.Email.Username)
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetText(s.Email.Password)
+	fromEntry := widget.NewEntry()
+	fromEntry.SetText(s.Email.From)
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("Comma-separated addresses")
+	toEntry.SetText(strings.Join(s.Email.To, ", "))
+	webhookEntry := widget.NewEntry()
+	webhookEntry.SetPlaceHolder("https://example.com/hooks/fynance")
+	webhookEntry.SetText(s.Webhook)
+
+	// The password is kept in preferences as plain text, so say so where it is entered
+	passwordItem := widget.NewFormItem("SMTP Password", passwordEntry)
+	passwordItem.HintText = "Saved unencrypted in the app's preferences; prefer an app password"
+
+	items = append(items,
+		widget.NewFormItem("Days Before Vest", vestEntry),
+		widget.NewFormItem("Days Before Expiry", expiryEntry),
+		widget.NewFormItem("Price Above ($)", aboveEntry),
+		widget.NewFormItem("Price Below ($)", belowEntry),
+		widget.NewFormItem("SMTP Server", serverEntry),
+		widget.NewFormItem("SMTP Username", userEntry),
+		passwordItem,
+		widget.NewFormItem("Email From", fromEntry),
+		widget.NewFormItem("Email To", toEntry),
+		widget.NewFormItem("Webhook URL", webhookEntry),
+	)
+	dialog.ShowForm("Notifications", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		vestDays, err1 := strconv.Atoi(strings.TrimSpace(vestEntry.Text))
+		expiryDays, err2 := strconv.Atoi(strings.TrimSpace(expiryEntry.Text))
+		above, err3 := parseFloat(aboveEntry.Text)
+		below, err4 := parseFloat(belowEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || vestDays < 0 || expiryDays < 0 || above < 0 || below < 0 {
+			dialog.ShowError(fmt.Errorf("Please enter whole numbers of days and prices of 0 or more"), win)
+			return
+		}
+
+		next := alertSettings{VestDays: vestDays, ExpiryDays: expiryDays, PriceAbove: above, PriceBelow: below}
+		next.Routes = make(map[notify.Kind][]notify.Backend, len(notify.Kinds))
+		for _, k := range notify.Kinds {
+			for _, b := range notify.Backends {
+				for _, label := range routeChecks[k].Selected {
+					if label == alertBackendLabels[b] {
+						next.Routes[k] = append(next.Routes[k], b)
+					}
+				}
+			}
+		}
+		next.Email = notify.EmailConfig{
+			Server:   strings.TrimSpace(serverEntry.Text),
+			Username: strings.TrimSpace(userEntry.Text),
+			Password: passwordEntry.Text,
+			From:     strings.TrimSpace(fromEntry.Text),
+		}
+		for _, addr := range strings.Split(toEntry.Text, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				next.Email.To = append(next.Email.To, addr)
+			}
+		}
+		next.Webhook = strings.TrimSpace(webhookEntry.Text)
+		if err := next.Validate(); err != nil {
+			dialog.ShowError(fmt.Errorf("Please finish setting up the chosen backends: %w", err), win)
+			return
+		}
+		saveAlertSettings(a, next)
+	}, win)
+}
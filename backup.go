@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+)
+
+// showBackupDialog saves the portfolio to a file and remembers it as the
+// merge base for a later restore
+func showBackupDialog(a fyne.App, win fyne.Window, pf *portfolio.Portfolio) {
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+		if err := pf.Save(w); err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if err := savePortfolioFile(a, mergeBaseFile, pf); err != nil {
+			fyne.LogError("Failed to save merge base", err)
+		}
+	}, win)
+	save.SetFileName("portfolio-" + time.Now().Format("2006-01-02") + ".json")
+	save.Show()
+}
+
+// showRestoreDialog merges a backup into the portfolio. Records changed on
+// only one side merge automatically; records changed on both are shown side
+// by side for the user to choose, rather than letting either copy win.
+func showRestoreDialog(a fyne.App, win fyne.Window, pf *portfolio.Portfolio, onChanged func()) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+		remote, err := portfolio.Load(r)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+
+		result := portfolio.Merge3(loadPortfolioFile(a, mergeBaseFile), pf, remote)
+		apply := func() {
+			merged, err := result.Final()
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			*pf = *merged
+			if err := savePortfolioFile(a, mergeBaseFile, pf); err != nil {
+				fyne.LogError("Failed to save merge base", err)
+			}
+			onChanged()
+		}
+		if len(result.Conflicts) == 0 {
+			apply()
+			dialog.ShowInformation("Restore Backup", "The backup merged without conflicts.", win)
+			return
+		}
+		showMergeDialog(win, &result, apply)
+	}, win)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
+
+// mergeChoices are the radio labels for each side of a conflict
+var mergeChoices = []struct {
+	label string
+	side  portfolio.Side
+}{
+	{"Keep This Device", portfolio.SideLocal},
+	{"Take Backup", portfolio.SideRemote},
+	{"Revert to Base", portfolio.SideBase},
+}
+
+// showMergeDialog lists every conflict with its base, local, and remote
+// versions and calls apply once each has a choice
+func showMergeDialog(win fyne.Window, result *portfolio.MergeResult, apply func()) {
+	labels := make([]string, len(mergeChoices))
+	for i, c := range mergeChoices {
+		labels[i] = c.label
+	}
+
+	rows := container.NewVBox()
+	groups := make([]*widget.RadioGroup, len(result.Conflicts))
+	for i, c := range result.Conflicts {
+		versions := container.NewGridWithColumns(3,
+			mergeVersion("Base", c.Base),
+			mergeVersion("This Device", c.Local),
+			mergeVersion("Backup", c.Remote),
+		)
+		groups[i] = widget.NewRadioGroup(labels, nil)
+		groups[i].Horizontal = true
+		groups[i].Required = true
+		groups[i].SetSelected(labels[0])
+		rows.Add(widget.NewCard(fmt.Sprintf("%s %s", c.Kind, c.ID), "", container.NewVBox(versions, groups[i])))
+	}
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(640, 360))
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("%d records were changed both here and in the backup.", len(result.Conflicts))),
+		nil, nil, nil, scroll)
+	dialog.ShowCustomConfirm("Merge Backup", "Merge", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		for i, g := range groups {
+			for _, c := range mergeChoices {
+				if c.label == g.Selected {
+					result.Resolve(i, c.side)
+				}
+			}
+		}
+		apply()
+	}, win)
+}
+
+// mergeVersion describes one side of a conflict
+func mergeVersion(title string, v any) fyne.CanvasObject {
+	text := "(deleted)"
+	switch r := v.(type) {
+	case *portfolio.Lot:
+		if r != nil {
+			text = fmt.Sprintf("%s %.4f sh\n@ $%.2f on %s\n%s", r.Symbol, r.Shares, r.CostBasis,
+				r.Acquired.Format("2006-01-02"), r.Source)
+		}
+	case *portfolio.Grant:
+		if r != nil {
+			text = fmt.Sprintf("%s %s\n%.0f shares", r.Symbol, r.Kind, r.Schedule.TotalShares)
+		}
+	case *portfolio.Valuation:
+		if r != nil {
+			text = fmt.Sprintf("%s $%.2f\nfrom %s", r.Source, r.Price, r.Effective.Format("2006-01-02"))
+		}
+	case *portfolio.Snapshot:
+		if r != nil {
+			text = fmt.Sprintf("%s\n%.0f sh worth $%.2f", r.Date.Format("2006-01-02"), r.Shares, r.Value)
+		}
+	}
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+	return container.NewVBox(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), label)
+}
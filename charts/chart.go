@@ -0,0 +1,178 @@
+// Package charts renders calculation results as images without a Fyne window,
+// for the CLI, server, and exported reports.
+package charts
+
+import (
+	"fmt"
+
+	"fynance/portfolio"
+	"fynance/stc"
+)
+
+// Kind selects which chart to render
+type Kind string
+
+const (
+	Pie         Kind = "pie"         // Where the vested or exercised value goes
+	Waterfall   Kind = "waterfall"   // Gross value stepping down to net value
+	Sensitivity Kind = "sensitivity" // Residual cash across a range of prices
+	Heatmap     Kind = "heatmap"     // One value across two varied inputs
+	TimeSeries  Kind = "timeseries"  // Several amounts tracked over dates
+)
+
+// Kinds lists every supported chart
+var Kinds = []Kind{Pie, Waterfall, Sensitivity, Heatmap, TimeSeries}
+
+// Segment is one labelled amount
+type Segment struct {
+	Label string
+	Value float64
+}
+
+// Point is one (x, y) sample
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Data is the chart-ready view of a single calculation
+type Data struct {
+	Title  string
+	Slices []Segment // Pie: how the gross value is split
+	Steps  []Segment // Waterfall: the gross value followed by signed changes
+
+	Sensitivity []Point // Residual at each swept price
+	XLabel      string
+	YLabel      string
+
+	Grid *Grid // Heatmap cells, e.g. from FromMatrix
+
+	Series []Series // TimeSeries lines, e.g. from FromSnapshots
+}
+
+// Series is one named line of a time series; each X is a Unix time in seconds
+type Series struct {
+	Label  string
+	Points []Point
+}
+
+// Grid is a table of values with labelled rows and columns
+type Grid struct {
+	Rows    []string
+	Columns []string
+	Values  [][]float64 // Values[row][column]
+}
+
+// sweepSteps and sweepRange control the sensitivity sweep: ±30% in 5% steps
+const (
+	sweepSteps = 13
+	sweepRange = 0.30
+)
+
+// FromResult runs an options calculation and prepares it for charting
+func FromResult(c *stc.Calculator, in stc.Input) Data {
+	r := c.Calculate(in)
+	d := Data{
+		Title: fmt.Sprintf("Exercise of %.0f shares @ $%.2f", r.ExercisedShares, r.FMV),
+		Slices: []Segment{
+			{"Option Cost", r.OptionCost},
+			{"Taxes", r.TotalTax},
+			{"Broker Fees", r.BrokerFees},
+			{"SEC/FINRA Fees", r.SECFee + r.TAF},
+			{"Residual Cash", r.Residual},
+			{"Kept Shares", r.NetShares * r.FMV},
+		},
+		Steps:  waterfallSteps(r.Graph(), r.ExercisedShares*r.FMV, "optionCost", "totalTax", "brokerFees", "secFee", "taf"),
+		XLabel: "FMV ($)",
+		YLabel: "Residual ($)",
+	}
+	d.Sensitivity = sweep(in.FMV, func(price float64) float64 {
+		in.FMV = price
+		return c.Calculate(in).Residual
+	})
+	return d
+}
+
+// FromRSUResult runs an RSU calculation and prepares it for charting
+func FromRSUResult(c *stc.Calculator, in stc.RSUInput) Data {
+	r := c.CalculateRSU(in)
+	d := Data{
+		Title: fmt.Sprintf("Release of %.0f shares @ $%.2f", r.SharesReleased, r.SalePrice),
+		Slices: []Segment{
+			{"Taxes", r.TotalTax},
+			{"Fees", r.TotalFees},
+			{"Residual Cash", r.Residual},
+			{"Kept Shares", r.NetShares * r.SalePrice},
+		},
+		Steps:  waterfallSteps(r.Graph(), (r.SharesReleased+r.DividendEquivalentShares)*r.SalePrice, "totalTax", "totalFees"),
+		XLabel: "Sale Price ($)",
+		YLabel: "Residual ($)",
+	}
+	d.Sensitivity = sweep(in.SalePrice, func(price float64) float64 {
+		in.SalePrice = price
+		return c.CalculateRSU(in).Residual
+	})
+	return d
+}
+
+// waterfallSteps starts from the gross value, subtracts each cost node of the
+// calculation graph, and adds back any cash top-up
+func waterfallSteps(g stc.Graph, gross float64, costIDs ...string) []Segment {
+	steps := []Segment{{"Gross Value", gross}}
+	for _, id := range costIDs {
+		if n, ok := g.Node(id); ok {
+			steps = append(steps, Segment{n.Label, -n.Value})
+		}
+	}
+	if n, ok := g.Node("cashTopUp"); ok && n.Value > 0 {
+		steps = append(steps, Segment{n.Label, n.Value})
+	}
+	return steps
+}
+
+// sweep samples residual at prices around the base price
+func sweep(base float64, residualAt func(price float64) float64) []Point {
+	if base <= 0 {
+		return nil
+	}
+	points := make([]Point, 0, sweepSteps)
+	for i := 0; i < sweepSteps; i++ {
+		f := 1 - sweepRange + 2*sweepRange*float64(i)/float64(sweepSteps-1)
+		price := base * f
+		points = append(points, Point{X: price, Y: residualAt(price)})
+	}
+	return points
+}
+
+// FromMatrix prepares one value of every matrix result for a heatmap. Rows
+// follow the matrix configs and columns follow its inputs.
+func FromMatrix(m stc.MatrixResult, title string, rowLabel func(stc.Config) string,
+	columnLabel func(stc.Input) string, value func(stc.Result) float64) Data {
+	g := &Grid{Values: make([][]float64, len(m.Configs))}
+	for j, cfg := range m.Configs {
+		g.Rows = append(g.Rows, rowLabel(cfg))
+		g.Values[j] = make([]float64, len(m.Inputs))
+		for i := range m.Inputs {
+			g.Values[j][i] = value(m.At(i, j))
+		}
+	}
+	for _, in := range m.Inputs {
+		g.Columns = append(g.Columns, columnLabel(in))
+	}
+	return Data{Title: title, Grid: g}
+}
+
+// FromSnapshots charts the position value against the cumulative taxes paid
+// and cash extracted at each recorded snapshot
+func FromSnapshots(snapshots []portfolio.Snapshot) Data {
+	value := Series{Label: "Position Value"}
+	taxes := Series{Label: "Taxes Paid"}
+	cash := Series{Label: "Cash Extracted"}
+	for _, s := range snapshots {
+		x := float64(s.Date.Unix())
+		value.Points = append(value.Points, Point{X: x, Y: s.Value})
+		taxes.Points = append(taxes.Points, Point{X: x, Y: s.TaxesPaid})
+		cash.Points = append(cash.Points, Point{X: x, Y: s.CashExtracted})
+	}
+	return Data{Title: "Net Worth Over Time", Series: []Series{value, taxes, cash}}
+}
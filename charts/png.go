@@ -0,0 +1,93 @@
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPNG draws a chart to PNG at the given pixel size
+func RenderPNG(d Data, kind Kind, size image.Point) ([]byte, error) {
+	s, err := layout(d, kind, size)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, s.Width, s.Height))
+	for _, sh := range s.Shapes {
+		switch sh := sh.(type) {
+		case rect:
+			r := image.Rect(int(sh.X), int(sh.Y), int(math.Ceil(sh.X+sh.W)), int(math.Ceil(sh.Y+sh.H)))
+			draw.Draw(img, r, image.NewUniform(sh.Fill), image.Point{}, draw.Src)
+		case line:
+			drawLine(img, sh)
+		case wedge:
+			drawWedge(img, sh)
+		case label:
+			drawLabel(img, sh)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine stamps a square brush along the line
+func drawLine(img *image.RGBA, l line) {
+	dx, dy := l.X2-l.X1, l.Y2-l.Y1
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy))) + 1
+	half := math.Max(l.Width, 1) / 2
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x, y := l.X1+dx*t, l.Y1+dy*t
+		r := image.Rect(int(x-half+0.5), int(y-half+0.5), int(x+half+0.5), int(y+half+0.5))
+		draw.Draw(img, r, image.NewUniform(l.Stroke), image.Point{}, draw.Src)
+	}
+}
+
+// drawWedge fills every pixel of the circle whose angle falls inside the slice
+func drawWedge(img *image.RGBA, w wedge) {
+	bounds := image.Rect(int(w.CX-w.R), int(w.CY-w.R), int(w.CX+w.R)+1, int(w.CY+w.R)+1).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dx, dy := float64(x)+0.5-w.CX, float64(y)+0.5-w.CY
+			if dx*dx+dy*dy > w.R*w.R {
+				continue
+			}
+			a := math.Atan2(dx, -dy) // Clockwise from 12 o'clock
+			if a < 0 {
+				a += 2 * math.Pi
+			}
+			if a >= w.Start && a < w.End {
+				img.SetRGBA(x, y, w.Fill)
+			}
+		}
+	}
+}
+
+func drawLabel(img *image.RGBA, l label) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(l.Fill),
+		Face: basicfont.Face7x13,
+	}
+	x := l.X
+	switch l.Anchor {
+	case anchorMiddle:
+		x -= float64(d.MeasureString(l.Text).Round()) / 2
+	case anchorEnd:
+		x -= float64(d.MeasureString(l.Text).Round())
+	}
+	d.Dot = fixed.P(int(x), int(l.Y))
+	d.DrawString(l.Text)
+}
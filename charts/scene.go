@@ -0,0 +1,404 @@
+package charts
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+)
+
+// scene is a backend-neutral list of shapes in pixel coordinates.
+// Layout happens once here; each output format only has to draw shapes.
+type scene struct {
+	Width  int
+	Height int
+	Shapes []shape
+}
+
+type shape interface{ isShape() }
+
+type rect struct {
+	X, Y, W, H float64
+	Fill       color.RGBA
+}
+
+type line struct {
+	X1, Y1, X2, Y2 float64
+	Width          float64
+	Stroke         color.RGBA
+}
+
+// wedge is a pie slice; angles are radians clockwise from 12 o'clock
+type wedge struct {
+	CX, CY, R  float64
+	Start, End float64
+	Fill       color.RGBA
+}
+
+// anchor aligns text horizontally around its X position
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// label is a line of text; Y is the baseline
+type label struct {
+	X, Y   float64
+	Text   string
+	Anchor anchor
+	Fill   color.RGBA
+}
+
+func (rect) isShape()  {}
+func (line) isShape()  {}
+func (wedge) isShape() {}
+func (label) isShape() {}
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	foreground = color.RGBA{0x21, 0x21, 0x21, 0xff}
+	gridColor  = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	positive   = color.RGBA{0x43, 0xa0, 0x47, 0xff}
+	negative   = color.RGBA{0xe5, 0x39, 0x35, 0xff}
+	total      = color.RGBA{0x1e, 0x88, 0xe5, 0xff}
+
+	palette = []color.RGBA{
+		{0x1e, 0x88, 0xe5, 0xff},
+		{0xe5, 0x39, 0x35, 0xff},
+		{0xfb, 0x8c, 0x00, 0xff},
+		{0x43, 0xa0, 0x47, 0xff},
+		{0x8e, 0x24, 0xaa, 0xff},
+		{0x00, 0xac, 0xc1, 0xff},
+	}
+)
+
+// Layout metrics shared by every chart
+const (
+	margin     = 16.0
+	titleSize  = 24.0 // Height reserved for the title
+	lineHeight = 16.0
+	charWidth  = 7.0 // Approximate advance of the label font
+	axisGutter = 72.0
+)
+
+// layout arranges a chart into shapes
+func layout(d Data, kind Kind, size image.Point) (*scene, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("invalid chart size %dx%d", size.X, size.Y)
+	}
+	s := &scene{Width: size.X, Height: size.Y}
+	s.add(rect{0, 0, float64(size.X), float64(size.Y), background})
+	s.add(label{float64(size.X) / 2, margin + lineHeight/2, d.Title, anchorMiddle, foreground})
+
+	switch kind {
+	case Pie:
+		s.pie(d.Slices)
+	case Waterfall:
+		s.waterfall(d.Steps)
+	case Sensitivity:
+		s.sensitivity(d.Sensitivity, d.XLabel, d.YLabel)
+	case Heatmap:
+		s.heatmap(d.Grid, d.XLabel, d.YLabel)
+	case TimeSeries:
+		s.timeSeries(d.Series)
+	default:
+		return nil, fmt.Errorf("unknown chart kind %q", kind)
+	}
+	return s, nil
+}
+
+func (s *scene) add(sh shape) {
+	s.Shapes = append(s.Shapes, sh)
+}
+
+// pie draws positive slices with a legend on the right
+func (s *scene) pie(slices []Segment) {
+	sum := 0.0
+	for _, sl := range slices {
+		if sl.Value > 0 {
+			sum += sl.Value
+		}
+	}
+	if sum == 0 {
+		s.empty()
+		return
+	}
+
+	legendWidth := 0.0
+	for _, sl := range slices {
+		legendWidth = math.Max(legendWidth, float64(len(legendText(sl, sum)))*charWidth)
+	}
+	legendWidth += lineHeight + margin
+
+	top := margin + titleSize
+	w := float64(s.Width) - 2*margin - legendWidth
+	h := float64(s.Height) - top - margin
+	r := math.Max(math.Min(w, h)/2, 1)
+	cx, cy := margin+r, top+h/2
+
+	angle := 0.0
+	legendY := cy - float64(len(slices))*lineHeight/2
+	for i, sl := range slices {
+		if sl.Value <= 0 {
+			continue
+		}
+		c := palette[i%len(palette)]
+		sweep := 2 * math.Pi * sl.Value / sum
+		s.add(wedge{cx, cy, r, angle, angle + sweep, c})
+		angle += sweep
+
+		x := cx + r + margin
+		s.add(rect{x, legendY + 3, lineHeight - 6, lineHeight - 6, c})
+		s.add(label{x + lineHeight, legendY + lineHeight - 4, legendText(sl, sum), anchorStart, foreground})
+		legendY += lineHeight
+	}
+}
+
+func legendText(sl Segment, sum float64) string {
+	return fmt.Sprintf("%s %s (%.0f%%)", sl.Label, formatMoney(sl.Value), 100*sl.Value/sum)
+}
+
+// waterfall draws the first step as a full bar, each later step as a floating
+// bar from the running total, and a final net bar
+func (s *scene) waterfall(steps []Segment) {
+	if len(steps) == 0 {
+		s.empty()
+		return
+	}
+
+	running, lo, hi := 0.0, 0.0, 0.0
+	for _, st := range steps {
+		running += st.Value
+		lo, hi = math.Min(lo, running), math.Max(hi, running)
+	}
+	p := s.plot(lo, hi)
+
+	n := float64(len(steps) + 1)
+	slot := p.w / n
+	barW := slot * 0.6
+
+	running = 0
+	for i, st := range steps {
+		from, to := running, running+st.Value
+		fill := positive
+		switch {
+		case i == 0:
+			fill = total
+		case st.Value < 0:
+			fill = negative
+		}
+		x := p.x + slot*float64(i) + (slot-barW)/2
+		s.bar(p, x, barW, from, to, fill)
+		s.add(label{x + barW/2, p.y + p.h + lineHeight, st.Label, anchorMiddle, foreground})
+		running = to
+	}
+
+	x := p.x + slot*float64(len(steps)) + (slot-barW)/2
+	s.bar(p, x, barW, 0, running, total)
+	s.add(label{x + barW/2, p.y + p.h + lineHeight, "Net Value", anchorMiddle, foreground})
+	s.add(label{x + barW/2, p.yAt(math.Max(running, 0)) - 4, formatMoney(running), anchorMiddle, foreground})
+}
+
+func (s *scene) bar(p plotArea, x, w, from, to float64, fill color.RGBA) {
+	y1, y2 := p.yAt(from), p.yAt(to)
+	top, h := math.Min(y1, y2), math.Abs(y1-y2)
+	s.add(rect{x, top, w, math.Max(h, 1), fill})
+}
+
+// sensitivity draws residual against price as a line
+func (s *scene) sensitivity(points []Point, xLabel, yLabel string) {
+	if len(points) < 2 {
+		s.empty()
+		return
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, pt := range points {
+		lo, hi = math.Min(lo, pt.Y), math.Max(hi, pt.Y)
+	}
+	p := s.plot(lo, hi)
+
+	xMin, xMax := points[0].X, points[len(points)-1].X
+	xAt := func(x float64) float64 { return p.x + (x-xMin)/(xMax-xMin)*p.w }
+
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		s.add(line{xAt(a.X), p.yAt(a.Y), xAt(b.X), p.yAt(b.Y), 2, total})
+	}
+
+	base := p.y + p.h + lineHeight
+	s.add(label{p.x, base, fmt.Sprintf("$%.2f", xMin), anchorStart, foreground})
+	s.add(label{p.x + p.w, base, fmt.Sprintf("$%.2f", xMax), anchorEnd, foreground})
+	s.add(label{p.x + p.w/2, base + lineHeight, xLabel, anchorMiddle, foreground})
+	s.add(label{margin, margin + titleSize + lineHeight, yLabel, anchorStart, foreground})
+}
+
+// timeSeries draws each series as a line across dates, with a legend above
+// the plot. A lone snapshot is drawn as a marker.
+func (s *scene) timeSeries(series []Series) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for _, sr := range series {
+		for _, pt := range sr.Points {
+			lo, hi = math.Min(lo, pt.Y), math.Max(hi, pt.Y)
+			xMin, xMax = math.Min(xMin, pt.X), math.Max(xMax, pt.X)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		s.empty()
+		return
+	}
+	p := s.plot(math.Min(lo, 0), hi)
+
+	xAt := func(x float64) float64 {
+		if xMax == xMin {
+			return p.x + p.w/2
+		}
+		return p.x + (x-xMin)/(xMax-xMin)*p.w
+	}
+
+	legendX := p.x
+	legendY := margin + titleSize
+	for i, sr := range series {
+		c := palette[i%len(palette)]
+		for j, pt := range sr.Points {
+			if j > 0 {
+				a := sr.Points[j-1]
+				s.add(line{xAt(a.X), p.yAt(a.Y), xAt(pt.X), p.yAt(pt.Y), 2, c})
+			}
+			if len(sr.Points) == 1 {
+				s.add(rect{xAt(pt.X) - 3, p.yAt(pt.Y) - 3, 6, 6, c})
+			}
+		}
+		s.add(rect{legendX, legendY + 3, lineHeight - 6, lineHeight - 6, c})
+		s.add(label{legendX + lineHeight, legendY + lineHeight - 4, sr.Label, anchorStart, foreground})
+		legendX += lineHeight + float64(len(sr.Label))*charWidth + margin
+	}
+
+	base := p.y + p.h + lineHeight
+	date := func(x float64) string { return time.Unix(int64(x), 0).UTC().Format("2006-01-02") }
+	if xMax == xMin {
+		s.add(label{p.x + p.w/2, base, date(xMin), anchorMiddle, foreground})
+		return
+	}
+	s.add(label{p.x, base, date(xMin), anchorStart, foreground})
+	s.add(label{p.x + p.w, base, date(xMax), anchorEnd, foreground})
+}
+
+// heatmap draws one cell per grid value, shaded from red (lowest) through
+// yellow to green (highest)
+func (s *scene) heatmap(g *Grid, xLabel, yLabel string) {
+	if g == nil || len(g.Rows) == 0 || len(g.Columns) == 0 {
+		s.empty()
+		return
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range g.Values {
+		for _, v := range row {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	x0 := margin + axisGutter
+	y0 := margin + titleSize + lineHeight
+	cellW := (float64(s.Width) - x0 - margin) / float64(len(g.Columns))
+	cellH := (float64(s.Height) - y0 - margin - lineHeight*2) / float64(len(g.Rows))
+
+	for r, row := range g.Values {
+		y := y0 + cellH*float64(r)
+		s.add(label{x0 - 4, y + cellH/2 + 4, g.Rows[r], anchorEnd, foreground})
+		for c, v := range row {
+			x := x0 + cellW*float64(c)
+			s.add(rect{x, y, cellW - 1, cellH - 1, heatColor(v, lo, hi)})
+			if text := formatMoney(v); float64(len(text))*charWidth < cellW-4 {
+				s.add(label{x + cellW/2, y + cellH/2 + 4, text, anchorMiddle, foreground})
+			}
+		}
+	}
+
+	base := y0 + cellH*float64(len(g.Rows)) + lineHeight
+	for c, col := range g.Columns {
+		s.add(label{x0 + cellW*(float64(c)+0.5), base, col, anchorMiddle, foreground})
+	}
+	s.add(label{x0 + cellW*float64(len(g.Columns))/2, base + lineHeight, xLabel, anchorMiddle, foreground})
+	s.add(label{margin, y0 - 4, yLabel, anchorStart, foreground})
+}
+
+// heatColor interpolates red → yellow → green across [lo, hi]
+func heatColor(v, lo, hi float64) color.RGBA {
+	t := 0.5
+	if hi > lo {
+		t = (v - lo) / (hi - lo)
+	}
+	mix := func(a, b uint8, f float64) uint8 { return uint8(float64(a) + (float64(b)-float64(a))*f) }
+	yellow := color.RGBA{0xfd, 0xd8, 0x35, 0xff}
+	if t < 0.5 {
+		f := t * 2
+		return color.RGBA{mix(negative.R, yellow.R, f), mix(negative.G, yellow.G, f), mix(negative.B, yellow.B, f), 0xff}
+	}
+	f := (t - 0.5) * 2
+	return color.RGBA{mix(yellow.R, positive.R, f), mix(yellow.G, positive.G, f), mix(yellow.B, positive.B, f), 0xff}
+}
+
+// plotArea maps values onto the region inside the axes
+type plotArea struct {
+	x, y, w, h float64
+	lo, hi     float64
+}
+
+func (p plotArea) yAt(v float64) float64 {
+	return p.y + p.h - (v-p.lo)/(p.hi-p.lo)*p.h
+}
+
+// plot reserves room for the title and axes, then draws gridlines, value
+// labels, and the axes for the value range [lo, hi]
+func (s *scene) plot(lo, hi float64) plotArea {
+	if hi == lo {
+		hi = lo + 1
+	}
+	pad := (hi - lo) * 0.05
+	p := plotArea{
+		x:  margin + axisGutter,
+		y:  margin + titleSize + lineHeight*2,
+		lo: lo - pad,
+		hi: hi + pad,
+	}
+	p.w = float64(s.Width) - p.x - margin
+	p.h = float64(s.Height) - p.y - margin - lineHeight*2
+
+	const ticks = 4
+	for i := 0; i <= ticks; i++ {
+		v := p.lo + (p.hi-p.lo)*float64(i)/ticks
+		y := p.yAt(v)
+		s.add(line{p.x, y, p.x + p.w, y, 1, gridColor})
+		s.add(label{p.x - 4, y + 4, formatMoney(v), anchorEnd, foreground})
+	}
+	if p.lo < 0 && p.hi > 0 {
+		s.add(line{p.x, p.yAt(0), p.x + p.w, p.yAt(0), 1, foreground})
+	}
+	s.add(line{p.x, p.y, p.x, p.y + p.h, 1, foreground})
+	s.add(line{p.x, p.y + p.h, p.x + p.w, p.y + p.h, 1, foreground})
+	return p
+}
+
+func (s *scene) empty() {
+	s.add(label{float64(s.Width) / 2, float64(s.Height) / 2, "No data", anchorMiddle, foreground})
+}
+
+// formatMoney renders whole dollars, e.g. $12,345 or -$1,200
+func formatMoney(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	digits := fmt.Sprintf("%.0f", v)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+	return sign + "$" + digits
+}
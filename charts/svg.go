@@ -0,0 +1,118 @@
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"text/template"
+)
+
+// svgTemplate draws a laid-out scene; escaping of label text is done by the
+// "text" function since text/template does not know about XML
+var svgTemplate = template.Must(template.New("svg").Funcs(template.FuncMap{
+	"hex":    hexColor,
+	"text":   escapeXML,
+	"anchor": svgAnchor,
+	"arc":    arcPath,
+	"num":    func(v float64) string { return fmt.Sprintf("%.1f", v) },
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" font-family="monospace" font-size="12">
+{{- range .Shapes}}
+{{- with .Rect}}
+<rect x="{{num .X}}" y="{{num .Y}}" width="{{num .W}}" height="{{num .H}}" fill="{{hex .Fill}}"/>
+{{- end}}
+{{- with .Line}}
+<line x1="{{num .X1}}" y1="{{num .Y1}}" x2="{{num .X2}}" y2="{{num .Y2}}" stroke="{{hex .Stroke}}" stroke-width="{{num .Width}}"/>
+{{- end}}
+{{- with .Wedge}}
+<path d="{{arc .}}" fill="{{hex .Fill}}"/>
+{{- end}}
+{{- with .Label}}
+<text x="{{num .X}}" y="{{num .Y}}" text-anchor="{{anchor .Anchor}}" fill="{{hex .Fill}}">{{text .Text}}</text>
+{{- end}}
+{{- end}}
+</svg>
+`))
+
+// svgShape exposes one shape to the template under its type's name
+type svgShape struct {
+	Rect  *rect
+	Line  *line
+	Wedge *wedge
+	Label *label
+}
+
+// RenderSVG draws a chart as a scalable SVG document
+func RenderSVG(d Data, kind Kind, size image.Point) ([]byte, error) {
+	s, err := layout(d, kind, size)
+	if err != nil {
+		return nil, err
+	}
+
+	view := struct {
+		Width, Height int
+		Shapes        []svgShape
+	}{Width: s.Width, Height: s.Height}
+	for _, sh := range s.Shapes {
+		switch sh := sh.(type) {
+		case rect:
+			view.Shapes = append(view.Shapes, svgShape{Rect: &sh})
+		case line:
+			view.Shapes = append(view.Shapes, svgShape{Line: &sh})
+		case wedge:
+			view.Shapes = append(view.Shapes, svgShape{Wedge: &sh})
+		case label:
+			view.Shapes = append(view.Shapes, svgShape{Label: &sh})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := svgTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgAnchor(a anchor) string {
+	switch a {
+	case anchorMiddle:
+		return "middle"
+	case anchorEnd:
+		return "end"
+	}
+	return "start"
+}
+
+// arcPath builds a pie slice path; a full circle needs two arcs since SVG
+// cannot draw one whose start and end points coincide
+func arcPath(w wedge) string {
+	point := func(a float64) (float64, float64) {
+		return w.CX + w.R*math.Sin(a), w.CY - w.R*math.Cos(a)
+	}
+	if w.End-w.Start >= 2*math.Pi-1e-9 {
+		x1, y1 := point(0)
+		x2, y2 := point(math.Pi)
+		return fmt.Sprintf("M%.1f,%.1f A%.1f,%.1f 0 1 1 %.1f,%.1f A%.1f,%.1f 0 1 1 %.1f,%.1f Z",
+			x1, y1, w.R, w.R, x2, y2, w.R, w.R, x1, y1)
+	}
+
+	large := 0
+	if w.End-w.Start > math.Pi {
+		large = 1
+	}
+	x1, y1 := point(w.Start)
+	x2, y2 := point(w.End)
+	return fmt.Sprintf("M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 %d 1 %.1f,%.1f Z",
+		w.CX, w.CY, x1, y1, w.R, w.R, large, x2, y2)
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	template.HTMLEscape(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command is a headless subcommand, e.g. "fynance stress --n 1e6". It returns the exit code.
+type command func(args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"compare":   runCompare,
+	"recompute": runRecompute,
+	"remit":     runRemit,
+	"render":    runRender,
+	"schema":    runSchema,
+	"scorecard": runScorecard,
+	"stress":    runStress,
+}
+
+// runCommand dispatches os.Args to a subcommand. ok is false when the first
+// argument is not a command, in which case the GUI should start.
+func runCommand(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		printCommands(os.Stdout)
+		return 0, true
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return cmd(args[1:], os.Stdout, os.Stderr), true
+}
+
+// printCommands lists the available subcommands
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "Usage: fynance [command] [flags]")
+	fmt.Fprintln(w, "Without a command the calculator window opens. Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+)
+
+// showCompareDialog shows what changed between two of this session's
+// calculations of the same kind, e.g. before and after a change of rate
+func showCompareDialog(win fyne.Window) {
+	if len(history) < 2 {
+		dialog.ShowInformation("Compare Calculations", "Run at least two calculations to compare them.", win)
+		return
+	}
+	titles := make([]string, len(history))
+	for i, e := range history {
+		titles[i] = fmt.Sprintf("%d. %s", i+1, e.Title)
+	}
+	beforeSelect := widget.NewSelect(titles, nil)
+	afterSelect := widget.NewSelect(titles, nil)
+	beforeSelect.SetSelectedIndex(len(titles) - 2)
+	afterSelect.SetSelectedIndex(len(titles) - 1)
+	form := widget.NewForm(
+		widget.NewFormItem("Before", beforeSelect),
+		widget.NewFormItem("After", afterSelect),
+	)
+	preview := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	run := func() {
+		a, b := beforeSelect.SelectedIndex(), afterSelect.SelectedIndex()
+		if a < 0 || b < 0 {
+			return
+		}
+		diff, ok := stc.CompareEntries(*history[a], *history[b])
+		if !ok {
+			preview.SetText("Only calculations of the same kind compare: two exercises or two releases.")
+			return
+		}
+		preview.SetText(diff.String())
+	}
+	beforeSelect.OnChanged = func(string) { run() }
+	afterSelect.OnChanged = func(string) { run() }
+
+	scroll := container.NewVScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(520, 320))
+	run()
+	dialog.ShowCustom("Compare Calculations", "Close", container.NewBorder(form, nil, nil, nil, scroll), win)
+}
+
+// runCompare calculates two saved scenarios and prints what changed from the
+// first to the second, e.g. "fynance compare before.json after.json"
+func runCompare(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text (the changes) or json (every field)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance compare [--format json] before.json after.json")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "compare: unknown format %q\n", *format)
+		return 2
+	}
+
+	var entries [2]stc.SessionEntry
+	for i, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "compare: failed to read scenario: %v\n", err)
+			return 1
+		}
+		scenario, err := stc.UnmarshalScenario(data)
+		if err != nil {
+			fmt.Fprintf(stderr, "compare: %s: %v\n", path, err)
+			return 1
+		}
+		if entries[i], err = scenario.Run(); err != nil {
+			fmt.Fprintf(stderr, "compare: %s: %v\n", path, err)
+			return 1
+		}
+	}
+	diff, ok := stc.CompareEntries(entries[0], entries[1])
+	if !ok {
+		fmt.Fprintln(stderr, "compare: cannot compare an exercise with a release")
+		return 1
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "compare: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+	fmt.Fprintln(stdout, diff.String())
+	return 0
+}
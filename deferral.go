@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// deferralRiskNote is shown with every deferral comparison
+const deferralRiskNote = "Deferred shares are an unsecured promise: if the company fails before settlement " +
+	"they can be lost, and the FICA paid at vest is not refunded. The election is usually irrevocable " +
+	"and must be made before the service year; check the plan's rules."
+
+// showDeferralComparison compares settling an RSU release at vest with
+// deferring settlement. Rates come from base; settlement-year federal and
+// state rates may differ, e.g. after retiring or moving.
+func showDeferralComparison(win fyne.Window, base stc.Config) {
+	sharesEntry := widgets.NewSmartEntry("1000")
+	vestDateEntry := widgets.NewSmartEntry(time.Now().Format("2006-01-02"))
+	priceEntry := widgets.NewSmartEntry("50.00")
+	growthEntry := widgets.NewSmartEntry("0.08")
+	yearsEntry := widgets.NewSmartEntry("5")
+	incomeEntry := widgets.NewSmartEntry("0.00")
+	settleIncomeEntry := widgets.NewSmartEntry("0.00")
+	settleFederalEntry := widgets.NewSmartEntry("")
+	settleFederalEntry.SetPlaceHolder(fmt.Sprintf("%g (current)", base.TaxRates.Federal))
+	settleStateEntry := widgets.NewSmartEntry("")
+	settleStateEntry.SetPlaceHolder(fmt.Sprintf("%g (current)", base.TaxRates.State))
+	capGainsEntry := widgets.NewSmartEntry("0.15")
+
+	form := widget.NewForm(
+		widget.NewFormItem("Shares", sharesEntry),
+		widget.NewFormItem("Vest Date", vestDateEntry),
+		widget.NewFormItem("Vest Price ($)", priceEntry),
+		widget.NewFormItem("Annual Growth", growthEntry),
+		widget.NewFormItem("Defer Years", yearsEntry),
+		widget.NewFormItem("Other Income Now ($)", incomeEntry),
+		widget.NewFormItem("Other Income Later ($)", settleIncomeEntry),
+		widget.NewFormItem("Federal Rate Later", settleFederalEntry),
+		widget.NewFormItem("State Rate Later", settleStateEntry),
+		widget.NewFormItem("Cap Gains Rate", capGainsEntry),
+	)
+
+	table := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	risk := widget.NewLabel(deferralRiskNote)
+	risk.Wrapping = fyne.TextWrapWord
+	risk.Importance = widget.WarningImportance
+
+	run := func() {
+		shares, err1 := parseFloat(sharesEntry.Text)
+		price, err2 := parseFloat(priceEntry.Text)
+		growth, err3 := parseFloat(growthEntry.Text)
+		years, err4 := parseFloat(yearsEntry.Text)
+		income, err5 := parseFloat(incomeEntry.Text)
+		settleIncome, err6 := parseFloat(settleIncomeEntry.Text)
+		capGains, err7 := parseFloat(capGainsEntry.Text)
+		vestDate, err8 := parseDate(vestDateEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil || err8 != nil || vestDate.IsZero() {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers and a YYYY-MM-DD vest date"), win)
+			return
+		}
+		if shares <= 0 || price <= 0 || years < 1 || years != float64(int(years)) {
+			dialog.ShowError(fmt.Errorf("Shares and price must be greater than 0 and the deferral a whole number of years"), win)
+			return
+		}
+
+		// Blank settlement rates keep the current ones
+		later := base.TaxRates
+		for _, r := range []struct {
+			entry *widgets.SmartEntry
+			rate  *float64
+		}{{settleFederalEntry, &later.Federal}, {settleStateEntry, &later.State}} {
+			if strings.TrimSpace(r.entry.Text) == "" {
+				continue
+			}
+			v, err := parseFloat(r.entry.Text)
+			if err != nil || v < 0 || v > 1 {
+				dialog.ShowError(fmt.Errorf("Settlement rates must be fractions from 0 to 1"), win)
+				return
+			}
+			*r.rate = v
+		}
+		if strings.TrimSpace(settleStateEntry.Text) != "" {
+			// A flat state rate replaces any itemized jurisdictions
+			later.Jurisdictions = nil
+		}
+
+		cmp := stc.NewCalculator(base).CompareDeferral(stc.DeferralElection{
+			Shares:           shares,
+			VestDate:         vestDate,
+			Years:            int(years),
+			Growth:           stc.GrowthAssumption{StartPrice: price, AnnualGrowth: growth},
+			YTDIncome:        income,
+			SettlementIncome: settleIncome,
+			SettlementRates:  &later,
+			CapGainsRate:     capGains,
+		})
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Settle on %s at $%.2f (vest price $%.2f)\n\n", cmp.SettleDate.Format("2006-01-02"), cmp.SettlePrice, cmp.VestPrice)
+		fmt.Fprintf(&b, "%-26s %14s %14s\n", "", "Settle Now", "Defer")
+		// Settling now withholds FICA along with income tax
+		fmt.Fprintf(&b, "%-26s %14s %14.2f\n", "FICA paid in cash at vest", "-", cmp.DeferFICA)
+		fmt.Fprintf(&b, "%-26s %14.2f %14.2f\n", "Withheld at settlement", cmp.NowTax, cmp.DeferIncomeTax)
+		fmt.Fprintf(&b, "%-26s %14.2f %14.2f\n", "Capital gains on growth", cmp.NowGainTax, 0.0)
+		fmt.Fprintf(&b, "%-26s %14.0f %14.0f\n", "Net shares", cmp.NowNetShares, cmp.DeferNetShares)
+		fmt.Fprintf(&b, "%-26s %14.2f %14.2f\n", "After-tax value", cmp.NowAfterTax, cmp.DeferAfterTax)
+		verdict := "Deferring comes out ahead"
+		if cmp.Advantage < 0 {
+			verdict = "Settling now comes out ahead"
+		}
+		fmt.Fprintf(&b, "\n%s by $%.2f\n", verdict, math.Abs(cmp.Advantage))
+		table.SetText(b.String())
+	}
+
+	for _, e := range []*widgets.SmartEntry{sharesEntry, vestDateEntry, priceEntry, growthEntry, yearsEntry, incomeEntry,
+		settleIncomeEntry, settleFederalEntry, settleStateEntry, capGainsEntry} {
+		e.SetOnEnter(run)
+	}
+
+	scroll := container.NewVScroll(container.NewVBox(form, widget.NewButton("Compare", run), table, risk))
+	scroll.SetMinSize(fyne.NewSize(560, 480))
+	dialog.ShowCustom("Deferral Election", "Close", scroll, win)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/stc"
+)
+
+// demoFiles hold a made-up employee's grants, price history, broker
+// statement, and calculations, so the app can be explored without
+// entering personal data
+//
+//go:embed demo/*
+var demoFiles embed.FS
+
+// demoPrice is the sample company's current share price
+const demoPrice = 57.30
+
+// demoMode is set while the sample data replaces the user's own. Nothing
+// is saved to app storage while it is on.
+var demoMode bool
+
+// demoSavedHistory is the user's calculation history, put back when the demo ends
+var demoSavedHistory []*stc.SessionEntry
+
+// loadDemoPortfolio builds the sample portfolio: grants, valuations, and
+// price history from the embedded portfolio, plus lots imported from the
+// embedded broker statement the way a user would import their own
+func loadDemoPortfolio() (*portfolio.Portfolio, error) {
+	data, err := demoFiles.ReadFile("demo/portfolio.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample portfolio: %w", err)
+	}
+	pf, err := portfolio.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data, err = demoFiles.ReadFile("demo/statement.csv"); err != nil {
+		return nil, fmt.Errorf("failed to read sample statement: %w", err)
+	}
+	lots, err := portfolio.ReadLotsCSV(bytes.NewReader(data), taxHome())
+	if err != nil {
+		return nil, err
+	}
+	pf.Import(lots, portfolio.ImportSkip)
+	return pf, nil
+}
+
+// loadDemoSession reads the sample calculations
+func loadDemoSession() (stc.Session, error) {
+	data, err := demoFiles.ReadFile("demo/session.json")
+	if err != nil {
+		return stc.Session{}, fmt.Errorf("failed to read sample session: %w", err)
+	}
+	return stc.LoadSession(bytes.NewReader(data))
+}
+
+// setDemoMode swaps the sample data into every tab, or puts the user's own
+// portfolio and history back. The user's saved portfolio is never touched.
+func setDemoMode(a fyne.App, win fyne.Window, bus *events.Bus, pf *portfolio.Portfolio, on bool) {
+	if on == demoMode {
+		return
+	}
+	if !on {
+		demoMode = false
+		*pf = *loadPortfolio(a)
+		history = demoSavedHistory
+		demoSavedHistory = nil
+		bus.Publish(events.PortfolioChanged, nil)
+		return
+	}
+
+	demo, err := loadDemoPortfolio()
+	if err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	session, err := loadDemoSession()
+	if err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	demoMode = true
+	demoSavedHistory, history = history, nil
+	*pf = *demo
+	bus.Publish(events.PortfolioChanged, nil)
+	bus.Publish(events.PriceFetched, events.Price{Price: demoPrice})
+	for _, e := range session.Entries {
+		bus.Publish(events.EntryOpened, e)
+	}
+}
+
+// demoBlocks tells the user an action needs their own data and reports
+// whether the demo is running
+func demoBlocks(win fyne.Window, title string) bool {
+	if demoMode {
+		dialog.ShowInformation(title, "Turn off Demo Mode in the Tools menu to use your own portfolio.", win)
+	}
+	return demoMode
+}
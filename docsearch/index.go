@@ -0,0 +1,242 @@
+// Package docsearch extracts the text of attached documents and indexes it,
+// so a phrase such as "post-termination exercise" finds the clause that uses
+// it across every stored plan document.
+package docsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// snippetContext is how many bytes of text a snippet shows on each side of
+// a match
+const snippetContext = 90
+
+// Doc is the extracted text of one stored document
+type Doc struct {
+	ID   string `json:"id"` // The document's checksum
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// Index is an inverted index from each word to the documents that use it.
+// Only the documents' text is saved; the word lists are rebuilt on load.
+type Index struct {
+	docs  map[string]Doc
+	terms map[string]map[string]bool // Word to document IDs
+}
+
+// Hit is a document that matches a search
+type Hit struct {
+	ID      string
+	Name    string
+	Matches int    // Times the whole phrase occurs; 0 when only its words do
+	Snippet string // Text around the first match
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{docs: map[string]Doc{}, terms: map[string]map[string]bool{}}
+}
+
+// Load reads an index written by Save
+func Load(r io.Reader) (*Index, error) {
+	var saved struct {
+		Docs []Doc `json:"docs"`
+	}
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("failed to read document index: %w", err)
+	}
+	ix := NewIndex()
+	for _, d := range saved.Docs {
+		ix.Add(d)
+	}
+	return ix, nil
+}
+
+// Save writes the indexed documents as JSON
+func (ix *Index) Save(w io.Writer) error {
+	docs := make([]Doc, 0, len(ix.docs))
+	for _, d := range ix.docs {
+		docs = append(docs, d)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Docs []Doc `json:"docs"`
+	}{docs}); err != nil {
+		return fmt.Errorf("failed to write document index: %w", err)
+	}
+	return nil
+}
+
+// Add indexes d, replacing any document with the same ID
+func (ix *Index) Add(d Doc) {
+	ix.Remove(d.ID)
+	ix.docs[d.ID] = d
+	for _, w := range words(d.Text) {
+		if ix.terms[w.term] == nil {
+			ix.terms[w.term] = map[string]bool{}
+		}
+		ix.terms[w.term][d.ID] = true
+	}
+}
+
+// Remove drops the document with id from the index
+func (ix *Index) Remove(id string) {
+	d, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, w := range words(d.Text) {
+		delete(ix.terms[w.term], id)
+		if len(ix.terms[w.term]) == 0 {
+			delete(ix.terms, w.term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Has reports whether the document with id is indexed
+func (ix *Index) Has(id string) bool {
+	_, ok := ix.docs[id]
+	return ok
+}
+
+// Doc returns the indexed document with id
+func (ix *Index) Doc(id string) (Doc, bool) {
+	d, ok := ix.docs[id]
+	return d, ok
+}
+
+// Search finds the documents that use every word of query. Documents where
+// the words occur together as a phrase come first, most matches first; the
+// rest follow by name. Case and punctuation are ignored, so
+// "post-termination exercise" also matches "Post-Termination Exercise".
+func (ix *Index) Search(query string) []Hit {
+	q := words(query)
+	if len(q) == 0 {
+		return nil
+	}
+
+	// Only documents with the rarest word can match
+	candidates := ix.terms[q[0].term]
+	for _, w := range q[1:] {
+		if ids := ix.terms[w.term]; len(ids) < len(candidates) {
+			candidates = ids
+		}
+	}
+	var hits []Hit
+	for id := range candidates {
+		d := ix.docs[id]
+		dw := words(d.Text)
+		if !containsAll(dw, q) {
+			continue
+		}
+		hit := Hit{ID: id, Name: d.Name}
+		first := -1
+		for i := 0; i+len(q) <= len(dw); i++ {
+			if phraseAt(dw, i, q) {
+				if first < 0 {
+					first = i
+				}
+				hit.Matches++
+			}
+		}
+		if first >= 0 {
+			hit.Snippet = snippet(d.Text, dw[first].start, dw[first+len(q)-1].end)
+		} else {
+			for _, w := range dw {
+				if w.term == q[0].term {
+					hit.Snippet = snippet(d.Text, w.start, w.end)
+					break
+				}
+			}
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Matches != hits[j].Matches {
+			return hits[i].Matches > hits[j].Matches
+		}
+		return hits[i].Name < hits[j].Name
+	})
+	return hits
+}
+
+// word is one word of a text, lowercased, with where it sits in the text
+type word struct {
+	term       string
+	start, end int
+}
+
+// words splits text into lowercase words of letters and digits. Hyphens and
+// other punctuation separate words.
+func words(text string) []word {
+	var out []word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			out = append(out, word{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, word{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return out
+}
+
+// containsAll reports whether text has every word of q
+func containsAll(text, q []word) bool {
+	have := make(map[string]bool, len(text))
+	for _, w := range text {
+		have[w.term] = true
+	}
+	for _, w := range q {
+		if !have[w.term] {
+			return false
+		}
+	}
+	return true
+}
+
+// phraseAt reports whether the words of q occur in text starting at i
+func phraseAt(text []word, i int, q []word) bool {
+	for j, w := range q {
+		if text[i+j].term != w.term {
+			return false
+		}
+	}
+	return true
+}
+
+// snippet returns the text around start:end on one line, marking where it
+// was cut
+func snippet(text string, start, end int) string {
+	from, to := max(start-snippetContext, 0), min(end+snippetContext, len(text))
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	s := strings.Join(strings.Fields(text[from:to]), " ")
+	if from > 0 {
+		s = "…" + s
+	}
+	if to < len(text) {
+		s += "…"
+	}
+	return s
+}
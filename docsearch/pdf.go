@@ -0,0 +1,741 @@
+package docsearch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrEncrypted reports a PDF whose text cannot be read without its password
+var ErrEncrypted = errors.New("the PDF is encrypted")
+
+// ErrNotPDF reports a file that does not start with a PDF header
+var ErrNotPDF = errors.New("not a PDF file")
+
+// ExtractPDFText returns the text a PDF draws, page by page. It reads plain
+// and Flate-compressed streams, compressed object streams, and the ToUnicode
+// maps of embedded fonts. Scanned pages are only images and have no text, and
+// text in fonts without a ToUnicode map may come out garbled.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return "", ErrNotPDF
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", ErrEncrypted
+	}
+	f := readObjects(data)
+
+	var b strings.Builder
+	pages := f.pages()
+	for _, page := range pages {
+		for _, ref := range f.contents(page.dict) {
+			if c := f.objs[ref]; c != nil && c.stream != nil {
+				writeContent(&b, c.stream, page.fonts)
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	// Without a page tree to follow, read every stream that draws text
+	if len(pages) == 0 {
+		fonts := f.allFonts()
+		for _, num := range f.order {
+			if o := f.objs[num]; o.stream != nil && isContent(o) {
+				writeContent(&b, o.stream, fonts)
+				b.WriteByte('\n')
+			}
+		}
+	}
+	return cleanText(b.String()), nil
+}
+
+// object is one indirect object of a PDF
+type object struct {
+	dict   []byte // The object's body, up to its stream if it has one
+	stream []byte // Decoded stream; nil when there is none or its filter is unsupported
+}
+
+// pdfFile is the objects of a PDF, in the order they appear
+type pdfFile struct {
+	objs  map[int]*object
+	order []int
+}
+
+var (
+	objPattern      = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	refPattern      = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	namedRefPattern = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	catalogPattern  = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	filterPattern   = regexp.MustCompile(`/[A-Za-z0-9]+Decode\b`)
+	imageEndPattern = regexp.MustCompile(`\sEI\b`)
+)
+
+// readObjects scans data for "N 0 obj" bodies. Objects packed into object
+// streams are unpacked alongside the others. Later copies of an object,
+// from incremental updates, replace earlier ones.
+func readObjects(data []byte) pdfFile {
+	f := pdfFile{objs: map[int]*object{}}
+	add := func(num int, o *object) {
+		if _, ok := f.objs[num]; !ok {
+			f.order = append(f.order, num)
+		}
+		f.objs[num] = o
+	}
+
+	pos := 0
+	for {
+		loc := objPattern.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		start := pos + loc[1]
+		end := bytes.Index(data[start:], []byte("endobj"))
+		if end < 0 {
+			end = len(data) - start
+		}
+		body := data[start : start+end]
+		pos = start + end
+
+		o := &object{dict: body}
+		if s := bytes.Index(body, []byte("stream")); s >= 0 {
+			o.dict = body[:s]
+			raw := body[s+len("stream"):]
+			raw = bytes.TrimPrefix(raw, []byte("\r"))
+			raw = bytes.TrimPrefix(raw, []byte("\n"))
+			if e := bytes.LastIndex(raw, []byte("endstream")); e >= 0 {
+				raw = raw[:e]
+			}
+			o.stream = decodeStream(o.dict, raw)
+		}
+		add(num, o)
+
+		if o.stream != nil && bytes.Contains(o.dict, []byte("/ObjStm")) {
+			for n, packed := range unpackObjects(o.dict, o.stream) {
+				add(n, packed)
+			}
+		}
+	}
+	return f
+}
+
+// decodeStream applies a stream's filter. Only Flate, the filter nearly
+// every PDF writer uses for text, is supported.
+func decodeStream(dict, raw []byte) []byte {
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return raw
+	}
+	filters := filterPattern.FindAll(dict, -1)
+	if len(filters) != 1 || string(filters[0]) != "/FlateDecode" {
+		return nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	// Writers sometimes get the checksum wrong; keep whatever inflated
+	out, err := io.ReadAll(zr)
+	if err != nil && len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// unpackObjects splits an object stream into the objects it holds
+func unpackObjects(dict, stream []byte) map[int]*object {
+	count, first := dictInt(dict, "/N"), dictInt(dict, "/First")
+	if count <= 0 || first <= 0 || first > len(stream) {
+		return nil
+	}
+	header := strings.Fields(string(stream[:first]))
+	if len(header) < 2*count {
+		return nil
+	}
+	nums := make([]int, count)
+	offsets := make([]int, count)
+	for i := range count {
+		nums[i], _ = strconv.Atoi(header[2*i])
+		offsets[i], _ = strconv.Atoi(header[2*i+1])
+	}
+	objs := make(map[int]*object, count)
+	for i := range count {
+		start, end := first+offsets[i], len(stream)
+		if i+1 < count {
+			end = first + offsets[i+1]
+		}
+		if start < 0 || start > end || end > len(stream) {
+			continue
+		}
+		objs[nums[i]] = &object{dict: stream[start:end]}
+	}
+	return objs
+}
+
+// dictInt reads an integer entry, such as /N 12, from a dictionary
+func dictInt(dict []byte, key string) int {
+	re := regexp.MustCompile(regexp.QuoteMeta(key) + `\b\s*(\d+)`)
+	m := re.FindSubmatch(dict)
+	if m == nil {
+		return -1
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+// entry returns the value of a dictionary key up to the next key or the end
+// of a nested dictionary or array, resolving a reference to its object
+func (f pdfFile) entry(dict []byte, key string) []byte {
+	i := bytes.Index(dict, []byte(key))
+	for i >= 0 {
+		// Skip keys that merely start with key, such as /Contents for /Content
+		next := i + len(key)
+		if next >= len(dict) || !isNameChar(dict[next]) {
+			break
+		}
+		j := bytes.Index(dict[next:], []byte(key))
+		if j < 0 {
+			return nil
+		}
+		i = next + j
+	}
+	if i < 0 {
+		return nil
+	}
+	rest := bytes.TrimLeft(dict[i+len(key):], " \t\r\n")
+	switch {
+	case bytes.HasPrefix(rest, []byte("<<")):
+		return balanced(rest, "<<", ">>")
+	case bytes.HasPrefix(rest, []byte("[")):
+		return balanced(rest, "[", "]")
+	}
+	if m := refPattern.FindSubmatchIndex(rest); m != nil && m[0] == 0 {
+		num, _ := strconv.Atoi(string(rest[m[2]:m[3]]))
+		if o := f.objs[num]; o != nil {
+			return o.dict
+		}
+	}
+	return nil
+}
+
+// balanced returns the prefix of b up to the close that matches its open
+func balanced(b []byte, open, close string) []byte {
+	depth := 0
+	for i := 0; i < len(b); {
+		switch {
+		case bytes.HasPrefix(b[i:], []byte(open)):
+			depth++
+			i += len(open)
+		case bytes.HasPrefix(b[i:], []byte(close)):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return b[:i]
+			}
+		default:
+			i++
+		}
+	}
+	return b
+}
+
+// contents returns the object numbers of a page's content streams
+func (f pdfFile) contents(page []byte) []int {
+	i := bytes.Index(page, []byte("/Contents"))
+	if i < 0 {
+		return nil
+	}
+	rest := bytes.TrimLeft(page[i+len("/Contents"):], " \t\r\n")
+	if bytes.HasPrefix(rest, []byte("[")) {
+		rest = balanced(rest, "[", "]")
+	} else if m := refPattern.FindIndex(rest); m != nil {
+		rest = rest[:m[1]]
+	}
+	var refs []int
+	for _, m := range refPattern.FindAllSubmatch(rest, -1) {
+		num, _ := strconv.Atoi(string(m[1]))
+		// A content array may itself be stored as an object
+		if o := f.objs[num]; o != nil && o.stream == nil && bytes.HasPrefix(bytes.TrimSpace(o.dict), []byte("[")) {
+			refs = append(refs, f.contents(append([]byte("/Contents "), o.dict...))...)
+			continue
+		}
+		refs = append(refs, num)
+	}
+	return refs
+}
+
+// page is a page's dictionary and the fonts its content can use
+type page struct {
+	dict  []byte
+	fonts map[string]*font
+}
+
+// pages walks the page tree from the catalog, in reading order. Fonts are
+// inherited from the tree's nodes, as the spec allows.
+func (f pdfFile) pages() []page {
+	var root []byte
+	for _, num := range f.order {
+		if catalogPattern.Match(f.objs[num].dict) {
+			root = f.entry(f.objs[num].dict, "/Pages")
+		}
+	}
+	var out []page
+	seen := map[int]bool{}
+	var walk func(node []byte, fonts map[string]*font, depth int)
+	walk = func(node []byte, fonts map[string]*font, depth int) {
+		if res := f.entry(node, "/Resources"); res != nil {
+			if own := f.fontsIn(f.entry(res, "/Font")); len(own) > 0 {
+				fonts = own
+			}
+		}
+		kids := f.entry(node, "/Kids")
+		if kids == nil {
+			out = append(out, page{node, fonts})
+			return
+		}
+		if depth > 32 {
+			return
+		}
+		for _, m := range refPattern.FindAllSubmatch(kids, -1) {
+			num, _ := strconv.Atoi(string(m[1]))
+			// A malformed tree could list a node twice or loop
+			if o := f.objs[num]; o != nil && !seen[num] {
+				seen[num] = true
+				walk(o.dict, fonts, depth+1)
+			}
+		}
+	}
+	if root != nil {
+		walk(root, nil, 0)
+	}
+	for i := range out {
+		if out[i].fonts == nil {
+			out[i].fonts = f.allFonts()
+		}
+	}
+	return out
+}
+
+// allFonts returns every font named in any resource dictionary of the file
+func (f pdfFile) allFonts() map[string]*font {
+	fonts := map[string]*font{}
+	for _, num := range f.order {
+		if fd := f.entry(f.objs[num].dict, "/Font"); fd != nil {
+			for name, ft := range f.fontsIn(fd) {
+				fonts[name] = ft
+			}
+		}
+	}
+	return fonts
+}
+
+// fontsIn reads the fonts of a /Font resource dictionary
+func (f pdfFile) fontsIn(dict []byte) map[string]*font {
+	fonts := map[string]*font{}
+	for _, m := range namedRefPattern.FindAllSubmatch(dict, -1) {
+		num, _ := strconv.Atoi(string(m[2]))
+		o := f.objs[num]
+		if o == nil {
+			continue
+		}
+		ft := &font{wide: bytes.Contains(o.dict, []byte("/Type0"))}
+		if ref := refPattern.FindSubmatch(afterKey(o.dict, "/ToUnicode")); ref != nil {
+			cm, _ := strconv.Atoi(string(ref[1]))
+			if c := f.objs[cm]; c != nil && c.stream != nil {
+				ft.toUnicode = parseCMap(c.stream)
+			}
+		}
+		fonts[string(m[1])] = ft
+	}
+	return fonts
+}
+
+// afterKey returns what follows key in dict, or nil when key is absent
+func afterKey(dict []byte, key string) []byte {
+	i := bytes.Index(dict, []byte(key))
+	if i < 0 {
+		return nil
+	}
+	return dict[i+len(key):]
+}
+
+func isNameChar(c byte) bool {
+	return c > ' ' && !strings.ContainsRune("/<>[]()%{}", rune(c))
+}
+
+// isContent reports whether a stream looks like page content that draws text
+func isContent(o *object) bool {
+	for _, key := range []string{"/Subtype", "/Length1", "/Type"} {
+		if bytes.Contains(o.dict, []byte(key)) && !bytes.Contains(o.dict, []byte("/Form")) {
+			return false
+		}
+	}
+	return bytes.Contains(o.stream, []byte("BT")) &&
+		(bytes.Contains(o.stream, []byte("Tj")) || bytes.Contains(o.stream, []byte("TJ")))
+}
+
+// font is what decoding a font's strings needs
+type font struct {
+	wide      bool           // Codes are two bytes, as in Identity-H fonts
+	toUnicode map[int]string // Text for each code, from the ToUnicode map
+}
+
+// decode converts a string shown in f to text
+func (f *font) decode(s []byte) string {
+	if f == nil {
+		return latin1(s)
+	}
+	var b strings.Builder
+	step := 1
+	if f.wide {
+		step = 2
+	}
+	for i := 0; i+step <= len(s); i += step {
+		code := int(s[i])
+		if step == 2 {
+			code = code<<8 | int(s[i+1])
+		}
+		if t, ok := f.toUnicode[code]; ok {
+			b.WriteString(t)
+		} else if step == 1 {
+			b.WriteRune(rune(code))
+		}
+	}
+	return b.String()
+}
+
+// latin1 reads bytes as Latin-1, which matches WinAnsi for letters and
+// punctuation
+func latin1(s []byte) string {
+	r := make([]rune, len(s))
+	for i, c := range s {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+// parseCMap reads the bfchar and bfrange sections of a ToUnicode CMap
+func parseCMap(data []byte) map[int]string {
+	m := map[int]string{}
+	toks := tokenize(data)
+	for i := 0; i < len(toks); i++ {
+		switch toks[i].op {
+		case "beginbfchar":
+			for i++; i+1 < len(toks) && toks[i].op != "endbfchar"; i += 2 {
+				if toks[i].kind == tokString && toks[i+1].kind == tokString {
+					m[codeOf(toks[i].str)] = utf16Text(toks[i+1].str)
+				}
+			}
+		case "beginbfrange":
+			for i++; i+2 < len(toks) && toks[i].op != "endbfrange"; i += 3 {
+				lo, hi := codeOf(toks[i].str), codeOf(toks[i+1].str)
+				if hi < lo || hi-lo > 0xFFFF {
+					continue
+				}
+				dst := toks[i+2]
+				if dst.kind == tokArray {
+					for j, d := range dst.array {
+						if lo+j <= hi {
+							m[lo+j] = utf16Text(d.str)
+						}
+					}
+					continue
+				}
+				// Consecutive codes map to consecutive text
+				base := utf16.Decode(utf16Units(dst.str))
+				if len(base) == 0 {
+					continue
+				}
+				for c := lo; c <= hi; c++ {
+					r := append([]rune{}, base...)
+					r[len(r)-1] += rune(c - lo)
+					m[c] = string(r)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// codeOf reads a big-endian character code
+func codeOf(s []byte) int {
+	code := 0
+	for _, c := range s {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+func utf16Units(s []byte) []uint16 {
+	u := make([]uint16, len(s)/2)
+	for i := range u {
+		u[i] = uint16(s[2*i])<<8 | uint16(s[2*i+1])
+	}
+	return u
+}
+
+func utf16Text(s []byte) string {
+	return string(utf16.Decode(utf16Units(s)))
+}
+
+// writeContent writes the text a content stream shows to b, starting a new
+// line where the stream moves down a line
+func writeContent(b *strings.Builder, content []byte, fonts map[string]*font) {
+	var cur *font
+	var operands []token
+	for _, t := range tokenize(content) {
+		if t.kind != tokOp {
+			operands = append(operands, t)
+			continue
+		}
+		last := func(kind tokenKind) (token, bool) {
+			if len(operands) > 0 && operands[len(operands)-1].kind == kind {
+				return operands[len(operands)-1], true
+			}
+			return token{}, false
+		}
+		switch t.op {
+		case "Tf":
+			if len(operands) >= 2 && operands[len(operands)-2].kind == tokName {
+				cur = fonts[operands[len(operands)-2].name]
+			}
+		case "Tj":
+			if s, ok := last(tokString); ok {
+				b.WriteString(cur.decode(s.str))
+			}
+		case "'", `"`:
+			b.WriteByte('\n')
+			if s, ok := last(tokString); ok {
+				b.WriteString(cur.decode(s.str))
+			}
+		case "TJ":
+			if a, ok := last(tokArray); ok {
+				for _, e := range a.array {
+					switch {
+					case e.kind == tokString:
+						b.WriteString(cur.decode(e.str))
+					case e.kind == tokNumber && e.num < -200: // A gap as wide as a space
+						b.WriteByte(' ')
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[len(operands)-1].num != 0 {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(' ')
+			}
+		case "T*":
+			b.WriteByte('\n')
+		case "Tm", "ET":
+			b.WriteByte(' ')
+		}
+		operands = operands[:0]
+	}
+}
+
+// cleanText collapses runs of spaces and blank lines
+func cleanText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := false
+	for _, l := range lines {
+		l = strings.Join(strings.Fields(l), " ")
+		if l == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, l)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+type tokenKind int
+
+const (
+	tokOp tokenKind = iota
+	tokNumber
+	tokString
+	tokName
+	tokArray
+	tokDict // The << and >> of a dictionary; their contents are tokens of their own
+)
+
+// token is one lexical element of a content stream or CMap
+type token struct {
+	kind  tokenKind
+	op    string
+	num   float64
+	str   []byte
+	name  string
+	array []token
+}
+
+// tokenize splits PDF syntax into tokens, collecting arrays into a single
+// token and skipping inline image data
+func tokenize(data []byte) []token {
+	var stack [][]token
+	var toks []token
+	emit := func(t token) { toks = append(toks, t) }
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			i++
+		case c == '%':
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := literalString(data[i:])
+			emit(token{kind: tokString, str: s})
+			i += n
+		case c == '<' && i+1 < len(data) && data[i+1] == '<', c == '>' && i+1 < len(data) && data[i+1] == '>':
+			emit(token{kind: tokDict})
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				end = len(data) - i
+			}
+			emit(token{kind: tokString, str: hexString(data[i+1 : i+end])})
+			i += end + 1
+		case c == '[':
+			stack = append(stack, toks)
+			toks = nil
+			i++
+		case c == ']':
+			if len(stack) > 0 {
+				arr := token{kind: tokArray, array: toks}
+				toks = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				emit(arr)
+			}
+			i++
+		case c == '/':
+			j := i + 1
+			for j < len(data) && isNameChar(data[j]) {
+				j++
+			}
+			emit(token{kind: tokName, name: string(data[i+1 : j])})
+			i = j
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(data) && (data[j] == '.' || (data[j] >= '0' && data[j] <= '9')) {
+				j++
+			}
+			n, _ := strconv.ParseFloat(string(data[i:j]), 64)
+			emit(token{kind: tokNumber, num: n})
+			i = j
+		default:
+			j := i + 1
+			for j < len(data) && isNameChar(data[j]) {
+				j++
+			}
+			op := string(data[i:j])
+			i = j
+			// Inline image data runs from ID to EI and is not syntax
+			if op == "ID" {
+				end := imageEndPattern.FindIndex(data[i:])
+				if end == nil {
+					i = len(data)
+				} else {
+					i += end[1]
+				}
+				continue
+			}
+			emit(token{kind: tokOp, op: op})
+		}
+	}
+	for len(stack) > 0 { // Unclosed arrays
+		toks = append(stack[len(stack)-1], token{kind: tokArray, array: toks})
+		stack = stack[:len(stack)-1]
+	}
+	return toks
+}
+
+// literalString reads a (string) with its escapes and balanced parentheses,
+// returning its bytes and how much of data it used
+func literalString(data []byte) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(data) {
+				return out, i
+			}
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n': // A line continuation
+				if e == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+					i++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					v, j := 0, i
+					for ; j < len(data) && j < i+3 && data[j] >= '0' && data[j] <= '7'; j++ {
+						v = v*8 + int(data[j]-'0')
+					}
+					out = append(out, byte(v))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, len(data)
+}
+
+// hexString decodes a <hex string>, padding an odd final digit with zero
+func hexString(h []byte) []byte {
+	var digits []byte
+	for _, c := range h {
+		if strings.IndexByte("0123456789abcdefABCDEF", c) >= 0 {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
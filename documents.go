@@ -0,0 +1,251 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/docsearch"
+	"fynance/portfolio"
+)
+
+// documentsDir holds attached documents in app storage, named by checksum
+const documentsDir = "documents"
+
+// documentKindLabels names each kind of document in the documents dialog
+var documentKindLabels = map[portfolio.DocumentKind]string{
+	portfolio.DocGrantAgreement: "Grant Agreement",
+	portfolio.DocPlan:           "Plan Document",
+	portfolio.DocOther:          "Other",
+}
+
+// documentURI returns where the stored copy of d lives, creating the
+// documents folder when create is set
+func documentURI(a fyne.App, d portfolio.Document, create bool) (fyne.URI, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	dir, err := storage.Child(a.Storage().RootURI(), documentsDir)
+	if err != nil {
+		return nil, err
+	}
+	if create {
+		if ok, _ := storage.Exists(dir); !ok {
+			if err := storage.CreateListable(dir); err != nil {
+				return nil, fmt.Errorf("failed to create documents folder: %w", err)
+			}
+		}
+	}
+	return storage.Child(dir, d.StoredName())
+}
+
+// storeDocument writes the contents of d to app storage
+func storeDocument(a fyne.App, d portfolio.Document, data []byte) error {
+	uri, err := documentURI(a, d, true)
+	if err != nil {
+		return err
+	}
+	w, err := storage.Writer(uri)
+	if err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+	defer w.Close()
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+	return nil
+}
+
+// verifyDocument checks the stored copy of d against its checksum
+func verifyDocument(a fyne.App, d portfolio.Document) error {
+	uri, err := documentURI(a, d, false)
+	if err != nil {
+		return err
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return fmt.Errorf("failed to open document: %w", err)
+	}
+	defer r.Close()
+	return d.Verify(r)
+}
+
+// grantLabel names a grant in the documents dialog
+func grantLabel(g portfolio.Grant) string {
+	return fmt.Sprintf("%s %s · %.0f units · granted %s", g.Symbol, g.Kind, g.Schedule.TotalShares,
+		g.Schedule.GrantDate.Format("2006-01-02"))
+}
+
+// showDocumentsDialog lists the documents attached to each grant and
+// attaches, previews, and removes them
+func showDocumentsDialog(a fyne.App, win fyne.Window, pf *portfolio.Portfolio, onChanged func()) {
+	if len(pf.Grants) == 0 {
+		dialog.ShowInformation("Grant Documents", "The portfolio has no grants. Add one with Portfolio → Add Grant.", win)
+		return
+	}
+
+	labels := make([]string, len(pf.Grants))
+	for i, g := range pf.Grants {
+		labels[i] = grantLabel(g)
+	}
+	grantSelect := widget.NewSelect(labels, nil)
+	kindOptions := make([]string, len(portfolio.DocumentKinds))
+	for i, k := range portfolio.DocumentKinds {
+		kindOptions[i] = documentKindLabels[k]
+	}
+	kindSelect := widget.NewSelect(kindOptions, nil)
+	kindSelect.SetSelectedIndex(0)
+
+	rows := container.NewVBox()
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		i := grantSelect.SelectedIndex()
+		if i < 0 {
+			return
+		}
+		g := pf.Grants[i]
+		if len(g.Documents) == 0 {
+			rows.Add(widget.NewLabel("No documents attached."))
+		}
+		for _, d := range g.Documents {
+			info := widget.NewLabel(fmt.Sprintf("%s · %s · %s", d.Name, documentKindLabels[d.Kind], formatSize(d.Size)))
+			preview := widget.NewButton("Preview", func() { showDocumentPreview(a, win, d) })
+			remove := widget.NewButton("Remove", func() {
+				dialog.ShowConfirm("Remove Document", fmt.Sprintf("Remove %s from this grant?", d.Name), func(ok bool) {
+					if !ok {
+						return
+					}
+					if err := pf.DetachDocument(g.ID, d.SHA256); err != nil {
+						dialog.ShowError(err, win)
+						return
+					}
+					// The same file may be attached to another grant
+					if !pf.DocumentInUse(d.StoredName()) {
+						if uri, err := documentURI(a, d, false); err == nil {
+							if err := storage.Delete(uri); err != nil {
+								fyne.LogError("Failed to delete document", err)
+							}
+						}
+					}
+					onChanged()
+					refresh()
+				}, win)
+			})
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(preview, remove), info))
+		}
+	}
+	grantSelect.OnChanged = func(string) { refresh() }
+	grantSelect.SetSelectedIndex(0)
+
+	attach := widget.NewButton("Attach PDF...", func() {
+		if demoMode {
+			dialog.ShowInformation("Grant Documents", "Documents cannot be attached in demo mode.", win)
+			return
+		}
+		i := grantSelect.SelectedIndex()
+		if i < 0 {
+			return
+		}
+		grantID := pf.Grants[i].ID
+		kind := portfolio.DocumentKinds[kindSelect.SelectedIndex()]
+		open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil || r == nil {
+				return
+			}
+			defer r.Close()
+			d, data, err := portfolio.ReadDocument(r.URI().Name(), kind, r)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if err := storeDocument(a, d, data); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if err := pf.AttachDocument(grantID, d); err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			indexDocuments(a, []portfolio.Document{d}, nil)
+			onChanged()
+			refresh()
+		}, win)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".pdf"}))
+		open.Show()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Grant", grantSelect),
+		widget.NewFormItem("Attach As", container.NewBorder(nil, nil, nil, attach, kindSelect)),
+	)
+	search := widget.NewButton("Search Documents...", func() { showDocumentSearch(a, win, pf) })
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(560, 220))
+	dialog.ShowCustom("Grant Documents", "Close", container.NewBorder(form, search, nil, nil, scroll), win)
+}
+
+// showDocumentPreview shows a document's details and whether the stored
+// copy still matches its checksum, and opens it in the system viewer
+func showDocumentPreview(a fyne.App, win fyne.Window, d portfolio.Document) {
+	status := "Checksum verified"
+	if err := verifyDocument(a, d); errors.Is(err, portfolio.ErrChecksum) {
+		status = "The stored copy has changed since it was attached"
+	} else if err != nil {
+		status = err.Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-10s %s\n", "Name", d.Name)
+	fmt.Fprintf(&b, "%-10s %s\n", "Kind", documentKindLabels[d.Kind])
+	fmt.Fprintf(&b, "%-10s %s\n", "Size", formatSize(d.Size))
+	fmt.Fprintf(&b, "%-10s %s\n", "Attached", d.Added.In(taxHome()).Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "%-10s %s\n", "SHA-256", d.SHA256)
+	fmt.Fprintf(&b, "%-10s %s\n", "Status", status)
+	fmt.Fprintf(&b, "%-10s %s", "Search", searchStatus(documentIndex(a), d))
+	details := widget.NewLabelWithStyle(b.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	open := widget.NewButton("Open", func() {
+		uri, err := documentURI(a, d, false)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		u, err := url.Parse(uri.String())
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		if err := a.OpenURL(u); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to open document: %w", err), win)
+		}
+	})
+	dialog.ShowCustom(d.Name, "Close", container.NewVBox(details, open), win)
+}
+
+// formatSize writes a file size in KB or MB
+func formatSize(n int64) string {
+	if n >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	}
+	return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+}
+
+// searchStatus says whether d can be found by searching
+func searchStatus(ix *docsearch.Index, d portfolio.Document) string {
+	doc, ok := ix.Doc(d.SHA256)
+	switch {
+	case !ok:
+		return "Not indexed yet"
+	case doc.Text == "":
+		return "No text found; scanned and encrypted PDFs cannot be searched"
+	}
+	return fmt.Sprintf("%d words indexed", len(strings.Fields(doc.Text)))
+}
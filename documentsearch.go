@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/docsearch"
+	"fynance/portfolio"
+)
+
+// documentIndexFile holds the extracted text of attached documents, in the
+// documents folder
+const documentIndexFile = "index.json"
+
+// docIndex is the search index, loaded on first use. It is only touched on
+// the UI thread.
+var docIndex *docsearch.Index
+
+// documentIndex returns the search index, loading it from app storage
+func documentIndex(a fyne.App) *docsearch.Index {
+	if docIndex != nil {
+		return docIndex
+	}
+	docIndex = docsearch.NewIndex()
+	uri, err := documentIndexURI(a)
+	if err != nil {
+		return docIndex
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return docIndex // Nothing indexed yet
+	}
+	defer r.Close()
+	if ix, err := docsearch.Load(r); err != nil {
+		fyne.LogError("Failed to load document index", err)
+	} else {
+		docIndex = ix
+	}
+	return docIndex
+}
+
+func documentIndexURI(a fyne.App) (fyne.URI, error) {
+	dir, err := storage.Child(a.Storage().RootURI(), documentsDir)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Child(dir, documentIndexFile)
+}
+
+// saveDocumentIndex writes the search index to app storage
+func saveDocumentIndex(a fyne.App) {
+	uri, err := documentIndexURI(a)
+	if err != nil {
+		fyne.LogError("Failed to save document index", err)
+		return
+	}
+	w, err := storage.Writer(uri)
+	if err != nil {
+		fyne.LogError("Failed to save document index", err)
+		return
+	}
+	defer w.Close()
+	if err := documentIndex(a).Save(w); err != nil {
+		fyne.LogError("Failed to save document index", err)
+	}
+}
+
+// indexDocuments extracts the text of each document in the background and
+// adds it to the search index, calling done on the UI thread when finished.
+// A document with no text, such as a scan, is indexed empty so it is not
+// read again.
+func indexDocuments(a fyne.App, docs []portfolio.Document, done func()) {
+	go func() {
+		extracted := make([]docsearch.Doc, 0, len(docs))
+		for _, d := range docs {
+			text, err := readDocumentText(a, d)
+			if err != nil && !errors.Is(err, docsearch.ErrEncrypted) {
+				fyne.LogError("Failed to index "+d.Name, err)
+			}
+			extracted = append(extracted, docsearch.Doc{ID: d.SHA256, Name: d.Name, Text: text})
+		}
+		fyne.Do(func() {
+			ix := documentIndex(a)
+			for _, d := range extracted {
+				ix.Add(d)
+			}
+			saveDocumentIndex(a)
+			if done != nil {
+				done()
+			}
+		})
+	}()
+}
+
+// readDocumentText extracts the text of the stored copy of d
+func readDocumentText(a fyne.App, d portfolio.Document) (string, error) {
+	uri, err := documentURI(a, d, false)
+	if err != nil {
+		return "", err
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, portfolio.MaxDocumentSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+	return docsearch.ExtractPDFText(data)
+}
+
+// unindexedDocuments returns the attached documents missing from the index,
+// each file once
+func unindexedDocuments(a fyne.App, pf *portfolio.Portfolio) []portfolio.Document {
+	ix := documentIndex(a)
+	seen := map[string]bool{}
+	var docs []portfolio.Document
+	for _, g := range pf.Grants {
+		for _, d := range g.Documents {
+			if !seen[d.SHA256] && !ix.Has(d.SHA256) {
+				docs = append(docs, d)
+			}
+			seen[d.SHA256] = true
+		}
+	}
+	return docs
+}
+
+// showDocumentSearch searches the text of every attached document and shows
+// the clause around each match
+func showDocumentSearch(a fyne.App, win fyne.Window, pf *portfolio.Portfolio) {
+	query := widget.NewEntry()
+	query.SetPlaceHolder(`e.g. post-termination exercise`)
+	status := widget.NewLabel("")
+	rows := container.NewVBox()
+
+	search := func() {
+		rows.RemoveAll()
+		hits := documentIndex(a).Search(query.Text)
+		switch {
+		case query.Text == "":
+			status.SetText("")
+		case len(hits) == 0:
+			status.SetText("No documents match.")
+		default:
+			status.SetText(fmt.Sprintf("%d matching documents", len(hits)))
+		}
+		for _, h := range hits {
+			d, grants := findDocument(pf, h.ID)
+			if grants == "" {
+				continue // Detached since it was indexed
+			}
+			title := widget.NewLabelWithStyle(h.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			where := fmt.Sprintf("%s · %s", documentKindLabels[d.Kind], grants)
+			if h.Matches == 0 {
+				where += " · words found, but not as a phrase"
+			} else if h.Matches > 1 {
+				where += fmt.Sprintf(" · %d matches", h.Matches)
+			}
+			snippet := widget.NewLabel(h.Snippet)
+			snippet.Wrapping = fyne.TextWrapWord
+			preview := widget.NewButton("Preview", func() { showDocumentPreview(a, win, d) })
+			rows.Add(container.NewBorder(nil, nil, nil, preview, container.NewVBox(title, widget.NewLabel(where))))
+			rows.Add(snippet)
+			rows.Add(widget.NewSeparator())
+		}
+	}
+	query.OnSubmitted = func(string) { search() }
+	button := widget.NewButton("Search", search)
+
+	if missing := unindexedDocuments(a, pf); len(missing) > 0 {
+		status.SetText(fmt.Sprintf("Indexing %d documents...", len(missing)))
+		button.Disable()
+		indexDocuments(a, missing, func() {
+			button.Enable()
+			search()
+		})
+	}
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(620, 320))
+	top := container.NewVBox(container.NewBorder(nil, nil, nil, button, query), status)
+	dialog.ShowCustom("Search Documents", "Close", container.NewBorder(top, nil, nil, nil, scroll), win)
+	win.Canvas().Focus(query)
+}
+
+// findDocument returns the attached document with checksum sum and the
+// grants it is attached to
+func findDocument(pf *portfolio.Portfolio, sum string) (portfolio.Document, string) {
+	var doc portfolio.Document
+	grants := ""
+	for _, g := range pf.Grants {
+		for _, d := range g.Documents {
+			if d.SHA256 != sum {
+				continue
+			}
+			doc = d
+			if grants != "" {
+				grants += "; "
+			}
+			grants += fmt.Sprintf("%s %s granted %s", g.Symbol, g.Kind, g.Schedule.GrantDate.Format("2006-01-02"))
+		}
+	}
+	return doc, grants
+}
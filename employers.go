@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/stc"
+	"fynance/stc/amt"
+	"fynance/widgets"
+)
+
+// makeEmployersCard totals the year's payroll taxes across a job change.
+// Each employer withholds Social Security up to its own wage base and the
+// Additional Medicare Tax on its own wages, so the card shows what comes back
+// or is still owed on the return. Rates follow the latest calculation.
+func makeEmployersCard(pf *portfolio.Portfolio, bus *events.Bus) fyne.CanvasObject {
+	cfg := stc.Config{TaxRates: defaultTaxRates, SocialSecWageBase: stc.SocialSecWageBase}
+
+	type employerRow struct {
+		name, wages, socialSec, surtax *widgets.SmartEntry
+	}
+	newRow := func(name string) employerRow {
+		r := employerRow{
+			name:      widgets.NewSmartEntry(name),
+			wages:     widgets.NewSmartEntry("0.00"),
+			socialSec: widgets.NewSmartEntry(""),
+			surtax:    widgets.NewSmartEntry(""),
+		}
+		r.socialSec.SetPlaceHolder("Estimated from wages")
+		r.surtax.SetPlaceHolder("Estimated from wages")
+		return r
+	}
+	rows := []employerRow{newRow("Previous employer"), newRow("Current employer")}
+
+	statusOptions := make([]string, 0, len(amt.FilingStatuses))
+	for _, s := range amt.FilingStatuses {
+		statusOptions = append(statusOptions, filingStatusLabels[s])
+	}
+	statusSelect := widget.NewSelect(statusOptions, nil)
+	statusSelect.SetSelectedIndex(0)
+
+	lblEquity := widget.NewLabel("")
+	lblEquity.Wrapping = fyne.TextWrapWord
+	summary := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	summary.Wrapping = fyne.TextWrapWord
+
+	run := func() {
+		var employers []stc.EmployerWages
+		for _, r := range rows {
+			wages, err1 := parseFloat(r.wages.Text)
+			socialSec, err2 := parseFloat(r.socialSec.Text)
+			surtax, err3 := parseFloat(r.surtax.Text)
+			if err1 != nil || err2 != nil || err3 != nil {
+				summary.SetText("Please enter valid numbers")
+				return
+			}
+			if wages == 0 && socialSec == 0 && surtax == 0 {
+				continue
+			}
+			employers = append(employers, stc.EmployerWages{
+				Name:           r.name.Text,
+				Wages:          wages,
+				SocialSecTax:   socialSec,
+				MedicareSurtax: surtax,
+			})
+		}
+		if len(employers) == 0 {
+			summary.SetText("Enter each employer's wages for the year")
+			return
+		}
+		status := amt.FilingStatuses[statusSelect.SelectedIndex()]
+		summary.SetText(employerYearText(stc.NewCalculator(cfg).CombineEmployers(employers, status)))
+	}
+
+	// Equity income is wages of the employer that paid it, so point out how much was recorded
+	refreshEquity := func() {
+		year := time.Now().In(taxHome()).Year()
+		var income float64
+		for _, l := range pf.Lots {
+			if l.Acquired.In(taxHome()).Year() == year {
+				income += l.Income
+			}
+		}
+		if income > 0 {
+			lblEquity.SetText(fmt.Sprintf("Equity income kept in the portfolio this year: $%.2f. Include it in the wages of the employer that paid it.", income))
+			lblEquity.Show()
+		} else {
+			lblEquity.Hide()
+		}
+	}
+
+	form := widget.NewForm()
+	for i, r := range rows {
+		if i > 0 {
+			form.Append("", widget.NewSeparator())
+		}
+		form.Append("Employer", r.name)
+		form.Append("Wages ($)", r.wages)
+		form.Append("Social Security Withheld ($)", r.socialSec)
+		form.Append("Addl. Medicare Withheld ($)", r.surtax)
+		for _, e := range []*widgets.SmartEntry{r.wages, r.socialSec, r.surtax} {
+			e.SetOnEnter(run)
+		}
+	}
+	form.Append("Filing Status", statusSelect)
+
+	bus.Subscribe(events.InputChanged, func(e events.Event) {
+		latest := e.Payload.(stc.Config)
+		cfg.TaxRates = latest.TaxRates
+	})
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) { refreshEquity() })
+	refreshEquity()
+
+	return widget.NewCard("Employers", "Social Security and Additional Medicare across a job change",
+		container.NewVBox(form, lblEquity, widget.NewButton("Combine", run), summary))
+}
+
+// employerYearText lists each employer's withholding and the year's totals
+func employerYearText(y stc.EmployerYear) string {
+	var b strings.Builder
+	for _, e := range y.Employers {
+		estimated := ""
+		if e.Estimated {
+			estimated = " (est.)"
+		}
+		fmt.Fprintf(&b, "%-20s wages $%.2f · SS $%.2f · Addl. Medicare $%.2f%s\n",
+			e.Name, e.Wages, e.SocialSecTax, e.MedicareSurtax, estimated)
+	}
+	fmt.Fprintf(&b, "\nCombined wages        $%.2f\n", y.Wages)
+	fmt.Fprintf(&b, "Social Security       $%.2f withheld, $%.2f due, $%.2f excess\n",
+		y.SocialSecWithheld, y.SocialSecDue, y.ExcessSocialSec)
+	fmt.Fprintf(&b, "Addl. Medicare        $%.2f withheld, $%.2f due above $%.0f\n",
+		y.SurtaxWithheld, y.SurtaxDue, y.SurtaxThreshold)
+	for _, n := range y.Notes {
+		b.WriteString("\n• " + n)
+	}
+	return b.String()
+}
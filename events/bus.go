@@ -0,0 +1,92 @@
+// Package events is a small publish/subscribe bus that keeps every open view
+// in step when shared state (prices, profiles, the portfolio) changes.
+package events
+
+import "sync"
+
+// Kind identifies what happened
+type Kind string
+
+const (
+	InputChanged     Kind = "input.changed"     // Payload: stc.Config used by the latest calculation
+	PriceFetched     Kind = "price.fetched"     // Payload: Price
+	ProfileSwitched  Kind = "profile.switched"  // Payload: stc.Config to load into every form
+	PortfolioChanged Kind = "portfolio.changed" // Payload: nil; re-read the shared portfolio
+	FieldsHidden     Kind = "fields.hidden"     // Payload: map[string]bool of hidden field and row labels
+	ResultReady      Kind = "result.ready"      // Payload: viewmodel.ViewModel of the latest calculation
+	ModeChanged      Kind = "mode.changed"      // Payload: Mode the app now prices shares in
+	EntryOpened      Kind = "entry.opened"      // Payload: stc.SessionEntry to load into the tab for its kind
+)
+
+// Event is one published change
+type Event struct {
+	Kind    Kind
+	Payload any
+}
+
+// Price is the payload of PriceFetched
+type Price struct {
+	Symbol string // Blank for the default holding
+	Price  float64
+}
+
+// Mode is the payload of ModeChanged
+type Mode string
+
+const (
+	ModePublic  Mode = "public"  // Exchange-listed: prices come from the market
+	ModePrivate Mode = "private" // Private company: prices come from 409A valuations
+)
+
+// Bus delivers events to subscribers in the order they subscribed.
+// Publish runs handlers synchronously on the caller's goroutine, so UI
+// publishers must already be on the Fyne thread.
+type Bus struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[Kind][]subscription
+}
+
+type subscription struct {
+	id int
+	fn func(Event)
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[Kind][]subscription)}
+}
+
+// Subscribe registers fn for one kind of event and returns a function that removes it
+func (b *Bus) Subscribe(kind Kind, fn func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs[kind] = append(b.subs[kind], subscription{id: id, fn: fn})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[kind]
+		for i, s := range subs {
+			if s.id == id {
+				b.subs[kind] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers an event to every current subscriber of its kind
+func (b *Bus) Publish(kind Kind, payload any) {
+	b.mu.Lock()
+	subs := append([]subscription(nil), b.subs[kind]...)
+	b.mu.Unlock()
+
+	e := Event{Kind: kind, Payload: payload}
+	for _, s := range subs {
+		s.fn(e)
+	}
+}
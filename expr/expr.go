@@ -0,0 +1,205 @@
+// Package expr evaluates the small arithmetic formulas users can type into
+// numeric fields, e.g. "price*0.95" or "(shares - 12) / 4".
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Vars maps variable names to values
+type Vars map[string]float64
+
+// Eval evaluates a formula of numbers, variables, + - * /, and parentheses
+func Eval(src string, vars Vars) (float64, error) {
+	p := &parser{src: src, vars: vars}
+	p.next()
+	v, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok != "" {
+		return 0, fmt.Errorf("unexpected %q in %q", p.tok, src)
+	}
+	return v, nil
+}
+
+// ParseDefinitions reads one "name = formula" per line. Later lines may refer
+// to names defined earlier; blank lines and lines starting with # are skipped.
+func ParseDefinitions(text string) (Vars, error) {
+	vars := Vars{}
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, formula, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isName(name) {
+			return nil, fmt.Errorf("line %d: expected name = value", n+1)
+		}
+		v, err := Eval(formula, vars)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
+
+// isName reports whether s is a valid variable name
+func isName(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// parser is a recursive-descent evaluator over a single token of lookahead
+type parser struct {
+	src  string
+	pos  int
+	tok  string
+	vars Vars
+}
+
+// next advances to the following token; tok is "" at end of input
+func (p *parser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		p.tok = ""
+		return
+	}
+
+	start := p.pos
+	c := rune(p.src[p.pos])
+	switch {
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// Exponent, e.g. 1e6 or 2.5E-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+				p.pos++
+			}
+		}
+	case c == '_' || unicode.IsLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isDigit(p.src[p.pos]) || unicode.IsLetter(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+	default:
+		p.pos++
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// sum = product { ("+" | "-") product }
+func (p *parser) sum() (float64, error) {
+	v, err := p.product()
+	if err != nil {
+		return 0, err
+	}
+	for p.tok == "+" || p.tok == "-" {
+		op := p.tok
+		p.next()
+		rhs, err := p.product()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+	return v, nil
+}
+
+// product = unary { ("*" | "/") unary }
+func (p *parser) product() (float64, error) {
+	v, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	for p.tok == "*" || p.tok == "/" {
+		op := p.tok
+		p.next()
+		rhs, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		if op == "*" {
+			v *= rhs
+		} else {
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero in %q", p.src)
+			}
+			v /= rhs
+		}
+	}
+	return v, nil
+}
+
+// unary = ["-" | "+"] primary
+func (p *parser) unary() (float64, error) {
+	if p.tok == "-" || p.tok == "+" {
+		neg := p.tok == "-"
+		p.next()
+		v, err := p.unary()
+		if neg {
+			v = -v
+		}
+		return v, err
+	}
+	return p.primary()
+}
+
+// primary = number | name | "(" sum ")"
+func (p *parser) primary() (float64, error) {
+	tok := p.tok
+	switch {
+	case tok == "":
+		return 0, fmt.Errorf("unexpected end of %q", p.src)
+	case tok == "(":
+		p.next()
+		v, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		if p.tok != ")" {
+			return 0, fmt.Errorf("missing ) in %q", p.src)
+		}
+		p.next()
+		return v, nil
+	case isDigit(tok[0]) || tok[0] == '.':
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", tok)
+		}
+		p.next()
+		return v, nil
+	case isName(tok):
+		v, ok := p.vars[tok]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %q", tok)
+		}
+		p.next()
+		return v, nil
+	}
+	return 0, fmt.Errorf("unexpected %q in %q", tok, p.src)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+//go:embed help/*.md
+var helpFiles embed.FS
+
+// helpTopic is a single glossary page loaded from the embedded help directory
+type helpTopic struct {
+	ID    string // File name without extension, used for cross-links
+	Title string // First Markdown heading
+	Body  string // Full Markdown source
+}
+
+var helpTopics = loadHelpTopics()
+
+// loadHelpTopics reads every embedded Markdown page, sorted by title
+func loadHelpTopics() []helpTopic {
+	entries, err := helpFiles.ReadDir("help")
+	if err != nil {
+		return nil
+	}
+
+	var topics []helpTopic
+	for _, entry := range entries {
+		data, err := helpFiles.ReadFile(path.Join("help", entry.Name()))
+		if err != nil {
+			continue
+		}
+		body := string(data)
+		title := strings.TrimSuffix(entry.Name(), ".md")
+		if line, _, _ := strings.Cut(body, "\n"); strings.HasPrefix(line, "# ") {
+			title = strings.TrimPrefix(line, "# ")
+		}
+		topics = append(topics, helpTopic{
+			ID:    strings.TrimSuffix(entry.Name(), ".md"),
+			Title: title,
+			Body:  body,
+		})
+	}
+
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Title < topics[j].Title })
+	return topics
+}
+
+// findHelpTopic looks up a topic by its ID
+func findHelpTopic(id string) (helpTopic, bool) {
+	for _, t := range helpTopics {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return helpTopic{}, false
+}
+
+// searchHelpTopics returns the topics whose title or body contain the query
+func searchHelpTopics(query string) []helpTopic {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return helpTopics
+	}
+
+	var matches []helpTopic
+	for _, t := range helpTopics {
+		if strings.Contains(strings.ToLower(t.Title), query) ||
+			strings.Contains(strings.ToLower(t.Body), query) {
+			matches = append(matches, t)
+		}
+	}
+	return matches
+}
+
+// withHelp attaches a "?" button to a form field that opens the matching glossary page
+func withHelp(win fyne.Window, topicID string, field fyne.CanvasObject) fyne.CanvasObject {
+	btn := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() {
+		showHelpTopic(win, topicID)
+	})
+	btn.Importance = widget.LowImportance
+	return container.NewBorder(nil, nil, nil, btn, field)
+}
+
+// showHelpTopic displays a single glossary page in a dialog
+func showHelpTopic(win fyne.Window, topicID string) {
+	topic, ok := findHelpTopic(topicID)
+	if !ok {
+		return
+	}
+	body := widget.NewRichTextFromMarkdown(topic.Body)
+	body.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(body)
+	scroll.SetMinSize(fyne.NewSize(380, 280))
+	dialog.ShowCustom(topic.Title, "Close", scroll, win)
+}
+
+// --- TOOL 4: Help / Glossary ---
+func makeHelpTab() fyne.CanvasObject {
+	visible := helpTopics
+
+	body := widget.NewRichTextFromMarkdown("")
+	body.Wrapping = fyne.TextWrapWord
+
+	list := widget.NewList(
+		func() int { return len(visible) },
+		func() fyne.CanvasObject { return widget.NewLabel("Topic") },
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(visible[id].Title)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		body.ParseMarkdown(visible[id].Body)
+	}
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search the glossary...")
+	search.OnChanged = func(q string) {
+		visible = searchHelpTopics(q)
+		list.UnselectAll()
+		list.Refresh()
+		if len(visible) > 0 {
+			list.Select(0)
+		} else {
+			body.ParseMarkdown("*No matching topics.*")
+		}
+	}
+
+	if len(visible) > 0 {
+		list.Select(0)
+	}
+
+	split := container.NewHSplit(list, container.NewVScroll(body))
+	split.Offset = 0.35
+
+	return container.NewBorder(search, nil, nil, nil, split)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/stc"
+)
+
+// maxHistory caps how many calculations are kept for recomputation
+const maxHistory = 50
+
+// history lists this session's calculations, oldest first, kept so they
+// can be recomputed when tax data changes or saved for the recompute command
+var history []*stc.SessionEntry
+
+// recordHistory remembers a calculation with its result
+func recordHistory(e stc.SessionEntry) *stc.SessionEntry {
+	history = append(history, &e)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return &e
+}
+
+// brokerYTD totals the commissions paid and sales made this tax year, for
+// annual fee caps and volume tiers. Only reconciled entries count: those
+// whose shares were kept describe real trades, the rest are what-ifs.
+func brokerYTD() stc.BrokerYTD {
+	year := time.Now().In(taxHome()).Year()
+	var ytd stc.BrokerYTD
+	add := func(fees, sold float64, taxYear int) {
+		if sold > 0 && taxYear == year {
+			ytd.Fees += fees
+			ytd.Trades++
+		}
+	}
+	for _, e := range history {
+		if !e.Reconciled {
+			continue
+		}
+		switch {
+		case e.Result != nil:
+			add(e.Result.BrokerFees-e.Result.FlatFee, e.Result.SharesToSell, e.Result.Meta.TaxYear)
+		case e.RSUResult != nil:
+			add(e.RSUResult.BrokerCommission, e.RSUResult.SharesToSell, e.RSUResult.Meta.TaxYear)
+		}
+	}
+	return ytd
+}
+
+// pendingRecompute is one history entry's numbers under the updated tax data
+type pendingRecompute struct {
+	entry   *stc.SessionEntry
+	updated stc.SessionEntry
+	changes []stc.Change
+}
+
+// offerRecompute reruns every non-reconciled calculation with the rates and
+// fees of updated, shows the before/after differences, and replaces the
+// stored numbers if the user accepts
+func offerRecompute(win fyne.Window, updated stc.Config) {
+	var pending []pendingRecompute
+	for _, e := range history {
+		if e.Reconciled {
+			continue
+		}
+		next := e.Run(e.Config.Rebase(updated))
+		if changes := stc.Diff(e.Graph(), next.Graph()); len(changes) > 0 {
+			pending = append(pending, pendingRecompute{e, next, changes})
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	for _, p := range pending {
+		fmt.Fprintf(&b, "%s\n", p.entry.Title)
+		for _, c := range p.changes {
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	diff := widget.NewLabel(b.String())
+	diff.TextStyle = fyne.TextStyle{Monospace: true}
+	scroll := container.NewVScroll(diff)
+	scroll.SetMinSize(fyne.NewSize(420, 280))
+
+	content := container.NewBorder(
+		widget.NewLabel(fmt.Sprintf("The new tax data changes %d earlier calculations:", len(pending))),
+		nil, nil, nil, scroll)
+	dialog.ShowCustomConfirm("Recompute Calculations", "Recompute", "Keep Old Numbers", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		for _, p := range pending {
+			*p.entry = p.updated
+		}
+	}, win)
+}
+
+// showSaveSessionDialog writes this session's calculations for the
+// recompute command or a later Open Session
+func showSaveSessionDialog(win fyne.Window) {
+	if len(history) == 0 {
+		dialog.ShowInformation("Save Session", "No calculations have been run yet.", win)
+		return
+	}
+	session := stc.Session{Saved: time.Now()}
+	for _, e := range history {
+		session.Entries = append(session.Entries, *e)
+	}
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+		if err := session.Save(w); err != nil {
+			dialog.ShowError(err, win)
+		}
+	}, win)
+	save.SetFileName("session-" + session.Saved.Format("2006-01-02") + ".json")
+	save.Show()
+}
+
+// showOpenSessionDialog replaces the history with a saved session, e.g. one
+// written by the recompute command
+func showOpenSessionDialog(win fyne.Window) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+		session, err := stc.LoadSession(r)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		history = nil
+		for _, e := range session.Entries {
+			recordHistory(e)
+		}
+		dialog.ShowInformation("Open Session", fmt.Sprintf("Loaded %d calculations.", len(history)), win)
+	}, win)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
+
+// showSaveScenarioDialog writes the latest exercise or release as a
+// scenario, its config and inputs, to share or open again later
+func showSaveScenarioDialog(win fyne.Window) {
+	var scenario stc.Scenario
+	found := false
+	for i := len(history) - 1; i >= 0 && !found; i-- {
+		scenario, found = history[i].Scenario()
+	}
+	if !found {
+		dialog.ShowInformation("Save Calculation", "No exercise or release has been calculated yet.", win)
+		return
+	}
+	data, err := scenario.Marshal()
+	if err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			return
+		}
+		defer w.Close()
+		if _, err := w.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write scenario: %w", err), win)
+		}
+	}, win)
+	save.SetFileName("scenario-" + time.Now().Format("2006-01-02") + ".json")
+	save.Show()
+}
+
+// showOpenScenarioDialog loads a saved scenario into the tab for its kind,
+// which recalculates it under the scenario's own config
+func showOpenScenarioDialog(win fyne.Window, bus *events.Bus) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read scenario: %w", err), win)
+			return
+		}
+		scenario, err := stc.UnmarshalScenario(data)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		bus.Publish(events.EntryOpened, scenario.Entry())
+	}, win)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	open.Show()
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/widgets"
+)
+
+// --- TOOL 6: PORTFOLIO ---
+// makePortfolioTab lists each held lot with its long-term countdown and the tax
+// difference between selling today and waiting. It re-renders on portfolio and price events.
+func makePortfolioTab(win fyne.Window, pf *portfolio.Portfolio, bus *events.Bus) fyne.CanvasObject {
+	priceEntry := widgets.NewSmartEntry("0.00")
+	stRateEntry := widgets.NewSmartEntry("0.24")
+	ltRateEntry := widgets.NewSmartEntry("0.15")
+
+	var statuses []portfolio.LotStatus
+	lblTotals := widget.NewLabel("-")
+	lblHarvest := widget.NewLabel("")
+	lblHarvest.Wrapping = fyne.TextWrapWord
+	lblValuation := widget.NewLabel("-")
+
+	// Private companies are priced at the valuation in effect today
+	currentPrice := func() float64 {
+		if valuationMode == events.ModePrivate {
+			v, ok := pf.ValuationOn(time.Now())
+			if !ok {
+				lblValuation.SetText("No valuation recorded")
+				return 0
+			}
+			lblValuation.SetText(fmt.Sprintf("$%.2f (%s, %s)", v.Price, v.Source, v.Effective.Format("2006-01-02")))
+			return v.Price
+		}
+		price, _ := parseFloat(priceEntry.Text)
+		return price
+	}
+
+	list := widget.NewList(
+		func() int { return len(statuses) },
+		func() fyne.CanvasObject {
+			title := widget.NewLabelWithStyle("Lot", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			return container.NewVBox(title, widget.NewLabel("Detail"))
+		},
+		func(id widget.ListItemID, o fyne.CanvasObject) {
+			st := statuses[id]
+			rows := o.(*fyne.Container).Objects
+
+			symbol := st.Lot.Symbol
+			if symbol == "" {
+				symbol = st.Lot.Source
+			}
+			basis := fmt.Sprintf("$%.2f", st.Lot.CostBasis)
+			if st.Lot.FX != nil {
+				basis = fmt.Sprintf("%.2f %s ($%.2f)", st.Lot.CostBasis, st.Lot.Currency, st.Lot.BasisUSD())
+			}
+			rows[0].(*widget.Label).SetText(fmt.Sprintf("%s · %.0f sh @ %s · %s",
+				symbol, st.Lot.Shares, basis, st.Lot.Acquired.Format("2006-01-02")))
+
+			detail := fmt.Sprintf("Long-term · gain $%.2f · tax $%.2f", st.Gain, st.TaxNow)
+			if st.DaysToLongTerm > 0 {
+				detail = fmt.Sprintf("%d days to long-term · gain $%.2f · waiting saves $%.2f",
+					st.DaysToLongTerm, st.Gain, st.WaitSavings)
+			}
+			rows[1].(*widget.Label).SetText(detail)
+		},
+	)
+
+	refresh := func() {
+		price := currentPrice()
+		st, _ := parseFloat(stRateEntry.Text)
+		lt, _ := parseFloat(ltRateEntry.Text)
+
+		now := time.Now()
+		rates := portfolio.CapGainsRates{ShortTerm: st, LongTerm: lt}
+		statuses = pf.Statuses(price, now, rates)
+		list.Refresh()
+		lblHarvest.SetText(harvestSummary(pf.HarvestCandidates(price, now, rates)))
+
+		var gain, taxNow, savings float64
+		for _, s := range statuses {
+			gain += s.Gain
+			taxNow += s.TaxNow
+			savings += s.WaitSavings
+		}
+		lblTotals.SetText(fmt.Sprintf("Gain $%.2f  ·  Tax if sold today $%.2f  ·  Saved by waiting $%.2f",
+			gain, taxNow, savings))
+	}
+
+	// A new price is shared with every tab; rate changes only affect this one
+	priceEntry.SetOnEnter(func() {
+		price, err := parseFloat(priceEntry.Text)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		bus.Publish(events.PriceFetched, events.Price{Price: price})
+	})
+	for _, e := range []*widgets.SmartEntry{stRateEntry, ltRateEntry} {
+		e.SetOnEnter(refresh)
+	}
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) { refresh() })
+	bus.Subscribe(events.ModeChanged, func(events.Event) { refresh() })
+	bus.Subscribe(events.PriceFetched, func(e events.Event) {
+		priceEntry.SetText(fmt.Sprintf("%.2f", e.Payload.(events.Price).Price))
+		refresh()
+	})
+
+	giftBtn := widget.NewButtonWithIcon("Gift Report...", theme.DocumentSaveIcon(), func() {
+		showGiftReportDialog(win, pf, currentPrice())
+	})
+
+	form := widgets.NewFieldSet()
+	form.Append(fieldCurrentPrice, priceEntry)
+	form.Append(fieldValuation, lblValuation)
+	form.Append("Short-Term Rate", stRateEntry)
+	form.Append("Long-Term Rate", ltRateEntry)
+	bus.Subscribe(events.FieldsHidden, func(e events.Event) {
+		form.SetHidden(e.Payload.(map[string]bool))
+	})
+
+	content := container.NewBorder(
+		container.NewVBox(widget.NewCard("Holdings", "", form), widget.NewSeparator()),
+		container.NewVBox(widget.NewSeparator(), lblTotals, lblHarvest, giftBtn),
+		nil, nil,
+		list,
+	)
+
+	refresh()
+	return container.NewPadded(content)
+}
+
+// harvestSummary describes tax-loss harvesting candidates and their wash-sale status
+func harvestSummary(candidates []portfolio.HarvestCandidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("Tax-loss harvesting:\n")
+	for _, c := range candidates {
+		fmt.Fprintf(&b, "• %.0f sh from %s: loss $%.2f, saves ~$%.2f",
+			c.Lot.Shares, c.Lot.Acquired.Format("2006-01-02"), c.Loss, c.TaxBenefit)
+		if c.WashSale {
+			fmt.Fprintf(&b, " — wash sale: acquisition on %s", c.Conflicts[0].Format("2006-01-02"))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Do not buy the same stock (or vest new shares) until %s.",
+		candidates[0].RepurchaseAfter.Format("2006-01-02"))
+	return b.String()
+}
+
+// showGiftReportDialog collects the lots and recipient for a gift and saves the basis report.
+// Files ending in .csv are written as CSV, anything else as a plain-text statement.
+func showGiftReportDialog(win fyne.Window, pf *portfolio.Portfolio, price float64) {
+	if len(pf.Lots) == 0 {
+		dialog.ShowInformation("Gift Report", "The portfolio has no lots to gift.", win)
+		return
+	}
+
+	labels := make([]string, len(pf.Lots))
+	byLabel := make(map[string]portfolio.Lot, len(pf.Lots))
+	for i, lot := range pf.Lots {
+		labels[i] = fmt.Sprintf("%s %.0f sh (%s)", lot.Symbol, lot.Shares, lot.Acquired.Format("2006-01-02"))
+		byLabel[labels[i]] = lot
+	}
+	lotGroup := widget.NewCheckGroup(labels, nil)
+	donorEntry := widget.NewEntry()
+	recipientEntry := widget.NewEntry()
+	recipientEntry.SetPlaceHolder("e.g. Fidelity Charitable DAF")
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().Format("2006-01-02"))
+	localeSelect := newReportLocaleSelect()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Lots", lotGroup),
+		widget.NewFormItem("Donor", donorEntry),
+		widget.NewFormItem("Recipient", recipientEntry),
+		widget.NewFormItem("Gift Date", dateEntry),
+		widget.NewFormItem("Locale", localeSelect),
+	}
+
+	dialog.ShowForm("Gift Report", "Save...", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		giftDate, err := time.ParseInLocation("2006-01-02", dateEntry.Text, time.Local)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Gift date must be YYYY-MM-DD"), win)
+			return
+		}
+		var lots []portfolio.Lot
+		for _, label := range lotGroup.Selected {
+			lots = append(lots, byLabel[label])
+		}
+		if len(lots) == 0 {
+			dialog.ShowError(fmt.Errorf("Select at least one lot"), win)
+			return
+		}
+
+		report := portfolio.NewGiftReport(lots, donorEntry.Text, recipientEntry.Text, giftDate, price)
+		loc := reportLocale(localeSelect)
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if strings.EqualFold(w.URI().Extension(), ".csv") {
+				err = report.ToCSV(w)
+			} else {
+				err = report.WriteTextIn(w, loc)
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName("gift-basis-" + giftDate.Format("2006-01-02") + ".txt")
+		save.Show()
+	}, win)
+}
+
+// showLotCurrencyDialog records the currency a lot was bought in and fetches
+// the FX rate on its acquisition date, so reports can state the basis in USD
+func showLotCurrencyDialog(win fyne.Window, pf *portfolio.Portfolio, onChanged func()) {
+	if len(pf.Lots) == 0 {
+		dialog.ShowInformation("Lot Currency", "The portfolio has no lots.", win)
+		return
+	}
+
+	labels := make([]string, len(pf.Lots))
+	lots := make(map[string]portfolio.Lot, len(pf.Lots))
+	for i, lot := range pf.Lots {
+		labels[i] = fmt.Sprintf("%s %.0f sh (%s)", lot.Symbol, lot.Shares, lot.Acquired.Format("2006-01-02"))
+		lots[labels[i]] = lot
+	}
+	lotSelect := widget.NewSelect(labels, nil)
+	currencyEntry := widget.NewEntry()
+	currencyEntry.SetPlaceHolder("EUR")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Lot", lotSelect),
+		widget.NewFormItem("Currency", currencyEntry),
+	}
+	dialog.ShowForm("Lot Currency", "Fetch Rate", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		lot, found := lots[lotSelect.Selected]
+		if !found {
+			dialog.ShowError(fmt.Errorf("Select a lot"), win)
+			return
+		}
+		currency := currencyEntry.Text
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			rate, err := portfolio.FrankfurterSource{}.RateOn(ctx, currency, lot.Acquired)
+			fyne.Do(func() {
+				if err == nil {
+					err = pf.SetFX(lot.ID, rate)
+				}
+				if err != nil {
+					dialog.ShowError(err, win)
+					return
+				}
+				onChanged()
+				dialog.ShowInformation("Lot Currency", fmt.Sprintf("1 %s = %g USD on %s\nSource: %s",
+					rate.Currency, rate.Rate, rate.Date.Format("2006-01-02"), rate.Source), win)
+			})
+		}()
+	}, win)
+}
+
+// showImportLotsDialog reads lots from a statement CSV. When some rows are
+// already held, the user chooses whether to skip, merge, or replace them.
+func showImportLotsDialog(win fyne.Window, pf *portfolio.Portfolio, onChanged func()) {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		defer r.Close()
+		lots, err := portfolio.ReadLotsCSV(r, taxHome())
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+
+		apply := func(mode portfolio.ImportMode) {
+			report := pf.Import(lots, mode)
+			onChanged()
+			dialog.ShowInformation("Import Lots", "Imported: "+report.String()+".", win)
+		}
+		dups := pf.Duplicates(lots)
+		if dups == 0 {
+			apply(portfolio.ImportSkip)
+			return
+		}
+
+		labels := map[string]portfolio.ImportMode{
+			"Skip duplicates":                portfolio.ImportSkip,
+			"Merge into existing lots":       portfolio.ImportMerge,
+			"Replace existing with imported": portfolio.ImportReplace,
+		}
+		modeGroup := widget.NewRadioGroup([]string{
+			"Skip duplicates", "Merge into existing lots", "Replace existing with imported",
+		}, nil)
+		modeGroup.Required = true
+		modeGroup.SetSelected("Skip duplicates")
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("", widget.NewLabel(fmt.Sprintf(
+				"%d of %d rows match lots already in the portfolio.", dups, len(lots)))),
+			widget.NewFormItem("Duplicates", modeGroup),
+		}
+		dialog.ShowForm("Import Lots", "Import", "Cancel", items, func(ok bool) {
+			if ok {
+				apply(labels[modeGroup.Selected])
+			}
+		}, win)
+	}, win)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".csv"}))
+	open.Show()
+}
@@ -0,0 +1,28 @@
+//go:build (darwin && !ios) || (linux && !android) || windows
+
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"golang.design/x/hotkey"
+)
+
+// registerGlobalHotkey calls show when the quick-calc hotkey is pressed,
+// even while another application has focus. A combination another program
+// already holds is logged and left to the in-app shortcut.
+func registerGlobalHotkey(show func()) {
+	// macOS only accepts hotkeys registered on the main thread; presses are
+	// then waited for off it
+	fyne.Do(func() {
+		hk := hotkey.New(globalHotkeyMods, hotkey.KeySpace)
+		if err := hk.Register(); err != nil {
+			fyne.LogError("Failed to register the quick-calc hotkey", err)
+			return
+		}
+		go func() {
+			for range hk.Keydown() {
+				fyne.Do(show)
+			}
+		}()
+	})
+}
@@ -0,0 +1,8 @@
+//go:build darwin && !ios
+
+package main
+
+import "golang.design/x/hotkey"
+
+// globalHotkeyMods match quickCalcShortcut: Cmd+Shift+Space
+var globalHotkeyMods = []hotkey.Modifier{hotkey.ModCmd, hotkey.ModShift}
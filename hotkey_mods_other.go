@@ -0,0 +1,8 @@
+//go:build (linux && !android) || windows
+
+package main
+
+import "golang.design/x/hotkey"
+
+// globalHotkeyMods match quickCalcShortcut: Ctrl+Shift+Space
+var globalHotkeyMods = []hotkey.Modifier{hotkey.ModCtrl, hotkey.ModShift}
@@ -0,0 +1,7 @@
+//go:build !((darwin && !ios) || (linux && !android) || windows)
+
+package main
+
+// registerGlobalHotkey does nothing where there is no global hotkey, such as
+// mobile and the browser; the in-app shortcut still opens quick calc
+func registerGlobalHotkey(show func()) {}
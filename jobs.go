@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/schedule"
+	"fynance/stc"
+)
+
+const (
+	jobsKey      = "jobs.records"
+	priceFeedKey = "jobs.priceFeed" // URL of a JSON quote, e.g. {"price": 123.45}
+
+	autoBackupPrefix = "backup-"
+	maxAutoBackups   = 8
+	maxQuoteSize     = 64 << 10
+)
+
+// prefJobStore keeps job records in preferences
+type prefJobStore struct {
+	app fyne.App
+}
+
+// Load reads the saved records
+func (s prefJobStore) Load() (map[string]schedule.Record, error) {
+	records := map[string]schedule.Record{}
+	raw := s.app.Preferences().String(jobsKey)
+	if raw == "" {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &records); err != nil {
+		return nil, fmt.Errorf("invalid job records: %w", err)
+	}
+	return records, nil
+}
+
+// Save writes the records
+func (s prefJobStore) Save(records map[string]schedule.Record) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	s.app.Preferences().SetString(jobsKey, string(data))
+	return nil
+}
+
+// newScheduler builds the background jobs: price refresh, vest and expiry
+// reminders, plan template checks, and portfolio backups
+func newScheduler(a fyne.App, win fyne.Window, pf *portfolio.Portfolio, bus *events.Bus, apply func(stc.Config)) *schedule.Scheduler {
+	return schedule.New(prefJobStore{a},
+		schedule.Job{ID: "price", Name: "Price Refresh", Every: 15 * time.Minute, Run: func(ctx context.Context) error {
+			url := a.Preferences().String(priceFeedKey)
+			if url == "" || demoMode {
+				return nil
+			}
+			price, err := fetchPrice(ctx, url)
+			if err != nil {
+				return err
+			}
+			fyne.DoAndWait(func() { bus.Publish(events.PriceFetched, events.Price{Price: price}) })
+			return nil
+		}},
+		schedule.Job{ID: "reminders", Name: "Vest Reminders", Every: 24 * time.Hour, Run: func(context.Context) error {
+			fyne.DoAndWait(func() { checkPortfolioAlerts(a, pf, time.Now()) })
+			return nil
+		}},
+		schedule.Job{ID: "plan", Name: "Tax Data Updates", Every: 24 * time.Hour, Run: func(context.Context) error {
+			// Checks in the background and asks before applying a change
+			checkPlanUpdates(a, win, apply, false)
+			return nil
+		}},
+		schedule.Job{ID: "backup", Name: "Auto-Backup", Every: 7 * 24 * time.Hour, Run: func(context.Context) error {
+			if demoMode {
+				return nil
+			}
+			var err error
+			fyne.DoAndWait(func() { err = autoBackup(a, pf) })
+			return err
+		}},
+	)
+}
+
+// fetchPrice reads a quote from url: a JSON object with a "price" field,
+// or a bare number
+func fetchPrice(ctx context.Context, url string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price feed URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to fetch price: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxQuoteSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read price: %w", err)
+	}
+	var quote struct {
+		Price float64 `json:"price"`
+	}
+	if err := json.Unmarshal(data, &quote); err != nil {
+		if quote.Price, err = strconv.ParseFloat(strings.TrimSpace(string(data)), 64); err != nil {
+			return 0, fmt.Errorf("invalid price feed response")
+		}
+	}
+	if quote.Price <= 0 {
+		return 0, fmt.Errorf("price feed returned no price")
+	}
+	return quote.Price, nil
+}
+
+// autoBackup saves the portfolio to app storage under today's date and
+// deletes all but the newest maxAutoBackups
+func autoBackup(a fyne.App, pf *portfolio.Portfolio) error {
+	name := autoBackupPrefix + time.Now().Format("2006-01-02") + ".json"
+	if err := savePortfolioFile(a, name, pf); err != nil {
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+	files, err := storage.List(a.Storage().RootURI())
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	var backups []fyne.URI
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), autoBackupPrefix) {
+			backups = append(backups, f)
+		}
+	}
+	// Dated names sort oldest first
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name() < backups[j].Name() })
+	for len(backups) > maxAutoBackups {
+		if err := storage.Delete(backups[0]); err != nil {
+			return fmt.Errorf("failed to delete old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// formatJobTime writes a job time for the jobs page, or "-" when unset
+func formatJobTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(taxHome()).Format("2006-01-02 15:04")
+}
+
+// showJobsDialog lists the background jobs with their last and next runs,
+// and lets each be turned off or run now
+func showJobsDialog(a fyne.App, win fyne.Window, s *schedule.Scheduler) {
+	feedEntry := widget.NewEntry()
+	feedEntry.SetPlaceHolder("https://example.com/quote.json")
+	feedEntry.SetText(a.Preferences().String(priceFeedKey))
+	feedEntry.OnChanged = func(url string) {
+		a.Preferences().SetString(priceFeedKey, strings.TrimSpace(url))
+	}
+
+	// Rows are built once; toggling or running a job only updates the
+	// details, since rebuilding from a check's callback would recreate it
+	rows := container.NewVBox()
+	details := make(map[string]*widget.Label)
+	refresh := func() {
+		for _, st := range s.Statuses() {
+			detail, ok := details[st.ID]
+			if !ok {
+				continue
+			}
+			info := fmt.Sprintf("Every %s · last %s · next %s", st.Every, formatJobTime(st.LastRun), formatJobTime(st.NextRun))
+			if st.LastError != "" {
+				info += "\nLast error: " + st.LastError
+			}
+			detail.SetText(info)
+		}
+	}
+	for _, st := range s.Statuses() {
+		id := st.ID
+		enabled := widget.NewCheck(st.Name, nil)
+		enabled.SetChecked(!st.Disabled)
+		enabled.OnChanged = func(on bool) {
+			if err := s.SetEnabled(id, on); err != nil {
+				dialog.ShowError(err, win)
+			}
+			refresh()
+		}
+		detail := widget.NewLabel("")
+		detail.Wrapping = fyne.TextWrapWord
+		details[id] = detail
+		runBtn := widget.NewButton("Run Now", func() {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				defer cancel()
+				err := s.RunNow(ctx, id)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, win)
+					}
+					refresh()
+				})
+			}()
+		})
+		rows.Add(container.NewBorder(nil, nil, enabled, runBtn, detail))
+	}
+	refresh()
+
+	form := widget.NewForm(widget.NewFormItem("Price Feed URL", feedEntry))
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(620, 280))
+	dialog.ShowCustom("Scheduled Jobs", "Close", container.NewBorder(form, nil, nil, nil, scroll), win)
+}
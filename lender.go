@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+)
+
+// showIncomeStatementDialog saves a lender-friendly summary of equity income
+// received over the lookback period and vests scheduled over the same period ahead
+func showIncomeStatementDialog(win fyne.Window, pf *portfolio.Portfolio) {
+	if len(pf.Lots) == 0 && len(pf.Grants) == 0 {
+		dialog.ShowInformation("Income Statement", "The portfolio has no lots or grants.", win)
+		return
+	}
+
+	employeeEntry := widget.NewEntry()
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().In(taxHome()).Format("2006-01-02"))
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetText("24")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("Price per share for future vests")
+	if valuationMode == events.ModePrivate {
+		if v, ok := pf.ValuationOn(time.Now()); ok {
+			priceEntry.SetText(fmt.Sprintf("%.2f", v.Price))
+		}
+	}
+
+	localeSelect := newReportLocaleSelect()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Employee", employeeEntry),
+		widget.NewFormItem("As Of", dateEntry),
+		widget.NewFormItem("Months", monthsEntry),
+		widget.NewFormItem("Price ($)", priceEntry),
+		widget.NewFormItem("Locale", localeSelect),
+	}
+	dialog.ShowForm("Income Statement", "Save...", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		asOf, errDate := parseDate(dateEntry.Text)
+		months, errMonths := strconv.Atoi(monthsEntry.Text)
+		price, errPrice := parseFloat(priceEntry.Text)
+		if errDate != nil || asOf.IsZero() || errMonths != nil || months <= 0 || errPrice != nil || price < 0 {
+			dialog.ShowError(fmt.Errorf("Please enter a YYYY-MM-DD date, a number of months, and a price"), win)
+			return
+		}
+
+		// Include the whole as-of day
+		statement := portfolio.NewIncomeStatement(pf, employeeEntry.Text, asOf.AddDate(0, 0, 1).Add(-time.Nanosecond), months, price)
+		loc := reportLocale(localeSelect)
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if err := statement.WriteTextIn(w, loc); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName("equity-income-" + asOf.Format("2006-01-02") + ".txt")
+		save.Show()
+	}, win)
+}
@@ -1,38 +1,252 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"os"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/schedule"
+	"fynance/stc"
 )
 
 //go:embed appicon.png
 var appIcon []byte
 
 func main() {
-	myApp := app.New()
+	// Subcommands run headless; anything else opens the window
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
+	myApp := app.NewWithID("com.limpdev.fynance")
 	myApp.SetIcon(fyne.NewStaticResource("appicon.png", appIcon))
-	myWindow := myApp.NewWindow("Fynance")
+	myWindow := myApp.NewWindow(appTitle)
 	myWindow.Resize(fyne.NewSize(500, 400)) // Slightly wider for tabs
 	myApp.Settings().SetTheme(newCustomTheme())
+	loadVariables(myApp)
+	loadTaxHome(myApp)
+	loadValuationMode(myApp)
+	loadDisplayLocale(myApp)
 
 	// Create the individual tool interfaces
-	stcTab := makeSTCTab(myWindow)
-	rsuTab := makeRSUTab(myWindow) // New RSU Tab
+	// Tabs share state through the event bus instead of calling each other
+	bus := events.NewBus()
+
+	// Shares kept after a sell-to-cover feed the YEAR dashboard
+	pf := loadPortfolio(myApp)
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) {
+		if demoMode {
+			return
+		}
+		if err := savePortfolio(myApp, pf); err != nil {
+			fyne.LogError("Failed to save portfolio", err)
+		}
+	})
+	// Vest, expiry, and price alerts go out through the routes in Notifications
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) {
+		checkPortfolioAlerts(myApp, pf, time.Now())
+	})
+	bus.Subscribe(events.PriceFetched, func(e events.Event) {
+		checkPriceAlert(myApp, e.Payload.(events.Price), time.Now())
+	})
+	yearTab := makeYearTab(pf, bus)
+	portfolioTab := makePortfolioTab(myWindow, pf, bus)
+	portfolioChanged := func() {
+		bus.Publish(events.PortfolioChanged, nil)
+	}
+	keepLot := func(lot portfolio.Lot) {
+		pf.Add(lot)
+		portfolioChanged()
+	}
+
+	// Tools start from the rates of the most recent calculation
+	currentConfig := stc.Config{TaxRates: defaultTaxRates, BrokerFees: defaultBrokerFees, RegulatoryFees: stc.CurrentRegulatoryFees,
+		SocialSecWageBase: stc.SocialSecWageBase}
+	bus.Subscribe(events.InputChanged, func(e events.Event) {
+		currentConfig = e.Payload.(stc.Config)
+	})
+
+	showQuickCalc := newQuickCalc(myApp, func() stc.Config { return currentConfig })
+	myWindow.Canvas().AddShortcut(quickCalcShortcut, func(fyne.Shortcut) { showQuickCalc() })
+	registerGlobalHotkey(showQuickCalc)
+
+	stcTab := makeSTCTab(myWindow, bus, keepLot, pf.ValuationOn)
+	rsuTab := makeRSUTab(myWindow, bus, keepLot, pf.ValuationOn) // New RSU Tab
+	bus.Publish(events.FieldsHidden, hiddenFields(myApp))
+	pinSummary(myApp, myWindow, bus)
 	calcTab := makeCalculatorTab()
+	helpTab := makeHelpTab()
 
-	// Create the navigation tabs
-	tabs := container.NewAppTabs(
-		container.NewTabItemWithIcon("EXERCISE", theme.DocumentIcon(), stcTab), // Renamed for clarity
-		container.NewTabItemWithIcon("RELEASE", theme.AccountIcon(), rsuTab),   // New Tab
-		container.NewTabItemWithIcon("KEYS", theme.ContentAddIcon(), calcTab),
-	)
+	// Create the navigation tabs; each can be renamed and given an accent color
+	styledTabs := []*styledTab{
+		newStyledTab(myApp, "exercise", "EXERCISE", theme.DocumentIcon(), stcTab),
+		newStyledTab(myApp, "release", "RELEASE", theme.AccountIcon(), rsuTab),
+		newStyledTab(myApp, "portfolio", "PORTFOLIO", theme.StorageIcon(), portfolioTab),
+		newStyledTab(myApp, "year", "YEAR", theme.HistoryIcon(), yearTab),
+		newStyledTab(myApp, "keys", "KEYS", theme.ContentAddIcon(), calcTab),
+		newStyledTab(myApp, "help", "HELP", theme.HelpIcon(), helpTab),
+	}
+	tabs := container.NewAppTabs()
+	for _, t := range styledTabs {
+		tabs.Append(t.item)
+	}
 
 	tabs.SetTabLocation(container.TabLocationTop)
+	// The lock shows the tabs and covers them after the idle time in Settings
+	lock := newAppLock(myApp, myWindow, bus, tabs)
+
+	// Plan templates update every open form at once
+	applyConfig := func(cfg stc.Config) {
+		bus.Publish(events.ProfileSwitched, cfg)
+	}
+	sched := newScheduler(myApp, myWindow, pf, bus, applyConfig)
+	// Valuations only apply to private companies
+	valuationItem := fyne.NewMenuItem("Record Valuation...", func() {
+		showValuationDialog(myWindow, pf, portfolioChanged)
+	})
+	valuationItem.Disabled = valuationMode != events.ModePrivate
+	portfolioMenu := fyne.NewMenu("Portfolio",
+		fyne.NewMenuItem("Add Grant...", func() {
+			showAddGrantDialog(myWindow, pf, portfolioChanged)
+		}),
+		fyne.NewMenuItem("Grant Documents...", func() {
+			showDocumentsDialog(myApp, myWindow, pf, portfolioChanged)
+		}),
+		fyne.NewMenuItem("Search Documents...", func() {
+			showDocumentSearch(myApp, myWindow, pf)
+		}),
+		fyne.NewMenuItem("PSU Payouts...", func() {
+			showPSUPayouts(myWindow, pf, currentConfig)
+		}),
+		fyne.NewMenuItem("Model Acquisition...", func() {
+			showMergerDialog(myWindow, pf, portfolioChanged)
+		}),
+		fyne.NewMenuItem("Record Corporate Action...", func() {
+			showCorporateActionDialog(myWindow, pf, portfolioChanged)
+		}),
+		fyne.NewMenuItem("Import Lots...", func() {
+			showImportLotsDialog(myWindow, pf, portfolioChanged)
+		}),
+		fyne.NewMenuItem("Set Lot Currency...", func() {
+			showLotCurrencyDialog(myWindow, pf, portfolioChanged)
+		}),
+		valuationItem,
+		fyne.NewMenuItem("Income Statement...", func() {
+			showIncomeStatementDialog(myWindow, pf)
+		}),
+		fyne.NewMenuItem("Diversification Plan...", func() {
+			showSellDownDialog(myWindow, pf)
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Back Up...", func() {
+			if demoBlocks(myWindow, "Back Up") {
+				return
+			}
+			showBackupDialog(myApp, myWindow, pf)
+		}),
+		fyne.NewMenuItem("Restore Backup...", func() {
+			if demoBlocks(myWindow, "Restore Backup") {
+				return
+			}
+			showRestoreDialog(myApp, myWindow, pf, portfolioChanged)
+		}),
+	)
+	bus.Subscribe(events.ModeChanged, func(e events.Event) {
+		valuationItem.Disabled = e.Payload.(events.Mode) != events.ModePrivate
+		portfolioMenu.Refresh()
+	})
+	quickCalcItem := fyne.NewMenuItem("Quick Calc...", showQuickCalc)
+	quickCalcItem.Shortcut = quickCalcShortcut
+	// Sample data for exploring the app; the user's own data is left alone
+	demoItem := fyne.NewMenuItem("Demo Mode", nil)
+	// Key figures in a small window kept above the broker's site
+	showMini := newMiniWindow(myApp, bus, lock)
+	toolsMenu := fyne.NewMenu("Tools",
+		quickCalcItem,
+		fyne.NewMenuItem("Mini Window", showMini),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Grant Value Projector...", func() {
+			showGrantProjector(myWindow)
+		}),
+		fyne.NewMenuItem("Refresher vs Cash...", func() {
+			showRefresherComparison(myWindow)
+		}),
+		fyne.NewMenuItem("Deferral Election...", func() {
+			showDeferralComparison(myWindow, currentConfig)
+		}),
+		fyne.NewMenuItem("Cash-Settled Award...", func() {
+			showCashAward(myWindow, currentConfig)
+		}),
+		fyne.NewMenuItem("Explain My Paystub...", func() {
+			showPaystubExplainer(myWindow, currentConfig)
+		}),
+		fyne.NewMenuItem("Year-End Scorecard...", func() {
+			showScorecardDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Tax Remittance...", func() {
+			showRemittanceDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Foreign Tax Credit...", func() {
+			showForeignTaxCreditDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Compare Calculations...", func() {
+			showCompareDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Scenario Matrix...", func() {
+			showScenarioMatrix(myWindow, currentConfig)
+		}),
+		fyne.NewMenuItem("Price Sensitivity...", func() {
+			showPriceSensitivity(myWindow, currentConfig)
+		}),
+		fyne.NewMenuItem("Named Variables...", func() {
+			showVariablesDialog(myApp, myWindow)
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Save Session...", func() {
+			showSaveSessionDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Open Session...", func() {
+			showOpenSessionDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Save Calculation...", func() {
+			showSaveScenarioDialog(myWindow)
+		}),
+		fyne.NewMenuItem("Open Calculation...", func() {
+			showOpenScenarioDialog(myWindow, bus)
+		}),
+		fyne.NewMenuItemSeparator(),
+		demoItem,
+		fyne.NewMenuItem("Lock Now", func() { lock.Lock() }),
+		fyne.NewMenuItem("Settings...", func() {
+			showSettingsDialog(myApp, myWindow, bus)
+		}),
+		fyne.NewMenuItem("Customize Tabs...", func() {
+			showTabStyleDialog(myApp, myWindow, tabs, styledTabs)
+		}),
+		fyne.NewMenuItem("Notifications...", func() {
+			showNotificationsDialog(myApp, myWindow)
+		}),
+		fyne.NewMenuItem("Scheduled Jobs...", func() {
+			showJobsDialog(myApp, myWindow, sched)
+		}),
+	)
+	demoItem.Action = func() {
+		setDemoMode(myApp, myWindow, bus, pf, !demoMode)
+		demoItem.Checked = demoMode
+		toolsMenu.Refresh()
+	}
+	myWindow.SetMainMenu(fyne.NewMainMenu(makePlanMenu(myApp, myWindow, applyConfig), portfolioMenu, toolsMenu))
+	// Reminders, plan checks, price refreshes, and backups that fell due
+	// while the app was closed run now
+	sched.Start(context.Background(), schedule.DefaultTick)
 
-	myWindow.SetContent(tabs)
 	myWindow.ShowAndRun()
 }
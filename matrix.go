@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/charts"
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// maxMatrixSteps keeps the heatmap cells readable
+const maxMatrixSteps = 12
+
+// showScenarioMatrix sweeps FMV against the federal rate and shows residual cash as a heatmap.
+// Every other rate and fee comes from base.
+func showScenarioMatrix(win fyne.Window, base stc.Config) {
+	sharesEntry := widgets.NewSmartEntry("1000")
+	strikeEntry := widgets.NewSmartEntry("10.00")
+	fmvFromEntry := widgets.NewSmartEntry("40.00")
+	fmvToEntry := widgets.NewSmartEntry("60.00")
+	fedFromEntry := widgets.NewSmartEntry("0.22")
+	fedToEntry := widgets.NewSmartEntry("0.37")
+	stepsEntry := widgets.NewSmartEntry("5")
+
+	heatmap := canvas.NewImageFromImage(nil)
+	heatmap.FillMode = canvas.ImageFillContain
+	heatmap.SetMinSize(fyne.NewSize(560, 320))
+
+	run := func() {
+		shares, err1 := parseFloat(sharesEntry.Text)
+		strike, err2 := parseFloat(strikeEntry.Text)
+		fmvFrom, err3 := parseFloat(fmvFromEntry.Text)
+		fmvTo, err4 := parseFloat(fmvToEntry.Text)
+		fedFrom, err5 := parseFloat(fedFromEntry.Text)
+		fedTo, err6 := parseFloat(fedToEntry.Text)
+		steps, err7 := parseFloat(stepsEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+			return
+		}
+		if shares <= 0 || fmvFrom <= 0 || fmvTo <= 0 || steps < 2 || steps > maxMatrixSteps {
+			dialog.ShowError(fmt.Errorf("Shares and FMV must be greater than 0 and steps between 2 and %d", maxMatrixSteps), win)
+			return
+		}
+
+		n := int(steps)
+		inputs := make([]stc.Input, n)
+		configs := make([]stc.Config, n)
+		for i := 0; i < n; i++ {
+			f := float64(i) / float64(n-1)
+			inputs[i] = stc.Input{ExercisePrice: strike, ExercisedShares: shares, FMV: fmvFrom + (fmvTo-fmvFrom)*f}
+			configs[i] = base
+			configs[i].TaxRates.Federal = fedFrom + (fedTo-fedFrom)*f
+		}
+
+		data := charts.FromMatrix(stc.Matrix(inputs, configs), "Residual Cash",
+			func(cfg stc.Config) string { return fmt.Sprintf("%.1f%%", cfg.TaxRates.Federal*100) },
+			func(in stc.Input) string { return fmt.Sprintf("$%.2f", in.FMV) },
+			func(r stc.Result) float64 { return r.Residual },
+		)
+		data.XLabel = "FMV"
+		data.YLabel = "Federal Rate"
+
+		png, err := charts.RenderPNG(data, charts.Heatmap, image.Pt(720, 420))
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		heatmap.Image = nil
+		heatmap.Resource = fyne.NewStaticResource("matrix.png", png)
+		heatmap.Refresh()
+	}
+
+	for _, e := range []*widgets.SmartEntry{sharesEntry, strikeEntry, fmvFromEntry, fmvToEntry, fedFromEntry, fedToEntry, stepsEntry} {
+		e.SetOnEnter(run)
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Exercised Shares", sharesEntry),
+		widget.NewFormItem("Exercise Price ($)", strikeEntry),
+		widget.NewFormItem("FMV ($)", container.NewGridWithColumns(2, fmvFromEntry, fmvToEntry)),
+		widget.NewFormItem("Federal Rate", container.NewGridWithColumns(2, fedFromEntry, fedToEntry)),
+		widget.NewFormItem("Steps", stepsEntry),
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(form, widget.NewButton("Sweep", run)),
+		nil, nil, nil,
+		heatmap,
+	)
+	dialog.ShowCustom("Scenario Matrix", "Close", content, win)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+	"fynance/stc"
+)
+
+// showAddGrantDialog records an equity award with its vesting schedule
+func showAddGrantDialog(win fyne.Window, pf *portfolio.Portfolio, onChange func()) {
+	kindSelect := widget.NewSelect([]string{string(portfolio.GrantRSU), string(portfolio.GrantNSO), string(portfolio.GrantISO), string(portfolio.GrantPSU)}, nil)
+	kindSelect.SetSelected(string(portfolio.GrantRSU))
+	symbolEntry := widget.NewEntry()
+	unitsEntry := widget.NewEntry()
+	strikeEntry := widget.NewEntry()
+	strikeEntry.SetText("0.00")
+	grantDateEntry := widget.NewEntry()
+	grantDateEntry.SetText(time.Now().Format("2006-01-02"))
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetText("48")
+	cliffEntry := widget.NewEntry()
+	cliffEntry.SetText("12")
+	everyEntry := widget.NewEntry()
+	everyEntry.SetText("3")
+	dateRuleSelect := newDateRuleSelect()
+	// PSU units are the target; the payout range scales them per period
+	payoutEntries := [3]*widget.Entry{widget.NewEntry(), widget.NewEntry(), widget.NewEntry()}
+	for i, m := range []float64{portfolio.DefaultPayoutRange.Threshold, portfolio.DefaultPayoutRange.Target, portfolio.DefaultPayoutRange.Max} {
+		payoutEntries[i].SetText(fmt.Sprintf("%.0f", m*100))
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Type", kindSelect),
+		widget.NewFormItem("Symbol", symbolEntry),
+		widget.NewFormItem("Units", unitsEntry),
+		widget.NewFormItem("Strike ($)", strikeEntry),
+		widget.NewFormItem("Grant Date", grantDateEntry),
+		widget.NewFormItem("Vesting Months", monthsEntry),
+		widget.NewFormItem("Cliff Months", cliffEntry),
+		widget.NewFormItem("Vest Every (mo)", everyEntry),
+		widget.NewFormItem("Vest Dates", withHelp(win, "vest-dates", dateRuleSelect)),
+		widget.NewFormItem("PSU Payout %", container.NewGridWithColumns(3, payoutEntries[0], payoutEntries[1], payoutEntries[2])),
+	}
+
+	dialog.ShowForm("Add Grant", "Add", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		units, err1 := parseFloat(unitsEntry.Text)
+		strike, err2 := parseFloat(strikeEntry.Text)
+		months, err3 := parseFloat(monthsEntry.Text)
+		cliff, err4 := parseFloat(cliffEntry.Text)
+		every, err5 := parseFloat(everyEntry.Text)
+		grantDate, err6 := time.ParseInLocation("2006-01-02", grantDateEntry.Text, time.Local)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers and a YYYY-MM-DD grant date"), win)
+			return
+		}
+		if units <= 0 || months <= 0 || every <= 0 {
+			dialog.ShowError(fmt.Errorf("Units, vesting months, and frequency must be greater than 0"), win)
+			return
+		}
+
+		grant := portfolio.Grant{
+			Symbol: strings.ToUpper(strings.TrimSpace(symbolEntry.Text)),
+			Kind:   portfolio.GrantKind(kindSelect.Selected),
+			Strike: strike,
+			Schedule: stc.VestingSchedule{
+				GrantDate:   grantDate,
+				TotalShares: units,
+				Months:      int(months),
+				CliffMonths: int(cliff),
+				EveryMonths: int(every),
+				DateRule:    stc.DateRules[dateRuleSelect.SelectedIndex()],
+			},
+		}
+		if grant.Kind == portfolio.GrantPSU {
+			var pct [3]float64
+			for i, e := range payoutEntries {
+				v, err := parseFloat(e.Text)
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Please enter PSU payouts as percentages, e.g. 50, 100, 200"), win)
+					return
+				}
+				pct[i] = v / 100
+			}
+			payout := portfolio.PayoutRange{Threshold: pct[0], Target: pct[1], Max: pct[2]}
+			if err := payout.Validate(); err != nil {
+				dialog.ShowError(fmt.Errorf("Please check the PSU payouts: %w", err), win)
+				return
+			}
+			grant.Payout = &payout
+		}
+		pf.AddGrant(grant)
+		onChange()
+	}, win)
+}
+
+// showMergerDialog models announced acquisition terms against the portfolio.
+// If the user adopts the outcome, apply replaces the portfolio contents.
+func showMergerDialog(win fyne.Window, pf *portfolio.Portfolio, onChange func()) {
+	acquirerEntry := widget.NewEntry()
+	closeEntry := widget.NewEntry()
+	closeEntry.SetText(time.Now().AddDate(0, 3, 0).Format("2006-01-02"))
+	cashEntry := widget.NewEntry()
+	cashEntry.SetText("0.00")
+	ratioEntry := widget.NewEntry()
+	ratioEntry.SetText("1")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetText("0.00")
+	accelEntry := widget.NewEntry()
+	accelEntry.SetText("0")
+	treatmentSelect := widget.NewSelect([]string{"Convert", "Cash Out"}, nil)
+	treatmentSelect.SetSelected("Convert")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Acquirer", acquirerEntry),
+		widget.NewFormItem("Close Date", closeEntry),
+		widget.NewFormItem("Cash / Share ($)", cashEntry),
+		widget.NewFormItem("Stock Ratio", ratioEntry),
+		widget.NewFormItem("Acquirer Price ($)", priceEntry),
+		widget.NewFormItem("Acceleration (0-1)", accelEntry),
+		widget.NewFormItem("Unvested Awards", treatmentSelect),
+	}
+
+	dialog.ShowForm("Model Acquisition", "Model", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		cash, err1 := parseFloat(cashEntry.Text)
+		ratio, err2 := parseFloat(ratioEntry.Text)
+		price, err3 := parseFloat(priceEntry.Text)
+		accel, err4 := parseFloat(accelEntry.Text)
+		closeDate, err5 := time.ParseInLocation("2006-01-02", closeEntry.Text, time.Local)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers and a YYYY-MM-DD close date"), win)
+			return
+		}
+
+		terms := portfolio.MergerTerms{
+			Acquirer:      strings.ToUpper(strings.TrimSpace(acquirerEntry.Text)),
+			Close:         closeDate,
+			CashPerShare:  cash,
+			StockRatio:    ratio,
+			AcquirerPrice: price,
+			Acceleration:  accel,
+			Unvested:      portfolio.TreatConvert,
+		}
+		if treatmentSelect.Selected == "Cash Out" {
+			terms.Unvested = portfolio.TreatCashOut
+		}
+
+		out := portfolio.ModelMerger(pf, terms, stc.NewDefaultCalculator())
+		summary := fmt.Sprintf(
+			"Deal value: $%.2f / share\n"+
+				"Cash for held shares: $%.2f\n"+
+				"Shares accelerated at close: %.0f\n"+
+				"Income at close: $%.2f\n"+
+				"Withholding at close: $%.2f\n"+
+				"Cash-out income (all periods): $%.2f\n"+
+				"Lots after close: %d (%.0f shares)\n"+
+				"Grants after close: %d",
+			terms.DealValue(), out.LotCash, out.AcceleratedShares, out.AcceleratedIncome,
+			out.CloseWithholding, out.CashOutIncome, len(out.Portfolio.Lots),
+			out.Portfolio.TotalShares(), len(out.Portfolio.Grants),
+		)
+
+		dialog.ShowConfirm("Acquisition Outcome", summary+"\n\nReplace the portfolio with the post-close holdings?", func(apply bool) {
+			if !apply {
+				return
+			}
+			*pf = *out.Portfolio
+			onChange()
+		}, win)
+	}, win)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/viewmodel"
+)
+
+// miniRows are the outputs the mini window shows, besides the net shares
+var miniRows = []string{viewmodel.RowSharesSold, viewmodel.RowTaxes}
+
+// newMiniWindow returns a function that opens a small window with the four
+// key outputs of the latest calculation: shares kept, shares sold, total
+// taxes, and residual cash. It asks to stay above other windows, so the
+// figures stay in view while filling in the broker's exercise form. Only
+// one window is kept, and it hides while the app is locked.
+func newMiniWindow(a fyne.App, bus *events.Bus, lock *appLock) (show func()) {
+	var win fyne.Window
+	var latest *viewmodel.ViewModel
+
+	title := widget.NewLabelWithStyle("No result yet", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	netShares := widget.NewLabelWithStyle("—", fyne.TextAlignTrailing, fyne.TextStyle{Monospace: true})
+	residual := widget.NewLabelWithStyle("—", fyne.TextAlignTrailing, fyne.TextStyle{Monospace: true})
+	values := make([]*widget.Label, len(miniRows))
+	form := widget.NewForm(widget.NewFormItem("Net Shares:", netShares))
+	for i, label := range miniRows {
+		values[i] = widget.NewLabelWithStyle("—", fyne.TextAlignTrailing, fyne.TextStyle{Monospace: true})
+		form.Append(label, values[i])
+	}
+	form.Append("Residual:", residual)
+
+	refresh := func() {
+		if latest == nil {
+			return
+		}
+		title.SetText(latest.Title)
+		netShares.SetText(latest.NetShares)
+		residual.SetText(latest.Residual)
+		for i, label := range miniRows {
+			v, ok := latest.Value(label)
+			if !ok {
+				v = "—"
+			}
+			values[i].SetText(v)
+		}
+	}
+	bus.Subscribe(events.ResultReady, func(e events.Event) {
+		vm := e.Payload.(viewmodel.ViewModel)
+		latest = &vm
+		refresh()
+	})
+	lock.OnLockChanged(func(locked bool) {
+		if win == nil {
+			return
+		}
+		if locked {
+			win.Hide()
+		} else {
+			win.Show()
+		}
+	})
+
+	return func() {
+		if win != nil {
+			win.Show()
+			win.RequestFocus()
+			return
+		}
+
+		win = a.NewWindow(appTitle + " Mini")
+		win.SetFixedSize(true)
+		win.SetOnClosed(func() { win = nil })
+		win.Canvas().SetOnTypedKey(func(k *fyne.KeyEvent) {
+			if k.Name == fyne.KeyEscape {
+				win.Close()
+			}
+		})
+		win.SetContent(container.NewPadded(container.NewVBox(title, form)))
+		win.Resize(fyne.NewSize(260, 0))
+		refresh()
+		win.Show()
+		keepOnTop(win)
+	}
+}
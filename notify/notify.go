@@ -0,0 +1,128 @@
+// Package notify delivers alerts (vest reminders, price alerts, expiring
+// options) through pluggable sinks: desktop notifications, email, and
+// webhooks. Each kind of alert is routed to its own set of backends.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind identifies a type of alert
+type Kind string
+
+const (
+	VestReminder Kind = "vest"   // Shares release soon
+	PriceAlert   Kind = "price"  // The share price crossed a threshold
+	OptionExpiry Kind = "expiry" // Options expire soon
+	FeeChange    Kind = "fees"   // A subscribed plan template changed its broker fees
+)
+
+// Kinds lists every kind of alert, in the order Settings shows them
+var Kinds = []Kind{VestReminder, PriceAlert, OptionExpiry, FeeChange}
+
+// Message is one alert
+type Message struct {
+	Kind  Kind      `json:"kind"`
+	Title string    `json:"title"`
+	Body  string    `json:"body"`
+	Time  time.Time `json:"time"`
+}
+
+// Sink delivers messages through one backend
+type Sink interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// Backend names a sink in a Config
+type Backend string
+
+const (
+	BackendDesktop Backend = "desktop"
+	BackendEmail   Backend = "email"
+	BackendWebhook Backend = "webhook"
+)
+
+// Backends lists every backend, in the order Settings shows them
+var Backends = []Backend{BackendDesktop, BackendEmail, BackendWebhook}
+
+// Config chooses the backends for each kind of alert and holds their settings
+type Config struct {
+	Routes  map[Kind][]Backend `json:"routes"`
+	Email   EmailConfig        `json:"email,omitempty"`
+	Webhook string             `json:"webhook,omitempty"` // URL that receives each message as JSON
+}
+
+// DefaultConfig sends every alert to the desktop
+func DefaultConfig() Config {
+	routes := make(map[Kind][]Backend, len(Kinds))
+	for _, k := range Kinds {
+		routes[k] = []Backend{BackendDesktop}
+	}
+	return Config{Routes: routes}
+}
+
+// Validate reports a route to a backend that is not set up
+func (c Config) Validate() error {
+	for _, k := range Kinds {
+		for _, b := range c.Routes[k] {
+			switch b {
+			case BackendDesktop:
+			case BackendEmail:
+				if err := c.Email.Validate(); err != nil {
+					return err
+				}
+			case BackendWebhook:
+				if c.Webhook == "" {
+					return fmt.Errorf("webhook alerts need a URL")
+				}
+			default:
+				return fmt.Errorf("unknown notification backend %q", b)
+			}
+		}
+	}
+	return nil
+}
+
+// Dispatcher sends each message to the sinks routed for its kind
+type Dispatcher struct {
+	routes map[Kind][]Backend
+	sinks  map[Backend]Sink
+}
+
+// NewDispatcher routes messages as cfg says. desktop delivers desktop
+// notifications, since only the app can post them; nil drops them.
+func NewDispatcher(cfg Config, desktop Sink) *Dispatcher {
+	d := &Dispatcher{routes: cfg.Routes, sinks: map[Backend]Sink{}}
+	if desktop != nil {
+		d.sinks[BackendDesktop] = desktop
+	}
+	if cfg.Email.Validate() == nil {
+		d.sinks[BackendEmail] = &Email{Config: cfg.Email}
+	}
+	if cfg.Webhook != "" {
+		d.sinks[BackendWebhook] = &Webhook{URL: cfg.Webhook}
+	}
+	return d
+}
+
+// Notify sends m to every sink routed for its kind, trying them all and
+// returning their errors joined
+func (d *Dispatcher) Notify(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	var errs []error
+	for _, b := range d.routes[m.Kind] {
+		sink, ok := d.sinks[b]
+		if !ok {
+			continue
+		}
+		if err := sink.Send(ctx, m); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send %s alert by %s: %w", m.Kind, b, err))
+		}
+	}
+	return errors.Join(errs...)
+}
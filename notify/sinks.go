@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// Desktop posts messages as operating system notifications
+type Desktop struct {
+	App fyne.App
+}
+
+// Send posts m from the Fyne thread
+func (d *Desktop) Send(_ context.Context, m Message) error {
+	fyne.Do(func() {
+		d.App.SendNotification(fyne.NewNotification(m.Title, m.Body))
+	})
+	return nil
+}
+
+// EmailConfig is the SMTP server and addresses for email alerts
+type EmailConfig struct {
+	Server   string   `json:"server"` // host:port, e.g. "smtp.example.com:587"
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Validate reports a missing server or address
+func (c EmailConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Server); err != nil {
+		return fmt.Errorf("email alerts need an SMTP server as host:port")
+	}
+	if c.From == "" || len(c.To) == 0 {
+		return fmt.Errorf("email alerts need a sender and at least one recipient")
+	}
+	return nil
+}
+
+// Email sends messages through an SMTP server, authenticating when a
+// username is set
+type Email struct {
+	Config EmailConfig
+}
+
+// Send mails m to every recipient. net/smtp takes no context, so ctx is unused.
+// A line break in a header value would start a new header, so it is an error;
+// a subject that is not plain ASCII is MIME-encoded.
+func (e *Email) Send(_ context.Context, m Message) error {
+	for _, v := range append([]string{m.Title, e.Config.From}, e.Config.To...) {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("invalid email header %q: contains a line break", v)
+		}
+	}
+	var auth smtp.Auth
+	if e.Config.Username != "" {
+		host, _, _ := net.SplitHostPort(e.Config.Server)
+		auth = smtp.PlainAuth("", e.Config.Username, e.Config.Password, host)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.Config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.Config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", m.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	fmt.Fprintf(&b, "Content-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", m.Body)
+	return smtp.SendMail(e.Config.Server, auth, e.Config.From, e.Config.To, []byte(b.String()))
+}
+
+// Webhook posts each message as JSON to a URL, e.g. a chat integration
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil uses http.DefaultClient
+}
+
+// Send posts m and fails on any status other than 2xx
+func (w *Webhook) Send(ctx context.Context, m Message) error {
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post webhook: %s", resp.Status)
+	}
+	return nil
+}
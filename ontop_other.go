@@ -0,0 +1,32 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+// keepOnTop asks the window manager to keep win above other applications.
+// Fyne has no such setting, so on X11 it goes through wmctrl when installed;
+// elsewhere the window manager's own "Always on Top" has to be used.
+func keepOnTop(win fyne.Window) {
+	native, ok := win.(driver.NativeWindow)
+	if !ok {
+		return
+	}
+	native.RunNative(func(ctx any) {
+		w, ok := ctx.(driver.X11WindowContext)
+		if !ok {
+			return
+		}
+		path, err := exec.LookPath("wmctrl")
+		if err != nil {
+			return
+		}
+		go exec.Command(path, "-i", "-r", fmt.Sprintf("0x%x", w.WindowHandle), "-b", "add,above").Run()
+	})
+}
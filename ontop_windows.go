@@ -0,0 +1,32 @@
+//go:build windows
+
+package main
+
+import (
+	"syscall"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+var procSetWindowPos = syscall.NewLazyDLL("user32.dll").NewProc("SetWindowPos")
+
+// keepOnTop makes win a topmost window, above other applications
+func keepOnTop(win fyne.Window) {
+	native, ok := win.(driver.NativeWindow)
+	if !ok {
+		return
+	}
+	native.RunNative(func(ctx any) {
+		w, ok := ctx.(driver.WindowsWindowContext)
+		if !ok {
+			return
+		}
+		const (
+			hwndTopmost = ^uintptr(0) // HWND_TOPMOST, -1
+			swpNoSize   = 0x0001
+			swpNoMove   = 0x0002
+		)
+		procSetWindowPos.Call(w.HWND, hwndTopmost, 0, 0, 0, 0, swpNoSize|swpNoMove)
+	})
+}
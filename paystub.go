@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+	"fynance/viewmodel"
+	"fynance/widgets"
+)
+
+// showPaystubExplainer rebuilds the paystub of a pay period with an RSU
+// release in it, next to the same period without one, so every line the
+// release changed can be matched to the employer's statement
+func showPaystubExplainer(win fyne.Window, base stc.Config) {
+	wagesEntry := widgets.NewSmartEntry("8000")
+	federalEntry := widgets.NewSmartEntry("")
+	federalEntry.SetPlaceHolder("From a paystub without a vest")
+	stateEntry := widgets.NewSmartEntry("")
+	localEntry := widgets.NewSmartEntry("")
+	sharesEntry := widgets.NewSmartEntry("100")
+	vestPriceEntry := widgets.NewSmartEntry("50.00")
+	salePriceEntry := widgets.NewSmartEntry("")
+	salePriceEntry.SetPlaceHolder("Vest price")
+	saleModeSelect := newSaleModeSelect()
+	ytdWagesEntry := widgets.NewSmartEntry("")
+	ytdWagesEntry.SetPlaceHolder("Before this pay period")
+	ytdSupplementalEntry := widgets.NewSmartEntry("")
+
+	// Some payrolls withhold on part of the release in the next pay period
+	firstShareEntry := widgets.NewSmartEntry("50")
+	nextWagesEntry := widgets.NewSmartEntry("8000")
+	nextFederalEntry := widgets.NewSmartEntry("")
+	nextStateEntry := widgets.NewSmartEntry("")
+	nextLocalEntry := widgets.NewSmartEntry("")
+	splitForm := widget.NewForm(
+		widget.NewFormItem("On First Paystub (%)", firstShareEntry),
+		widget.NewFormItem("Next Regular Pay ($)", nextWagesEntry),
+		widget.NewFormItem("Next Federal Withheld ($)", nextFederalEntry),
+		widget.NewFormItem("Next State Withheld ($)", nextStateEntry),
+		widget.NewFormItem("Next Local Withheld ($)", nextLocalEntry),
+	)
+	splitForm.Hide()
+	splitCheck := widget.NewCheck("Split over two paychecks", func(on bool) {
+		if on {
+			splitForm.Show()
+		} else {
+			splitForm.Hide()
+		}
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Regular Pay ($)", wagesEntry),
+		widget.NewFormItem("Federal Withheld ($)", withHelp(win, "paystub", federalEntry)),
+		widget.NewFormItem("State Withheld ($)", stateEntry),
+		widget.NewFormItem("Local Withheld ($)", localEntry),
+		widget.NewFormItem("Shares Released", sharesEntry),
+		widget.NewFormItem("Vest Price ($)", vestPriceEntry),
+		widget.NewFormItem("Sale Price ($)", salePriceEntry),
+		widget.NewFormItem("Settlement", saleModeSelect),
+		widget.NewFormItem("YTD Wages ($)", ytdWagesEntry),
+		widget.NewFormItem("YTD Supplemental ($)", ytdSupplementalEntry),
+		widget.NewFormItem("Withholding", splitCheck),
+	)
+
+	stub := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	stub.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(stub)
+	scroll.SetMinSize(fyne.NewSize(620, 360))
+	copyBtn, setCopyText := newCopyResultButton()
+
+	run := func() {
+		wages, err1 := parseFloat(wagesEntry.Text)
+		federal, err2 := parseFloat(federalEntry.Text)
+		state, err3 := parseFloat(stateEntry.Text)
+		local, err4 := parseFloat(localEntry.Text)
+		shares, err5 := parseFloat(sharesEntry.Text)
+		vestPrice, err6 := parseFloat(vestPriceEntry.Text)
+		salePrice, err7 := parseFloat(salePriceEntry.Text)
+		ytdWages, err8 := parseFloat(ytdWagesEntry.Text)
+		ytdSupplemental, err9 := parseFloat(ytdSupplementalEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil || err8 != nil || err9 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+			return
+		}
+		if shares <= 0 || vestPrice <= 0 {
+			dialog.ShowError(fmt.Errorf("Please enter the shares released and the vest price"), win)
+			return
+		}
+		if salePrice == 0 {
+			salePrice = vestPrice
+		}
+
+		input := stc.PaystubInput{
+			RegularWages:   wages,
+			RegularFederal: federal,
+			RegularState:   state,
+			RegularLocal:   local,
+			Release: stc.RSUInput{
+				SharesReleased:       shares,
+				VestPrice:            vestPrice,
+				SalePrice:            salePrice,
+				Mode:                 saleModes[saleModeSelect.SelectedIndex()],
+				YTDIncome:            ytdWages,
+				YTDWages:             ytdWages,
+				YTDSupplementalWages: ytdSupplemental,
+			},
+		}
+		if splitCheck.Checked {
+			firstShare, err1 := parseFloat(firstShareEntry.Text)
+			nextWages, err2 := parseFloat(nextWagesEntry.Text)
+			nextFederal, err3 := parseFloat(nextFederalEntry.Text)
+			nextState, err4 := parseFloat(nextStateEntry.Text)
+			nextLocal, err5 := parseFloat(nextLocalEntry.Text)
+			if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+				dialog.ShowError(fmt.Errorf("Please enter valid numbers for the next pay period"), win)
+				return
+			}
+			input.Split = &stc.PaystubSplit{
+				FirstShare:     firstShare / 100,
+				RegularWages:   nextWages,
+				RegularFederal: nextFederal,
+				RegularState:   nextState,
+				RegularLocal:   nextLocal,
+			}
+		}
+		result, err := stc.NewCalculator(base).PaystubChecked(input)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("Please check the inputs: %w", err), win)
+			return
+		}
+		text := viewmodel.ExplainPaystub(result).Text()
+		stub.SetText(text)
+		setCopyText(text)
+	}
+
+	for _, e := range []*widgets.SmartEntry{wagesEntry, federalEntry, stateEntry, localEntry, sharesEntry,
+		vestPriceEntry, salePriceEntry, ytdWagesEntry, ytdSupplementalEntry,
+		firstShareEntry, nextWagesEntry, nextFederalEntry, nextStateEntry, nextLocalEntry} {
+		e.SetOnEnter(run)
+	}
+
+	content := container.NewBorder(
+		container.NewVBox(form, splitForm, container.NewHBox(widget.NewButton("Explain", run), copyBtn)),
+		nil, nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Explain My Paystub", "Close", content, win)
+}
@@ -0,0 +1,98 @@
+package plan
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"fynance/stc"
+)
+
+// SchemaDraft is the JSON Schema dialect the schemas are written in
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums lists the values allowed for the string types that take a
+// fixed set, from the same lists Config.Validate checks against
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeFor[stc.SharePolicy]():      enumOf(stc.SharePolicies),
+	reflect.TypeFor[stc.Solver]():           enumOf(stc.Solvers),
+	reflect.TypeFor[stc.TaxModel]():         enumOf(stc.TaxModels),
+	reflect.TypeFor[stc.JurisdictionKind](): {string(stc.JurisdictionState), string(stc.JurisdictionLocal)},
+}
+
+func enumOf[T ~string](values []T) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return s
+}
+
+// ConfigSchema describes a bare config profile, as read by "fynance
+// recompute --config"
+func ConfigSchema() map[string]any {
+	s := schemaFor(reflect.TypeFor[stc.Config](), "")
+	s["$schema"] = SchemaDraft
+	s["title"] = "Fynance config profile"
+	return s
+}
+
+// TemplateSchema describes a plan template published for subscription
+func TemplateSchema() map[string]any {
+	s := schemaFor(reflect.TypeFor[Template](), "")
+	s["$schema"] = SchemaDraft
+	s["title"] = "Fynance plan template"
+	return s
+}
+
+// schemaFor describes how t, encoded by encoding/json as the field name, is
+// written. Every field is optional, as missing fields decode to zero, but
+// unknown fields are rejected so misspelt keys are caught. Numbers must be
+// non-negative, and at most the limit Config.Validate applies to the field.
+func schemaFor(t reflect.Type, name string) map[string]any {
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), name)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := map[string]any{"type": "integer"}
+		if limit, ok := stc.SettingMaximum(name); ok {
+			s["minimum"], s["maximum"] = 0, limit
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		s := map[string]any{"type": "number", "minimum": 0}
+		if limit, ok := stc.SettingMaximum(name); ok {
+			s["maximum"] = limit
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), "")}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), "")}
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = schemaFor(f.Type, name)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	}
+	return map[string]any{}
+}
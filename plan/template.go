@@ -0,0 +1,168 @@
+package plan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"fynance/stc"
+)
+
+// maxTemplateSize caps how much of a remote template is read
+const maxTemplateSize = 1 << 20
+
+// ErrChecksumMismatch is returned when a fetched template does not match the pinned checksum
+var ErrChecksumMismatch = errors.New("plan template checksum does not match pinned value")
+
+// Template is a company stock plan preset published by a plan administrator
+type Template struct {
+	Name    string     `json:"name"`
+	Company string     `json:"company"`
+	Version string     `json:"version"`
+	Notes   string     `json:"notes,omitempty"`
+	Config  stc.Config `json:"config"`
+}
+
+// Subscription tracks a template URL and the last accepted version of it
+type Subscription struct {
+	URL      string    `json:"url"`
+	Checksum string    `json:"checksum"` // Hex SHA-256 of the accepted template body
+	Template *Template `json:"template,omitempty"`
+}
+
+// Update describes the outcome of checking a subscription for changes
+type Update struct {
+	Template    Template
+	Checksum    string
+	Changed     bool     // Body differs from the pinned checksum
+	FeeChanges  []string // Human-readable list of fee schedule differences
+	RateChanges []string // Human-readable list of tax rate differences
+}
+
+// Parse decodes a template body and returns it with its checksum
+func Parse(data []byte) (Template, string, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Template{}, "", fmt.Errorf("invalid plan template: %w", err)
+	}
+	if err := t.Config.Validate(); err != nil {
+		return Template{}, "", fmt.Errorf("invalid plan template config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return t, hex.EncodeToString(sum[:]), nil
+}
+
+// Fetch downloads and parses a template. If pin is non-empty the body must hash to it.
+func Fetch(ctx context.Context, client *http.Client, url, pin string) (Template, string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Template{}, "", fmt.Errorf("invalid template URL: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Template{}, "", fmt.Errorf("failed to fetch template: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Template{}, "", fmt.Errorf("failed to fetch template: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTemplateSize))
+	if err != nil {
+		return Template{}, "", fmt.Errorf("failed to read template: %w", err)
+	}
+
+	t, sum, err := Parse(data)
+	if err != nil {
+		return Template{}, "", err
+	}
+	if pin != "" && !strings.EqualFold(pin, sum) {
+		return t, sum, ErrChecksumMismatch
+	}
+	return t, sum, nil
+}
+
+// Check fetches the subscribed URL and compares it against the pinned version.
+// A changed body is not an error; the caller decides whether to Accept it.
+func (s *Subscription) Check(ctx context.Context, client *http.Client) (Update, error) {
+	t, sum, err := Fetch(ctx, client, s.URL, "")
+	if err != nil {
+		return Update{}, err
+	}
+
+	update := Update{
+		Template: t,
+		Checksum: sum,
+		Changed:  !strings.EqualFold(sum, s.Checksum),
+	}
+	if update.Changed && s.Template != nil {
+		update.FeeChanges = DiffFees(s.Template.Config.BrokerFees, t.Config.BrokerFees)
+		update.RateChanges = DiffRates(s.Template.Config.TaxRates, t.Config.TaxRates)
+	}
+	return update, nil
+}
+
+// Accept pins the subscription to the given update
+func (s *Subscription) Accept(u Update) {
+	t := u.Template
+	s.Template = &t
+	s.Checksum = u.Checksum
+}
+
+// DiffFees lists the broker fee fields that differ between two schedules
+func DiffFees(old, new stc.BrokerFees) []string {
+	var changes []string
+	if old.CommissionRate != new.CommissionRate {
+		changes = append(changes, fmt.Sprintf("Commission rate: %g → %g", old.CommissionRate, new.CommissionRate))
+	}
+	if old.MinimumFee != new.MinimumFee {
+		changes = append(changes, fmt.Sprintf("Minimum fee: $%.2f → $%.2f", old.MinimumFee, new.MinimumFee))
+	}
+	if old.FlatFee != new.FlatFee {
+		changes = append(changes, fmt.Sprintf("Processing fee: $%.2f → $%.2f", old.FlatFee, new.FlatFee))
+	}
+	if !slices.Equal(old.CommissionTiers, new.CommissionTiers) {
+		changes = append(changes, fmt.Sprintf("Commission tiers: %d → %d tiers", len(old.CommissionTiers), len(new.CommissionTiers)))
+	}
+	if old.AnnualCap != new.AnnualCap {
+		changes = append(changes, fmt.Sprintf("Annual commission cap: $%.2f → $%.2f", old.AnnualCap, new.AnnualCap))
+	}
+	if !slices.Equal(old.VolumeTiers, new.VolumeTiers) {
+		changes = append(changes, fmt.Sprintf("Volume tiers: %d → %d tiers", len(old.VolumeTiers), len(new.VolumeTiers)))
+	}
+	return changes
+}
+
+// DiffRates lists the flat tax rates that differ between two configurations
+func DiffRates(old, new stc.TaxRates) []string {
+	var changes []string
+	rates := []struct {
+		name     string
+		old, new float64
+	}{
+		{"Federal", old.Federal, new.Federal},
+		{"Medicare", old.Medicare, new.Medicare},
+		{"Social Security", old.SocialSec, new.SocialSec},
+		{"State", old.State, new.State},
+		{"Local/SDI", old.LocalSDI, new.LocalSDI},
+	}
+	for _, r := range rates {
+		if r.old != r.new {
+			changes = append(changes, fmt.Sprintf("%s rate: %g → %g", r.name, r.old, r.new))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,164 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"fynance/stc"
+)
+
+// ActionKind identifies a type of corporate action
+type ActionKind string
+
+const (
+	ActionSplit  ActionKind = "split"
+	ActionRename ActionKind = "rename"
+)
+
+// CorporateAction records a split or ticker change that affects held lots
+type CorporateAction struct {
+	Kind      ActionKind `json:"kind"`
+	Symbol    string     `json:"symbol"`
+	Effective time.Time  `json:"effective"`
+	Ratio     float64    `json:"ratio,omitempty"`     // New shares per old share (10 for a 10:1 split, 0.1 for 1:10 reverse)
+	NewSymbol string     `json:"newSymbol,omitempty"` // Rename target
+}
+
+// Validate checks that the action has the fields its kind requires
+func (a CorporateAction) Validate() error {
+	switch a.Kind {
+	case ActionSplit:
+		if a.Ratio <= 0 {
+			return errors.New("split ratio must be greater than 0")
+		}
+	case ActionRename:
+		if a.NewSymbol == "" {
+			return errors.New("rename requires a new symbol")
+		}
+	default:
+		return fmt.Errorf("unknown corporate action %q", a.Kind)
+	}
+	if a.Effective.IsZero() {
+		return errors.New("corporate action requires an effective date")
+	}
+	return nil
+}
+
+// affects reports whether the action applies to a lot of the given symbol acquired at the given time
+func (a CorporateAction) affects(symbol string, acquired time.Time) bool {
+	return symbol == a.Symbol && acquired.Before(a.Effective)
+}
+
+// adjust rewrites a lot in post-action terms
+func (a CorporateAction) adjust(lot Lot) Lot {
+	if !a.affects(lot.Symbol, lot.Acquired) {
+		return lot
+	}
+	switch a.Kind {
+	case ActionSplit:
+		lot.Shares *= a.Ratio
+		lot.CostBasis /= a.Ratio
+	case ActionRename:
+		lot.Symbol = a.NewSymbol
+	}
+	return lot
+}
+
+// adjustGrant rewrites the releases and strike of a grant still vesting at the effective date
+func (a CorporateAction) adjustGrant(g Grant) Grant {
+	if g.Symbol != a.Symbol {
+		return g
+	}
+	switch a.Kind {
+	case ActionSplit:
+		releases := make([]stc.Vest, 0, len(g.AllVests()))
+		for _, v := range g.AllVests() {
+			if !v.Date.Before(a.Effective) {
+				v.Shares *= a.Ratio
+			}
+			releases = append(releases, v)
+		}
+		g.Releases = releases
+		g.Strike /= a.Ratio
+	case ActionRename:
+		g.Symbol = a.NewSymbol
+	}
+	return g
+}
+
+// ApplyAction records a corporate action and adjusts every affected lot.
+// Total basis (shares × cost basis) is preserved across splits.
+func (p *Portfolio) ApplyAction(a CorporateAction) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	// Applying the same split twice would multiply every lot again
+	for _, have := range p.Actions {
+		if sameRecord(a, have) {
+			return fmt.Errorf("%s of %s effective %s is already applied", a.Kind, a.Symbol, a.Effective.Format("2006-01-02"))
+		}
+	}
+	for i, lot := range p.Lots {
+		p.Lots[i] = a.adjust(lot)
+	}
+	for i, g := range p.Grants {
+		p.Grants[i] = a.adjustGrant(g)
+	}
+	p.Actions = append(p.Actions, a)
+	return nil
+}
+
+// SplitFactor returns the cumulative split ratio for a symbol between the given
+// date and today. Multiply historical share counts (and divide historical prices
+// or option strikes) by this factor to express them in current terms.
+func (p *Portfolio) SplitFactor(symbol string, since time.Time) float64 {
+	factor := 1.0
+	for _, a := range p.Actions {
+		if a.Kind == ActionRename && a.Symbol == symbol && since.Before(a.Effective) {
+			symbol = a.NewSymbol
+			continue
+		}
+		if a.Kind == ActionSplit && a.affects(symbol, since) {
+			factor *= a.Ratio
+		}
+	}
+	return factor
+}
+
+// AdjustHistorical converts a historical share count and per-share price
+// (e.g. an option strike or a past FMV) into post-action terms.
+func (p *Portfolio) AdjustHistorical(symbol string, date time.Time, shares, price float64) (float64, float64) {
+	factor := p.SplitFactor(symbol, date)
+	return shares * factor, price / factor
+}
+
+// historicalSymbol undoes the renames effective after date, turning a
+// current ticker into the one it traded under then
+func (p *Portfolio) historicalSymbol(symbol string, date time.Time) string {
+	for i := len(p.Actions) - 1; i >= 0; i-- {
+		if a := p.Actions[i]; a.Kind == ActionRename && a.NewSymbol == symbol && date.Before(a.Effective) {
+			symbol = a.Symbol
+		}
+	}
+	return symbol
+}
+
+// positionSymbol is the ticker the position is held under today: that of
+// the lots, or of the grants when nothing is held yet
+func (p *Portfolio) positionSymbol() string {
+	if len(p.Lots) > 0 {
+		return p.Lots[0].Symbol
+	}
+	if len(p.Grants) > 0 {
+		return p.Grants[0].Symbol
+	}
+	return ""
+}
+
+// adjustPosition converts a share count and price recorded for the whole
+// position on date, such as a snapshot or a valuation, into current terms
+func (p *Portfolio) adjustPosition(date time.Time, shares, price float64) (float64, float64) {
+	symbol := p.positionSymbol()
+	return p.AdjustHistorical(p.historicalSymbol(symbol, date), date, shares, price)
+}
@@ -0,0 +1,104 @@
+package portfolio
+
+import (
+	"math"
+	"time"
+)
+
+// qualifiedHoldingDays is the minimum holding period for qualified dividend treatment
+const qualifiedHoldingDays = 60
+
+// DividendAssumption describes the expected dividend stream on held shares
+type DividendAssumption struct {
+	AnnualPerShare  float64   `json:"annualPerShare"`
+	PaymentsPerYear int       `json:"paymentsPerYear"` // e.g. 4 for quarterly
+	FirstPayment    time.Time `json:"firstPayment"`
+	Qualified       bool      `json:"qualified"`     // Whether the issuer pays qualified dividends
+	QualifiedRate   float64   `json:"qualifiedRate"` // e.g. 0.15
+	OrdinaryRate    float64   `json:"ordinaryRate"`  // e.g. 0.22
+	WithholdingRate float64   `json:"withholdingRate"`
+}
+
+// DividendPayment is a projected dividend on a single lot
+type DividendPayment struct {
+	Date      time.Time `json:"date"`
+	LotID     string    `json:"lotId"`
+	Shares    float64   `json:"shares"`
+	Gross     float64   `json:"gross"`
+	Qualified bool      `json:"qualified"`
+	EstTax    float64   `json:"estTax"`   // Tax owed at filing
+	Withheld  float64   `json:"withheld"` // Tax withheld at payment
+	Net       float64   `json:"net"`      // Cash received
+}
+
+// ProjectDividends lists dividend payments on every lot between from and to (inclusive).
+// A lot's payment is treated as qualified only once it has been held for the minimum period.
+func ProjectDividends(p *Portfolio, a DividendAssumption, from, to time.Time) []DividendPayment {
+	if p == nil || a.AnnualPerShare <= 0 || a.PaymentsPerYear <= 0 {
+		return nil
+	}
+
+	perPayment := a.AnnualPerShare / float64(a.PaymentsPerYear)
+	months := 12 / a.PaymentsPerYear
+	if months < 1 {
+		months = 1
+	}
+
+	var payments []DividendPayment
+	for date := a.FirstPayment; !date.After(to); date = date.AddDate(0, months, 0) {
+		if date.Before(from) {
+			continue
+		}
+		for _, lot := range p.Lots {
+			if !lot.Acquired.Before(date) {
+				continue
+			}
+			gross := roundMoney(lot.Shares * perPayment)
+			qualified := a.Qualified && date.Sub(lot.Acquired) > qualifiedHoldingDays*24*time.Hour
+
+			rate := a.OrdinaryRate
+			if qualified {
+				rate = a.QualifiedRate
+			}
+			withheld := roundMoney(gross * a.WithholdingRate)
+
+			payments = append(payments, DividendPayment{
+				Date:      date,
+				LotID:     lot.ID,
+				Shares:    lot.Shares,
+				Gross:     gross,
+				Qualified: qualified,
+				EstTax:    roundMoney(gross * rate),
+				Withheld:  withheld,
+				Net:       gross - withheld,
+			})
+		}
+	}
+
+	return payments
+}
+
+// MonthlyDividends buckets payments by calendar month (1-12) of the given
+// year, as seen from loc (the tax home)
+func MonthlyDividends(payments []DividendPayment, year int, loc *time.Location) [12]DividendPayment {
+	var months [12]DividendPayment
+	for _, pmt := range payments {
+		date := pmt.Date.In(loc)
+		if date.Year() != year {
+			continue
+		}
+		m := &months[date.Month()-1]
+		m.Date = time.Date(year, date.Month(), 1, 0, 0, 0, 0, loc)
+		m.Shares += pmt.Shares
+		m.Gross += pmt.Gross
+		m.EstTax += pmt.EstTax
+		m.Withheld += pmt.Withheld
+		m.Net += pmt.Net
+	}
+	return months
+}
+
+// roundMoney rounds a float64 to 2 decimal places for monetary values
+func roundMoney(val float64) float64 {
+	return math.Round(val*100) / 100
+}
@@ -0,0 +1,162 @@
+package portfolio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// DocumentKind identifies the paperwork a document is
+type DocumentKind string
+
+const (
+	DocGrantAgreement DocumentKind = "agreement" // The award agreement for one grant
+	DocPlan           DocumentKind = "plan"      // The equity plan or its prospectus
+	DocOther          DocumentKind = "other"
+)
+
+// DocumentKinds lists every kind, in the order the documents dialog shows them
+var DocumentKinds = []DocumentKind{DocGrantAgreement, DocPlan, DocOther}
+
+// MaxDocumentSize is the largest file that can be attached
+const MaxDocumentSize = 50 << 20
+
+// documentExts are the file types that can be attached. Any other extension
+// is dropped from the stored name.
+var documentExts = map[string]bool{".pdf": true}
+
+// ErrInvalidDocument reports document metadata that can't name a stored
+// file, such as a checksum that isn't 64 lowercase hex digits
+var ErrInvalidDocument = errors.New("invalid document")
+
+// ErrChecksum reports a stored document that no longer matches its checksum
+var ErrChecksum = errors.New("document does not match its checksum")
+
+// Document is a file attached to a grant, such as the grant agreement. The
+// file itself is stored apart from the portfolio, under StoredName.
+type Document struct {
+	Name   string       `json:"name"` // Original file name
+	Kind   DocumentKind `json:"kind"`
+	SHA256 string       `json:"sha256"` // Hex checksum of the contents
+	Size   int64        `json:"size"`
+	Added  time.Time    `json:"added"`
+}
+
+// ReadDocument reads a file to attach and returns its metadata and contents
+func ReadDocument(name string, kind DocumentKind, r io.Reader) (Document, []byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxDocumentSize+1))
+	if err != nil {
+		return Document{}, nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if len(data) > MaxDocumentSize {
+		return Document{}, nil, fmt.Errorf("%s is larger than %d MB", name, MaxDocumentSize>>20)
+	}
+	sum := sha256.Sum256(data)
+	return Document{
+		Name:   name,
+		Kind:   kind,
+		SHA256: hex.EncodeToString(sum[:]),
+		Size:   int64(len(data)),
+		Added:  time.Now(),
+	}, data, nil
+}
+
+// Validate checks that the checksum is a SHA-256 in lowercase hex, since it
+// names the stored file and must not be able to leave the documents folder
+func (d Document) Validate() error {
+	if len(d.SHA256) != sha256.Size*2 {
+		return fmt.Errorf("%w: checksum of %s is not a SHA-256", ErrInvalidDocument, d.Name)
+	}
+	for _, c := range d.SHA256 {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Errorf("%w: checksum of %s is not a SHA-256", ErrInvalidDocument, d.Name)
+		}
+	}
+	return nil
+}
+
+// StoredName is the file name of the stored copy: the checksum, so the same
+// file attached twice is stored once, and the extension if it is one of
+// documentExts
+func (d Document) StoredName() string {
+	ext := strings.ToLower(path.Ext(d.Name))
+	if !documentExts[ext] {
+		ext = ""
+	}
+	return d.SHA256 + ext
+}
+
+// Verify checks that r holds the document as attached
+func (d Document) Verify(r io.Reader) error {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return fmt.Errorf("failed to read document: %w", err)
+	}
+	if n != d.Size || hex.EncodeToString(h.Sum(nil)) != d.SHA256 {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// grant returns the grant with id
+func (p *Portfolio) grant(id string) (*Grant, error) {
+	for i := range p.Grants {
+		if p.Grants[i].ID == id {
+			return &p.Grants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no grant with ID %s", id)
+}
+
+// AttachDocument adds d to a grant, replacing an attachment with the same checksum
+func (p *Portfolio) AttachDocument(grantID string, d Document) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	g, err := p.grant(grantID)
+	if err != nil {
+		return err
+	}
+	for i, existing := range g.Documents {
+		if existing.SHA256 == d.SHA256 {
+			g.Documents[i] = d
+			return nil
+		}
+	}
+	g.Documents = append(g.Documents, d)
+	return nil
+}
+
+// DetachDocument removes the document with checksum sum from a grant
+func (p *Portfolio) DetachDocument(grantID, sum string) error {
+	g, err := p.grant(grantID)
+	if err != nil {
+		return err
+	}
+	for i, d := range g.Documents {
+		if d.SHA256 == sum {
+			g.Documents = append(g.Documents[:i], g.Documents[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no document %s on grant %s", sum, grantID)
+}
+
+// DocumentInUse reports whether any grant still has the stored file name,
+// so a detached file is only deleted once nothing refers to it
+func (p *Portfolio) DocumentInUse(storedName string) bool {
+	for _, g := range p.Grants {
+		for _, d := range g.Documents {
+			if d.StoredName() == storedName {
+				return true
+			}
+		}
+	}
+	return false
+}
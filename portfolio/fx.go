@@ -0,0 +1,120 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxFXResponseSize caps how much of an FX response is read
+const maxFXResponseSize = 64 << 10
+
+// FXRate is the USD value of one unit of a currency on a date, with where it came from
+type FXRate struct {
+	Currency string    `json:"currency"`
+	Date     time.Time `json:"date"` // Date the rate was published, which may precede a weekend trade
+	Rate     float64   `json:"rate"` // USD per unit of Currency
+	Source   string    `json:"source"`
+}
+
+// FXSource looks up historical exchange rates
+type FXSource interface {
+	RateOn(ctx context.Context, currency string, date time.Time) (FXRate, error)
+}
+
+// FrankfurterSource fetches European Central Bank reference rates from the
+// Frankfurter API. Weekend and holiday dates return the last published rate.
+type FrankfurterSource struct {
+	Client  *http.Client // Defaults to http.DefaultClient
+	BaseURL string       // Defaults to https://api.frankfurter.app
+}
+
+// RateOn returns the USD rate for currency as of date
+func (s FrankfurterSource) RateOn(ctx context.Context, currency string, date time.Time) (FXRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == "USD" {
+		return FXRate{Currency: "USD", Date: date, Rate: 1, Source: "identity"}, nil
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	base := s.BaseURL
+	if base == "" {
+		base = "https://api.frankfurter.app"
+	}
+	endpoint := fmt.Sprintf("%s/%s?from=%s&to=USD", strings.TrimSuffix(base, "/"),
+		date.Format("2006-01-02"), url.QueryEscape(currency))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return FXRate{}, fmt.Errorf("invalid FX request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return FXRate{}, fmt.Errorf("failed to fetch FX rate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return FXRate{}, fmt.Errorf("failed to fetch FX rate: %s", resp.Status)
+	}
+
+	var body struct {
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFXResponseSize))
+	if err != nil {
+		return FXRate{}, fmt.Errorf("failed to read FX rate: %w", err)
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return FXRate{}, fmt.Errorf("invalid FX response: %w", err)
+	}
+	rate, ok := body.Rates["USD"]
+	if !ok || rate <= 0 {
+		return FXRate{}, fmt.Errorf("no USD rate for %s on %s", currency, date.Format("2006-01-02"))
+	}
+	published, err := time.ParseInLocation("2006-01-02", body.Date, date.Location())
+	if err != nil {
+		published = date
+	}
+
+	return FXRate{
+		Currency: currency,
+		Date:     published,
+		Rate:     rate,
+		Source:   "ECB reference rate via " + base,
+	}, nil
+}
+
+// BasisUSD returns the per-share cost basis in US dollars, converted at the
+// rate recorded for the acquisition date
+func (l Lot) BasisUSD() float64 {
+	if l.FX == nil {
+		return l.CostBasis
+	}
+	return l.CostBasis * l.FX.Rate
+}
+
+// SetFX records the currency and acquisition-date FX rate of the lot with the
+// given ID. A USD rate clears any previous conversion.
+func (p *Portfolio) SetFX(id string, rate FXRate) error {
+	for i := range p.Lots {
+		if p.Lots[i].ID != id {
+			continue
+		}
+		p.Lots[i].Currency = rate.Currency
+		p.Lots[i].FX = &rate
+		if rate.Currency == "USD" {
+			p.Lots[i].Currency, p.Lots[i].FX = "", nil
+		}
+		return nil
+	}
+	return fmt.Errorf("no lot with ID %s", id)
+}
@@ -0,0 +1,147 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fynance/report"
+)
+
+// GiftLine is the basis and holding-period record for one gifted lot
+type GiftLine struct {
+	LotID         string    `json:"lotId"`
+	Symbol        string    `json:"symbol"`
+	Shares        float64   `json:"shares"`
+	Acquired      time.Time `json:"acquired"`
+	BasisPerShare float64   `json:"basisPerShare"` // In USD, converted at FX when set
+	FX            *FXRate   `json:"fx,omitempty"`
+	TotalBasis    float64   `json:"totalBasis"`
+	HoldingDays   int       `json:"holdingDays"`
+	LongTerm      bool      `json:"longTerm"`
+	FMVPerShare   float64   `json:"fmvPerShare"`
+	TotalFMV      float64   `json:"totalFmv"`
+}
+
+// GiftReport documents lots transferred to a charity, donor-advised fund, or individual
+type GiftReport struct {
+	Donor     string     `json:"donor"`
+	Recipient string     `json:"recipient"`
+	GiftDate  time.Time  `json:"giftDate"`
+	Lines     []GiftLine `json:"lines"`
+}
+
+// NewGiftReport builds a report for the selected lots valued at price on the gift date
+func NewGiftReport(lots []Lot, donor, recipient string, giftDate time.Time, price float64) GiftReport {
+	report := GiftReport{Donor: donor, Recipient: recipient, GiftDate: giftDate}
+	for _, lot := range lots {
+		report.Lines = append(report.Lines, GiftLine{
+			LotID:         lot.ID,
+			Symbol:        lot.Symbol,
+			Shares:        lot.Shares,
+			Acquired:      lot.Acquired,
+			BasisPerShare: lot.BasisUSD(),
+			FX:            lot.FX,
+			TotalBasis:    roundMoney(lot.Shares * lot.BasisUSD()),
+			HoldingDays:   int(giftDate.Sub(lot.Acquired).Hours() / 24),
+			LongTerm:      !giftDate.Before(lot.LongTermDate()),
+			FMVPerShare:   price,
+			TotalFMV:      roundMoney(lot.Shares * price),
+		})
+	}
+	return report
+}
+
+// Totals returns the combined shares, basis, and fair market value of the gift
+func (r GiftReport) Totals() (shares, basis, fmv float64) {
+	for _, l := range r.Lines {
+		shares += l.Shares
+		basis += l.TotalBasis
+		fmv += l.TotalFMV
+	}
+	return shares, basis, fmv
+}
+
+// WriteText renders the report as a plain-text letter suitable for the receiving institution
+func (r GiftReport) WriteText(w io.Writer) error {
+	return r.WriteTextIn(w, report.USLocale)
+}
+
+// WriteTextIn renders the letter with the numbers and dates of loc
+func (r GiftReport) WriteTextIn(w io.Writer, loc report.Locale) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "GIFT OF SECURITIES — BASIS AND HOLDING PERIOD STATEMENT\n\n")
+	fmt.Fprintf(&b, "Donor:      %s\n", r.Donor)
+	fmt.Fprintf(&b, "Recipient:  %s\n", r.Recipient)
+	fmt.Fprintf(&b, "Gift Date:  %s\n\n", loc.LongDate(r.GiftDate))
+
+	fmt.Fprintf(&b, "%-8s %10s %-12s %12s %14s %8s %-10s %14s\n",
+		"Symbol", "Shares", "Acquired", "Basis/Sh", "Total Basis", "Days", "Term", "FMV at Gift")
+	for _, l := range r.Lines {
+		term := "Short"
+		if l.LongTerm {
+			term = "Long"
+		}
+		fmt.Fprintf(&b, "%-8s %10s %-12s %12s %14s %8d %-10s %14s\n",
+			l.Symbol, loc.Number(l.Shares, 4), loc.Date(l.Acquired), loc.Number(l.BasisPerShare, 4),
+			loc.Number(l.TotalBasis, 2), l.HoldingDays, term, loc.Number(l.TotalFMV, 2))
+	}
+
+	shares, basis, fmv := r.Totals()
+	fmt.Fprintf(&b, "\nTotal: %s shares, basis %s, fair market value %s\n", loc.Number(shares, 4), loc.Money(basis), loc.Money(fmv))
+	fmt.Fprintf(&b, "\nFair market value is stated per share as of the gift date. Holding periods\n"+
+		"are measured from the acquisition (exercise or vest) date of each lot.\n")
+	for _, l := range r.Lines {
+		if l.FX != nil {
+			fmt.Fprintf(&b, "Lot %s basis converted from %s at %s USD (%s, %s).\n",
+				l.LotID, l.FX.Currency, loc.Number(l.FX.Rate, -1), loc.Date(l.FX.Date), l.FX.Source)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ToCSV writes the report lines as CSV
+func (r GiftReport) ToCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{
+		"Lot ID", "Symbol", "Shares", "Acquired", "Basis Per Share", "Total Basis",
+		"Holding Days", "Long Term", "FMV Per Share", "Total FMV",
+		"Currency", "FX Rate", "FX Date", "FX Source",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, l := range r.Lines {
+		currency, rate, date, source := "USD", "1", "", ""
+		if l.FX != nil {
+			currency, rate = l.FX.Currency, strconv.FormatFloat(l.FX.Rate, 'f', -1, 64)
+			date, source = l.FX.Date.Format("2006-01-02"), l.FX.Source
+		}
+		row := []string{
+			l.LotID,
+			l.Symbol,
+			fmt.Sprintf("%.4f", l.Shares),
+			l.Acquired.Format("2006-01-02"),
+			fmt.Sprintf("%.4f", l.BasisPerShare),
+			fmt.Sprintf("%.2f", l.TotalBasis),
+			fmt.Sprintf("%d", l.HoldingDays),
+			fmt.Sprintf("%t", l.LongTerm),
+			fmt.Sprintf("%.4f", l.FMVPerShare),
+			fmt.Sprintf("%.2f", l.TotalFMV),
+			currency, rate, date, source,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package portfolio
+
+import (
+	"strconv"
+	"time"
+
+	"fynance/stc"
+)
+
+// GrantKind identifies the type of equity award
+type GrantKind string
+
+const (
+	GrantRSU GrantKind = "RSU"
+	GrantNSO GrantKind = "NSO"
+	GrantISO GrantKind = "ISO"
+	GrantPSU GrantKind = "PSU" // Performance shares: each vest is the target units for a performance period
+)
+
+// Grant is an equity award that releases shares on a vesting schedule
+type Grant struct {
+	ID       string              `json:"id"`
+	Symbol   string              `json:"symbol"`
+	Kind     GrantKind           `json:"kind"`
+	Strike   float64             `json:"strike,omitempty"` // Exercise price for options
+	Schedule stc.VestingSchedule `json:"schedule"`
+	Releases []stc.Vest          `json:"releases,omitempty"` // Explicit releases, overriding Schedule when set
+	Payout   *PayoutRange        `json:"payout,omitempty"`   // PSU multipliers; DefaultPayoutRange when unset
+	Expires  time.Time           `json:"expires,omitempty"`  // Last day to exercise options; see Expiration
+
+	Documents []Document `json:"documents,omitempty"` // Grant agreement and other paperwork
+}
+
+// OptionTerm is the usual life of an option grant, and the longest an ISO may have
+const OptionTerm = 10
+
+// Expiration returns the last day to exercise an option grant: Expires,
+// or OptionTerm years after the grant date. Other grants never expire.
+func (g Grant) Expiration() time.Time {
+	if !g.IsOption() {
+		return time.Time{}
+	}
+	if !g.Expires.IsZero() {
+		return g.Expires
+	}
+	return g.Schedule.GrantDate.AddDate(OptionTerm, 0, 0)
+}
+
+// AllVests returns every release of the grant
+func (g Grant) AllVests() []stc.Vest {
+	if len(g.Releases) > 0 {
+		return g.Releases
+	}
+	return g.Schedule.Vests()
+}
+
+// IsOption reports whether the grant must be exercised
+func (g Grant) IsOption() bool {
+	return g.Kind == GrantNSO || g.Kind == GrantISO
+}
+
+// Unvested returns the vests that occur after the given time
+func (g Grant) Unvested(after time.Time) []stc.Vest {
+	var vests []stc.Vest
+	for _, v := range g.AllVests() {
+		if v.Date.After(after) {
+			vests = append(vests, v)
+		}
+	}
+	return vests
+}
+
+// UnvestedShares totals the shares still to vest after the given time
+func (g Grant) UnvestedShares(after time.Time) float64 {
+	total := 0.0
+	for _, v := range g.Unvested(after) {
+		total += v.Shares
+	}
+	return total
+}
+
+// AddGrant appends a grant, assigning an ID if none is set
+func (p *Portfolio) AddGrant(g Grant) Grant {
+	if g.ID == "" {
+		g.ID = "g" + strconv.FormatInt(g.Schedule.GrantDate.UnixNano(), 36) + "-" + strconv.Itoa(len(p.Grants)+1)
+	}
+	p.Grants = append(p.Grants, g)
+	return g
+}
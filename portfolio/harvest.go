@@ -0,0 +1,81 @@
+package portfolio
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// washSaleDays is the window on either side of a loss sale in which a purchase disallows the loss
+const washSaleDays = 30
+
+// HarvestCandidate is an underwater lot that could be sold to realize a deductible loss
+type HarvestCandidate struct {
+	Lot             Lot         `json:"lot"`
+	Loss            float64     `json:"loss"`            // Realizable loss as a positive amount
+	TaxBenefit      float64     `json:"taxBenefit"`      // Estimated tax saved by the deduction
+	Conflicts       []time.Time `json:"conflicts"`       // Acquisitions or vests inside the wash-sale window
+	WashSale        bool        `json:"washSale"`        // Selling today would trigger the wash-sale rule
+	RepurchaseAfter time.Time   `json:"repurchaseAfter"` // First day shares may be bought back safely
+}
+
+// WashSaleConflicts lists acquisitions (other lots and scheduled vests) within
+// 30 days of a sale on the given date. skipLotID excludes the lot being sold.
+func (p *Portfolio) WashSaleConflicts(symbol string, sale time.Time, skipLotID string) []time.Time {
+	window := washSaleDays * 24 * time.Hour
+	inWindow := func(t time.Time) bool {
+		d := t.Sub(sale)
+		return d >= -window && d <= window
+	}
+
+	var conflicts []time.Time
+	for _, lot := range p.Lots {
+		if lot.ID != skipLotID && lot.Symbol == symbol && inWindow(lot.Acquired) {
+			conflicts = append(conflicts, lot.Acquired)
+		}
+	}
+	for _, g := range p.Grants {
+		if g.Symbol != symbol {
+			continue
+		}
+		for _, v := range g.AllVests() {
+			if inWindow(v.Date) {
+				conflicts = append(conflicts, v.Date)
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Before(conflicts[j]) })
+	return conflicts
+}
+
+// HarvestCandidates suggests underwater lots to sell at the given price, largest loss first.
+// Lots whose sale would be a wash sale are still listed, flagged with their conflicts.
+func (p *Portfolio) HarvestCandidates(price float64, now time.Time, rates CapGainsRates) []HarvestCandidate {
+	var candidates []HarvestCandidate
+	for _, lot := range p.Lots {
+		st := lot.Status(price, now, rates)
+		if st.Gain >= 0 {
+			continue
+		}
+
+		loss := math.Abs(st.Gain)
+		rate := rates.LongTerm
+		if st.DaysToLongTerm > 0 {
+			rate = rates.ShortTerm
+		}
+
+		conflicts := p.WashSaleConflicts(lot.Symbol, now, lot.ID)
+		candidates = append(candidates, HarvestCandidate{
+			Lot:             lot,
+			Loss:            loss,
+			TaxBenefit:      roundMoney(loss * rate),
+			Conflicts:       conflicts,
+			WashSale:        len(conflicts) > 0,
+			RepurchaseAfter: now.AddDate(0, 0, washSaleDays+1),
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Loss > candidates[j].Loss })
+	return candidates
+}
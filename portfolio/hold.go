@@ -0,0 +1,39 @@
+package portfolio
+
+import "time"
+
+// HoldEstimate compares selling a lot at its cost basis today with holding
+// it for a while and selling at a future price
+type HoldEstimate struct {
+	Months   int       `json:"months"`
+	SaleDate time.Time `json:"saleDate"`
+	Price    float64   `json:"price"`    // Planned sale price
+	LongTerm bool      `json:"longTerm"` // Whether the sale date gets long-term treatment
+	Gain     float64   `json:"gain"`     // Capital gain at Price (negative for a loss)
+	Tax      float64   `json:"tax"`      // Capital gains tax on Gain
+	Proceeds float64   `json:"proceeds"` // Value at Price before tax
+	AfterTax float64   `json:"afterTax"` // Proceeds less Tax
+	SellNow  float64   `json:"sellNow"`  // Value at the cost basis, which carries no further gain
+}
+
+// EstimateHold estimates the capital gains tax on the lot if held for
+// months after it was acquired and then sold at price. Shares from an
+// exercise or release have a basis of their FMV, so selling them at once
+// owes no further tax; any later gain is taxed at rates.ShortTerm unless
+// the lot is by then long-term.
+func (l Lot) EstimateHold(months int, price float64, rates CapGainsRates) HoldEstimate {
+	sale := l.Acquired.AddDate(0, months, 0)
+	st := l.Status(price, sale, rates)
+	e := HoldEstimate{
+		Months:   months,
+		SaleDate: sale,
+		Price:    price,
+		LongTerm: st.DaysToLongTerm == 0,
+		Gain:     st.Gain,
+		Tax:      st.TaxNow,
+		Proceeds: roundMoney(price * l.Shares),
+		SellNow:  roundMoney(l.BasisUSD() * l.Shares),
+	}
+	e.AfterTax = roundMoney(e.Proceeds - e.Tax)
+	return e
+}
@@ -0,0 +1,66 @@
+package portfolio
+
+import (
+	"math"
+	"time"
+)
+
+// CapGainsRates are the marginal rates applied to short- and long-term gains
+type CapGainsRates struct {
+	ShortTerm float64 `json:"shortTerm"` // Ordinary income rate, e.g. 0.24
+	LongTerm  float64 `json:"longTerm"`  // e.g. 0.15
+}
+
+// LongTermDate is the first day a sale receives long-term treatment (held more than one year)
+func (l Lot) LongTermDate() time.Time {
+	return l.Acquired.AddDate(1, 0, 1)
+}
+
+// DaysToLongTerm returns the whole days remaining until long-term treatment, or 0 if already long-term
+func (l Lot) DaysToLongTerm(now time.Time) int {
+	remaining := l.LongTermDate().Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	return int(math.Ceil(remaining.Hours() / 24))
+}
+
+// LotStatus summarizes the tax position of a lot at a given price
+type LotStatus struct {
+	Lot            Lot     `json:"lot"`
+	Gain           float64 `json:"gain"` // Unrealized gain (negative for a loss)
+	DaysToLongTerm int     `json:"daysToLongTerm"`
+	TaxNow         float64 `json:"taxNow"`    // Tax if sold today
+	TaxIfWait      float64 `json:"taxIfWait"` // Tax if sold once long-term, at the same price
+	WaitSavings    float64 `json:"waitSavings"`
+}
+
+// Status evaluates a lot at the given price and time
+func (l Lot) Status(price float64, now time.Time, rates CapGainsRates) LotStatus {
+	st := LotStatus{
+		Lot:            l,
+		Gain:           roundMoney((price - l.BasisUSD()) * l.Shares),
+		DaysToLongTerm: l.DaysToLongTerm(now),
+	}
+
+	// Losses produce no tax; their value is covered by harvesting suggestions
+	taxable := math.Max(st.Gain, 0)
+	st.TaxIfWait = roundMoney(taxable * rates.LongTerm)
+	if st.DaysToLongTerm > 0 {
+		st.TaxNow = roundMoney(taxable * rates.ShortTerm)
+	} else {
+		st.TaxNow = st.TaxIfWait
+	}
+	st.WaitSavings = st.TaxNow - st.TaxIfWait
+
+	return st
+}
+
+// Statuses evaluates every lot at the given price
+func (p *Portfolio) Statuses(price float64, now time.Time, rates CapGainsRates) []LotStatus {
+	statuses := make([]LotStatus, len(p.Lots))
+	for i, lot := range p.Lots {
+		statuses[i] = lot.Status(price, now, rates)
+	}
+	return statuses
+}
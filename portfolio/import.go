@@ -0,0 +1,162 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"fynance/stc"
+)
+
+// ImportMode decides what happens to an imported lot that is already held
+type ImportMode string
+
+const (
+	ImportSkip    ImportMode = "skip"    // Keep the existing lot and drop the imported row
+	ImportMerge   ImportMode = "merge"   // Keep the existing lot, filling its blank fields from the row
+	ImportReplace ImportMode = "replace" // Overwrite the existing lot with the row, keeping its ID
+)
+
+// ImportModes lists every mode in menu order
+var ImportModes = []ImportMode{ImportSkip, ImportMerge, ImportReplace}
+
+// DuplicateTolerance is how far shares and per-share basis may differ for two
+// lots on the same date to count as the same transaction
+const DuplicateTolerance = 0.01
+
+// ImportReport counts what an import did
+type ImportReport struct {
+	Added    int `json:"added"`
+	Skipped  int `json:"skipped"`
+	Merged   int `json:"merged"`
+	Replaced int `json:"replaced"`
+}
+
+func (r ImportReport) String() string {
+	return fmt.Sprintf("%d added, %d skipped, %d merged, %d replaced", r.Added, r.Skipped, r.Merged, r.Replaced)
+}
+
+// ReadLotsCSV reads lots from a statement export with the columns
+// Date, Symbol, Shares, Cost Basis, and optionally Source. Amounts may be
+// formatted, e.g. "$1,234.50".
+func ReadLotsCSV(r io.Reader, loc *time.Location) ([]Lot, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	// Skip header
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var lots []Lot
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		if len(record) < 4 {
+			continue // Skip invalid rows
+		}
+
+		acquired, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(record[0]), loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date on line %d: %w", line, err)
+		}
+		shares, err := stc.ParseMoney(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid shares on line %d: %w", line, err)
+		}
+		basis, err := stc.ParseMoney(record[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cost basis on line %d: %w", line, err)
+		}
+		lot := Lot{
+			Symbol:    strings.TrimSpace(record[1]),
+			Shares:    shares.Float64(),
+			CostBasis: basis.Float64(),
+			Acquired:  acquired,
+			Source:    "Import",
+		}
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			lot.Source = strings.TrimSpace(record[4])
+		}
+		lots = append(lots, lot)
+	}
+	return lots, nil
+}
+
+// sameTransaction reports whether two lots describe the same acquisition
+func sameTransaction(a, b Lot) bool {
+	ay, am, ad := a.Acquired.Date()
+	by, bm, bd := b.Acquired.In(a.Acquired.Location()).Date()
+	return ay == by && am == bm && ad == bd &&
+		(a.Symbol == "" || b.Symbol == "" || strings.EqualFold(a.Symbol, b.Symbol)) &&
+		math.Abs(a.Shares-b.Shares) <= DuplicateTolerance &&
+		math.Abs(a.CostBasis-b.CostBasis) <= DuplicateTolerance
+}
+
+// FindDuplicate returns the held lot that matches lot's date, symbol, shares,
+// and basis within DuplicateTolerance
+func (p *Portfolio) FindDuplicate(lot Lot) (int, bool) {
+	for i, held := range p.Lots {
+		if sameTransaction(held, lot) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Duplicates counts the lots that are already held
+func (p *Portfolio) Duplicates(lots []Lot) int {
+	n := 0
+	for _, lot := range lots {
+		if _, ok := p.FindDuplicate(lot); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Import adds lots to the portfolio, resolving rows that are already held
+// according to mode so a statement imported twice is not counted twice
+func (p *Portfolio) Import(lots []Lot, mode ImportMode) ImportReport {
+	var report ImportReport
+	for _, row := range lots {
+		// Held lots are in post-split terms, so compare against the adjusted row
+		lot := row
+		for _, a := range p.Actions {
+			lot = a.adjust(lot)
+		}
+		i, dup := p.FindDuplicate(lot)
+		if !dup {
+			p.Add(row)
+			report.Added++
+			continue
+		}
+
+		held := &p.Lots[i]
+		switch mode {
+		case ImportReplace:
+			lot.ID = held.ID
+			*held = lot
+			report.Replaced++
+		case ImportMerge:
+			if held.Symbol == "" {
+				held.Symbol = lot.Symbol
+			}
+			if held.Currency == "" && held.FX == nil {
+				held.Currency, held.FX = lot.Currency, lot.FX
+			}
+			report.Merged++
+		default:
+			report.Skipped++
+		}
+	}
+	return report
+}
@@ -0,0 +1,147 @@
+package portfolio
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"fynance/report"
+)
+
+// IncomeLine is one past release or exercise and the income it produced
+type IncomeLine struct {
+	Date      time.Time `json:"date"`
+	Symbol    string    `json:"symbol"`
+	Source    string    `json:"source"`
+	Shares    float64   `json:"shares"`
+	Income    float64   `json:"income"`
+	Estimated bool      `json:"estimated,omitempty"` // No recorded income; valued as retained shares × basis
+}
+
+// ScheduledVest is one future release valued at an assumed price
+type ScheduledVest struct {
+	Date   time.Time `json:"date"`
+	Symbol string    `json:"symbol"`
+	Kind   GrantKind `json:"kind"`
+	Shares float64   `json:"shares"`
+	Value  float64   `json:"value"` // Shares × price, less the strike for options
+}
+
+// IncomeStatement summarizes equity compensation for a mortgage or loan
+// application: income received over the lookback period and releases
+// scheduled over the same period ahead
+type IncomeStatement struct {
+	Employee string          `json:"employee"`
+	AsOf     time.Time       `json:"asOf"`
+	Months   int             `json:"months"`
+	Price    float64         `json:"price"` // Per-share value assumed for future vests
+	Past     []IncomeLine    `json:"past"`
+	Future   []ScheduledVest `json:"future"`
+}
+
+// NewIncomeStatement collects lots acquired in the months before asOf and
+// grant vests scheduled in the months after it
+func NewIncomeStatement(p *Portfolio, employee string, asOf time.Time, months int, price float64) IncomeStatement {
+	s := IncomeStatement{Employee: employee, AsOf: asOf, Months: months, Price: price}
+	from, until := asOf.AddDate(0, -months, 0), asOf.AddDate(0, months, 0)
+
+	for _, lot := range p.Lots {
+		if !lot.Acquired.After(from) || lot.Acquired.After(asOf) {
+			continue
+		}
+		line := IncomeLine{Date: lot.Acquired, Symbol: lot.Symbol, Source: lot.Source, Shares: lot.Shares, Income: lot.Income}
+		if line.Income == 0 {
+			line.Income, line.Estimated = roundMoney(lot.Shares*lot.BasisUSD()), true
+		}
+		s.Past = append(s.Past, line)
+	}
+
+	for _, g := range p.Grants {
+		perShare := price
+		if g.IsOption() {
+			perShare = max(price-g.Strike, 0)
+		}
+		for _, v := range g.Unvested(asOf) {
+			if v.Date.After(until) {
+				continue
+			}
+			s.Future = append(s.Future, ScheduledVest{
+				Date: v.Date, Symbol: g.Symbol, Kind: g.Kind, Shares: v.Shares, Value: roundMoney(v.Shares * perShare),
+			})
+		}
+	}
+
+	sort.Slice(s.Past, func(i, j int) bool { return s.Past[i].Date.Before(s.Past[j].Date) })
+	sort.Slice(s.Future, func(i, j int) bool { return s.Future[i].Date.Before(s.Future[j].Date) })
+	return s
+}
+
+// Totals returns the income received and the value scheduled to vest
+func (s IncomeStatement) Totals() (received, scheduled float64) {
+	for _, l := range s.Past {
+		received += l.Income
+	}
+	for _, v := range s.Future {
+		scheduled += v.Value
+	}
+	return received, scheduled
+}
+
+// MonthlyAverage returns the income received averaged over the lookback period
+func (s IncomeStatement) MonthlyAverage() float64 {
+	if s.Months <= 0 {
+		return 0
+	}
+	received, _ := s.Totals()
+	return roundMoney(received / float64(s.Months))
+}
+
+// WriteText renders the statement in a verification-letter format
+func (s IncomeStatement) WriteText(w io.Writer) error {
+	return s.WriteTextIn(w, report.USLocale)
+}
+
+// WriteTextIn renders the statement with the numbers and dates of loc
+func (s IncomeStatement) WriteTextIn(w io.Writer, loc report.Locale) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "EQUITY COMPENSATION INCOME STATEMENT\n\n")
+	fmt.Fprintf(&b, "Employee:   %s\n", s.Employee)
+	fmt.Fprintf(&b, "As Of:      %s\n", loc.LongDate(s.AsOf))
+	fmt.Fprintf(&b, "Period:     %d months before and after\n\n", s.Months)
+
+	fmt.Fprintf(&b, "VESTED AND EXERCISED INCOME\n")
+	fmt.Fprintf(&b, "%-12s %-8s %-8s %12s %14s\n", "Date", "Symbol", "Source", "Shares", "Income")
+	for _, l := range s.Past {
+		mark := ""
+		if l.Estimated {
+			mark = " *"
+		}
+		fmt.Fprintf(&b, "%-12s %-8s %-8s %12s %14s%s\n",
+			loc.Date(l.Date), l.Symbol, l.Source, loc.Number(l.Shares, 4), loc.Number(l.Income, 2), mark)
+	}
+	received, scheduled := s.Totals()
+	fmt.Fprintf(&b, "\nTotal received: %s  (monthly average %s)\n\n", loc.Money(received), loc.Money(s.MonthlyAverage()))
+
+	fmt.Fprintf(&b, "SCHEDULED VESTS\n")
+	fmt.Fprintf(&b, "%-12s %-8s %-4s %12s %14s\n", "Date", "Symbol", "Type", "Shares", "Est. Value")
+	for _, v := range s.Future {
+		fmt.Fprintf(&b, "%-12s %-8s %-4s %12s %14s\n",
+			loc.Date(v.Date), v.Symbol, v.Kind, loc.Number(v.Shares, 4), loc.Number(v.Value, 2))
+	}
+	fmt.Fprintf(&b, "\nTotal scheduled: %s at %s per share\n", loc.Money(scheduled), loc.Money(s.Price))
+
+	fmt.Fprintf(&b, "\nIncome is the ordinary income recognized at each vest or exercise.\n"+
+		"Scheduled vests are subject to continued employment and are valued at the\n"+
+		"stated price, less the exercise price for options.\n")
+	for _, l := range s.Past {
+		if l.Estimated {
+			fmt.Fprintf(&b, "* No income recorded; valued as retained shares at cost basis.\n")
+			break
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
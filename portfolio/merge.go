@@ -0,0 +1,180 @@
+package portfolio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Side names one version in a three-way merge
+type Side string
+
+const (
+	SideBase   Side = "base"   // The last version both copies agreed on
+	SideLocal  Side = "local"  // This device's current version
+	SideRemote Side = "remote" // The version being restored or synced in
+)
+
+// Conflict is a lot, grant, valuation, or snapshot changed differently in the local and remote
+// versions since the base. A nil version means the record was deleted there,
+// or did not exist yet.
+type Conflict struct {
+	Kind   string // "lot", "grant", "valuation", or "snapshot"
+	ID     string
+	Base   any // *Lot, *Grant, *Valuation, or *Snapshot
+	Local  any
+	Remote any
+	Choice Side // Blank until resolved
+}
+
+// Version returns the record on the given side, or nil
+func (c Conflict) Version(s Side) any {
+	switch s {
+	case SideBase:
+		return c.Base
+	case SideLocal:
+		return c.Local
+	default:
+		return c.Remote
+	}
+}
+
+// MergeResult is a three-way merge that may still need conflicts resolved
+type MergeResult struct {
+	Merged    Portfolio // Every record that merged cleanly
+	Conflicts []Conflict
+}
+
+// sameRecord compares two records by their saved form, so in-memory details
+// such as monotonic clock readings do not count as changes
+func sameRecord(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// mergeRecords merges one keyed collection. A record changed on one side
+// only takes that side's version; a record changed on both sides in
+// different ways becomes a conflict.
+func mergeRecords[T any](kind string, base, local, remote []T, id func(T) string) ([]T, []Conflict) {
+	index := func(records []T) (map[string]*T, []string) {
+		m := make(map[string]*T, len(records))
+		var order []string
+		for i := range records {
+			m[id(records[i])] = &records[i]
+			order = append(order, id(records[i]))
+		}
+		return m, order
+	}
+	b, _ := index(base)
+	l, localOrder := index(local)
+	r, remoteOrder := index(remote)
+
+	// Local order first, then records only the remote has. Records deleted on
+	// both sides are in neither and drop out.
+	ids := localOrder
+	for _, k := range remoteOrder {
+		if _, ok := l[k]; !ok {
+			ids = append(ids, k)
+		}
+	}
+	var merged []T
+	var conflicts []Conflict
+	for _, k := range ids {
+		bv, lv, rv := b[k], l[k], r[k]
+		var pick *T
+		switch {
+		case sameRecord(lv, rv):
+			pick = lv
+		case sameRecord(lv, bv):
+			pick = rv // Only the remote changed it
+		case sameRecord(rv, bv):
+			pick = lv // Only this device changed it
+		default:
+			conflicts = append(conflicts, Conflict{Kind: kind, ID: k, Base: bv, Local: lv, Remote: rv})
+			continue
+		}
+		if pick != nil {
+			merged = append(merged, *pick)
+		}
+	}
+	return merged, conflicts
+}
+
+// Merge3 combines local and remote portfolios that both descend from base.
+// Corporate actions are only ever added, so they are unioned.
+func Merge3(base, local, remote *Portfolio) MergeResult {
+	var res MergeResult
+	var conflicts []Conflict
+
+	res.Merged.Lots, conflicts = mergeRecords("lot", base.Lots, local.Lots, remote.Lots, func(l Lot) string { return l.ID })
+	res.Conflicts = append(res.Conflicts, conflicts...)
+	res.Merged.Grants, conflicts = mergeRecords("grant", base.Grants, local.Grants, remote.Grants, func(g Grant) string { return g.ID })
+	res.Conflicts = append(res.Conflicts, conflicts...)
+	res.Merged.Valuations, conflicts = mergeRecords("valuation", base.Valuations, local.Valuations, remote.Valuations, Valuation.key)
+	res.Conflicts = append(res.Conflicts, conflicts...)
+	res.Merged.Snapshots, conflicts = mergeRecords("snapshot", base.Snapshots, local.Snapshots, remote.Snapshots, Snapshot.key)
+	res.Conflicts = append(res.Conflicts, conflicts...)
+
+	res.Merged.Actions = append(res.Merged.Actions, local.Actions...)
+	for _, a := range remote.Actions {
+		found := false
+		for _, have := range local.Actions {
+			if sameRecord(a, have) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res.Merged.Actions = append(res.Merged.Actions, a)
+		}
+	}
+	return res
+}
+
+// Resolve records which version of a conflict to keep
+func (m *MergeResult) Resolve(i int, s Side) {
+	m.Conflicts[i].Choice = s
+}
+
+// Final returns the merged portfolio with every conflict's chosen version.
+// It fails if any conflict is unresolved.
+func (m *MergeResult) Final() (*Portfolio, error) {
+	out := Portfolio{
+		Lots:    append([]Lot(nil), m.Merged.Lots...),
+		Grants:  append([]Grant(nil), m.Merged.Grants...),
+		Actions: append([]CorporateAction(nil), m.Merged.Actions...),
+	}
+	valuations := append([]Valuation(nil), m.Merged.Valuations...)
+	snapshots := append([]Snapshot(nil), m.Merged.Snapshots...)
+	for _, c := range m.Conflicts {
+		if c.Choice == "" {
+			return nil, fmt.Errorf("conflict on %s %s is unresolved", c.Kind, c.ID)
+		}
+		switch v := c.Version(c.Choice).(type) {
+		case *Lot:
+			if v != nil {
+				out.Lots = append(out.Lots, *v)
+			}
+		case *Grant:
+			if v != nil {
+				out.Grants = append(out.Grants, *v)
+			}
+		case *Valuation:
+			if v != nil {
+				valuations = append(valuations, *v)
+			}
+		case *Snapshot:
+			if v != nil {
+				snapshots = append(snapshots, *v)
+			}
+		}
+	}
+	for _, v := range valuations {
+		out.AddValuation(v)
+	}
+	for _, s := range snapshots {
+		out.RecordSnapshot(s)
+	}
+	return &out, nil
+}
@@ -0,0 +1,127 @@
+package portfolio
+
+import (
+	"math"
+	"time"
+
+	"fynance/stc"
+)
+
+// UnvestedTreatment is how an acquirer handles awards that have not vested at close
+type UnvestedTreatment string
+
+const (
+	TreatConvert UnvestedTreatment = "convert" // Assumed into acquirer awards on the original schedule; cashed out by an all-cash deal
+	TreatCashOut UnvestedTreatment = "cashout" // Paid out in cash on the original schedule
+)
+
+// MergerTerms are the announced terms of an acquisition
+type MergerTerms struct {
+	Acquirer      string            `json:"acquirer"`      // Acquirer ticker
+	Close         time.Time         `json:"close"`         // Expected closing date
+	CashPerShare  float64           `json:"cashPerShare"`  // Cash consideration per target share
+	StockRatio    float64           `json:"stockRatio"`    // Acquirer shares per target share
+	AcquirerPrice float64           `json:"acquirerPrice"` // Assumed acquirer share price
+	Acceleration  float64           `json:"acceleration"`  // Fraction of unvested awards that vests at close (0-1)
+	Unvested      UnvestedTreatment `json:"unvested"`
+}
+
+// DealValue is the per-share value of the consideration
+func (t MergerTerms) DealValue() float64 {
+	return t.CashPerShare + t.StockRatio*t.AcquirerPrice
+}
+
+// MergerOutcome is the portfolio after the deal closes and the cash events it triggers
+type MergerOutcome struct {
+	Portfolio         *Portfolio `json:"portfolio"`         // Converted lots and grants
+	LotCash           float64    `json:"lotCash"`           // Cash paid for held shares
+	AcceleratedShares float64    `json:"acceleratedShares"` // Target shares vesting at close
+	AcceleratedIncome float64    `json:"acceleratedIncome"` // Ordinary income at close
+	CashOutIncome     float64    `json:"cashOutIncome"`     // Ordinary income from cashed-out awards (all periods)
+	CloseWithholding  float64    `json:"closeWithholding"`  // Tax withheld on income recognized at close
+}
+
+// ModelMerger applies the terms to a copy of the portfolio. calc supplies the
+// withholding rates used for income recognized at close.
+func ModelMerger(p *Portfolio, terms MergerTerms, calc *stc.Calculator) MergerOutcome {
+	out := MergerOutcome{Portfolio: &Portfolio{Actions: p.Actions}}
+	dealValue := terms.DealValue()
+
+	// Held lots: cash portion is paid out, stock portion converts at the ratio
+	for _, lot := range p.Lots {
+		out.LotCash += roundMoney(lot.Shares * terms.CashPerShare)
+		if terms.StockRatio > 0 {
+			converted := lot
+			converted.Symbol = terms.Acquirer
+			converted.Shares = lot.Shares * terms.StockRatio
+			converted.CostBasis = lot.CostBasis / terms.StockRatio
+			out.Portfolio.Lots = append(out.Portfolio.Lots, converted)
+		}
+	}
+
+	closeIncome := 0.0
+	for _, g := range p.Grants {
+		spread := dealValue
+		if g.IsOption() {
+			spread = math.Max(dealValue-g.Strike, 0)
+		}
+
+		// Tranches vested by the close are untouched by the deal's unvested
+		// terms: they convert at the ratio, or an all-cash deal buys out the
+		// options' spread. Vested RSUs were already released as held lots.
+		var vested []stc.Vest
+		for _, v := range g.AllVests() {
+			if v.Date.After(terms.Close) {
+				continue
+			}
+			if terms.StockRatio > 0 {
+				vested = append(vested, v)
+			} else if g.IsOption() {
+				out.CashOutIncome += roundMoney(v.Shares * spread)
+			}
+		}
+
+		// Accelerated portion vests at close
+		accelerated := 0.0
+		var remaining []stc.Vest
+		for _, v := range g.Unvested(terms.Close) {
+			acc := math.Floor(v.Shares * terms.Acceleration)
+			accelerated += acc
+			if v.Shares-acc > 0 {
+				remaining = append(remaining, stc.Vest{Date: v.Date, Shares: v.Shares - acc})
+			}
+		}
+		out.AcceleratedShares += accelerated
+		closeIncome += roundMoney(accelerated * spread)
+
+		// With no acquirer stock to assume them into, unvested awards are cashed out
+		if terms.Unvested == TreatCashOut || terms.StockRatio <= 0 {
+			for _, v := range remaining {
+				out.CashOutIncome += roundMoney(v.Shares * spread)
+			}
+			remaining = nil
+		}
+		if len(vested)+len(remaining) == 0 {
+			continue
+		}
+
+		// Assumed awards keep their dates; share counts and strikes convert
+		converted := g
+		converted.Symbol = terms.Acquirer
+		converted.Releases = append(vested, remaining...)
+		for i := range converted.Releases {
+			converted.Releases[i].Shares *= terms.StockRatio
+		}
+		converted.Strike = g.Strike / terms.StockRatio
+		out.Portfolio.Grants = append(out.Portfolio.Grants, converted)
+	}
+
+	out.AcceleratedIncome = closeIncome
+	if closeIncome > 0 && calc != nil {
+		// Withholding on accelerated vesting is computed as a release at deal value
+		res := calc.CalculateRSU(stc.RSUInput{SharesReleased: 1, VestPrice: closeIncome, SalePrice: closeIncome})
+		out.CloseWithholding = res.TotalTax
+	}
+
+	return out
+}
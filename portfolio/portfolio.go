@@ -0,0 +1,106 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"fynance/stc"
+)
+
+// Lot is a block of shares acquired in a single exercise or release
+type Lot struct {
+	ID        string    `json:"id"`
+	Symbol    string    `json:"symbol"`
+	Shares    float64   `json:"shares"`
+	CostBasis float64   `json:"costBasis"` // Per-share basis (FMV at exercise or vest)
+	Acquired  time.Time `json:"acquired"`
+	Source    string    `json:"source"`           // "Option", "RSU", ...
+	Income    float64   `json:"income,omitempty"` // Ordinary income recognized at acquisition (vest value or exercise spread)
+	Tax       float64   `json:"tax,omitempty"`    // Tax withheld or paid at acquisition
+	Cash      float64   `json:"cash,omitempty"`   // Net cash from the transaction; negative when cash was paid in
+
+	Currency string  `json:"currency,omitempty"` // Currency of CostBasis; blank is USD
+	FX       *FXRate `json:"fx,omitempty"`       // Rate on the acquisition date, for reporting in USD
+}
+
+// Value returns the lot's market value at the given price
+func (l Lot) Value(price float64) float64 {
+	return l.Shares * price
+}
+
+// UnmarshalJSON accepts formatted amounts such as "$1,234.50"; see stc.DecodeAmounts
+func (l *Lot) UnmarshalJSON(data []byte) error {
+	type plain Lot
+	return stc.DecodeAmounts(data, (*plain)(l), "shares", "costBasis", "income", "tax", "cash")
+}
+
+// Portfolio holds the lots an employee has retained after sell-to-cover events
+type Portfolio struct {
+	Lots    []Lot             `json:"lots"`
+	Grants  []Grant           `json:"grants,omitempty"`  // Awards with shares still to vest
+	Actions []CorporateAction `json:"actions,omitempty"` // Splits and renames already applied to Lots
+
+	Valuations []Valuation `json:"valuations,omitempty"` // Private-company valuations in effective-date order
+	Snapshots  []Snapshot  `json:"snapshots,omitempty"`  // Net-worth history in date order
+}
+
+// Add appends a lot, assigning an ID if none is set. Lots acquired before a
+// recorded corporate action are adjusted so every lot is in current terms.
+func (p *Portfolio) Add(lot Lot) Lot {
+	for _, a := range p.Actions {
+		lot = a.adjust(lot)
+	}
+	if lot.ID == "" {
+		lot.ID = strconv.FormatInt(lot.Acquired.UnixNano(), 36) + "-" + strconv.Itoa(len(p.Lots)+1)
+	}
+	p.Lots = append(p.Lots, lot)
+	return lot
+}
+
+// Remove deletes the lot with the given ID
+func (p *Portfolio) Remove(id string) {
+	for i, lot := range p.Lots {
+		if lot.ID == id {
+			p.Lots = append(p.Lots[:i], p.Lots[i+1:]...)
+			return
+		}
+	}
+}
+
+// TotalShares returns the number of shares held across all lots
+func (p *Portfolio) TotalShares() float64 {
+	total := 0.0
+	for _, lot := range p.Lots {
+		total += lot.Shares
+	}
+	return total
+}
+
+// Load reads a portfolio from JSON
+func Load(r io.Reader) (*Portfolio, error) {
+	var p Portfolio
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to read portfolio: %w", err)
+	}
+	for _, g := range p.Grants {
+		for _, d := range g.Documents {
+			if err := d.Validate(); err != nil {
+				return nil, fmt.Errorf("failed to read portfolio: grant %s: %w", g.ID, err)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// Save writes the portfolio as JSON
+func (p *Portfolio) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return fmt.Errorf("failed to write portfolio: %w", err)
+	}
+	return nil
+}
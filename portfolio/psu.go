@@ -0,0 +1,96 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fynance/stc"
+)
+
+// MaxPayout is the highest payout multiplier a PSU plan may set (200%)
+const MaxPayout = 2.0
+
+// PayoutRange is the share of a PSU grant's target units earned at each
+// level of performance, e.g. 50% at threshold, 100% at target, and 200% at
+// maximum. A period that misses threshold earns nothing.
+type PayoutRange struct {
+	Threshold float64 `json:"threshold"`
+	Target    float64 `json:"target"`
+	Max       float64 `json:"max"`
+}
+
+// DefaultPayoutRange is the common 50/100/200% schedule
+var DefaultPayoutRange = PayoutRange{Threshold: 0.5, Target: 1, Max: 2}
+
+// Validate reports multipliers outside 0 to MaxPayout or out of order
+func (r PayoutRange) Validate() error {
+	for _, m := range []float64{r.Threshold, r.Target, r.Max} {
+		if m < 0 || m > MaxPayout {
+			return fmt.Errorf("payout multipliers must be from 0 to %.0f%%", MaxPayout*100)
+		}
+	}
+	if r.Threshold > r.Target || r.Target > r.Max {
+		return fmt.Errorf("payout multipliers must rise from threshold to target to maximum")
+	}
+	return nil
+}
+
+// PSUScenario is one payout level of a performance period
+type PSUScenario struct {
+	Name       string  `json:"name"` // Threshold, Target, or Max
+	Multiplier float64 `json:"multiplier"`
+	Units      float64 `json:"units"`    // Whole shares earned: target units × multiplier, rounded down
+	Value      float64 `json:"value"`    // Units at the assumed price
+	TotalTax   float64 `json:"totalTax"` // Withholding on the release
+	NetShares  float64 `json:"netShares"`
+}
+
+// PSUProjection is a performance period with its release at each payout level
+type PSUProjection struct {
+	GrantID   string         `json:"grantId"`
+	Symbol    string         `json:"symbol"`
+	PeriodEnd time.Time      `json:"periodEnd"`
+	Target    float64        `json:"target"` // Target units for the period
+	Scenarios [3]PSUScenario `json:"scenarios"`
+}
+
+// payout returns the grant's range, or DefaultPayoutRange when none is set
+func (g Grant) payout() PayoutRange {
+	if g.Payout == nil {
+		return DefaultPayoutRange
+	}
+	return *g.Payout
+}
+
+// ProjectPSU releases each PSU performance period ending after asOf at the
+// threshold, target, and maximum payouts, withholding as an RSU release at
+// price. A grant's vests mark the ends of its performance periods, with the
+// target units of each.
+func ProjectPSU(calc *stc.Calculator, p *Portfolio, asOf time.Time, price float64) []PSUProjection {
+	var out []PSUProjection
+	for _, g := range p.Grants {
+		if g.Kind != GrantPSU {
+			continue
+		}
+		r := g.payout()
+		levels := [3]struct {
+			name string
+			m    float64
+		}{{"Threshold", r.Threshold}, {"Target", r.Target}, {"Max", r.Max}}
+		for _, v := range g.Unvested(asOf) {
+			proj := PSUProjection{GrantID: g.ID, Symbol: g.Symbol, PeriodEnd: v.Date, Target: v.Shares}
+			for i, l := range levels {
+				s := PSUScenario{Name: l.name, Multiplier: l.m, Units: math.Floor(v.Shares * l.m)}
+				s.Value = roundMoney(s.Units * price)
+				if s.Units > 0 {
+					res := calc.CalculateRSU(stc.RSUInput{SharesReleased: s.Units, VestPrice: price, SalePrice: price, Dates: stc.TransactionDates{Vest: v.Date}})
+					s.TotalTax, s.NetShares = res.TotalTax, res.NetShares
+				}
+				proj.Scenarios[i] = s
+			}
+			out = append(out, proj)
+		}
+	}
+	return out
+}
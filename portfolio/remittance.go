@@ -0,0 +1,116 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fynance/report"
+	"fynance/stc"
+)
+
+// RemittanceEntry is one transaction's withholding by authority
+type RemittanceEntry struct {
+	Title string           `json:"title"`
+	Date  time.Time        `json:"date,omitzero"` // Vest or exercise date, when recorded
+	Lines []stc.Remittance `json:"lines"`
+}
+
+// RemittanceReport lists the tax withheld on each transaction by the
+// authority it is paid to, with totals per authority, for estimated payments
+// or an employer's funding report
+type RemittanceReport struct {
+	Entries []RemittanceEntry `json:"entries"`
+	Totals  []stc.Remittance  `json:"totals"`
+}
+
+// NewRemittanceReport collects the withholding of every entry with a result;
+// with reconciledOnly, the what-ifs are left out
+func NewRemittanceReport(entries []stc.SessionEntry, reconciledOnly bool) RemittanceReport {
+	var r RemittanceReport
+	var all []stc.Remittance
+	for _, e := range entries {
+		if reconciledOnly && !e.Reconciled {
+			continue
+		}
+		entry := RemittanceEntry{Title: e.Title}
+		switch {
+		case e.RSUResult != nil:
+			entry.Lines = e.RSUResult.Remittances()
+			if e.RSU != nil {
+				entry.Date = e.RSU.Dates.Vest
+			}
+		case e.Result != nil:
+			entry.Lines = e.Result.Remittances()
+			if e.Options != nil {
+				entry.Date = e.Options.Dates.Vest
+			}
+		default:
+			continue
+		}
+		r.Entries = append(r.Entries, entry)
+		all = append(all, entry.Lines...)
+	}
+	r.Totals = stc.RemittanceTotals(all)
+	return r
+}
+
+// WriteText renders the report
+func (r RemittanceReport) WriteText(w io.Writer) error {
+	return r.WriteTextIn(w, report.USLocale)
+}
+
+// WriteTextIn renders the report with the numbers and dates of loc
+func (r RemittanceReport) WriteTextIn(w io.Writer, loc report.Locale) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TAX REMITTANCE BREAKDOWN\n")
+	for _, e := range r.Entries {
+		date := ""
+		if !e.Date.IsZero() {
+			date = "  " + loc.Date(e.Date)
+		}
+		fmt.Fprintf(&b, "\n%s%s\n", e.Title, date)
+		if len(e.Lines) == 0 {
+			fmt.Fprintf(&b, "  Nothing withheld\n")
+		}
+		for _, l := range e.Lines {
+			fmt.Fprintf(&b, "  %-50s %-22s %14s\n", l.Authority, l.Tax, loc.Number(l.Amount, 2))
+		}
+	}
+
+	fmt.Fprintf(&b, "\nTOTAL BY AUTHORITY\n")
+	var total float64
+	for _, t := range r.Totals {
+		fmt.Fprintf(&b, "  %-73s %14s\n", t.Authority, loc.Number(t.Amount, 2))
+		total += t.Amount
+	}
+	fmt.Fprintf(&b, "\nTotal withheld: %s\n", loc.Money(roundMoney(total)))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ToCSV writes one row per remittance line, in plain numbers for a spreadsheet
+func (r RemittanceReport) ToCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Transaction", "Date", "Authority", "Tax", "Amount"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	for _, e := range r.Entries {
+		date := ""
+		if !e.Date.IsZero() {
+			date = e.Date.Format("2006-01-02")
+		}
+		for _, l := range e.Lines {
+			row := []string{e.Title, date, l.Authority, l.Tax, fmt.Sprintf("%.2f", l.Amount)}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write row: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package portfolio
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"fynance/report"
+	"fynance/stc"
+	"fynance/stc/amt"
+)
+
+// Thresholds past which the scorecard suggests a change
+const (
+	scorecardGapRate      = 0.02 // Federal withholding off the estimated tax by more than 2% of income
+	scorecardGapMinimum   = 500  // and by more than $500
+	scorecardFeeRate      = 0.01 // Fees above 1% of proceeds
+	scorecardResidualRate = 0.02 // Residual cash above 2% of proceeds
+)
+
+// Scorecard grades a tax year's equity transactions: how close withholding
+// came to the tax actually owed, what the broker took, and how much stock
+// was sold only to be returned as residual cash
+type Scorecard struct {
+	Year         int              `json:"year"`
+	Status       amt.FilingStatus `json:"status,omitempty"`
+	OtherIncome  float64          `json:"otherIncome"` // Taxable income outside the transactions, e.g. salary
+	Transactions int              `json:"transactions"`
+
+	Income           float64 `json:"income"`           // Ordinary income from the transactions
+	Withheld         float64 `json:"withheld"`         // Every tax withheld
+	FederalWithheld  float64 `json:"federalWithheld"`  // The federal income tax part of Withheld
+	FederalLiability float64 `json:"federalLiability"` // Federal tax on Income stacked on OtherIncome
+
+	Proceeds float64 `json:"proceeds"` // Gross proceeds of the shares sold
+	Fees     float64 `json:"fees"`     // Commissions, flat fees, and regulatory fees
+	Residual float64 `json:"residual"` // Cash returned after covering the costs
+
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// NewScorecard totals the reconciled entries of a tax year; the others are
+// what-ifs. The federal tax owed uses the 2025 brackets for status, with
+// FICA and state tax taken to be withheld exactly.
+func NewScorecard(entries []stc.SessionEntry, year int, otherIncome float64, status amt.FilingStatus) Scorecard {
+	s := Scorecard{Year: year, Status: status, OtherIncome: otherIncome}
+	for _, e := range entries {
+		if !e.Reconciled {
+			continue
+		}
+		switch {
+		case e.Result != nil && e.Result.Meta.TaxYear == year:
+			r := e.Result
+			s.add(r.TaxableGain, r.TotalTax, r.FederalTax, r.EstGrossProceeds, r.BrokerFees+r.SECFee+r.TAF, r.Residual)
+		case e.RSUResult != nil && e.RSUResult.Meta.TaxYear == year:
+			r := e.RSUResult
+			s.add(r.TaxableGain, r.TotalTax, r.FederalTax, r.SharesToSell*r.SalePrice, r.TotalFees, r.Residual)
+		}
+	}
+	s.Income, s.Withheld, s.FederalWithheld = roundMoney(s.Income), roundMoney(s.Withheld), roundMoney(s.FederalWithheld)
+	s.Proceeds, s.Fees, s.Residual = roundMoney(s.Proceeds), roundMoney(s.Fees), roundMoney(s.Residual)
+	s.FederalLiability = roundMoney(stc.FederalBracketsFor(status).TaxOnTop(math.Max(otherIncome, 0), s.Income))
+	s.Suggestions = s.suggest()
+	return s
+}
+
+// add counts one transaction
+func (s *Scorecard) add(income, withheld, federal, proceeds, fees, residual float64) {
+	s.Transactions++
+	s.Income += income
+	s.Withheld += withheld
+	s.FederalWithheld += federal
+	s.Proceeds += proceeds
+	s.Fees += fees
+	s.Residual += math.Max(residual, 0)
+}
+
+// Liability returns the tax owed on the transactions: the federal estimate
+// plus the FICA and state tax withheld
+func (s Scorecard) Liability() float64 {
+	return roundMoney(s.Withheld - s.FederalWithheld + s.FederalLiability)
+}
+
+// Shortfall returns the tax still owed at filing; negative is a refund
+func (s Scorecard) Shortfall() float64 {
+	return roundMoney(s.Liability() - s.Withheld)
+}
+
+// ratio divides, returning 0 for an empty denominator
+func ratio(num, den float64) float64 {
+	if den <= 0 {
+		return 0
+	}
+	return num / den
+}
+
+// suggest lists the changes worth making next year
+func (s Scorecard) suggest() []string {
+	var out []string
+	gap := s.Shortfall()
+	if math.Abs(gap) > scorecardGapMinimum && math.Abs(gap) > scorecardGapRate*s.Income {
+		if gap > 0 {
+			out = append(out, fmt.Sprintf("Withholding fell about $%.0f short of the estimated tax. Elect a higher "+
+				"supplemental rate if the plan allows, or make an estimated payment to limit underpayment penalties.", gap))
+		} else {
+			out = append(out, fmt.Sprintf("Withholding exceeded the estimated tax by about $%.0f. A lower supplemental "+
+				"election, where the plan offers one, keeps more shares; otherwise lower the W-4 withholding on wages.", -gap))
+		}
+	}
+	if rate := ratio(s.Fees, s.Proceeds); rate > scorecardFeeRate {
+		out = append(out, fmt.Sprintf("Fees took %.1f%% of proceeds. Ask the broker for a lower commission or an annual "+
+			"cap, or withhold shares to cover, which sells nothing.", rate*100))
+	}
+	if rate := ratio(s.Residual, s.Proceeds); rate > scorecardResidualRate {
+		out = append(out, fmt.Sprintf("Residual cash was %.1f%% of proceeds: stock sold only to be paid back. Selling "+
+			"fractional shares or topping up with cash sells less.", rate*100))
+	}
+	return out
+}
+
+// WriteText renders the scorecard as a one-page summary
+func (s Scorecard) WriteText(w io.Writer) error {
+	return s.WriteTextIn(w, report.USLocale)
+}
+
+// WriteTextIn renders the scorecard with the numbers of loc
+func (s Scorecard) WriteTextIn(w io.Writer, loc report.Locale) error {
+	status := s.Status
+	if status == "" {
+		status = amt.Single
+	}
+	pct := func(v float64) string { return loc.Number(v*100, 1) + "%" }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "EQUITY TAX AND FEE SCORECARD %d\n\n", s.Year)
+	fmt.Fprintf(&b, "Transactions:   %d\n", s.Transactions)
+	fmt.Fprintf(&b, "Filing Status:  %s\n", status)
+	fmt.Fprintf(&b, "Other Income:   %s\n\n", loc.Money(s.OtherIncome))
+
+	fmt.Fprintf(&b, "WITHHOLDING\n")
+	fmt.Fprintf(&b, "%-28s %16s\n", "Equity income", loc.Money(s.Income))
+	fmt.Fprintf(&b, "%-28s %16s %8s\n", "Tax withheld", loc.Money(s.Withheld), pct(ratio(s.Withheld, s.Income)))
+	fmt.Fprintf(&b, "%-28s %16s %8s\n", "Estimated tax owed", loc.Money(s.Liability()), pct(ratio(s.Liability(), s.Income)))
+	fmt.Fprintf(&b, "%-28s %16s\n", "  of which federal", loc.Money(s.FederalLiability))
+	if gap := s.Shortfall(); gap >= 0 {
+		fmt.Fprintf(&b, "%-28s %16s\n\n", "Still owed at filing", loc.Money(gap))
+	} else {
+		fmt.Fprintf(&b, "%-28s %16s\n\n", "Over-withheld", loc.Money(-gap))
+	}
+
+	fmt.Fprintf(&b, "COSTS OF SELLING\n")
+	fmt.Fprintf(&b, "%-28s %16s\n", "Proceeds", loc.Money(s.Proceeds))
+	fmt.Fprintf(&b, "%-28s %16s %8s\n", "Fees", loc.Money(s.Fees), pct(ratio(s.Fees, s.Proceeds)))
+	fmt.Fprintf(&b, "%-28s %16s %8s\n\n", "Residual cash", loc.Money(s.Residual), pct(ratio(s.Residual, s.Proceeds)))
+
+	fmt.Fprintf(&b, "SUGGESTIONS\n")
+	if len(s.Suggestions) == 0 {
+		fmt.Fprintf(&b, "None: withholding, fees, and residual cash are all within range.\n")
+	}
+	for _, line := range s.Suggestions {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+
+	fmt.Fprintf(&b, "\nThe federal estimate stacks the equity income on the other income in the\n"+
+		"2025 brackets and ignores deductions, credits, and the AMT. FICA and state\n"+
+		"tax are taken as withheld.\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
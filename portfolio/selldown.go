@@ -0,0 +1,221 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fynance/report"
+)
+
+// SellDownTerms describe a systematic sell-down of retained shares
+type SellDownTerms struct {
+	Start       time.Time     `json:"start"`       // First trade date, after any cooling-off period
+	Periods     int           `json:"periods"`     // Number of scheduled sales
+	EveryMonths int           `json:"everyMonths"` // Months between sales, e.g. 3 for quarterly
+	Percent     float64       `json:"percent"`     // Fraction of the starting position sold each period, e.g. 0.10
+	Price       float64       `json:"price"`       // Assumed share price at the first sale
+	Growth      float64       `json:"growth"`      // Assumed annual price change, e.g. 0.05
+	LimitPrice  float64       `json:"limitPrice"`  // Minimum sale price written into the schedule; 0 is a market order
+	OtherAssets float64       `json:"otherAssets"` // Net worth outside the position, for concentration
+	Target      float64       `json:"target"`      // Stop selling once concentration is at or below this; 0 sells on schedule
+	Rates       CapGainsRates `json:"rates"`
+}
+
+// SellDownSale is the part of one scheduled sale drawn from a single lot
+type SellDownSale struct {
+	LotID    string  `json:"lotId"`
+	Shares   float64 `json:"shares"`
+	Gain     float64 `json:"gain"`
+	LongTerm bool    `json:"longTerm"`
+	Tax      float64 `json:"tax"`
+}
+
+// SellDownPeriod is one scheduled sale and the position left after it
+type SellDownPeriod struct {
+	Date          time.Time      `json:"date"`
+	Price         float64        `json:"price"`
+	Shares        float64        `json:"shares"`
+	Proceeds      float64        `json:"proceeds"`
+	Gain          float64        `json:"gain"`
+	Tax           float64        `json:"tax"`
+	Remaining     float64        `json:"remaining"`     // Shares still held
+	Concentration float64        `json:"concentration"` // Position value over total net worth, after the sale
+	Sales         []SellDownSale `json:"sales"`
+}
+
+// SellDownPlan is the projected schedule for a set of terms
+type SellDownPlan struct {
+	Terms         SellDownTerms    `json:"terms"`
+	StartShares   float64          `json:"startShares"`
+	StartValue    float64          `json:"startValue"`
+	Concentration float64          `json:"concentration"` // Before the first sale
+	Periods       []SellDownPeriod `json:"periods"`
+}
+
+// PlanSellDown projects the terms against the portfolio's lots. Each sale
+// draws first from lots that are long-term on the trade date, then from the
+// highest basis, so the least tax is realized early.
+func PlanSellDown(p *Portfolio, t SellDownTerms) SellDownPlan {
+	held := append([]Lot(nil), p.Lots...)
+	plan := SellDownPlan{Terms: t, StartShares: p.TotalShares()}
+	plan.StartValue = roundMoney(plan.StartShares * t.Price)
+	plan.Concentration = concentration(plan.StartValue, t.OtherAssets)
+
+	perPeriod := plan.StartShares * t.Percent
+	remaining := plan.StartShares
+	cash := t.OtherAssets
+	for i := 0; i < t.Periods && remaining > 0; i++ {
+		date := t.Start.AddDate(0, i*t.EveryMonths, 0)
+		price := t.Price * math.Pow(1+t.Growth, float64(i*t.EveryMonths)/12)
+		period := SellDownPeriod{Date: date, Price: roundMoney(price)}
+
+		// Nothing trades below the limit or once the target concentration is reached
+		toSell := math.Min(perPeriod, remaining)
+		if t.LimitPrice > 0 && price < t.LimitPrice || t.Target > 0 && concentration(remaining*price, cash) <= t.Target {
+			toSell = 0
+		}
+
+		sort.SliceStable(held, func(a, b int) bool {
+			la, lb := !date.Before(held[a].LongTermDate()), !date.Before(held[b].LongTermDate())
+			if la != lb {
+				return la
+			}
+			return held[a].BasisUSD() > held[b].BasisUSD()
+		})
+		for j := range held {
+			if toSell <= 0 {
+				break
+			}
+			lot := &held[j]
+			shares := math.Min(lot.Shares, toSell)
+			if shares <= 0 {
+				continue
+			}
+			sale := SellDownSale{
+				LotID:    lot.ID,
+				Shares:   shares,
+				Gain:     roundMoney((price - lot.BasisUSD()) * shares),
+				LongTerm: !date.Before(lot.LongTermDate()),
+			}
+			// Losses produce no tax, as in the holdings view
+			rate := t.Rates.ShortTerm
+			if sale.LongTerm {
+				rate = t.Rates.LongTerm
+			}
+			sale.Tax = roundMoney(math.Max(sale.Gain, 0) * rate)
+
+			period.Sales = append(period.Sales, sale)
+			period.Shares += shares
+			period.Gain += sale.Gain
+			period.Tax += sale.Tax
+			lot.Shares -= shares
+			toSell -= shares
+		}
+
+		period.Proceeds = roundMoney(period.Shares * price)
+		period.Gain = roundMoney(period.Gain)
+		period.Tax = roundMoney(period.Tax)
+		// Shares are tracked to the millionth, so repeated fractions do not leave dust
+		remaining = math.Round((remaining-period.Shares)*1e6) / 1e6
+		cash += period.Proceeds - period.Tax
+		period.Remaining = remaining
+		period.Concentration = concentration(remaining*price, cash)
+		plan.Periods = append(plan.Periods, period)
+	}
+	return plan
+}
+
+// concentration is the share of net worth held in the position
+func concentration(position, other float64) float64 {
+	if position+other <= 0 {
+		return 0
+	}
+	return position / (position + other)
+}
+
+// Totals returns the shares sold, proceeds, and tax across every period
+func (s SellDownPlan) Totals() (shares, proceeds, tax float64) {
+	for _, p := range s.Periods {
+		shares += p.Shares
+		proceeds += p.Proceeds
+		tax += p.Tax
+	}
+	return shares, proceeds, tax
+}
+
+// WriteText renders the plan as a Rule 10b5-1 style sale schedule for a broker
+func (s SellDownPlan) WriteText(w io.Writer) error {
+	return s.WriteTextIn(w, report.USLocale)
+}
+
+// WriteTextIn renders the schedule with the numbers and dates of loc
+func (s SellDownPlan) WriteTextIn(w io.Writer, loc report.Locale) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "RULE 10b5-1 TRADING PLAN — PROPOSED SALE SCHEDULE\n\n")
+	fmt.Fprintf(&b, "Starting Position:  %s shares (%s, %s of net worth)\n",
+		loc.Number(s.StartShares, 4), loc.Money(s.StartValue), loc.Percent(s.Concentration, 1))
+	fmt.Fprintf(&b, "Sale Amount:        %s of the starting position every %d months\n",
+		loc.Percent(s.Terms.Percent, 1), s.Terms.EveryMonths)
+	limit := "Market"
+	if s.Terms.LimitPrice > 0 {
+		limit = loc.Money(s.Terms.LimitPrice) + " minimum"
+	}
+	fmt.Fprintf(&b, "Limit Price:        %s\n\n", limit)
+
+	fmt.Fprintf(&b, "%-12s %12s %10s %14s %12s %12s %12s\n",
+		"Trade Date", "Shares", "Est. Price", "Est. Proceeds", "Est. Tax", "Remaining", "Concentr.")
+	for _, p := range s.Periods {
+		fmt.Fprintf(&b, "%-12s %12s %10s %14s %12s %12s %12s\n",
+			loc.Date(p.Date), loc.Number(p.Shares, 4), loc.Number(p.Price, 2), loc.Number(p.Proceeds, 2),
+			loc.Number(p.Tax, 2), loc.Number(p.Remaining, 4), loc.Percent(p.Concentration, 1))
+	}
+	shares, proceeds, tax := s.Totals()
+	fmt.Fprintf(&b, "\nTotal: %s shares, est. proceeds %s, est. tax %s\n", loc.Number(shares, 4), loc.Money(proceeds), loc.Money(tax))
+
+	fmt.Fprintf(&b, "\nPrices assume %s annual change from %s and are estimates only; the\n"+
+		"schedule fixes the share amounts and dates. Trades must begin after the plan's\n"+
+		"cooling-off period, and the plan may only be adopted outside a blackout window.\n",
+		loc.Percent(s.Terms.Growth, 1), loc.Money(s.Terms.Price))
+	if s.Terms.Target > 0 {
+		fmt.Fprintf(&b, "Sales are skipped while concentration is at or below %s.\n", loc.Percent(s.Terms.Target, 1))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ToCSV writes one row per lot drawn on each trade date
+func (s SellDownPlan) ToCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Trade Date", "Lot ID", "Shares", "Est. Price", "Gain", "Long Term", "Est. Tax", "Concentration"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, p := range s.Periods {
+		for _, sale := range p.Sales {
+			row := []string{
+				p.Date.Format("2006-01-02"),
+				sale.LotID,
+				fmt.Sprintf("%.4f", sale.Shares),
+				fmt.Sprintf("%.2f", p.Price),
+				fmt.Sprintf("%.2f", sale.Gain),
+				fmt.Sprintf("%t", sale.LongTerm),
+				fmt.Sprintf("%.2f", sale.Tax),
+				fmt.Sprintf("%.4f", p.Concentration),
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write row: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package portfolio
+
+import (
+	"sort"
+	"time"
+)
+
+// SnapshotInterval is how often scheduled snapshots are taken
+const SnapshotInterval = 30 * 24 * time.Hour
+
+// Snapshot is the equity position on one date, kept to chart net worth over time
+type Snapshot struct {
+	Date          time.Time `json:"date"`
+	Shares        float64   `json:"shares"`
+	Price         float64   `json:"price"`
+	Value         float64   `json:"value"`
+	TaxesPaid     float64   `json:"taxesPaid"`     // Cumulative tax on every acquisition so far
+	CashExtracted float64   `json:"cashExtracted"` // Cumulative net cash from those transactions
+}
+
+// key identifies a snapshot by its date
+func (s Snapshot) key() string {
+	return s.Date.Format("2006-01-02")
+}
+
+// Snapshot values the portfolio at price on the given date. Taxes and cash
+// are summed over lots acquired on or before it.
+func (p *Portfolio) Snapshot(date time.Time, price float64) Snapshot {
+	s := Snapshot{Date: date, Price: price}
+	for _, lot := range p.Lots {
+		if lot.Acquired.After(date) {
+			continue
+		}
+		s.Shares += lot.Shares
+		s.TaxesPaid += lot.Tax
+		s.CashExtracted += lot.Cash
+	}
+	s.Value = roundMoney(s.Shares * price)
+	s.TaxesPaid = roundMoney(s.TaxesPaid)
+	s.CashExtracted = roundMoney(s.CashExtracted)
+	return s
+}
+
+// AdjustedSnapshots returns the net-worth history with the shares and price
+// of snapshots taken before a split in post-split terms. Values are unchanged.
+func (p *Portfolio) AdjustedSnapshots() []Snapshot {
+	out := make([]Snapshot, len(p.Snapshots))
+	for i, s := range p.Snapshots {
+		s.Shares, s.Price = p.adjustPosition(s.Date, s.Shares, s.Price)
+		out[i] = s
+	}
+	return out
+}
+
+// RecordSnapshot keeps a snapshot in date order, replacing one taken the same day
+func (p *Portfolio) RecordSnapshot(s Snapshot) {
+	for i, have := range p.Snapshots {
+		if have.key() == s.key() {
+			p.Snapshots[i] = s
+			return
+		}
+	}
+	p.Snapshots = append(p.Snapshots, s)
+	sort.SliceStable(p.Snapshots, func(i, j int) bool {
+		return p.Snapshots[i].Date.Before(p.Snapshots[j].Date)
+	})
+}
+
+// SnapshotDue reports whether a scheduled snapshot should be taken at now
+func (p *Portfolio) SnapshotDue(now time.Time) bool {
+	if len(p.Lots) == 0 {
+		return false
+	}
+	if len(p.Snapshots) == 0 {
+		return true
+	}
+	return now.Sub(p.Snapshots[len(p.Snapshots)-1].Date) >= SnapshotInterval
+}
@@ -0,0 +1,68 @@
+package portfolio
+
+import (
+	"sort"
+	"time"
+
+	"fynance/stc"
+)
+
+// Valuation is a private company's fair market value per share, such as a 409A
+// appraisal. It is in effect from its date until the next valuation.
+type Valuation struct {
+	Effective time.Time `json:"effective"`
+	Price     float64   `json:"price"`
+	Source    string    `json:"source,omitempty"` // "409A", "Tender offer", ...
+}
+
+// UnmarshalJSON accepts a formatted price such as "$12.50"; see stc.DecodeAmounts
+func (v *Valuation) UnmarshalJSON(data []byte) error {
+	type plain Valuation
+	return stc.DecodeAmounts(data, (*plain)(v), "price")
+}
+
+// key identifies a valuation by its effective date
+func (v Valuation) key() string {
+	return v.Effective.Format("2006-01-02")
+}
+
+// AddValuation records a valuation, keeping them in effective-date order.
+// A valuation on the same date as an existing one replaces it.
+func (p *Portfolio) AddValuation(v Valuation) {
+	for i, have := range p.Valuations {
+		if have.key() == v.key() {
+			p.Valuations[i] = v
+			return
+		}
+	}
+	p.Valuations = append(p.Valuations, v)
+	sort.SliceStable(p.Valuations, func(i, j int) bool {
+		return p.Valuations[i].Effective.Before(p.Valuations[j].Effective)
+	})
+}
+
+// ValuationOn returns the latest valuation effective on or before t, priced
+// in post-split terms
+func (p *Portfolio) ValuationOn(t time.Time) (Valuation, bool) {
+	for i := len(p.Valuations) - 1; i >= 0; i-- {
+		if !p.Valuations[i].Effective.After(t) {
+			return p.adjustValuation(p.Valuations[i]), true
+		}
+	}
+	return Valuation{}, false
+}
+
+// AdjustedValuations returns every valuation priced in post-split terms
+func (p *Portfolio) AdjustedValuations() []Valuation {
+	out := make([]Valuation, len(p.Valuations))
+	for i, v := range p.Valuations {
+		out[i] = p.adjustValuation(v)
+	}
+	return out
+}
+
+// adjustValuation reprices a valuation made before a split
+func (p *Portfolio) adjustValuation(v Valuation) Valuation {
+	_, v.Price = p.adjustPosition(v.Effective, 0, v.Price)
+	return v
+}
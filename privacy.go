@@ -0,0 +1,164 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/widgets"
+)
+
+const (
+	idleLockKey       = "privacy.idleLock"       // Minutes idle before locking; 0 never locks
+	clipboardClearKey = "privacy.clipboardClear" // Seconds before a copied result is cleared; 0 keeps it
+)
+
+// idleCheckInterval is how often the idle timer looks for activity
+const idleCheckInterval = 5 * time.Second
+
+// appLock covers the window with a privacy screen so figures are not left
+// on display. The app has no passcode, so Unlock only takes a click: the
+// lock hides the numbers from passers-by rather than securing them.
+type appLock struct {
+	win     fyne.Window
+	content fyne.CanvasObject
+	screen  fyne.CanvasObject
+	hidden  []fyne.CanvasObject // Dialogs hidden while locked
+	locked  bool
+	active  time.Time // Last time the user did something
+
+	onChange []func(locked bool) // Other windows showing figures
+}
+
+// newAppLock wraps content, which the caller must not set on win itself,
+// and starts locking it after the idle time chosen in Settings
+func newAppLock(a fyne.App, win fyne.Window, bus *events.Bus, content fyne.CanvasObject) *appLock {
+	l := &appLock{win: win, content: content, active: time.Now()}
+	title := widget.NewLabelWithStyle(appTitle+" is locked", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	unlock := widget.NewButtonWithIcon("Unlock", theme.VisibilityIcon(), l.Unlock)
+	unlock.Importance = widget.HighImportance
+	l.screen = container.NewCenter(container.NewVBox(title, unlock))
+	win.SetContent(content)
+
+	// Calculations, price fetches, and typing all count as activity
+	touch := func(events.Event) { l.active = time.Now() }
+	for _, k := range []events.Kind{events.InputChanged, events.PriceFetched, events.ProfileSwitched,
+		events.PortfolioChanged, events.ResultReady, events.EntryOpened} {
+		bus.Subscribe(k, touch)
+	}
+	a.Lifecycle().SetOnEnteredForeground(func() { l.active = time.Now() })
+
+	go func() {
+		var focused fyne.Focusable
+		var text string
+		for range time.Tick(idleCheckInterval) {
+			fyne.Do(func() {
+				// Moving focus or typing into the focused entry is activity too
+				f := win.Canvas().Focused()
+				t := entryText(f)
+				if f != focused || t != text {
+					focused, text = f, t
+					l.active = time.Now()
+				}
+				minutes := a.Preferences().Int(idleLockKey)
+				if !l.locked && minutes > 0 && time.Since(l.active) >= time.Duration(minutes)*time.Minute {
+					l.Lock()
+				}
+			})
+		}
+	}()
+	return l
+}
+
+// entryText is the text of a focused entry, or "" for anything else
+func entryText(f fyne.Focusable) string {
+	if e, ok := f.(*widget.Entry); ok {
+		return e.Text
+	}
+	if e, ok := f.(*widgets.SmartEntry); ok {
+		return e.Text
+	}
+	return ""
+}
+
+// Lock swaps in the privacy screen, hiding open dialogs and the result in
+// the title bar
+func (l *appLock) Lock() {
+	if l.locked {
+		return
+	}
+	l.locked = true
+	l.hidden = nil
+	for _, o := range l.win.Canvas().Overlays().List() {
+		if o.Visible() {
+			o.Hide()
+			l.hidden = append(l.hidden, o)
+		}
+	}
+	l.win.Canvas().Unfocus()
+	l.win.SetTitle(appTitle)
+	l.win.SetContent(l.screen)
+	for _, f := range l.onChange {
+		f(true)
+	}
+}
+
+// Unlock restores the window as it was before Lock
+func (l *appLock) Unlock() {
+	if !l.locked {
+		return
+	}
+	l.locked = false
+	l.active = time.Now()
+	l.win.SetContent(l.content)
+	for _, o := range l.hidden {
+		o.Show()
+	}
+	l.hidden = nil
+	for _, f := range l.onChange {
+		f(false)
+	}
+}
+
+// OnLockChanged calls f whenever the window is locked or unlocked, so other
+// windows can hide their figures too
+func (l *appLock) OnLockChanged(f func(locked bool)) {
+	l.onChange = append(l.onChange, f)
+}
+
+// copyToClipboard copies text and, when set in Settings, clears it again
+// after a delay so figures do not linger in clipboard managers. Anything
+// copied since is left alone.
+func copyToClipboard(a fyne.App, text string) {
+	cb := a.Clipboard()
+	cb.SetContent(text)
+	seconds := a.Preferences().Int(clipboardClearKey)
+	if seconds <= 0 {
+		return
+	}
+	time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		fyne.Do(func() {
+			if cb.Content() == text {
+				cb.SetContent("")
+			}
+		})
+	})
+}
+
+// newCopyResultButton copies the latest result's figures as plain text.
+// It starts disabled; pass each new result to the returned func.
+func newCopyResultButton() (*widget.Button, func(text string)) {
+	var latest string
+	btn := widget.NewButtonWithIcon("Copy Result", theme.ContentCopyIcon(), func() {
+		copyToClipboard(fyne.CurrentApp(), latest)
+	})
+	btn.Disable()
+	return btn, func(text string) {
+		latest = text
+		btn.Enable()
+	}
+}
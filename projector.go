@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// showGrantProjector projects the pre- and post-tax value of each vest of a proposed grant
+func showGrantProjector(win fyne.Window) {
+	unitsEntry := widgets.NewSmartEntry("1000")
+	grantDateEntry := widgets.NewSmartEntry(time.Now().Format("2006-01-02"))
+	monthsEntry := widgets.NewSmartEntry("48")
+	cliffEntry := widgets.NewSmartEntry("12")
+	everyEntry := widgets.NewSmartEntry("3")
+	dateRuleSelect := newDateRuleSelect()
+	priceEntry := widgets.NewSmartEntry("0.00")
+	growthEntry := widgets.NewSmartEntry("0.08")
+
+	table := container.NewGridWithColumns(5)
+	lblTotals := widget.NewLabel("")
+
+	project := func() {
+		units, err1 := parseFloat(unitsEntry.Text)
+		months, err2 := parseFloat(monthsEntry.Text)
+		cliff, err3 := parseFloat(cliffEntry.Text)
+		every, err4 := parseFloat(everyEntry.Text)
+		price, err5 := parseFloat(priceEntry.Text)
+		growth, err6 := parseFloat(growthEntry.Text)
+		grantDate, err7 := time.ParseInLocation("2006-01-02", grantDateEntry.Text, time.Local)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers and a YYYY-MM-DD grant date"), win)
+			return
+		}
+
+		schedule := stc.VestingSchedule{
+			GrantDate:   grantDate,
+			TotalShares: units,
+			Months:      int(months),
+			CliffMonths: int(cliff),
+			EveryMonths: int(every),
+			DateRule:    stc.DateRules[dateRuleSelect.SelectedIndex()],
+		}
+		assumption := stc.GrowthAssumption{StartPrice: price, StartDate: grantDate, AnnualGrowth: growth}
+		projections := stc.NewDefaultCalculator().ProjectVests(schedule.Vests(), assumption)
+
+		table.RemoveAll()
+		for _, h := range []string{"Vest", "Shares", "Price", "Pre-Tax", "Post-Tax"} {
+			table.Add(widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		}
+		var pre, post float64
+		for _, p := range projections {
+			table.Add(widget.NewLabel(p.Vest.Date.Format("2006-01-02")))
+			table.Add(widget.NewLabel(fmt.Sprintf("%.0f", p.Vest.Shares)))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", p.Price)))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", p.PreTax)))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", p.PostTax)))
+			pre += p.PreTax
+			post += p.PostTax
+		}
+		lblTotals.SetText(fmt.Sprintf("Total pre-tax $%.2f  ·  post-tax $%.2f", pre, post))
+	}
+
+	for _, e := range []*widgets.SmartEntry{unitsEntry, grantDateEntry, monthsEntry, cliffEntry, everyEntry, priceEntry, growthEntry} {
+		e.SetOnEnter(project)
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Units", unitsEntry),
+		widget.NewFormItem("Grant Date", grantDateEntry),
+		widget.NewFormItem("Vesting Months", monthsEntry),
+		widget.NewFormItem("Cliff Months", cliffEntry),
+		widget.NewFormItem("Vest Every (mo)", everyEntry),
+		widget.NewFormItem("Vest Dates", dateRuleSelect),
+		widget.NewFormItem("Price Today ($)", priceEntry),
+		widget.NewFormItem("Annual Growth", growthEntry),
+	)
+
+	scroll := container.NewVScroll(table)
+	scroll.SetMinSize(fyne.NewSize(460, 220))
+	content := container.NewBorder(
+		container.NewVBox(form, widget.NewButton("Project", project)),
+		lblTotals, nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Grant Value Projector", "Close", content, win)
+}
+
+// showRefresherComparison weighs a proposed refresher against asking for cash instead
+func showRefresherComparison(win fyne.Window) {
+	unitsEntry := widget.NewEntry()
+	unitsEntry.SetText("1000")
+	monthsEntry := widget.NewEntry()
+	monthsEntry.SetText("48")
+	everyEntry := widget.NewEntry()
+	everyEntry.SetText("3")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetText("0.00")
+	growthEntry := widget.NewEntry()
+	growthEntry.SetText("0.08")
+	cashEntry := widget.NewEntry()
+	cashEntry.SetText("0.00")
+	retentionEntry := widget.NewEntry()
+	retentionEntry.SetText("0.90")
+	discountEntry := widget.NewEntry()
+	discountEntry.SetText("0.05")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Refresher Units", unitsEntry),
+		widget.NewFormItem("Vesting Months", monthsEntry),
+		widget.NewFormItem("Vest Every (mo)", everyEntry),
+		widget.NewFormItem("Price Today ($)", priceEntry),
+		widget.NewFormItem("Annual Growth", growthEntry),
+		widget.NewFormItem("Cash Alternative ($)", cashEntry),
+		widget.NewFormItem("Annual Retention", retentionEntry),
+		widget.NewFormItem("Discount Rate", discountEntry),
+	}
+
+	dialog.ShowForm("Refresher vs Cash", "Compare", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		units, err1 := parseFloat(unitsEntry.Text)
+		months, err2 := parseFloat(monthsEntry.Text)
+		every, err3 := parseFloat(everyEntry.Text)
+		price, err4 := parseFloat(priceEntry.Text)
+		growth, err5 := parseFloat(growthEntry.Text)
+		cash, err6 := parseFloat(cashEntry.Text)
+		retention, err7 := parseFloat(retentionEntry.Text)
+		discount, err8 := parseFloat(discountEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil || err8 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+			return
+		}
+
+		now := time.Now()
+		offer := stc.RefresherOffer{
+			Schedule: stc.VestingSchedule{
+				GrantDate:   now,
+				TotalShares: units,
+				Months:      int(months),
+				EveryMonths: int(every),
+			},
+			Growth:          stc.GrowthAssumption{StartPrice: price, StartDate: now, AnnualGrowth: growth},
+			Cash:            cash,
+			CashDate:        now,
+			AnnualRetention: retention,
+			DiscountRate:    discount,
+		}
+		res := stc.NewDefaultCalculator().CompareRefresher(offer)
+
+		breakEven := "not reachable"
+		if res.BreakEvenFound {
+			breakEven = fmt.Sprintf("%.2f%% per year", res.BreakEvenGrowth*100)
+		}
+		dialog.ShowInformation("Refresher vs Cash", fmt.Sprintf(
+			"Refresher (after tax, risk-adjusted): $%.2f\n"+
+				"Cash (after tax): $%.2f\n"+
+				"Refresher advantage: $%.2f\n"+
+				"Break-even growth: %s",
+			res.EquityValue, res.CashValue, res.Advantage, breakEven,
+		), win)
+	}, win)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// showPSUPayouts tabulates each PSU performance period still to end at the
+// threshold, target, and maximum payouts, with the withholding on each.
+// Every rate and fee comes from base.
+func showPSUPayouts(win fyne.Window, pf *portfolio.Portfolio, base stc.Config) {
+	hasPSU := false
+	for _, g := range pf.Grants {
+		hasPSU = hasPSU || g.Kind == portfolio.GrantPSU
+	}
+	if !hasPSU {
+		dialog.ShowInformation("PSU Payouts", "The portfolio has no PSU grants. Add one with Portfolio → Add Grant.", win)
+		return
+	}
+
+	priceEntry := widgets.NewSmartEntry("50.00")
+	table := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewVScroll(table)
+	scroll.SetMinSize(fyne.NewSize(620, 320))
+
+	run := func() {
+		price, err := parseFloat(priceEntry.Text)
+		if err != nil || price <= 0 {
+			dialog.ShowError(fmt.Errorf("Please enter a price greater than 0"), win)
+			return
+		}
+		periods := portfolio.ProjectPSU(stc.NewCalculator(base), pf, time.Now(), price)
+		if len(periods) == 0 {
+			table.SetText("Every performance period has ended.")
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%-10s %-8s %-10s %6s %8s %12s %12s %10s\n", "Period End", "Symbol", "Payout", "%", "Units", "Value", "Total Tax", "Net Shares")
+		for _, p := range periods {
+			for _, s := range p.Scenarios {
+				fmt.Fprintf(&b, "%-10s %-8s %-10s %5.0f%% %8.0f %12.2f %12.2f %10.0f\n",
+					p.PeriodEnd.Format("2006-01-02"), p.Symbol, s.Name, s.Multiplier*100, s.Units, s.Value, s.TotalTax, s.NetShares)
+			}
+		}
+		table.SetText(b.String())
+	}
+	priceEntry.SetOnEnter(run)
+
+	form := widget.NewForm(widget.NewFormItem("Price at Vest ($)", priceEntry))
+	content := container.NewBorder(
+		container.NewVBox(form, widget.NewButton("Project", run)),
+		nil, nil, nil,
+		scroll,
+	)
+	run()
+	dialog.ShowCustom("PSU Payouts", "Close", content, win)
+}
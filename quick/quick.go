@@ -0,0 +1,128 @@
+// Package quick parses the one-line calculation syntax used by the quick-calc
+// window, e.g. "rsu 100 @ 61.10 sell 60" or "opt 1000 x 10 @ 50 fed=0.32".
+package quick
+
+import (
+	"fmt"
+	"strings"
+
+	"fynance/expr"
+	"fynance/stc"
+	"fynance/viewmodel"
+)
+
+// Kind is the calculation a line requests
+type Kind string
+
+const (
+	Option Kind = "opt"
+	RSU    Kind = "rsu"
+)
+
+// Usage summarizes the syntax for display in the UI
+const Usage = `rsu <shares> @ <vest price> [sell <sale price>]
+opt <shares> x <strike> @ <fmv>
+Add overrides like fed=0.32 state=0.093 local=0 comm=0.03 min=25.
+Numbers may be formulas over named variables, e.g. price*0.95.`
+
+// Request is a parsed quick-calc line
+type Request struct {
+	Kind   Kind
+	Option stc.Input
+	RSU    stc.RSUInput
+	Config stc.Config
+}
+
+// overrides maps key=value names onto the config fields they set
+var overrides = map[string]func(*stc.Config, float64){
+	"fed":   func(c *stc.Config, v float64) { c.TaxRates.Federal = v },
+	"state": func(c *stc.Config, v float64) { c.TaxRates.State = v },
+	"local": func(c *stc.Config, v float64) { c.TaxRates.LocalSDI = v },
+	"comm":  func(c *stc.Config, v float64) { c.BrokerFees.CommissionRate = v },
+	"min":   func(c *stc.Config, v float64) { c.BrokerFees.MinimumFee = v },
+	"flat":  func(c *stc.Config, v float64) { c.BrokerFees.FlatFee = v },
+	"extra": func(c *stc.Config, v float64) { c.BrokerFees.ExtraShares = v },
+}
+
+// Parse reads a quick-calc line. Rates and fees not overridden come from base.
+func Parse(line string, base stc.Config, vars expr.Vars) (Request, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Request{}, fmt.Errorf("empty calculation")
+	}
+
+	req := Request{Kind: Kind(strings.ToLower(fields[0])), Config: base}
+	values := map[string]float64{}
+	var positional []float64
+
+	// Remaining words are numbers, "x"/"@"/"sell" markers, or key=value overrides
+	marker := "shares"
+	for _, f := range fields[1:] {
+		switch strings.ToLower(f) {
+		case "x", "@", "sell":
+			marker = strings.ToLower(f)
+			continue
+		}
+		if key, formula, ok := strings.Cut(f, "="); ok {
+			set, known := overrides[strings.ToLower(key)]
+			if !known {
+				return Request{}, fmt.Errorf("unknown override %q", key)
+			}
+			v, err := expr.Eval(formula, vars)
+			if err != nil {
+				return Request{}, err
+			}
+			set(&req.Config, v)
+			continue
+		}
+		v, err := expr.Eval(f, vars)
+		if err != nil {
+			return Request{}, err
+		}
+		if _, dup := values[marker]; dup {
+			return Request{}, fmt.Errorf("unexpected %q", f)
+		}
+		values[marker] = v
+		positional = append(positional, v)
+	}
+
+	switch req.Kind {
+	case RSU:
+		shares, okShares := values["shares"]
+		vest, okVest := values["@"]
+		if !okShares || !okVest {
+			return Request{}, fmt.Errorf("usage: rsu <shares> @ <vest price> [sell <sale price>]")
+		}
+		sale, ok := values["sell"]
+		if !ok {
+			sale = vest
+		}
+		req.RSU = stc.RSUInput{SharesReleased: shares, VestPrice: vest, SalePrice: sale}
+	case Option:
+		shares, okShares := values["shares"]
+		strike, okStrike := values["x"]
+		fmv, okFMV := values["@"]
+		if !okShares || !okStrike || !okFMV {
+			return Request{}, fmt.Errorf("usage: opt <shares> x <strike> @ <fmv>")
+		}
+		req.Option = stc.Input{ExercisedShares: shares, ExercisePrice: strike, FMV: fmv}
+	default:
+		return Request{}, fmt.Errorf("unknown calculation %q; start with rsu or opt", fields[0])
+	}
+
+	for _, v := range positional {
+		if v <= 0 {
+			return Request{}, fmt.Errorf("shares and prices must be greater than 0")
+		}
+	}
+	return req, nil
+}
+
+// Run calculates the request and formats it like the result card
+func (r Request) Run() viewmodel.ViewModel {
+	calc := stc.NewCalculator(r.Config)
+	if r.Kind == RSU {
+		return viewmodel.FromRSUResult(calc.CalculateRSU(r.RSU))
+	}
+	return viewmodel.FromResult(calc.Calculate(r.Option))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/quick"
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// quickCalcShortcut opens the quick-calc window from anywhere in the app. The
+// same keys are registered as a global hotkey on desktop.
+var quickCalcShortcut = &desktop.CustomShortcut{
+	KeyName:  fyne.KeySpace,
+	Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+}
+
+// newQuickCalc returns a function that opens a small window that evaluates one-line calculations,
+// e.g. "rsu 100 @ 61.10 sell 60", using the rates from config().
+// Only one window is kept, above other applications; asking again brings it to the front.
+func newQuickCalc(a fyne.App, config func() stc.Config) (show func()) {
+	var win fyne.Window
+
+	return func() {
+		if win != nil {
+			win.Show()
+			win.RequestFocus()
+			return
+		}
+
+		win = a.NewWindow("Quick Calc")
+		win.SetFixedSize(true)
+		win.SetOnClosed(func() { win = nil })
+
+		output := widget.NewLabel(quick.Usage)
+		output.TextStyle = fyne.TextStyle{Monospace: true}
+
+		entry := widgets.NewSmartEntry("")
+		entry.SetPlaceHolder("rsu 100 @ 61.10 sell 60")
+		entry.SetOnEnter(func() {
+			req, err := quick.Parse(entry.Text, config(), variables)
+			if err != nil {
+				output.SetText(err.Error())
+				return
+			}
+			output.SetText(req.Run().Snapshot())
+		})
+
+		win.Canvas().SetOnTypedKey(func(k *fyne.KeyEvent) {
+			if k.Name == fyne.KeyEscape {
+				win.Close()
+			}
+		})
+		win.SetContent(container.NewPadded(container.NewBorder(entry, nil, nil, nil, output)))
+		win.Resize(fyne.NewSize(420, 260))
+		win.Show()
+		keepOnTop(win)
+		win.Canvas().Focus(entry)
+	}
+}
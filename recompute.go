@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fynance/stc"
+)
+
+// runRecompute reruns a session saved from the GUI under a new config
+// profile, e.g. "fynance recompute session.json --config new-profile.json".
+// JSON output is itself a session the GUI can open.
+func runRecompute(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("recompute", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config profile or plan template with the new rates and fees (required)")
+	format := fs.String("format", "json", "output format: json (a session), csv, or text (the changes)")
+	all := fs.Bool("all", false, "also recompute calculations whose shares were kept in the portfolio")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance recompute session.json --config profile.json [--format json|csv|text]")
+		fs.PrintDefaults()
+	}
+
+	// The session may come before or after the flags
+	var path string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if path == "" && fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	if path == "" || *configPath == "" {
+		fs.Usage()
+		return 2
+	}
+	if *format != "json" && *format != "csv" && *format != "text" {
+		fmt.Fprintf(stderr, "recompute: unknown format %q\n", *format)
+		return 2
+	}
+
+	session, err := readSession(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "recompute: %v\n", err)
+		return 1
+	}
+	cfg, err := readConfigProfile(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "recompute: %v\n", err)
+		return 1
+	}
+	for _, w := range stc.ConfigLint(cfg) {
+		fmt.Fprintf(stderr, "recompute: warning: %s\n", w)
+	}
+
+	updated := session.Recompute(cfg, *all)
+	switch *format {
+	case "csv":
+		err = writeRecomputeCSV(stdout, session, updated)
+	case "text":
+		writeRecomputeText(stdout, session, updated)
+	default:
+		err = updated.Save(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "recompute: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readSession loads a saved session file
+func readSession(path string) (stc.Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return stc.Session{}, fmt.Errorf("failed to open session: %w", err)
+	}
+	defer f.Close()
+	return stc.LoadSession(f)
+}
+
+// readConfigProfile loads a config, either bare or wrapped in a plan
+// template's "config" field
+func readConfigProfile(path string) (stc.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return stc.Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	var wrapped struct {
+		Config *stc.Config `json:"config"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return stc.Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+	var cfg stc.Config
+	if wrapped.Config != nil {
+		cfg = *wrapped.Config
+	} else if err := json.Unmarshal(data, &cfg); err != nil {
+		return stc.Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return stc.Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+// writeRecomputeText lists each calculation whose numbers moved
+func writeRecomputeText(w io.Writer, before, after stc.Session) {
+	changed := 0
+	for i, e := range after.Entries {
+		changes := stc.Diff(before.Entries[i].Graph(), e.Graph())
+		if len(changes) == 0 {
+			continue
+		}
+		changed++
+		fmt.Fprintf(w, "%s\n", e.Title)
+		for _, c := range changes {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d calculations changed\n", changed, len(after.Entries))
+}
+
+// writeRecomputeCSV writes one row per changed quantity
+func writeRecomputeCSV(w io.Writer, before, after stc.Session) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Calculation", "ID", "Label", "Before", "After"}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	for i, e := range after.Entries {
+		for _, c := range stc.Diff(before.Entries[i].Graph(), e.Graph()) {
+			row := []string{e.Title, c.ID, c.Label, fmt.Sprintf("%.2f", c.Before), fmt.Sprintf("%.2f", c.After)}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write row: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+)
+
+// reconcileTolerance is how far a confirmed amount may drift from the
+// expected one, in dollars or shares, before it is flagged
+const reconcileTolerance = 0.01
+
+// showReconcileDialog reads a broker confirmation and compares the
+// withholding and fees it implies with the calculation on the form
+func showReconcileDialog(win fyne.Window, salePrice float64, reconcile func(stc.Confirmation) stc.Reconciliation) {
+	sharesEntry := widget.NewEntry()
+	priceEntry := widget.NewEntry()
+	priceEntry.SetText(fmt.Sprintf("%.2f", salePrice))
+	netEntry := widget.NewEntry()
+	feesEntry := widget.NewEntry()
+	feesEntry.SetPlaceHolder("Blank uses the configured fees")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Shares Sold", sharesEntry),
+		widget.NewFormItem("Sale Price ($)", priceEntry),
+		widget.NewFormItem("Net Cash ($)", netEntry),
+		widget.NewFormItem("Fees ($)", feesEntry),
+	}
+	dialog.ShowForm("Reconcile Confirmation", "Compare", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		shares, err1 := parseFloat(sharesEntry.Text)
+		price, err2 := parseFloat(priceEntry.Text)
+		net, err3 := parseFloat(netEntry.Text)
+		var fees float64
+		var err4 error
+		if strings.TrimSpace(feesEntry.Text) != "" {
+			fees, err4 = parseFloat(feesEntry.Text)
+		}
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers from the confirmation"), win)
+			return
+		}
+		if shares <= 0 || price <= 0 {
+			dialog.ShowError(fmt.Errorf("Shares Sold and Sale Price must be greater than 0"), win)
+			return
+		}
+
+		rec := reconcile(stc.Confirmation{SharesSold: shares, SalePrice: price, NetCash: net, Fees: fees})
+		showReconciliation(win, rec)
+	}, win)
+}
+
+// showReconciliation lays out expected against confirmed values
+func showReconciliation(win fyne.Window, rec stc.Reconciliation) {
+	grid := container.NewGridWithColumns(4)
+	for _, h := range []string{"", "Expected", "Confirmed", "Difference"} {
+		grid.Add(widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	for _, l := range rec.Lines {
+		f := "$%.2f"
+		if l.ID == "sharesSold" {
+			f = "%.4f"
+		}
+		grid.Add(widget.NewLabel(l.Label))
+		grid.Add(widget.NewLabel(fmt.Sprintf(f, l.Expected)))
+		grid.Add(widget.NewLabel(fmt.Sprintf(f, l.Actual)))
+		grid.Add(widget.NewLabel(fmt.Sprintf(f, l.Delta())))
+	}
+	grid.Add(widget.NewLabel("Withholding Rate"))
+	grid.Add(widget.NewLabel(fmt.Sprintf("%.2f%%", 100*rec.ExpectedRate)))
+	grid.Add(widget.NewLabel(fmt.Sprintf("%.2f%%", 100*rec.ImpliedRate)))
+	grid.Add(widget.NewLabel(fmt.Sprintf("%.2f%%", 100*(rec.ImpliedRate-rec.ExpectedRate))))
+
+	summary := "The confirmation matches the calculation."
+	if off := rec.Discrepancies(reconcileTolerance); len(off) > 0 {
+		var labels []string
+		for _, l := range off {
+			labels = append(labels, l.Label)
+		}
+		summary = "Differs from the calculation: " + strings.Join(labels, ", ") + "."
+	}
+	if rec.FeesAssumed {
+		summary += " Fees were not itemized, so the configured schedule was assumed."
+	}
+	lblSummary := widget.NewLabel(summary)
+	lblSummary.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(grid, widget.NewSeparator(), lblSummary)
+	d := dialog.NewCustom("Reconciliation", "Close", content, win)
+	d.Resize(fyne.NewSize(560, 320))
+	d.Show()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+	"fynance/report"
+	"fynance/stc"
+)
+
+// showRemittanceDialog previews the tax withheld on this session's
+// calculations by the authority it is paid to, and saves it as text or CSV
+func showRemittanceDialog(win fyne.Window) {
+	if len(history) == 0 {
+		dialog.ShowInformation("Tax Remittance", "No calculations have been run yet.", win)
+		return
+	}
+	entries := make([]stc.SessionEntry, 0, len(history))
+	for _, e := range history {
+		entries = append(entries, *e)
+	}
+
+	reconciledCheck := widget.NewCheck("Reconciled calculations only", nil)
+	localeSelect := newReportLocaleSelect()
+	form := widget.NewForm(
+		widget.NewFormItem("", reconciledCheck),
+		widget.NewFormItem("Locale", localeSelect),
+	)
+	preview := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	var breakdown portfolio.RemittanceReport
+	run := func() {
+		breakdown = portfolio.NewRemittanceReport(entries, reconciledCheck.Checked)
+		var b strings.Builder
+		breakdown.WriteTextIn(&b, reportLocale(localeSelect))
+		preview.SetText(b.String())
+	}
+	reconciledCheck.OnChanged = func(bool) { run() }
+	localeSelect.OnChanged = func(string) { run() }
+
+	save := widget.NewButton("Save...", func() {
+		run()
+		loc := reportLocale(localeSelect)
+		fileSave := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if strings.EqualFold(w.URI().Extension(), ".csv") {
+				err = breakdown.ToCSV(w)
+			} else {
+				err = breakdown.WriteTextIn(w, loc)
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		fileSave.SetFileName("remittance-" + time.Now().Format("2006-01-02") + ".txt")
+		fileSave.Show()
+	})
+
+	scroll := container.NewVScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(720, 360))
+	content := container.NewBorder(
+		container.NewVBox(form, save),
+		nil, nil, nil,
+		scroll,
+	)
+	run()
+	dialog.ShowCustom("Tax Remittance", "Close", content, win)
+}
+
+// runRemit writes the remittance breakdown of a saved session, e.g.
+// "fynance remit --session s.json --format csv"
+func runRemit(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("remit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sessionPath := fs.String("session", "", "session whose calculations are broken down (required)")
+	reconciled := fs.Bool("reconciled", false, "leave out calculations that were not carried out")
+	format := fs.String("format", "text", "output format: text or csv")
+	localeName := fs.String("locale", report.USLocale.Name, "locale for numbers in the text report")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance remit --session s.json [--reconciled] [--format csv]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *sessionPath == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *format != "text" && *format != "csv" {
+		fmt.Fprintf(stderr, "remit: unknown format %q\n", *format)
+		return 2
+	}
+	loc, ok := report.Lookup(*localeName)
+	if !ok {
+		fmt.Fprintf(stderr, "remit: unknown locale %q\n", *localeName)
+		return 2
+	}
+
+	session, err := readSession(*sessionPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "remit: %v\n", err)
+		return 1
+	}
+	breakdown := portfolio.NewRemittanceReport(session.Entries, *reconciled)
+	if len(breakdown.Entries) == 0 {
+		fmt.Fprintln(stderr, "remit: no calculated entries in the session")
+		return 1
+	}
+	if *format == "csv" {
+		err = breakdown.ToCSV(stdout)
+	} else {
+		err = breakdown.WriteTextIn(stdout, loc)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "remit: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/stc"
+)
+
+// renderTabs are the tabs the render command can draw
+var renderTabs = []string{"exercise", "release", "portfolio", "year"}
+
+// runRender draws one tab to a PNG on an in-memory canvas, e.g. "fynance
+// render --tab exercise --session s.json --out shot.png". A session entry
+// of the tab's kind fills the form and is calculated, so guides can show
+// the tool with real numbers. Rendering uses the app theme at scale 1 and
+// no window system, so the same inputs give the same image.
+func runRender(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	tab := fs.String("tab", "exercise", "tab to draw: "+strings.Join(renderTabs, ", "))
+	sessionPath := fs.String("session", "", "session whose calculation fills the exercise or release tab")
+	entryNum := fs.Int("entry", 0, "session entry to show, counting from 1; defaults to the last one for the tab")
+	portfolioPath := fs.String("portfolio", "", "portfolio backup shown on the portfolio and year tabs")
+	out := fs.String("out", "", "PNG file to write (required)")
+	width := fs.Int("width", 900, "image width in pixels")
+	height := fs.Int("height", 0, "image height in pixels; 0 fits the tab")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance render --tab exercise --session s.json --out shot.png")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *out == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	pf := &portfolio.Portfolio{}
+	if *portfolioPath != "" {
+		f, err := os.Open(*portfolioPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "render: failed to open portfolio: %v\n", err)
+			return 1
+		}
+		pf, err = portfolio.Load(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return 1
+		}
+	}
+	var entry *stc.SessionEntry
+	if *sessionPath != "" {
+		session, err := readSession(*sessionPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return 1
+		}
+		if entry, err = renderEntry(session, *tab, *entryNum); err != nil {
+			fmt.Fprintf(stderr, "render: %v\n", err)
+			return 1
+		}
+	}
+
+	a := test.NewApp()
+	defer a.Quit()
+	a.Settings().SetTheme(newCustomTheme())
+	win := test.NewWindow(nil)
+	defer win.Close()
+
+	bus := events.NewBus()
+	keepLot := func(lot portfolio.Lot) { pf.Add(lot) }
+	var content fyne.CanvasObject
+	switch *tab {
+	case "exercise":
+		content = makeSTCTab(win, bus, keepLot, pf.ValuationOn)
+	case "release":
+		content = makeRSUTab(win, bus, keepLot, pf.ValuationOn)
+	case "portfolio":
+		content = makePortfolioTab(win, pf, bus)
+	case "year":
+		content = makeYearTab(pf, bus)
+	default:
+		fmt.Fprintf(stderr, "render: unknown tab %q; use one of %s\n", *tab, strings.Join(renderTabs, ", "))
+		return 2
+	}
+	win.SetContent(content)
+	if entry != nil {
+		bus.Publish(events.EntryOpened, *entry)
+	}
+
+	size := content.MinSize().Max(fyne.NewSize(float32(*width), float32(*height)))
+	win.Resize(size)
+	img := win.Canvas().Capture()
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		return 1
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		fmt.Fprintf(stderr, "render: failed to write image: %v\n", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(stderr, "render: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Wrote %s (%dx%d)\n", *out, img.Bounds().Dx(), img.Bounds().Dy())
+	return 0
+}
+
+// renderEntry picks the session entry to show on tab: entry n, counting
+// from 1, or else the last one the tab can open
+func renderEntry(session stc.Session, tab string, n int) (*stc.SessionEntry, error) {
+	fits := func(e stc.SessionEntry) bool {
+		switch tab {
+		case "exercise":
+			return e.Options != nil || e.MultiLot != nil
+		case "release":
+			return e.RSU != nil
+		}
+		return false
+	}
+	if n > 0 {
+		if n > len(session.Entries) {
+			return nil, fmt.Errorf("session has %d entries, not %d", len(session.Entries), n)
+		}
+		if e := session.Entries[n-1]; fits(e) {
+			return &e, nil
+		}
+		return nil, fmt.Errorf("entry %d cannot be shown on the %s tab", n, tab)
+	}
+	for i := len(session.Entries) - 1; i >= 0; i-- {
+		if e := session.Entries[i]; fits(e) {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("session has no entry for the %s tab", tab)
+}
@@ -0,0 +1,105 @@
+// Package report formats amounts, numbers, and dates in saved documents for
+// the reader's locale, independently of the locale the app runs in. Amounts
+// stay in US dollars; a locale only changes how they are written.
+package report
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale describes how a document writes numbers and dates
+type Locale struct {
+	Name           string `json:"name"`           // BCP 47 tag, e.g. "de-DE"
+	Currency       string `json:"currency"`       // How the locale writes US dollars, e.g. "$" or "US$"
+	CurrencyAfter  bool   `json:"currencyAfter"`  // "1.234,56 $" rather than "$1,234.56"
+	Decimal        string `json:"decimal"`        // Decimal separator
+	Group          string `json:"group"`          // Thousands separator
+	DateLayout     string `json:"dateLayout"`     // Go layout for dates in tables
+	LongDateLayout string `json:"longDateLayout"` // Go layout for dates in prose
+}
+
+// USLocale is the default. It leaves out thousands separators, as the
+// documents always have, so their columns line up with earlier copies.
+var USLocale = Locale{
+	Name: "en-US", Currency: "$", Decimal: ".",
+	DateLayout: "2006-01-02", LongDateLayout: "January 2, 2006",
+}
+
+// Locales lists the supported locales, in the order a picker shows them.
+// Month names are always English, so other languages use numeric long dates.
+var Locales = []Locale{
+	USLocale,
+	{Name: "en-GB", Currency: "US$", Decimal: ".", Group: ",", DateLayout: "02/01/2006", LongDateLayout: "2 January 2006"},
+	{Name: "de-DE", Currency: "$", CurrencyAfter: true, Decimal: ",", Group: ".", DateLayout: "02.01.2006", LongDateLayout: "02.01.2006"},
+	{Name: "fr-FR", Currency: "$US", CurrencyAfter: true, Decimal: ",", Group: " ", DateLayout: "02/01/2006", LongDateLayout: "02/01/2006"},
+	{Name: "ja-JP", Currency: "$", Decimal: ".", Group: ",", DateLayout: "2006/01/02", LongDateLayout: "2006/01/02"},
+}
+
+// Lookup finds a supported locale by name
+func Lookup(name string) (Locale, bool) {
+	for _, l := range Locales {
+		if strings.EqualFold(l.Name, name) {
+			return l, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Number writes v with the given decimal places and the locale's
+// separators; negative places use as many as v needs
+func (l Locale) Number(v float64, places int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', places, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteString(l.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Shares writes a share count with as many decimals as it has, up to the
+// millionth a fractional share policy sells, e.g. "1,234" or "12,5"
+func (l Locale) Shares(v float64) string {
+	return l.Number(math.Round(v*1e6)/1e6, -1)
+}
+
+// Money writes a dollar amount to the cent, e.g. "$1,234.56" or "1.234,56 $"
+func (l Locale) Money(v float64) string {
+	n := l.Number(v, 2)
+	if l.CurrencyAfter {
+		return n + " " + l.Currency
+	}
+	if sign, rest, neg := strings.Cut(n, "-"); neg && sign == "" {
+		return "-" + l.Currency + rest
+	}
+	return l.Currency + n
+}
+
+// Percent writes a fraction as a percentage, e.g. 0.125 as "12.5%"
+func (l Locale) Percent(v float64, places int) string {
+	return l.Number(100*v, places) + "%"
+}
+
+// Date writes a date for a table column
+func (l Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// LongDate writes a date for prose
+func (l Locale) LongDate(t time.Time) string {
+	return t.Format(l.LongDateLayout)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/report"
+)
+
+const (
+	reportLocaleKey  = "report.locale"
+	displayLocaleKey = "display.locale"
+)
+
+// displayLocale writes the figures of the result cards: thousands
+// separators, decimal mark, and currency symbol. It is set in Settings and
+// applies from the next calculation.
+var displayLocale = report.USLocale
+
+// loadDisplayLocale reads the saved display locale from preferences
+func loadDisplayLocale(a fyne.App) {
+	if loc, ok := report.Lookup(a.Preferences().String(displayLocaleKey)); ok {
+		displayLocale = loc
+	}
+}
+
+// newReportLocaleSelect lists the report locales, starting on the one last
+// used. It is independent of the UI's own locale, since a report is often
+// written for an advisor abroad.
+func newReportLocaleSelect() *widget.Select {
+	sel := newLocaleSelect()
+	prefs := fyne.CurrentApp().Preferences()
+	sel.SetSelected(prefs.StringWithFallback(reportLocaleKey, report.USLocale.Name))
+	return sel
+}
+
+// newLocaleSelect lists the supported locales by name
+func newLocaleSelect() *widget.Select {
+	names := make([]string, len(report.Locales))
+	for i, l := range report.Locales {
+		names[i] = l.Name
+	}
+	return widget.NewSelect(names, nil)
+}
+
+// reportLocale returns the locale chosen in sel and remembers it for next time
+func reportLocale(sel *widget.Select) report.Locale {
+	loc, ok := report.Lookup(sel.Selected)
+	if !ok {
+		return report.USLocale
+	}
+	fyne.CurrentApp().Preferences().SetString(reportLocaleKey, loc.Name)
+	return loc
+}
@@ -0,0 +1,182 @@
+// Package schedule runs recurring background jobs, such as price refreshes
+// and backups, at fixed intervals. When each job last ran is persisted, so
+// a job that fell due while the app was closed runs once at the next start.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTick is how often the scheduler looks for due jobs
+const DefaultTick = time.Minute
+
+// Job is a recurring task. Run is called on the scheduler's goroutine, one
+// job at a time, so UI work must be handed to the UI thread.
+type Job struct {
+	ID    string
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
+// Record is what is persisted about a job between runs of the app
+type Record struct {
+	LastRun   time.Time `json:"lastRun,omitzero"`
+	LastError string    `json:"lastError,omitempty"`
+	Disabled  bool      `json:"disabled,omitempty"`
+}
+
+// Status describes a job for the Settings page
+type Status struct {
+	Job
+	Record
+	NextRun time.Time // Zero when the job is disabled
+}
+
+// Store loads and saves job records
+type Store interface {
+	Load() (map[string]Record, error)
+	Save(map[string]Record) error
+}
+
+// Scheduler runs its jobs whenever they fall due
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    []Job
+	records map[string]Record
+	store   Store
+	running map[string]bool
+	now     func() time.Time
+}
+
+// New creates a scheduler for jobs with the records in store. A store that
+// fails to load starts every job afresh.
+func New(store Store, jobs ...Job) *Scheduler {
+	records, err := store.Load()
+	if err != nil || records == nil {
+		records = map[string]Record{}
+	}
+	return &Scheduler{jobs: jobs, records: records, store: store, running: map[string]bool{}, now: time.Now}
+}
+
+// next returns when a job is due, or zero when it is disabled
+func next(j Job, r Record) time.Time {
+	if r.Disabled {
+		return time.Time{}
+	}
+	if r.LastRun.IsZero() {
+		return time.Unix(0, 0)
+	}
+	return r.LastRun.Add(j.Every)
+}
+
+// Statuses lists every job with its last and next run, in the order added
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, len(s.jobs))
+	for i, j := range s.jobs {
+		r := s.records[j.ID]
+		out[i] = Status{Job: j, Record: r, NextRun: next(j, r)}
+		if r.LastRun.IsZero() && !r.Disabled {
+			// Never run, so due at the next tick
+			out[i].NextRun = s.now()
+		}
+	}
+	return out
+}
+
+// SetEnabled turns a job on or off and saves the change
+func (s *Scheduler) SetEnabled(id string, on bool) error {
+	s.mu.Lock()
+	r := s.records[id]
+	r.Disabled = !on
+	s.records[id] = r
+	err := s.save()
+	s.mu.Unlock()
+	return err
+}
+
+// RunDue runs every enabled job that is due at now, in the order added
+func (s *Scheduler) RunDue(ctx context.Context) {
+	s.mu.Lock()
+	now := s.now()
+	var due []Job
+	for _, j := range s.jobs {
+		if n := next(j, s.records[j.ID]); !n.IsZero() && !n.After(now) && !s.running[j.ID] {
+			due = append(due, j)
+		}
+	}
+	s.mu.Unlock()
+	for _, j := range due {
+		s.run(ctx, j)
+	}
+}
+
+// RunNow runs one job immediately, enabled or not, and reports its error
+func (s *Scheduler) RunNow(ctx context.Context, id string) error {
+	for _, j := range s.jobs {
+		if j.ID == id {
+			return s.run(ctx, j)
+		}
+	}
+	return fmt.Errorf("unknown job %q", id)
+}
+
+// run runs j unless it is already running and records the outcome
+func (s *Scheduler) run(ctx context.Context, j Job) error {
+	s.mu.Lock()
+	if s.running[j.ID] {
+		s.mu.Unlock()
+		return fmt.Errorf("%s is already running", j.Name)
+	}
+	s.running[j.ID] = true
+	s.mu.Unlock()
+
+	err := j.Run(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, j.ID)
+	r := s.records[j.ID]
+	r.LastRun, r.LastError = s.now(), ""
+	if err != nil {
+		r.LastError = err.Error()
+	}
+	s.records[j.ID] = r
+	if saveErr := s.save(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// save persists a copy of the records; the caller holds mu
+func (s *Scheduler) save() error {
+	records := make(map[string]Record, len(s.records))
+	for id, r := range s.records {
+		records[id] = r
+	}
+	if err := s.store.Save(records); err != nil {
+		return fmt.Errorf("failed to save job records: %w", err)
+	}
+	return nil
+}
+
+// Start runs due jobs now and then every tick until ctx is done
+func (s *Scheduler) Start(ctx context.Context, tick time.Duration) {
+	go func() {
+		t := time.NewTicker(tick)
+		defer t.Stop()
+		for {
+			s.RunDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
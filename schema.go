@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"fynance/plan"
+)
+
+// runSchema writes the JSON Schema of a plan template or config profile,
+// e.g. "fynance schema --kind template --out template.schema.json", so
+// configs distributed by IT can be checked in their own pipelines before
+// employees receive them. The schema comes from the types the app decodes,
+// so it stays in step with each release.
+func runSchema(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	kind := fs.String("kind", "template", "format to describe: template (a plan template) or config (a bare config profile)")
+	out := fs.String("out", "", "file to write; defaults to standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance schema [--kind template|config] [--out file]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var schema map[string]any
+	switch *kind {
+	case "template":
+		schema = plan.TemplateSchema()
+	case "config":
+		schema = plan.ConfigSchema()
+	default:
+		fmt.Fprintf(stderr, "schema: unknown kind %q; use template or config\n", *kind)
+		return 2
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "schema: %v\n", err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintf(stderr, "schema: failed to write schema: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/portfolio"
+	"fynance/report"
+	"fynance/stc"
+	"fynance/stc/amt"
+	"fynance/widgets"
+)
+
+// showScorecardDialog previews the year-end scorecard of this session's
+// reconciled calculations and saves it as text, e.g. to share with a
+// manager or broker
+func showScorecardDialog(win fyne.Window) {
+	entries := make([]stc.SessionEntry, 0, len(history))
+	reconciled := false
+	for _, e := range history {
+		entries = append(entries, *e)
+		reconciled = reconciled || e.Reconciled
+	}
+	if !reconciled {
+		dialog.ShowInformation("Year-End Scorecard", "No calculations are reconciled yet. Keep the shares of "+
+			"the ones you carried out in the portfolio, or open a saved session, to score them.", win)
+		return
+	}
+
+	yearEntry := widgets.NewSmartEntry(strconv.Itoa(time.Now().In(taxHome()).Year()))
+	incomeEntry := widgets.NewSmartEntry("0.00")
+	statusOptions := make([]string, 0, len(amt.FilingStatuses))
+	for _, s := range amt.FilingStatuses {
+		statusOptions = append(statusOptions, filingStatusLabels[s])
+	}
+	statusSelect := widget.NewSelect(statusOptions, nil)
+	statusSelect.SetSelectedIndex(0)
+	localeSelect := newReportLocaleSelect()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Tax Year", yearEntry),
+		widget.NewFormItem("Other Income ($)", incomeEntry),
+		widget.NewFormItem("Filing Status", statusSelect),
+		widget.NewFormItem("Locale", localeSelect),
+	)
+	preview := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	var card portfolio.Scorecard
+	run := func() bool {
+		year, errYear := strconv.Atoi(yearEntry.Text)
+		income, errIncome := parseFloat(incomeEntry.Text)
+		if errYear != nil || errIncome != nil || income < 0 {
+			dialog.ShowError(fmt.Errorf("Please enter a tax year and a valid Other Income"), win)
+			return false
+		}
+		card = portfolio.NewScorecard(entries, year, income, amt.FilingStatuses[statusSelect.SelectedIndex()])
+		var b strings.Builder
+		card.WriteTextIn(&b, reportLocale(localeSelect))
+		preview.SetText(b.String())
+		return true
+	}
+	yearEntry.SetOnEnter(func() { run() })
+	incomeEntry.SetOnEnter(func() { run() })
+
+	save := widget.NewButton("Save...", func() {
+		if !run() {
+			return
+		}
+		loc := reportLocale(localeSelect)
+		fileSave := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if err := card.WriteTextIn(w, loc); err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		fileSave.SetFileName(fmt.Sprintf("scorecard-%d.txt", card.Year))
+		fileSave.Show()
+	})
+
+	scroll := container.NewVScroll(preview)
+	scroll.SetMinSize(fyne.NewSize(620, 360))
+	content := container.NewBorder(
+		container.NewVBox(form, container.NewGridWithColumns(2, widget.NewButton("Preview", func() { run() }), save)),
+		nil, nil, nil,
+		scroll,
+	)
+	run()
+	dialog.ShowCustom("Year-End Scorecard", "Close", content, win)
+}
+
+// runScorecard writes the year-end scorecard of a saved session, e.g.
+// "fynance scorecard --session s.json --year 2025 --income 180000"
+func runScorecard(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scorecard", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sessionPath := fs.String("session", "", "session whose reconciled entries are scored (required)")
+	year := fs.Int("year", time.Now().Year(), "tax year to score")
+	income := fs.Float64("income", 0, "taxable income outside the session, e.g. salary")
+	status := fs.String("status", string(amt.Single), "filing status: single, mfj, mfs, or hoh")
+	localeName := fs.String("locale", report.USLocale.Name, "locale for numbers in the report")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fynance scorecard --session s.json [--year 2025] [--income 180000]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *sessionPath == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if !amt.FilingStatus(*status).Valid() {
+		fmt.Fprintf(stderr, "scorecard: unknown filing status %q\n", *status)
+		return 2
+	}
+	loc, ok := report.Lookup(*localeName)
+	if !ok {
+		fmt.Fprintf(stderr, "scorecard: unknown locale %q\n", *localeName)
+		return 2
+	}
+
+	session, err := readSession(*sessionPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "scorecard: %v\n", err)
+		return 1
+	}
+	card := portfolio.NewScorecard(session.Entries, *year, *income, amt.FilingStatus(*status))
+	if card.Transactions == 0 {
+		fmt.Fprintf(stderr, "scorecard: no reconciled entries in %d\n", *year)
+		return 1
+	}
+	if err := card.WriteTextIn(stdout, loc); err != nil {
+		fmt.Fprintf(stderr, "scorecard: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/report"
+)
+
+// coolingOffDays is the default wait before the first scheduled sale, the
+// longest cooling-off period Rule 10b5-1 applies to officers
+const coolingOffDays = 120
+
+// showSellDownDialog projects a systematic sell-down of retained shares,
+// previews the taxes and concentration of each period, and saves the
+// schedule. Files ending in .csv are written lot by lot, anything else as
+// a plain-text schedule.
+func showSellDownDialog(win fyne.Window, pf *portfolio.Portfolio) {
+	if len(pf.Lots) == 0 {
+		dialog.ShowInformation("Diversification Plan", "The portfolio has no lots to sell.", win)
+		return
+	}
+
+	startEntry := widget.NewEntry()
+	startEntry.SetText(time.Now().In(taxHome()).AddDate(0, 0, coolingOffDays).Format("2006-01-02"))
+	periodsEntry := widget.NewEntry()
+	periodsEntry.SetText("8")
+	everyEntry := widget.NewEntry()
+	everyEntry.SetText("3")
+	percentEntry := widget.NewEntry()
+	percentEntry.SetText("0.10")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("Current price per share")
+	if valuationMode == events.ModePrivate {
+		if v, ok := pf.ValuationOn(time.Now()); ok {
+			priceEntry.SetText(fmt.Sprintf("%.2f", v.Price))
+		}
+	}
+	growthEntry := widget.NewEntry()
+	growthEntry.SetText("0.00")
+	limitEntry := widget.NewEntry()
+	limitEntry.SetText("0.00")
+	otherEntry := widget.NewEntry()
+	otherEntry.SetText("0.00")
+	targetEntry := widget.NewEntry()
+	targetEntry.SetText("0.00")
+	stRateEntry := widget.NewEntry()
+	stRateEntry.SetText("0.24")
+	ltRateEntry := widget.NewEntry()
+	ltRateEntry.SetText("0.15")
+	localeSelect := newReportLocaleSelect()
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("First Sale", startEntry),
+		widget.NewFormItem("Sales", periodsEntry),
+		widget.NewFormItem("Every (mo)", everyEntry),
+		widget.NewFormItem("Sell Each Time (0-1)", percentEntry),
+		widget.NewFormItem("Price ($)", priceEntry),
+		widget.NewFormItem("Annual Growth", growthEntry),
+		widget.NewFormItem("Limit Price ($)", limitEntry),
+		widget.NewFormItem("Other Assets ($)", otherEntry),
+		widget.NewFormItem("Target Share (0-1)", targetEntry),
+		widget.NewFormItem("Short-Term Rate", stRateEntry),
+		widget.NewFormItem("Long-Term Rate", ltRateEntry),
+		widget.NewFormItem("Locale", localeSelect),
+	}
+
+	dialog.ShowForm("Diversification Plan", "Preview", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		start, errStart := parseDate(startEntry.Text)
+		periods, errPeriods := strconv.Atoi(periodsEntry.Text)
+		every, errEvery := strconv.Atoi(everyEntry.Text)
+		if errStart != nil || start.IsZero() || errPeriods != nil || periods <= 0 || errEvery != nil || every <= 0 {
+			dialog.ShowError(fmt.Errorf("Please enter a YYYY-MM-DD first sale, a number of sales, and months between them"), win)
+			return
+		}
+		var values [8]float64
+		for i, e := range []*widget.Entry{percentEntry, priceEntry, growthEntry, limitEntry, otherEntry, targetEntry, stRateEntry, ltRateEntry} {
+			v, err := parseFloat(e.Text)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+				return
+			}
+			values[i] = v
+		}
+		if values[0] <= 0 || values[0] > 1 || values[1] <= 0 {
+			dialog.ShowError(fmt.Errorf("Sell each time must be between 0 and 1, and the price greater than 0"), win)
+			return
+		}
+
+		plan := portfolio.PlanSellDown(pf, portfolio.SellDownTerms{
+			Start:       start,
+			Periods:     periods,
+			EveryMonths: every,
+			Percent:     values[0],
+			Price:       values[1],
+			Growth:      values[2],
+			LimitPrice:  values[3],
+			OtherAssets: values[4],
+			Target:      values[5],
+			Rates:       portfolio.CapGainsRates{ShortTerm: values[6], LongTerm: values[7]},
+		})
+		showSellDownPreview(win, plan, reportLocale(localeSelect))
+	}, win)
+}
+
+// showSellDownPreview shows the schedule as it will be saved in loc and offers to save it
+func showSellDownPreview(win fyne.Window, plan portfolio.SellDownPlan, loc report.Locale) {
+	var b strings.Builder
+	if err := plan.WriteTextIn(&b, loc); err != nil {
+		dialog.ShowError(err, win)
+		return
+	}
+	text := widget.NewLabelWithStyle(b.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewScroll(text)
+	scroll.SetMinSize(fyne.NewSize(720, 400))
+
+	dialog.ShowCustomConfirm("Diversification Plan", "Save...", "Close", scroll, func(ok bool) {
+		if !ok {
+			return
+		}
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if strings.EqualFold(w.URI().Extension(), ".csv") {
+				err = plan.ToCSV(w)
+			} else {
+				err = plan.WriteTextIn(w, loc)
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+			}
+		}, win)
+		save.SetFileName("10b5-1-schedule-" + plan.Terms.Start.Format("2006-01-02") + ".txt")
+		save.Show()
+	}, win)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+// showPriceSensitivity sweeps the sale price and tabulates the shares sold,
+// tax, and residual at each step, to show what a move in the stock before
+// the sale executes would do. Every rate and fee comes from base.
+func showPriceSensitivity(win fyne.Window, base stc.Config) {
+	kindSelect := widget.NewSelect([]string{"RSU Release", "Option Exercise"}, nil)
+	kindSelect.SetSelectedIndex(0)
+	sharesEntry := widgets.NewSmartEntry("1000")
+	basisEntry := widgets.NewSmartEntry("50.00")
+	fromEntry := widgets.NewSmartEntry("45.00")
+	toEntry := widgets.NewSmartEntry("55.00")
+	stepEntry := widgets.NewSmartEntry("1.00")
+
+	// RSUs are taxed at the vest price; options are sold at the FMV swept
+	basisItem := widget.NewFormItem("Vest Price ($)", basisEntry)
+	form := widget.NewForm(
+		widget.NewFormItem("Grant Type", kindSelect),
+		widget.NewFormItem("Shares", sharesEntry),
+		basisItem,
+		widget.NewFormItem("Sale Price ($)", container.NewGridWithColumns(2, fromEntry, toEntry)),
+		widget.NewFormItem("Step ($)", stepEntry),
+	)
+	kindSelect.OnChanged = func(string) {
+		basisItem.Text = "Vest Price ($)"
+		if kindSelect.SelectedIndex() == 1 {
+			basisItem.Text = "Exercise Price ($)"
+		}
+		form.Refresh()
+	}
+
+	table := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	scroll := container.NewVScroll(table)
+	scroll.SetMinSize(fyne.NewSize(560, 320))
+
+	run := func() {
+		shares, err1 := parseFloat(sharesEntry.Text)
+		basis, err2 := parseFloat(basisEntry.Text)
+		from, err3 := parseFloat(fromEntry.Text)
+		to, err4 := parseFloat(toEntry.Text)
+		step, err5 := parseFloat(stepEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+			return
+		}
+		if shares <= 0 || from <= 0 || to < from || step <= 0 {
+			dialog.ShowError(fmt.Errorf("Shares and prices must be greater than 0, the range low to high, and the step greater than 0"), win)
+			return
+		}
+		if (to-from)/step >= stc.MaxSensitivityPoints {
+			dialog.ShowError(fmt.Errorf("Please use a larger step; at most %d prices are calculated", stc.MaxSensitivityPoints), win)
+			return
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "%10s %12s %12s %12s %12s\n", "Price", "Shares Sold", "Net Shares", "Total Tax", "Residual")
+		calc := stc.NewCalculator(base)
+		prices := stc.PriceRange{From: from, To: to}
+		if kindSelect.SelectedIndex() == 1 {
+			input := stc.Input{ExercisePrice: basis, ExercisedShares: shares}
+			for _, r := range calc.Sensitivity(input, prices, step) {
+				fmt.Fprintf(&b, "%10.2f %12.4f %12.4f %12.2f %12.2f\n", r.FMV, r.SharesToSell, r.NetShares, r.TotalTax, r.Residual)
+			}
+		} else {
+			input := stc.RSUInput{SharesReleased: shares, VestPrice: basis}
+			for _, r := range calc.SensitivityRSU(input, prices, step) {
+				fmt.Fprintf(&b, "%10.2f %12.4f %12.4f %12.2f %12.2f\n", r.SalePrice, r.SharesToSell, r.NetShares, r.TotalTax, r.Residual)
+			}
+		}
+		table.SetText(b.String())
+	}
+
+	for _, e := range []*widgets.SmartEntry{sharesEntry, basisEntry, fromEntry, toEntry, stepEntry} {
+		e.SetOnEnter(run)
+	}
+
+	content := container.NewBorder(
+		container.NewVBox(form, widget.NewButton("Sweep", run)),
+		nil, nil, nil,
+		scroll,
+	)
+	dialog.ShowCustom("Price Sensitivity", "Close", content, win)
+}
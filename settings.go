@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/report"
+	"fynance/stc"
+	"fynance/widgets"
+)
+
+const (
+	hiddenFieldsKey = "fields.hidden"
+	taxHomeKey      = "taxHome.zone"
+	valuationKey    = "valuation.mode"
+)
+
+// taxHomeZone is the IANA zone of the user's tax home. Dates are read and
+// bucketed into years there, so a late-December vest stays in its tax year.
+var taxHomeZone string
+
+// loadTaxHome reads the saved tax-home zone from preferences
+func loadTaxHome(a fyne.App) {
+	taxHomeZone = a.Preferences().String(taxHomeKey)
+}
+
+// taxHome returns the tax-home location, or the local zone when unset
+func taxHome() *time.Location {
+	return stc.Config{TimeZone: taxHomeZone}.Location()
+}
+
+// valuationMode is whether share prices come from the market or from
+// private-company valuations. It decides which inputs every tab shows.
+var valuationMode = events.ModePublic
+
+// loadValuationMode reads the saved valuation mode from preferences
+func loadValuationMode(a fyne.App) {
+	valuationMode = events.Mode(a.Preferences().StringWithFallback(valuationKey, string(events.ModePublic)))
+}
+
+// valuationModeLabels names each mode in Settings
+var valuationModeLabels = map[events.Mode]string{
+	events.ModePublic:  "Public (market price)",
+	events.ModePrivate: "Private (409A valuation)",
+}
+
+// optionalFields are the inputs and result rows a user may hide. The core
+// price, share, and federal/payroll rate fields are always shown.
+var optionalFields = []string{
+	"Target Cash ($)",
+	"Service Start",
+	"Service End",
+	"Additional Lots",
+	"Hold Estimate",
+	"State",
+	"Local/SDI",
+	"Medicare Surtax",
+	"YTD Wages",
+	"YTD Supplemental ($)",
+	"Jurisdictions",
+	"Residency",
+	"Processing Fee ($)",
+	"Commission Tiers",
+	"Annual Fee Cap ($)",
+	"Volume Tiers",
+	"Extra Shares",
+	"Share Policy",
+	"Price Haircut",
+	fieldCashTopUp,
+	fieldRegFees,
+	"Vests / Year",
+	widgets.RowCashTopUp,
+	widgets.RowSurtax,
+	widgets.RowRegFees,
+	widgets.RowGainLoss,
+	widgets.RowWithheld,
+	widgets.RowFeeRate,
+	widgets.RowSoldRate,
+	rowBufferRefund,
+}
+
+// loadHiddenFields reads the set of hidden field labels from preferences
+func loadHiddenFields(a fyne.App) map[string]bool {
+	hidden := make(map[string]bool)
+	for _, label := range a.Preferences().StringList(hiddenFieldsKey) {
+		hidden[label] = true
+	}
+	return hidden
+}
+
+// showSettingsDialog edits display and privacy preferences: the pinned summary,
+// number format, idle lock, clipboard clearing, and which optional fields appear
+func showSettingsDialog(a fyne.App, win fyne.Window, bus *events.Bus) {
+	hidden := loadHiddenFields(a)
+
+	checks := container.NewVBox()
+	for _, label := range optionalFields {
+		check := widget.NewCheck(label, nil)
+		check.SetChecked(!hidden[label])
+		checks.Add(check)
+	}
+
+	pinCheck := widget.NewCheck("Show latest result in title bar and tray", nil)
+	pinCheck.SetChecked(a.Preferences().Bool(titleSummaryKey))
+
+	modeSelect := widget.NewSelect([]string{
+		valuationModeLabels[events.ModePublic],
+		valuationModeLabels[events.ModePrivate],
+	}, nil)
+	modeSelect.SetSelected(valuationModeLabels[valuationMode])
+
+	localeSelect := newLocaleSelect()
+	localeSelect.SetSelected(displayLocale.Name)
+
+	zoneEntry := widget.NewEntry()
+	zoneEntry.SetPlaceHolder("Local (e.g. America/New_York)")
+	zoneEntry.SetText(taxHomeZone)
+
+	lockEntry := widget.NewEntry()
+	lockEntry.SetPlaceHolder("0 (never)")
+	lockEntry.SetText(strconv.Itoa(a.Preferences().Int(idleLockKey)))
+	clearEntry := widget.NewEntry()
+	clearEntry.SetPlaceHolder("0 (never)")
+	clearEntry.SetText(strconv.Itoa(a.Preferences().Int(clipboardClearKey)))
+
+	scroll := container.NewVScroll(checks)
+	scroll.SetMinSize(fyne.NewSize(260, 320))
+	items := []*widget.FormItem{
+		widget.NewFormItem("Summary", pinCheck),
+		widget.NewFormItem("Company", modeSelect),
+		widget.NewFormItem("Number Format", localeSelect),
+		widget.NewFormItem("Tax Home Zone", zoneEntry),
+		widget.NewFormItem("Lock After Idle (min)", lockEntry),
+		widget.NewFormItem("Clear Clipboard (s)", clearEntry),
+		widget.NewFormItem("Visible Fields", scroll),
+	}
+	dialog.ShowForm("Settings", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		zone := strings.TrimSpace(zoneEntry.Text)
+		if _, err := time.LoadLocation(zone); err != nil {
+			dialog.ShowError(fmt.Errorf("Unknown time zone %q", zone), win)
+			return
+		}
+		lockMinutes, err1 := strconv.Atoi(strings.TrimSpace(lockEntry.Text))
+		clearSeconds, err2 := strconv.Atoi(strings.TrimSpace(clearEntry.Text))
+		if err1 != nil || err2 != nil || lockMinutes < 0 || clearSeconds < 0 {
+			dialog.ShowError(fmt.Errorf("Please enter whole numbers of 0 or more for the lock and clipboard delays"), win)
+			return
+		}
+		taxHomeZone = zone
+		a.Preferences().SetString(taxHomeKey, zone)
+		a.Preferences().SetInt(idleLockKey, lockMinutes)
+		a.Preferences().SetInt(clipboardClearKey, clearSeconds)
+		if loc, ok := report.Lookup(localeSelect.Selected); ok {
+			displayLocale = loc
+			a.Preferences().SetString(displayLocaleKey, loc.Name)
+		}
+
+		m := events.ModePublic
+		if modeSelect.Selected == valuationModeLabels[events.ModePrivate] {
+			m = events.ModePrivate
+		}
+		if m != valuationMode {
+			valuationMode = m
+			a.Preferences().SetString(valuationKey, string(m))
+			bus.Publish(events.ModeChanged, m)
+		}
+
+		var list []string
+		for i, o := range checks.Objects {
+			if !o.(*widget.Check).Checked {
+				list = append(list, optionalFields[i])
+			}
+		}
+		a.Preferences().SetStringList(hiddenFieldsKey, list)
+		bus.Publish(events.FieldsHidden, hiddenFields(a))
+
+		a.Preferences().SetBool(titleSummaryKey, pinCheck.Checked)
+		if !pinCheck.Checked {
+			win.SetTitle(appTitle)
+		}
+	}, win)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// errNoSpeech is returned when the system has no text-to-speech engine
+var errNoSpeech = errors.New("no text-to-speech engine found; install espeak or speech-dispatcher")
+
+// speechEngine is a system command that speaks text, given as its last
+// argument or, with stdin set, on standard input
+type speechEngine struct {
+	name  string
+	args  []string
+	stdin bool
+}
+
+// speechEngines lists the engines to try on each system, best first
+var speechEngines = map[string][]speechEngine{
+	"darwin": {{name: "say"}},
+	"windows": {{name: "powershell", stdin: true, args: []string{"-NoProfile", "-Command",
+		"Add-Type -AssemblyName System.Speech; (New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak([Console]::In.ReadToEnd())"}}},
+	"linux": {{name: "spd-say", args: []string{"--wait"}}, {name: "espeak-ng"}, {name: "espeak"}},
+}
+
+// speaking is the engine currently reading aloud, stopped when something
+// new is read so summaries do not talk over each other
+var speaking struct {
+	sync.Mutex
+	cmd *exec.Cmd
+}
+
+// speak reads text aloud with the system's text-to-speech engine. It
+// returns once speech has started; a second call interrupts the first.
+func speak(text string) error {
+	engines, ok := speechEngines[runtime.GOOS]
+	if !ok {
+		engines = speechEngines["linux"]
+	}
+	for _, e := range engines {
+		path, err := exec.LookPath(e.name)
+		if err != nil {
+			continue
+		}
+		args := e.args
+		if !e.stdin {
+			args = append(append([]string{}, args...), text)
+		}
+		cmd := exec.Command(path, args...)
+		if e.stdin {
+			cmd.Stdin = strings.NewReader(text)
+		}
+
+		speaking.Lock()
+		defer speaking.Unlock()
+		if speaking.cmd != nil && speaking.cmd.Process != nil {
+			speaking.cmd.Process.Kill()
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		speaking.cmd = cmd
+		go cmd.Wait()
+		return nil
+	}
+	return errNoSpeech
+}
+
+// newSpeakResultButton reads the latest result's summary aloud, for users
+// who rely on a screen reader. It starts disabled; pass each new summary to
+// the returned func. onError reports a missing engine.
+func newSpeakResultButton(onError func(error)) (*widget.Button, func(text string)) {
+	var latest string
+	btn := widget.NewButtonWithIcon("Read Summary Aloud", theme.VolumeUpIcon(), func() {
+		if err := speak(latest); err != nil {
+			onError(err)
+		}
+	})
+	btn.Disable()
+	return btn, func(text string) {
+		latest = text
+		btn.Enable()
+	}
+}
@@ -0,0 +1,102 @@
+package stc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// UnmarshalText parses a formatted amount; see ParseMoney
+func (m *Money) UnmarshalText(text []byte) error {
+	v, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// UnmarshalJSON reads a number, or a string holding a formatted amount such
+// as "$1,234.50". Numbers are parsed from their decimal text, not a float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return m.UnmarshalText([]byte(s))
+	}
+	if v, err := ParseMoney(string(data)); err == nil {
+		*m = v
+		return nil
+	}
+	// Exponents such as 1e6 are valid JSON but not decimal amounts
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	if math.Abs(f) >= math.MaxInt64/moneyScale {
+		return fmt.Errorf("invalid amount %s: %w", data, ErrTooLarge)
+	}
+	*m = NewMoney(f)
+	return nil
+}
+
+// MarshalJSON writes the exact decimal value as a number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Format(moneyPlaces)), nil
+}
+
+// DecodeAmounts decodes a JSON object into v, accepting a formatted amount
+// string such as "$1,234.50" as well as a number for each of the named
+// fields. Types call it from UnmarshalJSON with v a pointer to a
+// method-free copy of themselves.
+func DecodeAmounts(data []byte, v any, fields ...string) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return json.Unmarshal(data, v)
+	}
+	for _, name := range fields {
+		// Numbers decode as they always have
+		raw, ok := object[name]
+		if !ok || len(raw) == 0 || raw[0] != '"' {
+			continue
+		}
+		var m Money
+		if err := m.UnmarshalJSON(raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		object[name], _ = m.MarshalJSON()
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// UnmarshalJSON accepts formatted amounts; see DecodeAmounts
+func (in *Input) UnmarshalJSON(data []byte) error {
+	type plain Input
+	return DecodeAmounts(data, (*plain)(in), "exercisePrice", "exercisedShares", "fmv", "salePrice",
+		"ytdIncome", "ytdWages", "ytdSupplementalWages", "priorAmtCredit")
+}
+
+// UnmarshalJSON accepts formatted amounts; see DecodeAmounts
+func (in *RSUInput) UnmarshalJSON(data []byte) error {
+	type plain RSUInput
+	return DecodeAmounts(data, (*plain)(in), "sharesReleased", "vestPrice", "salePrice",
+		"ytdIncome", "ytdWages", "ytdSupplementalWages", "dividendEquivalentShares")
+}
+
+// UnmarshalJSON accepts formatted amounts; see DecodeAmounts
+func (in *MultiLotInput) UnmarshalJSON(data []byte) error {
+	type plain MultiLotInput
+	return DecodeAmounts(data, (*plain)(in), "fmv", "salePrice", "ytdIncome", "ytdWages", "ytdSupplementalWages")
+}
+
+// UnmarshalJSON accepts formatted amounts; see DecodeAmounts
+func (l *EventLot) UnmarshalJSON(data []byte) error {
+	type plain EventLot
+	return DecodeAmounts(data, (*plain)(l), "shares", "exercisePrice")
+}
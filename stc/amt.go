@@ -0,0 +1,37 @@
+package stc
+
+import (
+	"math"
+
+	"fynance/stc/amt"
+)
+
+// GrantType distinguishes incentive from non-qualified stock options
+type GrantType string
+
+const (
+	GrantNSO GrantType = "nso" // Non-qualified: the spread is wages, withheld at exercise (the default)
+	GrantISO GrantType = "iso" // Incentive: no withholding; the spread is an AMT preference item
+)
+
+// AMTResult estimates the alternative minimum tax triggered by an ISO
+// exercise and the credit it carries forward; see package amt
+type AMTResult = amt.Result
+
+// amt estimates the AMT owed when an ISO spread is added to ytd income. The
+// regular tax comes from Config.FederalBrackets, else the status's schedule.
+func (c *Calculator) amt(spread, ytd float64, status amt.FilingStatus, priorCredit float64) *AMTResult {
+	ytd = math.Max(ytd, 0)
+	schedule := c.config.FederalBrackets
+	if len(schedule) == 0 {
+		schedule = FederalBracketsFor(status)
+	}
+	r := amt.Estimate(amt.Input{
+		Spread:         spread,
+		OrdinaryIncome: ytd,
+		RegularTax:     schedule.Tax(ytd),
+		Status:         status,
+		PriorCredit:    priorCredit,
+	})
+	return &r
+}
@@ -0,0 +1,110 @@
+// Package amt estimates the alternative minimum tax an ISO exercise
+// triggers and the minimum tax credit it carries forward. It ignores
+// deductions and preference items other than the ISO spread, so it is a
+// first-order estimate of what is due at filing, not withheld at exercise.
+package amt
+
+import "math"
+
+// FilingStatus selects the exemption, phaseout, and rate break
+type FilingStatus string
+
+const (
+	Single          FilingStatus = "single" // The default
+	MarriedJoint    FilingStatus = "mfj"
+	MarriedSeparate FilingStatus = "mfs"
+	HeadOfHousehold FilingStatus = "hoh"
+)
+
+// FilingStatuses lists every filing status, default first
+var FilingStatuses = []FilingStatus{Single, MarriedJoint, MarriedSeparate, HeadOfHousehold}
+
+// Valid reports whether s is a known filing status; blank means Single
+func (s FilingStatus) Valid() bool {
+	switch s {
+	case "", Single, MarriedJoint, MarriedSeparate, HeadOfHousehold:
+		return true
+	}
+	return false
+}
+
+// Params are the AMT amounts for one filing status
+type Params struct {
+	Exemption float64 `json:"exemption"`
+	Phaseout  float64 `json:"phaseout"`  // AMTI above which the exemption shrinks by 25 cents per dollar
+	RateBreak float64 `json:"rateBreak"` // AMT base above which the 28% rate applies
+}
+
+// params2025 are the 2025 amounts
+var params2025 = map[FilingStatus]Params{
+	Single:          {Exemption: 88100, Phaseout: 626350, RateBreak: 239100},
+	MarriedJoint:    {Exemption: 137000, Phaseout: 1252700, RateBreak: 239100},
+	MarriedSeparate: {Exemption: 68500, Phaseout: 626350, RateBreak: 119550},
+	HeadOfHousehold: {Exemption: 88100, Phaseout: 626350, RateBreak: 239100},
+}
+
+const (
+	lowRate            = 0.26
+	highRate           = 0.28
+	phaseoutPercentage = 0.25
+)
+
+// For returns the 2025 amounts for a filing status; unknown statuses get Single's
+func For(s FilingStatus) Params {
+	if p, ok := params2025[s]; ok {
+		return p
+	}
+	return params2025[Single]
+}
+
+// Input is what the estimate needs. RegularTax is the regular federal tax on
+// OrdinaryIncome, worked out by the caller from its own brackets.
+type Input struct {
+	Spread         float64      `json:"spread"`                // ISO exercise spread, the AMT preference
+	OrdinaryIncome float64      `json:"ordinaryIncome"`        // Taxable income for the year before the exercise
+	RegularTax     float64      `json:"regularTax"`            // Regular tax on OrdinaryIncome
+	Status         FilingStatus `json:"status,omitempty"`      // Blank means Single
+	PriorCredit    float64      `json:"priorCredit,omitempty"` // Minimum tax credit carried in from earlier years
+}
+
+// Result estimates the AMT for the year of an ISO exercise
+type Result struct {
+	Preference          float64 `json:"preference"`          // ISO spread added to AMT income
+	AMTI                float64 `json:"amti"`                // Ordinary income plus the preference
+	Exemption           float64 `json:"exemption"`           // After the phaseout
+	TentativeMinimumTax float64 `json:"tentativeMinimumTax"` // AMT rates on AMTI less the exemption
+	RegularTax          float64 `json:"regularTax"`          // Regular tax on ordinary income
+	Liability           float64 `json:"liability"`           // Tentative minimum tax above the regular tax
+
+	// An ISO spread only defers income, so AMT paid on it becomes a credit
+	// against regular tax in later years that exceeds the tentative minimum tax
+	CreditUsed         float64 `json:"creditUsed,omitempty"`         // Prior credit claimed this year
+	CreditCarryforward float64 `json:"creditCarryforward,omitempty"` // Credit left for later years
+}
+
+// Estimate works out the AMT owed when an ISO spread is added to ordinary income
+func Estimate(in Input) Result {
+	p := For(in.Status)
+	income := math.Max(in.OrdinaryIncome, 0)
+	r := Result{
+		Preference: roundMoney(math.Max(in.Spread, 0)),
+		RegularTax: roundMoney(math.Max(in.RegularTax, 0)),
+	}
+	r.AMTI = income + r.Preference
+	r.Exemption = roundMoney(math.Max(p.Exemption-phaseoutPercentage*math.Max(r.AMTI-p.Phaseout, 0), 0))
+
+	base := math.Max(r.AMTI-r.Exemption, 0)
+	r.TentativeMinimumTax = roundMoney(lowRate*math.Min(base, p.RateBreak) + highRate*math.Max(base-p.RateBreak, 0))
+	r.Liability = roundMoney(math.Max(r.TentativeMinimumTax-r.RegularTax, 0))
+
+	// Prior credit offsets regular tax only down to the tentative minimum tax
+	prior := math.Max(in.PriorCredit, 0)
+	r.CreditUsed = roundMoney(math.Min(prior, math.Max(r.RegularTax-r.TentativeMinimumTax, 0)))
+	r.CreditCarryforward = roundMoney(prior - r.CreditUsed + r.Liability)
+	return r
+}
+
+// roundMoney rounds a float64 to 2 decimal places for monetary values
+func roundMoney(val float64) float64 {
+	return math.Round(val*100) / 100
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
-	"strconv"
 )
 
 // BatchInput represents a batch of STC calculations
@@ -19,6 +18,7 @@ type BatchResult struct {
 
 // CalculateBatch processes multiple STC calculations at once
 func (c *Calculator) CalculateBatch(inputs []Input) BatchResult {
+	c = c.snapshot()
 	results := make([]Result, len(inputs))
 	for i, input := range inputs {
 		results[i] = c.Calculate(input)
@@ -65,17 +65,17 @@ func (br BatchResult) ToCSV(w io.Writer) error {
 	// Write data rows
 	for _, result := range br.Results {
 		row := []string{
-			fmt.Sprintf("%.2f", result.ExercisePrice),
-			fmt.Sprintf("%.2f", result.ExercisedShares),
-			fmt.Sprintf("%.2f", result.FMV),
-			fmt.Sprintf("%.4f", result.SharesToSell),
-			fmt.Sprintf("%.4f", result.NetShares),
-			fmt.Sprintf("%.2f", result.TotalCosts),
-			fmt.Sprintf("%.2f", result.EstGrossProceeds),
-			fmt.Sprintf("%.2f", result.TaxableGain),
-			fmt.Sprintf("%.2f", result.TotalTax),
-			fmt.Sprintf("%.2f", result.OptionCost),
-			fmt.Sprintf("%.2f", result.BrokerFees),
+			NewMoney(result.ExercisePrice).Format(2),
+			NewMoney(result.ExercisedShares).Format(2),
+			NewMoney(result.FMV).Format(2),
+			NewMoney(result.SharesToSell).Format(4),
+			NewMoney(result.NetShares).Format(4),
+			NewMoney(result.TotalCosts).Format(2),
+			NewMoney(result.EstGrossProceeds).Format(2),
+			NewMoney(result.TaxableGain).Format(2),
+			NewMoney(result.TotalTax).Format(2),
+			NewMoney(result.OptionCost).Format(2),
+			NewMoney(result.BrokerFees).Format(2),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write row: %w", err)
@@ -85,7 +85,7 @@ func (br BatchResult) ToCSV(w io.Writer) error {
 	return nil
 }
 
-// FromCSV reads inputs from a CSV reader
+// FromCSV reads inputs from a CSV reader. Values may be formatted amounts; see ParseMoney.
 func FromCSV(r io.Reader) ([]Input, error) {
 	reader := csv.NewReader(r)
 
@@ -109,26 +109,30 @@ func FromCSV(r io.Reader) ([]Input, error) {
 			continue // Skip invalid rows
 		}
 
-		exercisePrice, err := strconv.ParseFloat(record[0], 64)
+		exercisePrice, err := ParseMoney(record[0])
 		if err != nil {
 			return nil, fmt.Errorf("invalid exercise price: %w", err)
 		}
 
-		exercisedShares, err := strconv.ParseFloat(record[1], 64)
+		exercisedShares, err := ParseMoney(record[1])
 		if err != nil {
 			return nil, fmt.Errorf("invalid exercised shares: %w", err)
 		}
 
-		fmv, err := strconv.ParseFloat(record[2], 64)
+		fmv, err := ParseMoney(record[2])
 		if err != nil {
 			return nil, fmt.Errorf("invalid FMV: %w", err)
 		}
 
-		inputs = append(inputs, Input{
-			ExercisePrice:   exercisePrice,
-			ExercisedShares: exercisedShares,
-			FMV:             fmv,
-		})
+		input := Input{
+			ExercisePrice:   exercisePrice.Float64(),
+			ExercisedShares: exercisedShares.Float64(),
+			FMV:             fmv.Float64(),
+		}
+		if err := input.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid input %d: %w", len(inputs)+1, err)
+		}
+		inputs = append(inputs, input)
 	}
 
 	return inputs, nil
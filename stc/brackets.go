@@ -0,0 +1,139 @@
+package stc
+
+import (
+	"math"
+	"sort"
+
+	"fynance/stc/amt"
+)
+
+// TaxModel selects how federal tax is computed
+type TaxModel string
+
+const (
+	TaxModelFlat     TaxModel = "flat"     // TaxRates.Federal applied to the whole gain (the default)
+	TaxModelBrackets TaxModel = "brackets" // Marginal rates from Config.FederalBrackets, stacked on YTD income
+)
+
+// TaxModels lists every tax model, default first
+var TaxModels = []TaxModel{TaxModelFlat, TaxModelBrackets}
+
+// Bracket is a marginal rate that applies to income above Threshold
+type Bracket struct {
+	Threshold float64 `json:"threshold"`
+	Rate      float64 `json:"rate"`
+}
+
+// BracketSchedule is a progressive tax schedule. Brackets need not be sorted.
+type BracketSchedule []Bracket
+
+// DefaultFederalBrackets is the 2025 single-filer schedule, used when the
+// brackets model is selected without a schedule
+var DefaultFederalBrackets = BracketSchedule{
+	{0, 0.10},
+	{11925, 0.12},
+	{48475, 0.22},
+	{103350, 0.24},
+	{197300, 0.32},
+	{250525, 0.35},
+	{626350, 0.37},
+}
+
+// federalBrackets2025 are the 2025 schedules for the other filing statuses
+var federalBrackets2025 = map[amt.FilingStatus]BracketSchedule{
+	amt.MarriedJoint: {
+		{0, 0.10},
+		{23850, 0.12},
+		{96950, 0.22},
+		{206700, 0.24},
+		{394600, 0.32},
+		{501050, 0.35},
+		{751600, 0.37},
+	},
+	amt.MarriedSeparate: {
+		{0, 0.10},
+		{11925, 0.12},
+		{48475, 0.22},
+		{103350, 0.24},
+		{197300, 0.32},
+		{250525, 0.35},
+		{375800, 0.37},
+	},
+	amt.HeadOfHousehold: {
+		{0, 0.10},
+		{17000, 0.12},
+		{64850, 0.22},
+		{103350, 0.24},
+		{197300, 0.32},
+		{250500, 0.35},
+		{626350, 0.37},
+	},
+}
+
+// FederalBracketsFor returns the 2025 schedule for a filing status;
+// single filers and unknown statuses get DefaultFederalBrackets
+func FederalBracketsFor(status amt.FilingStatus) BracketSchedule {
+	if s, ok := federalBrackets2025[status]; ok {
+		return s
+	}
+	return DefaultFederalBrackets
+}
+
+// Tax returns the total tax on income under the schedule
+func (s BracketSchedule) Tax(income float64) float64 {
+	sorted := append(BracketSchedule(nil), s...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Threshold < sorted[j].Threshold })
+
+	tax := 0.0
+	for i, b := range sorted {
+		if income <= b.Threshold {
+			break
+		}
+		top := income
+		if i+1 < len(sorted) {
+			top = math.Min(income, sorted[i+1].Threshold)
+		}
+		tax += (top - b.Threshold) * b.Rate
+	}
+	return tax
+}
+
+// TaxOnTop returns the extra tax owed when gain is added on top of ytd income
+func (s BracketSchedule) TaxOnTop(ytd, gain float64) float64 {
+	return s.Tax(ytd+gain) - s.Tax(ytd)
+}
+
+// MarginalRate returns the rate on the last dollar of income
+func (s BracketSchedule) MarginalRate(income float64) float64 {
+	rate, top := 0.0, math.Inf(-1)
+	for _, b := range s {
+		if income > b.Threshold && b.Threshold > top {
+			rate, top = b.Rate, b.Threshold
+		}
+	}
+	return rate
+}
+
+// Supplemental wages above SupplementalMandatoryThreshold in a year must be
+// withheld at SupplementalMandatoryRate, whatever rate is otherwise used
+const (
+	SupplementalMandatoryThreshold = 1000000
+	SupplementalMandatoryRate      = 0.37
+)
+
+// federalTax applies the configured tax model to a gain earned on top of ytd
+// income. Under either model, the part of the gain that takes supplemental
+// wages past the threshold is withheld at the mandatory rate.
+func (c *Calculator) federalTax(gain, ytd, ytdSupplemental float64) float64 {
+	prior := math.Max(ytdSupplemental, 0)
+	above := math.Max(prior+gain, SupplementalMandatoryThreshold) - math.Max(prior, SupplementalMandatoryThreshold)
+	above = math.Min(math.Max(above, 0), gain)
+	if c.config.TaxModel != TaxModelBrackets {
+		return roundMoney((gain-above)*c.config.TaxRates.Federal + above*SupplementalMandatoryRate)
+	}
+	schedule := c.config.FederalBrackets
+	if len(schedule) == 0 {
+		schedule = DefaultFederalBrackets
+	}
+	return roundMoney(schedule.TaxOnTop(math.Max(ytd, 0), gain-above) + above*SupplementalMandatoryRate)
+}
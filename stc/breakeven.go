@@ -0,0 +1,99 @@
+package stc
+
+import "math"
+
+// maxBreakEvenRise bounds the search for a ceiling, as a multiple of the price
+const maxBreakEvenRise = 1000
+
+// BreakEven brackets the sale prices at which a calculation still sells the
+// same number of shares. Below Floor another share must be sold; from
+// Ceiling up, one share fewer covers every cost.
+type BreakEven struct {
+	Price   float64 `json:"price"`   // Price the calculation was made at
+	Shares  float64 `json:"shares"`  // Shares sold at Price
+	Floor   float64 `json:"floor"`   // Lowest price, to the cent, at which Shares still cover every tax and fee
+	Ceiling float64 `json:"ceiling"` // Lowest price at which one share fewer would do; 0 when none up to 1,000 times Price would
+}
+
+// BreakEven finds how far the FMV of an exercise can move before the shares
+// sold change. The FMV sets the taxable spread too, so taxes are
+// recalculated at each price. ok is false when the mode does not sell to
+// cover, since the shares sold then do not depend on the price.
+func (c *Calculator) BreakEven(input Input) (b BreakEven, ok bool) {
+	c = c.snapshot()
+	if input.Mode != "" && input.Mode != SellToCover && input.Mode != WithholdToCover {
+		return BreakEven{}, false
+	}
+	// A sale price set apart from the FMV moves with it
+	spread := 0.0
+	if input.SalePrice > 0 {
+		spread = input.SalePrice - input.FMV
+	}
+	return breakEven(input.FMV, func(price float64) Money {
+		input.FMV = price
+		if spread != 0 {
+			input.SalePrice = math.Max(price+spread, 0.01)
+		}
+		return NewMoney(c.calculate(input, 0).SharesToSell)
+	})
+}
+
+// BreakEvenRSU finds how far the sale price of a release can move before the
+// shares sold change. Tax is fixed by the vest price, so only the proceeds
+// and fees move. ok is false unless the mode sells to cover: shares withheld
+// are valued at the vest price, so they do not depend on the sale price either.
+func (c *Calculator) BreakEvenRSU(input RSUInput) (b BreakEven, ok bool) {
+	c = c.snapshot()
+	if input.Mode != "" && input.Mode != SellToCover {
+		return BreakEven{}, false
+	}
+	return breakEven(input.SalePrice, func(price float64) Money {
+		input.SalePrice = price
+		return NewMoney(c.CalculateRSU(input).SharesToSell)
+	})
+}
+
+// breakEven searches whole cents for the prices bracketing the shares sold
+// at price, where sharesAt solves for the shares sold at a price. Fewer
+// shares are sold as the price rises, so both ends are found by bisection.
+func breakEven(price float64, sharesAt func(price float64) Money) (BreakEven, bool) {
+	cents := int64(math.Round(price * 100))
+	if cents <= 0 {
+		return BreakEven{}, false
+	}
+	shares := sharesAt(float64(cents) / 100)
+	if shares <= 0 {
+		return BreakEven{}, false
+	}
+	b := BreakEven{Price: price, Shares: shares.Float64()}
+
+	// lowest bisects [lo, hi] for the first cent selling at most limit
+	// shares, given that hi does
+	lowest := func(lo, hi int64, limit Money) int64 {
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			if sharesAt(float64(mid)/100) <= limit {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		return lo
+	}
+	b.Floor = float64(lowest(1, cents, shares)) / 100
+
+	// Double the price until one share fewer is enough. Stepping up keeps the
+	// search near the price, since income large enough to reach a higher tax
+	// rate can make a far higher price sell more shares, not fewer.
+	fewer := shares - NewMoney(1)
+	if fewer < 0 {
+		return b, true
+	}
+	for lo, hi := cents, 2*cents; hi <= cents*maxBreakEvenRise; lo, hi = hi, 2*hi {
+		if sharesAt(float64(hi)/100) <= fewer {
+			b.Ceiling = float64(lowest(lo, hi, fewer)) / 100
+			break
+		}
+	}
+	return b, true
+}
@@ -0,0 +1,124 @@
+package stc
+
+import "time"
+
+// DateRule moves a vest that falls on a weekend or holiday to a business day,
+// as plan documents specify
+type DateRule string
+
+const (
+	VestAsScheduled       DateRule = ""                   // Keep the scheduled date (the default)
+	VestFollowing         DateRule = "following"          // Next business day
+	VestModifiedFollowing DateRule = "modified-following" // Next business day, or the previous one if that is next month
+	VestPreceding         DateRule = "preceding"          // Previous business day
+)
+
+// DateRules lists every rule, default first
+var DateRules = []DateRule{VestAsScheduled, VestFollowing, VestModifiedFollowing, VestPreceding}
+
+// Adjust returns the business day d moves to. Weekends, US market holidays,
+// and the extra holidays are not business days.
+func (r DateRule) Adjust(d time.Time, holidays []time.Time) time.Time {
+	switch r {
+	case VestFollowing:
+		return nextBusinessDay(d, 1, holidays)
+	case VestPreceding:
+		return nextBusinessDay(d, -1, holidays)
+	case VestModifiedFollowing:
+		if next := nextBusinessDay(d, 1, holidays); next.Month() == d.Month() {
+			return next
+		}
+		return nextBusinessDay(d, -1, holidays)
+	}
+	return d
+}
+
+// nextBusinessDay steps from d by step days until it reaches a business day
+func nextBusinessDay(d time.Time, step int, holidays []time.Time) time.Time {
+	for !IsBusinessDay(d, holidays) {
+		d = d.AddDate(0, 0, step)
+	}
+	return d
+}
+
+// IsBusinessDay reports whether the market is open on d: a weekday that is
+// neither a US market holiday nor one of the extra holidays
+func IsBusinessDay(d time.Time, holidays []time.Time) bool {
+	if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	for _, h := range append(MarketHolidays(d.Year()), holidays...) {
+		if sameDay(h, d) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameDay reports whether a and b fall on the same calendar date
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// MarketHolidays returns the days the NYSE closes for holidays in year, as
+// observed: a holiday on Saturday closes the Friday before, and one on
+// Sunday the Monday after. New Year's Day on a Saturday closes no day.
+func MarketHolidays(year int) []time.Time {
+	date := func(m time.Month, d int) time.Time { return time.Date(year, m, d, 0, 0, 0, 0, time.UTC) }
+	observed := func(d time.Time) time.Time {
+		switch d.Weekday() {
+		case time.Saturday:
+			return d.AddDate(0, 0, -1)
+		case time.Sunday:
+			return d.AddDate(0, 0, 1)
+		}
+		return d
+	}
+	// nth returns the nth weekday of a month; n of -1 is the last
+	nth := func(m time.Month, wd time.Weekday, n int) time.Time {
+		if n < 0 {
+			last := date(m+1, 0)
+			return last.AddDate(0, 0, -((int(last.Weekday()) - int(wd) + 7) % 7))
+		}
+		first := date(m, 1)
+		return first.AddDate(0, 0, (int(wd)-int(first.Weekday())+7)%7+7*(n-1))
+	}
+
+	var days []time.Time
+	if newYear := date(time.January, 1); newYear.Weekday() != time.Saturday {
+		days = append(days, observed(newYear))
+	}
+	days = append(days,
+		nth(time.January, time.Monday, 3),  // Martin Luther King Jr. Day
+		nth(time.February, time.Monday, 3), // Washington's Birthday
+		easter(year).AddDate(0, 0, -2),     // Good Friday
+		nth(time.May, time.Monday, -1),     // Memorial Day
+	)
+	if year >= 2022 {
+		days = append(days, observed(date(time.June, 19))) // Juneteenth
+	}
+	return append(days,
+		observed(date(time.July, 4)),
+		nth(time.September, time.Monday, 1),  // Labor Day
+		nth(time.November, time.Thursday, 4), // Thanksgiving
+		observed(date(time.December, 25)),
+	)
+}
+
+// easter returns Easter Sunday in year, by the anonymous Gregorian algorithm
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
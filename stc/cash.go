@@ -0,0 +1,86 @@
+package stc
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CashAwardInput is a cash-settled award: phantom stock or stock
+// appreciation units paid out at a unit price, or a cash LTIP with a fixed
+// amount. No shares change hands, so there is nothing to sell and no broker;
+// the payout is taxed through payroll as supplemental wages.
+type CashAwardInput struct {
+	Units     float64 `json:"units,omitempty"`     // Phantom units settled
+	UnitPrice float64 `json:"unitPrice,omitempty"` // Cash paid per unit, e.g. the FMV at settlement
+	Amount    float64 `json:"amount,omitempty"`    // Fixed cash paid on top of the units, e.g. a cash LTIP
+	YTDIncome float64 `json:"ytdIncome,omitempty"` // Income already earned this year, for the brackets tax model
+	YTDWages  float64 `json:"ytdWages,omitempty"`  // Wages this employer already paid this year, for the surtax and wage base
+
+	// YTDSupplementalWages are bonuses and equity income already paid this year, for the $1M mandatory rate
+	YTDSupplementalWages float64 `json:"ytdSupplementalWages,omitempty"`
+
+	// Service period (grant to settlement) used to apportion income across Config.Residency
+	ServiceStart time.Time `json:"serviceStart,omitzero"`
+	ServiceEnd   time.Time `json:"serviceEnd,omitzero"`
+
+	Dates TransactionDates `json:"dates,omitzero"`
+}
+
+// CashAwardResult contains the tax withheld from a cash-settled award
+type CashAwardResult struct {
+	// Input values
+	Units     float64 `json:"units,omitempty"`
+	UnitPrice float64 `json:"unitPrice,omitempty"`
+	Amount    float64 `json:"amount,omitempty"`
+
+	// Tax Calculations
+	Payout         float64   `json:"payout"` // Gross cash, all of it supplemental income
+	FederalTax     float64   `json:"federalTax"`
+	MedicareTax    float64   `json:"medicareTax"`
+	MedicareSurtax float64   `json:"medicareSurtax"` // Additional Medicare Tax above the wage threshold
+	SocialSecTax   float64   `json:"socialSecTax"`
+	StateTax       float64   `json:"stateTax"`
+	StateLines     []TaxLine `json:"stateLines,omitempty"` // Per-state breakdown (residency or jurisdictions)
+	LocalSDITax    float64   `json:"localSdiTax"`
+	LocalLines     []TaxLine `json:"localLines,omitempty"` // Per-locality breakdown
+	TotalTax       float64   `json:"totalTax"`
+
+	NetCash float64 `json:"netCash"` // Payout less TotalTax, as paid through payroll
+
+	Meta Metadata `json:"meta"` // Tax year, jurisdictions, and model versions used
+}
+
+// CalculateCashAward works out the tax on a cash-settled award with the
+// same rates, brackets, and jurisdictions as an RSU release of equal value
+func (c *Calculator) CalculateCashAward(input CashAwardInput) CashAwardResult {
+	c = c.snapshot()
+	result := CashAwardResult{
+		Units:     input.Units,
+		UnitPrice: input.UnitPrice,
+		Amount:    input.Amount,
+		Payout:    roundMoney(input.Units*input.UnitPrice + input.Amount),
+	}
+
+	result.FederalTax = c.federalTax(result.Payout, input.YTDIncome, input.YTDSupplementalWages)
+	result.MedicareTax = roundMoney(result.Payout * c.config.TaxRates.Medicare)
+	result.MedicareSurtax = c.medicareSurtax(result.Payout, input.YTDWages)
+	result.SocialSecTax = c.socialSecTax(result.Payout, input.YTDWages)
+	result.StateLines, result.StateTax, result.LocalLines, result.LocalSDITax =
+		c.regionalTax(result.Payout, input.ServiceStart, input.ServiceEnd)
+
+	result.TotalTax = sumMoney(result.FederalTax, result.MedicareTax, result.MedicareSurtax, result.SocialSecTax,
+		result.StateTax, result.LocalSDITax)
+	result.NetCash = roundMoney(result.Payout - result.TotalTax)
+	result.Meta = c.metadata(input.Dates.taxDate(input.ServiceEnd), result.StateLines, result.LocalLines)
+
+	return result
+}
+
+// ToJSON converts the cash award result to JSON string
+func (r CashAwardResult) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,84 @@
+package stc
+
+import (
+	"math"
+	"time"
+)
+
+// RefresherOffer pits a proposed refresher grant against an equivalent cash award
+type RefresherOffer struct {
+	Schedule        VestingSchedule  `json:"schedule"`
+	Growth          GrowthAssumption `json:"growth"`
+	Cash            float64          `json:"cash"`            // Gross cash alternative
+	CashDate        time.Time        `json:"cashDate"`        // When the cash would be paid
+	AnnualRetention float64          `json:"annualRetention"` // Probability of still being employed after each year (0-1)
+	DiscountRate    float64          `json:"discountRate"`    // Annual rate used to discount future value
+}
+
+// RefresherComparison reports the present value of each alternative after tax and vesting risk
+type RefresherComparison struct {
+	EquityValue     float64 `json:"equityValue"` // Risk-adjusted present value of the refresher, after tax
+	CashValue       float64 `json:"cashValue"`   // Present value of the cash alternative, after tax
+	Advantage       float64 `json:"advantage"`   // EquityValue - CashValue
+	BreakEvenGrowth float64 `json:"breakEvenGrowth"`
+	BreakEvenFound  bool    `json:"breakEvenFound"` // False when no growth rate in range equalizes the two
+}
+
+// presentFactor discounts a value received at t, including the chance of leaving before then
+func (o RefresherOffer) presentFactor(t time.Time) float64 {
+	years := math.Max(t.Sub(o.Schedule.GrantDate).Hours()/(24*365.25), 0)
+	retention := o.AnnualRetention
+	if retention <= 0 {
+		retention = 1
+	}
+	return math.Pow(retention, years) / math.Pow(1+o.DiscountRate, years)
+}
+
+// equityValue is the risk-adjusted present value of the refresher at a given growth rate
+func (c *Calculator) equityValue(o RefresherOffer, growth float64) float64 {
+	assumption := o.Growth
+	assumption.AnnualGrowth = growth
+	if assumption.StartDate.IsZero() {
+		assumption.StartDate = o.Schedule.GrantDate
+	}
+
+	total := 0.0
+	for _, p := range c.ProjectVests(o.Schedule.Vests(), assumption) {
+		total += p.PostTax * o.presentFactor(p.Vest.Date)
+	}
+	return total
+}
+
+// CompareRefresher values both alternatives and solves for the growth rate at which they are equal
+func (c *Calculator) CompareRefresher(o RefresherOffer) RefresherComparison {
+	c = c.snapshot()
+	cashDate := o.CashDate
+	if cashDate.IsZero() {
+		cashDate = o.Schedule.GrantDate
+	}
+
+	res := RefresherComparison{
+		EquityValue: roundMoney(c.equityValue(o, o.Growth.AnnualGrowth)),
+		CashValue:   roundMoney((o.Cash - c.TaxOn(o.Cash)) * o.presentFactor(cashDate)),
+	}
+	res.Advantage = roundMoney(res.EquityValue - res.CashValue)
+
+	// Bisection: equity value rises monotonically with growth
+	lo, hi := -0.95, 5.0
+	diff := func(g float64) float64 { return c.equityValue(o, g) - res.CashValue }
+	if diff(lo) > 0 || diff(hi) < 0 {
+		return res
+	}
+	for i := 0; i < 100 && hi-lo > 1e-6; i++ {
+		mid := (lo + hi) / 2
+		if diff(mid) < 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	res.BreakEvenGrowth = math.Round((lo+hi)/2*10000) / 10000
+	res.BreakEvenFound = true
+
+	return res
+}
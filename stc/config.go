@@ -3,215 +3,511 @@ package stc
 import (
 	"encoding/json"
 	"fmt"
-	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"fynance/stc/amt"
 )
 
 // Config holds the static configuration for STC calculations
 type Config struct {
 	TaxRates   TaxRates   `json:"taxRates"`
 	BrokerFees BrokerFees `json:"brokerFees"`
+	CashTopUp  bool       `json:"cashTopUp"` // Cover the final fractional shortfall in cash instead of selling a whole share
+
+	// SharePolicy sets how the shares sold are rounded; blank sells whole shares
+	SharePolicy SharePolicy `json:"sharePolicy,omitempty"`
+
+	// PriceHaircut discounts the sale price when sizing a sell-to-cover order,
+	// e.g. 0.05 sizes it as if it filled 5% below the quote
+	PriceHaircut float64 `json:"priceHaircut,omitempty"`
+
+	// Residency replaces the flat State rate with workday-apportioned lines for part-year residents
+	Residency []ResidencyPeriod `json:"residency,omitempty"`
+
+	// TaxModel selects flat or bracket-based federal tax; FederalBrackets defaults to DefaultFederalBrackets
+	TaxModel        TaxModel        `json:"taxModel,omitempty"`
+	FederalBrackets BracketSchedule `json:"federalBrackets,omitempty"`
+
+	TaxYear int    `json:"taxYear,omitempty"` // Defaults to the service end year, or the current year
+	Country string `json:"country,omitempty"` // ISO country code, defaults to "US"
+
+	TimeZone string `json:"timeZone,omitempty"` // IANA zone of the tax home, e.g. "America/New_York"; defaults to local
+
+	// RegulatoryFees are charged on shares sold, on top of BrokerFees
+	RegulatoryFees RegulatoryFees `json:"regulatoryFees,omitzero"`
+
+	// Solver selects how the shares to sell are found; blank iterates
+	Solver Solver `json:"solver,omitempty"`
+
+	// SocialSecWageBase is the most wages one employer withholds Social
+	// Security on in a year, counting the input's YTDWages; 0 means no limit
+	SocialSecWageBase float64 `json:"socialSecWageBase,omitempty"`
 }
 
 // TaxRates represents tax rate configuration
 type TaxRates struct {
-	Federal   float64 `json:"federal"`
-	Medicare  float64 `json:"medicare"`
-	SocialSec float64 `json:"socialSec"`
-	State     float64 `json:"state"`
-	LocalSDI  float64 `json:"localSdi"`
+	Federal  float64 `json:"federal"`
+	Medicare float64 `json:"medicare"`
+	// MedicareSurtax is the Additional Medicare Tax rate (0.009) on wages above MedicareSurtaxThreshold
+	MedicareSurtax float64 `json:"medicareSurtax,omitempty"`
+	SocialSec      float64 `json:"socialSec"`
+	State          float64 `json:"state"`
+	LocalSDI       float64 `json:"localSdi"`
+
+	// Jurisdictions itemizes state and local withholding; when set, entries of each
+	// kind replace the flat State and LocalSDI rates
+	Jurisdictions []Jurisdiction `json:"jurisdictions,omitempty"`
 }
 
 // BrokerFees represents broker fee configuration
 type BrokerFees struct {
 	CommissionRate float64 `json:"commissionRate"`
 	MinimumFee     float64 `json:"minimumFee"`
-	FlatFee        float64 `json:"flatFee"` // Payment Processing Fee
+	FlatFee        float64 `json:"flatFee"`     // Payment Processing Fee
+	ExtraShares    float64 `json:"extraShares"` // Whole shares sold beyond the requirement as a buffer
+
+	// CommissionTiers add a commission by trade value, e.g. $19.95 up to $5,000 and 0.5% above
+	CommissionTiers []Tier `json:"commissionTiers,omitempty"`
+
+	// AnnualCap is the most commission charged in a year; 0 means no cap.
+	// VolumeTiers lower the rate after a number of trades. Both count from
+	// the input's BrokerYTD.
+	AnnualCap   float64      `json:"annualCap,omitempty"`
+	VolumeTiers []VolumeTier `json:"volumeTiers,omitempty"`
 }
 
 // Input represents the user-provided inputs for standard STC (Options)
 type Input struct {
-	ExercisePrice   float64 `json:"exercisePrice"`
-	ExercisedShares float64 `json:"exercisedShares"`
-	FMV             float64 `json:"fmv"`
+	ExercisePrice   float64   `json:"exercisePrice"`
+	ExercisedShares float64   `json:"exercisedShares"`
+	FMV             float64   `json:"fmv"`
+	SalePrice       float64   `json:"salePrice,omitempty"` // Price the shares sold fetch; 0 sells at FMV
+	GrantType       GrantType `json:"grantType,omitempty"` // ISO exercises are not withheld; see Result.AMT
+	Mode            SaleMode  `json:"mode,omitempty"`      // Sell to cover (the default) or sell every share
+	YTDIncome       float64   `json:"ytdIncome,omitempty"` // Income already earned this year, for the brackets tax model
+	YTDWages        float64   `json:"ytdWages,omitempty"`  // Wages this employer already paid this year, for the surtax and wage base
+
+	// YTDSupplementalWages are bonuses and equity income already paid this year, for the $1M mandatory rate
+	YTDSupplementalWages float64 `json:"ytdSupplementalWages,omitempty"`
+
+	// BrokerYTD is the commission paid and trades made this year, for annual fee caps and volume tiers
+	BrokerYTD BrokerYTD `json:"brokerYtd,omitzero"`
+
+	// FilingStatus and PriorAMTCredit refine the AMT estimate of an ISO exercise
+	FilingStatus   amt.FilingStatus `json:"filingStatus,omitempty"`
+	PriorAMTCredit float64          `json:"priorAmtCredit,omitempty"` // Minimum tax credit carried in from earlier years
+
+	// Service period (grant to vest) used to apportion income across Config.Residency
+	ServiceStart time.Time `json:"serviceStart,omitzero"`
+	ServiceEnd   time.Time `json:"serviceEnd,omitzero"`
+
+	Dates TransactionDates `json:"dates,omitzero"`
 }
 
 // RSUInput represents user-provided inputs for RSU STC
 type RSUInput struct {
-	SharesReleased float64 `json:"sharesReleased"`
-	VestPrice      float64 `json:"vestPrice"` // FMV at vest (for tax basis)
-	SalePrice      float64 `json:"salePrice"` // Estimated sale price per share
+	SharesReleased float64  `json:"sharesReleased"`
+	VestPrice      float64  `json:"vestPrice"`           // FMV at vest (for tax basis)
+	SalePrice      float64  `json:"salePrice"`           // Estimated sale price per share
+	Mode           SaleMode `json:"mode,omitempty"`      // Sell to cover (the default) or sell every share
+	YTDIncome      float64  `json:"ytdIncome,omitempty"` // Income already earned this year, for the brackets tax model
+	YTDWages       float64  `json:"ytdWages,omitempty"`  // Wages this employer already paid this year, for the surtax and wage base
+
+	// YTDSupplementalWages are bonuses and equity income already paid this year, for the $1M mandatory rate
+	YTDSupplementalWages float64 `json:"ytdSupplementalWages,omitempty"`
+
+	// DividendEquivalentShares accrued on the units release with them and are taxed as wages too
+	DividendEquivalentShares float64 `json:"dividendEquivalentShares,omitempty"`
+
+	// BrokerYTD is the commission paid and trades made this year, for annual fee caps and volume tiers
+	BrokerYTD BrokerYTD `json:"brokerYtd,omitzero"`
+
+	// Service period (grant to vest) used to apportion income across Config.Residency
+	ServiceStart time.Time `json:"serviceStart,omitzero"`
+	ServiceEnd   time.Time `json:"serviceEnd,omitzero"`
+
+	Dates TransactionDates `json:"dates,omitzero"`
 }
 
 // Result contains all calculated values from the standard STC calculation
 type Result struct {
 	// Input values
-	ExercisePrice   float64 `json:"exercisePrice"`
-	ExercisedShares float64 `json:"exercisedShares"`
-	FMV             float64 `json:"fmv"`
+	ExercisePrice   float64  `json:"exercisePrice"`
+	ExercisedShares float64  `json:"exercisedShares"`
+	FMV             float64  `json:"fmv"`
+	SalePrice       float64  `json:"salePrice,omitempty"` // Set when the shares sold fetch other than FMV
+	Mode            SaleMode `json:"mode,omitempty"`
 
 	// Calculated costs
-	OptionCost   float64 `json:"optionCost"`
-	TaxableGain  float64 `json:"taxableGain"`
-	FederalTax   float64 `json:"federalTax"`
-	MedicareTax  float64 `json:"medicareTax"`
-	SocialSecTax float64 `json:"socialSecTax"`
-	StateTax     float64 `json:"stateTax"`
-	LocalSDITax  float64 `json:"localSdiTax"`
-	TotalTax     float64 `json:"totalTax"`
+	OptionCost     float64   `json:"optionCost"`
+	TaxableGain    float64   `json:"taxableGain"`
+	FederalTax     float64   `json:"federalTax"`
+	MedicareTax    float64   `json:"medicareTax"`
+	MedicareSurtax float64   `json:"medicareSurtax"` // Additional Medicare Tax above the wage threshold
+	SocialSecTax   float64   `json:"socialSecTax"`
+	StateTax       float64   `json:"stateTax"`
+	StateLines     []TaxLine `json:"stateLines,omitempty"` // Per-state breakdown (residency or jurisdictions)
+	LocalSDITax    float64   `json:"localSdiTax"`
+	LocalLines     []TaxLine `json:"localLines,omitempty"` // Per-locality breakdown
+	TotalTax       float64   `json:"totalTax"`
 
 	// Broker fees
 	BrokerCommission float64 `json:"brokerCommission"`
-	BrokerFees       float64 `json:"brokerFees"`
+	BrokerFees       float64 `json:"brokerFees"`        // Commission after the minimum and annual cap, plus the processing fee
+	FlatFee          float64 `json:"flatFee,omitempty"` // Processing fee included in BrokerFees
+	SECFee           float64 `json:"secFee,omitempty"`  // SEC Section 31 fee on the proceeds
+	TAF              float64 `json:"taf,omitempty"`     // FINRA Trading Activity Fee on the shares sold
+	ExtraShares      float64 `json:"extraShares"`       // Buffer shares included in SharesToSell
 
 	// Final calculations
 	TotalCosts       float64 `json:"totalCosts"`
 	SharesToSell     float64 `json:"sharesToSell"`
 	EstGrossProceeds float64 `json:"estGrossProceeds"`
+	CashTopUp        float64 `json:"cashTopUp"`         // Cash paid by the employee when Config.CashTopUp is set
+	GrossUp          float64 `json:"grossUp,omitempty"` // Employer cash covering the tax, included in TaxableGain; see GrossUp
+	STCGainLoss      float64 `json:"stcGainLoss"`       // Short-term gain or loss on the shares sold; see stcGainLoss
 	Residual         float64 `json:"residual"`
+	NetCash          float64 `json:"netCash"` // Proceeds less every cost; negative when cash is paid in
 	NetShares        float64 `json:"netShares"`
+	TargetCash       float64 `json:"targetCash,omitempty"` // Residual asked of SolveForCash; Residual falls short only when every share is sold
+
+	// Headline ratios, as fractions (0.25 is 25%); see setRatios
+	EffectiveWithholdingRate float64 `json:"effectiveWithholdingRate"` // TotalTax over TaxableGain
+	FeesAsPercentOfProceeds  float64 `json:"feesAsPercentOfProceeds"`  // Broker and regulatory fees over the proceeds of the shares sold
+	PercentOfSharesSold      float64 `json:"percentOfSharesSold"`      // SharesToSell over ExercisedShares
+
+	AMT *AMTResult `json:"amt,omitempty"` // ISO exercises only
+
+	Warnings []Warning `json:"warnings,omitempty"` // Inputs that make the result misleading, e.g. an underwater option
+
+	Meta  Metadata     `json:"meta"`            // Tax year, jurisdictions, and model versions used
+	Trace []SolverStep `json:"trace,omitempty"` // Solver iterations, for debugging fee cliffs
 }
 
 // RSUResult contains all calculated values from the RSU STC calculation
 type RSUResult struct {
 	// Input values
-	SharesReleased float64 `json:"sharesReleased"`
-	VestPrice      float64 `json:"vestPrice"`
-	SalePrice      float64 `json:"salePrice"`
+	SharesReleased float64  `json:"sharesReleased"`
+	VestPrice      float64  `json:"vestPrice"`
+	SalePrice      float64  `json:"salePrice"`
+	Mode           SaleMode `json:"mode,omitempty"`
+
+	DividendEquivalentShares float64 `json:"dividendEquivalentShares,omitempty"` // Released with SharesReleased
 
 	// Tax Calculations
-	TaxableGain  float64 `json:"taxableGain"`
-	FederalTax   float64 `json:"federalTax"`
-	MedicareTax  float64 `json:"medicareTax"`
-	SocialSecTax float64 `json:"socialSecTax"`
-	StateTax     float64 `json:"stateTax"`
-	LocalSDITax  float64 `json:"localSdiTax"`
-	TotalTax     float64 `json:"totalTax"`
+	TaxableGain    float64   `json:"taxableGain"`
+	FederalTax     float64   `json:"federalTax"`
+	MedicareTax    float64   `json:"medicareTax"`
+	MedicareSurtax float64   `json:"medicareSurtax"` // Additional Medicare Tax above the wage threshold
+	SocialSecTax   float64   `json:"socialSecTax"`
+	StateTax       float64   `json:"stateTax"`
+	StateLines     []TaxLine `json:"stateLines,omitempty"` // Per-state breakdown (residency or jurisdictions)
+	LocalSDITax    float64   `json:"localSdiTax"`
+	LocalLines     []TaxLine `json:"localLines,omitempty"` // Per-locality breakdown
+	TotalTax       float64   `json:"totalTax"`
 
 	// Transaction Costs
 	BrokerCommission float64 `json:"brokerCommission"`
 	FlatFee          float64 `json:"flatFee"`
+	SECFee           float64 `json:"secFee,omitempty"` // SEC Section 31 fee on the proceeds
+	TAF              float64 `json:"taf,omitempty"`    // FINRA Trading Activity Fee on the shares sold
 	TotalFees        float64 `json:"totalFees"`
+	ExtraShares      float64 `json:"extraShares"` // Buffer shares included in SharesToSell
 
 	// Final calculations
 	TotalCosts       float64 `json:"totalCosts"`
 	SharesToSell     float64 `json:"sharesToSell"`
 	EstGrossProceeds float64 `json:"estGrossProceeds"`
+	CashTopUp        float64 `json:"cashTopUp"`         // Cash paid by the employee when Config.CashTopUp is set
+	GrossUp          float64 `json:"grossUp,omitempty"` // Employer cash covering the tax, included in TaxableGain; see GrossUp
+	STCGainLoss      float64 `json:"stcGainLoss"`       // Short-term gain or loss on the shares sold; see stcGainLoss
 	Residual         float64 `json:"residual"`
+	NetCash          float64 `json:"netCash"` // Proceeds less every cost; negative when cash is paid in
 	NetShares        float64 `json:"netShares"`
-	// NetSharesFormatted string  `json:"netSharesFormatted"`
+
+	// Headline ratios, as fractions (0.25 is 25%); see setRatios
+	EffectiveWithholdingRate float64 `json:"effectiveWithholdingRate"` // TotalTax over TaxableGain
+	FeesAsPercentOfProceeds  float64 `json:"feesAsPercentOfProceeds"`  // TotalFees over the proceeds of the shares sold
+	PercentOfSharesSold      float64 `json:"percentOfSharesSold"`      // SharesToSell over the shares released
+
+	Warnings []Warning `json:"warnings,omitempty"` // Sales the minimum fee dominates or that need more shares than released
+
+	Meta  Metadata     `json:"meta"`            // Tax year, jurisdictions, and model versions used
+	Trace []SolverStep `json:"trace,omitempty"` // Solver iterations, for debugging fee cliffs
 }
 
-// Calculator handles STC calculations with a given configuration
+// Calculator handles STC calculations with a given configuration. It keeps
+// its own copy of the configuration and is safe for concurrent use: each
+// call works from a snapshot, so the deprecated Update methods never change
+// a calculation in progress.
 type Calculator struct {
+	mu     sync.RWMutex // Guards config against the Update methods
 	config Config
+	ytd    BrokerYTD // Broker activity before the trade being solved; see forYear
 }
 
-// NewCalculator creates a new STC calculator with the given configuration
+// NewCalculator creates a new STC calculator with the given configuration.
+// It is equivalent to New(WithConfig(config)).
 func NewCalculator(config Config) *Calculator {
-	return &Calculator{config: config}
+	return &Calculator{config: config.clone()}
 }
 
 // NewDefaultCalculator creates a calculator with default tax rates and broker fees
 func NewDefaultCalculator() *Calculator {
-	return &Calculator{
-		config: Config{
-			TaxRates: TaxRates{
-				Federal:   0.22,
-				Medicare:  0.0145,
-				SocialSec: 0.062,
-				State:     0.0,
-				LocalSDI:  0.0,
-			},
-			BrokerFees: BrokerFees{
-				CommissionRate: 0.03,
-				MinimumFee:     25.0,
-				FlatFee:        0.0,
-			},
-		},
-	}
+	return New()
 }
 
 // Calculate performs the STC calculation for Options
 func (c *Calculator) Calculate(input Input) Result {
+	c = c.snapshot()
+	return c.calculate(input, 0)
+}
+
+// SolveForCash finds the shares to sell so that targetResidual is left in
+// cash after every cost: the sell-to-cover solver with the target added to
+// what must be raised. If even selling every share falls short, every share
+// is sold and Residual reports what that raises. Cash top-ups do not apply.
+func (c *Calculator) SolveForCash(input Input, targetResidual float64) Result {
+	solver := c.snapshot()
+	solver.config.CashTopUp = false
+	input.Mode = SellToCover
+	result := solver.calculate(input, NewMoney(targetResidual))
+	result.TargetCash = targetResidual
+	return result
+}
+
+// salePrice returns the price the shares sold fetch, FMV unless set
+func (in Input) salePrice() float64 {
+	if in.SalePrice > 0 {
+		return in.SalePrice
+	}
+	return in.FMV
+}
+
+// released returns every share released: the units and their dividend
+// equivalents, summed in Money so fractional equivalents add exactly
+func (in RSUInput) released() float64 {
+	return (NewMoney(in.SharesReleased) + NewMoney(in.DividendEquivalentShares)).Float64()
+}
+
+// stcGainLoss is the short-term capital gain or loss on the shares sold:
+// proceeds less the selling costs and the basis, which is the FMV or vest
+// price already taxed as income. A same-day sale at the basis price loses
+// exactly its fees. Withheld shares are not sold, so they have none.
+func stcGainLoss(mode SaleMode, proceeds, sellingCosts, sold Money, basis float64) float64 {
+	if mode == WithholdToCover || sold == 0 {
+		return 0
+	}
+	return roundMoney((proceeds - sellingCosts - sold.Mul(NewMoney(basis))).Float64())
+}
+
+// calculate runs the options calculation, raising target in cash on top of the costs
+func (c *Calculator) calculate(input Input, target Money) Result {
 	result := Result{
 		ExercisePrice:   input.ExercisePrice,
 		ExercisedShares: input.ExercisedShares,
 		FMV:             input.FMV,
+		SalePrice:       input.SalePrice,
+		Mode:            input.Mode,
 	}
 
 	// Calculate option cost and taxable gain
 	result.OptionCost = roundMoney(input.ExercisedShares * input.ExercisePrice)
 	result.TaxableGain = roundMoney((input.FMV - input.ExercisePrice) * input.ExercisedShares)
-
-	// Calculate taxes
-	result.FederalTax = roundMoney(result.TaxableGain * c.config.TaxRates.Federal)
-	result.MedicareTax = roundMoney(result.TaxableGain * c.config.TaxRates.Medicare)
-	result.SocialSecTax = roundMoney(result.TaxableGain * c.config.TaxRates.SocialSec)
-	result.StateTax = roundMoney(result.TaxableGain * c.config.TaxRates.State)
-	result.LocalSDITax = roundMoney(result.TaxableGain * c.config.TaxRates.LocalSDI)
-
-	result.TotalTax = result.FederalTax + result.MedicareTax + result.SocialSecTax +
-		result.StateTax + result.LocalSDITax
-
-	// Base liability (Costs excluding broker fees)
-	baseLiability := result.OptionCost + result.TotalTax + c.config.BrokerFees.FlatFee
-
-	// Initialize loop variables
-	sharesToSell := 0.0
-
-	// Initial guess: Cost / FMV, rounded UP to nearest whole number
-	if input.FMV > 0 {
-		sharesToSell = math.Ceil(baseLiability / input.FMV)
+	if w, ok := underwaterWarning("Exercise Price", input.ExercisePrice, input.FMV); ok {
+		result.Warnings = append(result.Warnings, w)
 	}
 
-	// Iteratively adjust for broker fees
-	const maxIterations = 100
-
-	for i := 0; i < maxIterations; i++ {
-		// NEEDS VETTING FOR CORRECTNESS
+	// An ISO spread is not wages: nothing is withheld, but it counts toward AMT.
+	// A same-day sale is a disqualifying disposition, so there is no AMT preference.
+	if input.GrantType == GrantISO {
+		if input.Mode != SellAll {
+			result.AMT = c.amt(result.TaxableGain, input.YTDIncome, input.FilingStatus, input.PriorAMTCredit)
+		}
+		result.TaxableGain = 0
+	}
+	return c.settle(result, input, target)
+}
 
-		brokerCommission := sharesToSell * c.config.BrokerFees.CommissionRate
-		brokerFeesApplied := math.Max(brokerCommission, c.config.BrokerFees.MinimumFee)
+// settle withholds tax on result.TaxableGain and solves for the shares that
+// cover it and result.OptionCost, raising target in cash on top
+func (c *Calculator) settle(result Result, input Input, target Money) Result {
+	c = c.forYear(input.BrokerYTD)
+
+	// A gross-up is itself taxed, so it joins the income before the tax is
+	// worked out. CalculateMultiLot sets it first when only some lots are covered.
+	if input.Mode == GrossUp && result.GrossUp == 0 {
+		result.GrossUp = grossUp(0, result.TaxableGain, func(gain float64) float64 {
+			return c.withheld(gain, input.YTDIncome, input.YTDWages, input.YTDSupplementalWages, input.ServiceStart, input.ServiceEnd)
+		})
+		result.TaxableGain = roundMoney(result.TaxableGain + result.GrossUp)
+	}
 
-		// 2. Calculate Total Liability
-		totalCosts := result.OptionCost + result.TotalTax + brokerFeesApplied // Original logic used baseLiability here, I need to be careful not to break it.
-		// To be safe, I'll stick to the exact previous logic for `Calculate` and only add the new method.
-		// I'll re-paste the original Calculate method logic exactly, just using the new struct definition which is compatible.
+	// Calculate taxes
+	result.FederalTax = c.federalTax(result.TaxableGain, input.YTDIncome, input.YTDSupplementalWages)
+	result.MedicareTax = roundMoney(result.TaxableGain * c.config.TaxRates.Medicare)
+	result.MedicareSurtax = c.medicareSurtax(result.TaxableGain, input.YTDWages)
+	result.SocialSecTax = c.socialSecTax(result.TaxableGain, input.YTDWages)
+	result.StateLines, result.StateTax, result.LocalLines, result.LocalSDITax =
+		c.regionalTax(result.TaxableGain, input.ServiceStart, input.ServiceEnd)
+
+	result.TotalTax = sumMoney(result.FederalTax, result.MedicareTax, result.MedicareSurtax, result.SocialSecTax,
+		result.StateTax, result.LocalSDITax)
+
+	// The solver works in fixed-point Money so repeated cost sums stay exact
+	price := NewMoney(input.salePrice())
+	if input.Mode == WithholdToCover {
+		// Withheld shares are valued at FMV, not sold
+		price = NewMoney(input.FMV)
+	}
+	optionCost := NewMoney(result.OptionCost)
+	// The employee covers only the tax the employer's gross-up does not
+	totalTax := (NewMoney(result.TotalTax) - NewMoney(result.GrossUp)).Max(0)
+	fees := c.config.BrokerFees
+	// The processing fee is charged once per sale, on top of the commission
+	flatFee := NewMoney(fees.FlatFee)
+
+	var solvedShares, brokerCommission, brokerFees, totalCosts, cashTopUp Money
+	if input.Mode == SellAll {
+		// Same-day sale: every share is sold and the costs come out of the proceeds
+		solvedShares = NewMoney(input.ExercisedShares)
+		brokerCommission = c.commission(solvedShares, price)
+		brokerFees = c.brokerFee(solvedShares, price) + flatFee
+		totalCosts = optionCost + totalTax + brokerFees
+	} else if input.Mode == PayCash {
+		// Cash exercise: nothing is sold, so the employee pays every cost
+		totalCosts = optionCost + totalTax
+		cashTopUp = totalCosts
+	} else if input.Mode == GrossUp && optionCost+totalTax+target == 0 {
+		// The gross-up covers every cost, so nothing is sold
+	} else if input.Mode == WithholdToCover {
+		// Net settlement: shares are withheld at FMV, so there is no commission to solve for
+		totalCosts = optionCost + totalTax
+		solvedShares = c.sharesFor(totalCosts, price)
+		result.Trace = append(result.Trace, SolverStep{
+			Iteration:     1,
+			SharesToSell:  solvedShares.Float64(),
+			TotalRequired: totalCosts.Float64(),
+			NextShares:    solvedShares.Float64(),
+		})
+		if c.config.CashTopUp {
+			solvedShares, cashTopUp = c.coverWithCash(solvedShares, price, func(Money) Money { return totalCosts })
+		}
+	} else {
+		// Base liability (Costs excluding broker fees)
+		baseLiability := optionCost + totalTax + flatFee + target
+
+		// Initial guess: Cost / sale price, rounded per the share policy. The
+		// order is sized at the haircut price; fees and proceeds stay at the
+		// sale price.
+		sizing := c.sizingPrice(price)
+		guess := c.sharesFor(baseLiability, sizing)
+
+		// Adjust for the broker fees on the shares sold, per Config.Solver
+		shares, trace, ok := c.solveShares(optionCost+totalTax+target, flatFee, guess, price, sizing)
+		result.Trace = append(result.Trace, trace...)
+		if ok {
+			solvedShares, brokerCommission = shares, c.commission(shares, price)
+			brokerFees = c.chargedFee(brokerCommission) + flatFee
+			totalCosts = optionCost + totalTax + brokerFees
+		}
 
-		// 3. Calculate new required shares (Rounded UP)
-		newSharesToSell := math.Ceil(totalCosts / input.FMV)
+		if c.config.CashTopUp {
+			// Pay the rounding difference in cash rather than in shares
+			solvedShares, cashTopUp = c.coverWithCash(solvedShares, sizing, func(shares Money) Money {
+				return optionCost + totalTax + c.saleFees(shares, price) + flatFee
+			})
+			brokerCommission = c.commission(solvedShares, price)
+			brokerFees = c.brokerFee(solvedShares, price) + flatFee
+			totalCosts = optionCost + totalTax + brokerFees
+		} else if extra := fees.ExtraShares; extra > 0 {
+			// Broker buffer policy: sell extra whole shares and re-apply commission
+			result.ExtraShares = extra
+			solvedShares += NewMoney(extra)
+			brokerCommission = c.commission(solvedShares, price)
+			brokerFees = c.brokerFee(solvedShares, price) + flatFee
+			totalCosts = optionCost + totalTax + brokerFees
+		}
 
-		// 4. Check for stability
-		if newSharesToSell == sharesToSell {
-			result.SharesToSell = sharesToSell
-			result.BrokerCommission = brokerCommission
-			result.BrokerFees = brokerFeesApplied
-			result.TotalCosts = totalCosts
-			break
+		// A cash target can ask for more shares than were exercised
+		if all := NewMoney(input.ExercisedShares); target > 0 && solvedShares > all {
+			solvedShares = all
+			brokerCommission = c.commission(solvedShares, price)
+			brokerFees = c.brokerFee(solvedShares, price) + flatFee
+			totalCosts = optionCost + totalTax + brokerFees
 		}
-		sharesToSell = newSharesToSell
 	}
 
-	result.EstGrossProceeds = result.SharesToSell * input.FMV
-	result.Residual = result.EstGrossProceeds - result.TotalCosts
+	if input.Mode != WithholdToCover && input.Mode != PayCash {
+		// Regulatory fees are charged on whatever is finally sold
+		sec, taf := c.regulatoryFees(solvedShares, price)
+		result.SECFee, result.TAF = sec.Float64(), taf.Float64()
+		totalCosts += sec + taf
+	}
+
+	proceeds := solvedShares.Mul(price)
+	result.SharesToSell = solvedShares.Float64()
+	result.BrokerCommission = brokerCommission.Float64()
+	result.BrokerFees = brokerFees.Float64()
+	if brokerFees > 0 {
+		result.FlatFee = fees.FlatFee
+	}
+	result.TotalCosts = totalCosts.Float64()
+	result.CashTopUp = cashTopUp.Float64()
+	result.EstGrossProceeds = proceeds.Float64()
+	result.Residual = (proceeds + cashTopUp - totalCosts).Float64()
+	result.NetCash = (proceeds - totalCosts).Float64()
 	result.NetShares = input.ExercisedShares - result.SharesToSell
+	result.STCGainLoss = stcGainLoss(input.Mode, proceeds, brokerFees+NewMoney(result.SECFee)+NewMoney(result.TAF), solvedShares, input.FMV)
+	result.Warnings = append(result.Warnings, c.saleWarnings(input.Mode, solvedShares, NewMoney(input.ExercisedShares), price, brokerFees)...)
+	result.setRatios(input.ExercisedShares)
+	result.Meta = c.metadata(input.Dates.taxDate(input.ServiceEnd), result.StateLines, result.LocalLines)
 
 	return result
 }
 
-// UpdateTaxRates updates the tax rate configuration
+// UpdateTaxRates updates the tax rate configuration. Calculations already
+// running finish with the old rates.
+//
+// Deprecated: other goroutines sharing the calculator see the change
+// partway through their work. Use c.With(WithTaxRates(rates)) for a new calculator.
 func (c *Calculator) UpdateTaxRates(rates TaxRates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.config.TaxRates = rates
+	c.config.TaxRates.Jurisdictions = slices.Clone(rates.Jurisdictions)
 }
 
-// UpdateBrokerFees updates the broker fee configuration
+// UpdateBrokerFees updates the broker fee configuration. Calculations
+// already running finish with the old fees.
+//
+// Deprecated: other goroutines sharing the calculator see the change
+// partway through their work. Use c.With(WithBrokerFees(fees)) for a new calculator.
 func (c *Calculator) UpdateBrokerFees(fees BrokerFees) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.config.BrokerFees = fees
+	c.config.BrokerFees.CommissionTiers = slices.Clone(fees.CommissionTiers)
+	c.config.BrokerFees.VolumeTiers = slices.Clone(fees.VolumeTiers)
 }
 
-// GetConfig returns the current configuration
+// GetConfig returns a copy of the current configuration
 func (c *Calculator) GetConfig() Config {
-	return c.config
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.clone()
+}
+
+// snapshot returns a private copy of the calculator for one call, so the
+// call sees a single configuration even if an Update method runs meanwhile.
+// Update methods replace slices rather than writing into them, so the copy
+// may share them.
+func (c *Calculator) snapshot() *Calculator {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return &Calculator{config: c.config}
 }
 
 // ToJSON converts the result to JSON string
@@ -233,12 +529,23 @@ func (r Result) String() string {
 	)
 }
 
-// roundMoney rounds a float64 to 2 decimal places for monetary values
+// brokerFee returns the commission charged for selling the given shares at
+// price, floored at the minimum fee and held to the annual cap
+func (c *Calculator) brokerFee(shares, price Money) Money {
+	return c.chargedFee(c.commission(shares, price))
+}
+
+// roundMoney rounds a float64 to 2 decimal places for monetary values,
+// half away from zero on the decimal value rather than its binary approximation
 func roundMoney(val float64) float64 {
-	return math.Round(val*100) / 100
+	return NewMoney(val).Round(2).Float64()
 }
 
-// func (r RSUResult) FormattedNetShares() string {
-// 	p := message.NewPrinter(language.English)
-// 	return p.Sprintf("%.2f", r.NetShares)
-// }
+// sumMoney adds monetary amounts exactly
+func sumMoney(vals ...float64) float64 {
+	var total Money
+	for _, v := range vals {
+		total += NewMoney(v)
+	}
+	return total.Float64()
+}
@@ -0,0 +1,38 @@
+package stc
+
+import "time"
+
+// TransactionDates are the moments a release or exercise happened. Each time
+// carries its own zone; tax-year bucketing converts to Config.TimeZone.
+type TransactionDates struct {
+	Vest       time.Time `json:"vest,omitzero"`       // Vest or exercise date
+	Trade      time.Time `json:"trade,omitzero"`      // Sell-to-cover trade date
+	Settlement time.Time `json:"settlement,omitzero"` // Trade settlement date
+}
+
+// taxDate is when the income is recognized: the vest date, else the end of service
+func (d TransactionDates) taxDate(serviceEnd time.Time) time.Time {
+	if !d.Vest.IsZero() {
+		return d.Vest
+	}
+	return serviceEnd
+}
+
+// Location returns the tax-home time zone, falling back to the local zone
+// when TimeZone is blank or unknown
+func (c Config) Location() *time.Location {
+	if c.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// TaxYearOf returns the tax year t falls in at the tax home. A vest at
+// 2025-12-31 20:00 in New York is 2026 in UTC but belongs to 2025.
+func (c Config) TaxYearOf(t time.Time) int {
+	return t.In(c.Location()).Year()
+}
@@ -0,0 +1,102 @@
+package stc
+
+import (
+	"math"
+	"time"
+)
+
+// DeferralElection compares settling an RSU release at vest with deferring
+// settlement for a number of years under a deferred compensation plan
+type DeferralElection struct {
+	Shares   float64          `json:"shares"`
+	VestDate time.Time        `json:"vestDate"`
+	Years    int              `json:"years"`  // Years settlement is deferred
+	Growth   GrowthAssumption `json:"growth"` // StartPrice is the vest price; StartDate defaults to VestDate
+
+	YTDIncome        float64 `json:"ytdIncome,omitempty"`        // Other income in the vest year, for the brackets tax model
+	SettlementIncome float64 `json:"settlementIncome,omitempty"` // Other income in the settlement year, e.g. lower in retirement
+
+	// SettlementRates are the income tax rates in the settlement year, e.g.
+	// after a move to a state without income tax; nil keeps the current rates
+	SettlementRates *TaxRates `json:"settlementRates,omitempty"`
+	CapGainsRate    float64   `json:"capGainsRate"` // On growth of shares kept after settling at vest
+}
+
+// DeferralComparison values both elections at the settlement date
+type DeferralComparison struct {
+	SettleDate  time.Time `json:"settleDate"`
+	VestPrice   float64   `json:"vestPrice"`
+	SettlePrice float64   `json:"settlePrice"` // Projected price at the settlement date
+
+	// Settling at vest withholds income tax and FICA on the vest value; the
+	// shares kept then grow and the growth is a capital gain
+	NowTax       float64 `json:"nowTax"`
+	NowNetShares float64 `json:"nowNetShares"`
+	NowGainTax   float64 `json:"nowGainTax"`
+	NowAfterTax  float64 `json:"nowAfterTax"`
+
+	// Deferring still owes FICA at vest on the vest value, paid from other
+	// wages, but later growth escapes FICA. Income tax waits for settlement
+	// and falls on the grown value.
+	DeferFICA      float64 `json:"deferFica"`
+	DeferIncomeTax float64 `json:"deferIncomeTax"`
+	DeferNetShares float64 `json:"deferNetShares"`
+	DeferAfterTax  float64 `json:"deferAfterTax"`
+
+	Advantage float64 `json:"advantage"` // DeferAfterTax - NowAfterTax
+}
+
+// CompareDeferral runs the release at vest and at the deferred settlement
+// date, each as a sell-to-cover, and values what is kept at the settlement
+// price. It ignores the time value of the cash paid at vest.
+func (c *Calculator) CompareDeferral(e DeferralElection) DeferralComparison {
+	c = c.snapshot()
+	growth := e.Growth
+	if growth.StartDate.IsZero() {
+		growth.StartDate = e.VestDate
+	}
+	settle := e.VestDate.AddDate(e.Years, 0, 0)
+	res := DeferralComparison{
+		SettleDate:  settle,
+		VestPrice:   growth.StartPrice,
+		SettlePrice: roundMoney(growth.PriceAt(settle)),
+	}
+
+	now := c.CalculateRSU(RSUInput{
+		SharesReleased: e.Shares,
+		VestPrice:      res.VestPrice,
+		SalePrice:      res.VestPrice,
+		YTDIncome:      e.YTDIncome,
+		YTDWages:       e.YTDIncome,
+		Dates:          TransactionDates{Vest: e.VestDate},
+	})
+	res.NowTax, res.NowNetShares = now.TotalTax, now.NetShares
+	gain := math.Max(now.NetShares*(res.SettlePrice-res.VestPrice), 0)
+	res.NowGainTax = roundMoney(gain * e.CapGainsRate)
+	res.NowAfterTax = roundMoney(now.NetShares*res.SettlePrice + now.Residual - res.NowGainTax)
+
+	// FICA is due at vest under the special timing rule
+	vestValue := roundMoney(e.Shares * res.VestPrice)
+	res.DeferFICA = sumMoney(roundMoney(vestValue*c.config.TaxRates.Medicare),
+		c.medicareSurtax(vestValue, e.YTDIncome),
+		roundMoney(vestValue*c.config.TaxRates.SocialSec))
+
+	// Settlement withholds income tax only
+	later := c.config
+	if e.SettlementRates != nil {
+		later.TaxRates = *e.SettlementRates
+	}
+	later.TaxRates.Medicare, later.TaxRates.MedicareSurtax, later.TaxRates.SocialSec = 0, 0, 0
+	deferred := (&Calculator{config: later}).CalculateRSU(RSUInput{
+		SharesReleased: e.Shares,
+		VestPrice:      res.SettlePrice,
+		SalePrice:      res.SettlePrice,
+		YTDIncome:      e.SettlementIncome,
+		Dates:          TransactionDates{Vest: settle},
+	})
+	res.DeferIncomeTax, res.DeferNetShares = deferred.TotalTax, deferred.NetShares
+	res.DeferAfterTax = roundMoney(deferred.NetShares*res.SettlePrice + deferred.Residual - res.DeferFICA)
+
+	res.Advantage = roundMoney(res.DeferAfterTax - res.NowAfterTax)
+	return res
+}
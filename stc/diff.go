@@ -0,0 +1,149 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Change is one quantity that differs between two runs of a calculation
+type Change struct {
+	ID     string  `json:"id"`
+	Label  string  `json:"label"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %.2f → %.2f", c.Label, c.Before, c.After)
+}
+
+// Diff lists the nodes whose values moved by at least a cent between two
+// graphs of the same calculation, in the order of the after graph
+func Diff(before, after Graph) []Change {
+	var changes []Change
+	for _, n := range after.Nodes {
+		old, ok := before.Node(n.ID)
+		if !ok || math.Abs(old.Value-n.Value) < 0.005 {
+			continue
+		}
+		changes = append(changes, Change{ID: n.ID, Label: n.Label, Before: old.Value, After: n.Value})
+	}
+	return changes
+}
+
+// FieldDelta is one result field in two runs of a calculation
+type FieldDelta struct {
+	Field  string  `json:"field"` // JSON name of the result field
+	Label  string  `json:"label"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Delta  float64 `json:"delta"` // After - Before
+}
+
+func (d FieldDelta) String() string {
+	return fmt.Sprintf("%s: %.2f → %.2f (%+.2f)", d.Label, d.Before, d.After, d.Delta)
+}
+
+// ResultDiff compares the headline fields of two results, e.g. before and
+// after a change of the state rate. Every compared field is listed, changed
+// or not, in a fixed order.
+type ResultDiff struct {
+	Fields []FieldDelta `json:"fields"`
+}
+
+// Changed lists the fields that moved by at least a cent
+func (d ResultDiff) Changed() []FieldDelta {
+	var out []FieldDelta
+	for _, f := range d.Fields {
+		if math.Abs(f.Delta) >= 0.005 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Field looks up a field by its JSON name, e.g. "totalTax"
+func (d ResultDiff) Field(name string) (FieldDelta, bool) {
+	for _, f := range d.Fields {
+		if f.Field == name {
+			return f, true
+		}
+	}
+	return FieldDelta{}, false
+}
+
+// String lists the changed fields, one per line
+func (d ResultDiff) String() string {
+	changed := d.Changed()
+	if len(changed) == 0 {
+		return "No changes"
+	}
+	lines := make([]string, len(changed))
+	for i, f := range changed {
+		lines[i] = f.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// add compares one field
+func (d *ResultDiff) add(field, label string, before, after float64) {
+	d.Fields = append(d.Fields, FieldDelta{Field: field, Label: label, Before: before, After: after, Delta: roundMoney(after - before)})
+}
+
+// CompareResults reports the field-by-field deltas from a to b of two
+// exercise results: the shares sold and kept, each tax, the costs, and the
+// cash left over
+func CompareResults(a, b Result) ResultDiff {
+	var d ResultDiff
+	d.add("sharesToSell", "Shares To Sell", a.SharesToSell, b.SharesToSell)
+	d.add("netShares", "Net Shares", a.NetShares, b.NetShares)
+	d.add("taxableGain", "Taxable Gain", a.TaxableGain, b.TaxableGain)
+	d.add("federalTax", "Federal Tax", a.FederalTax, b.FederalTax)
+	d.add("socialSecTax", "Social Security", a.SocialSecTax, b.SocialSecTax)
+	d.add("medicareTax", "Medicare", a.MedicareTax, b.MedicareTax)
+	d.add("medicareSurtax", "Medicare Surtax", a.MedicareSurtax, b.MedicareSurtax)
+	d.add("stateTax", "State Tax", a.StateTax, b.StateTax)
+	d.add("localSdiTax", "Local/SDI Tax", a.LocalSDITax, b.LocalSDITax)
+	d.add("totalTax", "Total Tax", a.TotalTax, b.TotalTax)
+	d.add("brokerFees", "Broker Fees", a.BrokerFees, b.BrokerFees)
+	d.add("totalCosts", "Total Costs", a.TotalCosts, b.TotalCosts)
+	d.add("cashTopUp", "Cash Top-Up", a.CashTopUp, b.CashTopUp)
+	d.add("residual", "Residual", a.Residual, b.Residual)
+	d.add("netCash", "Net Cash", a.NetCash, b.NetCash)
+	return d
+}
+
+// CompareRSUResults is CompareResults for two releases, with total fees in
+// place of broker fees
+func CompareRSUResults(a, b RSUResult) ResultDiff {
+	var d ResultDiff
+	d.add("sharesToSell", "Shares To Sell", a.SharesToSell, b.SharesToSell)
+	d.add("netShares", "Net Shares", a.NetShares, b.NetShares)
+	d.add("taxableGain", "Taxable Gain", a.TaxableGain, b.TaxableGain)
+	d.add("federalTax", "Federal Tax", a.FederalTax, b.FederalTax)
+	d.add("socialSecTax", "Social Security", a.SocialSecTax, b.SocialSecTax)
+	d.add("medicareTax", "Medicare", a.MedicareTax, b.MedicareTax)
+	d.add("medicareSurtax", "Medicare Surtax", a.MedicareSurtax, b.MedicareSurtax)
+	d.add("stateTax", "State Tax", a.StateTax, b.StateTax)
+	d.add("localSdiTax", "Local/SDI Tax", a.LocalSDITax, b.LocalSDITax)
+	d.add("totalTax", "Total Tax", a.TotalTax, b.TotalTax)
+	d.add("totalFees", "Total Fees", a.TotalFees, b.TotalFees)
+	d.add("totalCosts", "Total Costs", a.TotalCosts, b.TotalCosts)
+	d.add("cashTopUp", "Cash Top-Up", a.CashTopUp, b.CashTopUp)
+	d.add("residual", "Residual", a.Residual, b.Residual)
+	d.add("netCash", "Net Cash", a.NetCash, b.NetCash)
+	return d
+}
+
+// CompareEntries compares the stored results of two session entries of the
+// same kind. ok is false when either has no result or they differ in kind.
+func CompareEntries(a, b SessionEntry) (d ResultDiff, ok bool) {
+	switch {
+	case a.Result != nil && b.Result != nil:
+		return CompareResults(*a.Result, *b.Result), true
+	case a.RSUResult != nil && b.RSUResult != nil:
+		return CompareRSUResults(*a.RSUResult, *b.RSUResult), true
+	}
+	return ResultDiff{}, false
+}
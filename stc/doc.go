@@ -0,0 +1,96 @@
+// Package stc calculates sell-to-cover transactions for stock options and
+// restricted stock units: the taxes withheld, the broker fees, and how many
+// shares must be sold to pay for them.
+//
+// # Calculators
+//
+// A Calculator holds a Config. Build one with New and functional options,
+// starting from DefaultConfig:
+//
+//	calc := stc.New(
+//		stc.WithTaxRates(stc.TaxRates{Federal: 0.22, Medicare: 0.0145, SocialSec: 0.062, State: 0.093}),
+//		stc.WithBrokerFees(stc.BrokerFees{CommissionRate: 0.03, MinimumFee: 25}),
+//		stc.WithSharePolicy(stc.ShareWhole),
+//	)
+//
+// Options apply in order, so later ones adjust earlier ones:
+//
+//	calc := stc.New(stc.WithBrokerPreset(stc.PresetTiered), stc.WithTaxModel(stc.TaxModelBrackets))
+//
+// A calculator can also be built from a Config decoded from JSON with
+// NewCalculator, which is the same as New(WithConfig(cfg)). A calculator copies
+// its configuration and never changes it, so it is safe to share between
+// goroutines. To vary an assumption, derive a new calculator instead of
+// modifying one:
+//
+//	california := calc.With(stc.WithResidency(periods...))
+//
+// # Options exercises
+//
+//	r := calc.Calculate(stc.Input{ExercisePrice: 5, ExercisedShares: 1000, FMV: 50})
+//	fmt.Printf("sell %.0f shares, keep %.0f, residual $%.2f\n", r.SharesToSell, r.NetShares, r.Residual)
+//
+// SolveForCash runs the solver the other way round, selling enough shares to
+// leave a chosen amount of cash, and Reconcile compares a broker's
+// confirmation with the calculation.
+//
+// # RSU releases
+//
+//	r := calc.CalculateRSU(stc.RSUInput{SharesReleased: 100, VestPrice: 50, SalePrice: 50})
+//
+// Input.Mode and RSUInput.Mode select sell-to-cover (the default), sell-all,
+// withhold-to-cover, or pay-in-cash settlement. Every result carries a Trace
+// of solver iterations and Meta describing the tax year and data used, and
+// its Graph explains how each amount was derived. Explain lists the same
+// derivation as readable steps, for logging:
+//
+//	for _, step := range r.Explain() {
+//		log.Println(step)
+//	}
+//
+// # Validation
+//
+// The calculations trust their inputs: a negative share count or a NaN price
+// gives a meaningless result rather than an error. The Checked variants
+// validate the config and input first and return typed errors:
+//
+//	r, err := calc.CalculateChecked(in)
+//	var field *stc.InputError
+//	switch {
+//	case errors.As(err, &field):
+//		fmt.Printf("fix %s\n", field.Field) // errors.Is(err, stc.ErrNegativeShares), ...
+//	case errors.Is(err, stc.ErrSolverNoConverge):
+//		fmt.Printf("check the fees; last try sold %.0f shares\n", r.SharesToSell)
+//	}
+//
+// A config can be checked once, up front, with NewCalculatorChecked. Besides
+// out-of-range rates and fees it rejects unknown settings (ErrUnknownSetting)
+// and ones that contradict each other (ErrConfigConflict), such as
+// commission tiers whose limits do not increase. Sessions, plan templates,
+// and config overrides are validated as they are loaded.
+//
+// A Scenario bundles a config with one exercise or release input. It
+// round-trips through Marshal and UnmarshalScenario, and Run and Diff
+// recalculate it, so a calculation can be shared and compared:
+//
+//	s, err := stc.UnmarshalScenario(data)
+//	entry, err := s.Run() // entry.Result or entry.RSUResult
+//
+// CompareResults and CompareRSUResults report the field-by-field deltas
+// between two results, e.g. before and after a change of the state rate.
+//
+// # Compatibility
+//
+// Exported identifiers in this package are kept compatible: they are not
+// removed or renamed, and function signatures do not change. Struct types may
+// gain fields, so use keyed composite literals. JSON field names of Config,
+// the inputs, and the results are stable, and new fields are optional, so
+// saved configurations and sessions keep loading. Deprecated identifiers
+// remain until the next major version.
+//
+// Amounts are computed in Money, a six-place fixed-point decimal, so there
+// is no floating-point mode to select.
+//
+// Calculated amounts are not frozen: tax tables and rounding fixes change
+// results, and Result.Meta records the versions a result was computed with.
+package stc
@@ -0,0 +1,123 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+
+	"fynance/stc/amt"
+)
+
+// SocialSecWageBase is the most wages Social Security is withheld on in a
+// year (2025). Each employer applies it to the wages it pays, so after a job
+// change it restarts.
+const SocialSecWageBase = 176100
+
+// socialSecTax applies TaxRates.SocialSec to the part of gain below the
+// configured wage base, counting the employer's ytdWages already paid
+func (c *Calculator) socialSecTax(gain, ytdWages float64) float64 {
+	if base := c.config.SocialSecWageBase; base > 0 {
+		gain = math.Min(gain, math.Max(base-math.Max(ytdWages, 0), 0))
+	}
+	return roundMoney(math.Max(gain, 0) * c.config.TaxRates.SocialSec)
+}
+
+// EmployerWages is one employer's payroll for the year. The withholding is
+// as on the last paystub or the W-2; 0 estimates it from the wages.
+type EmployerWages struct {
+	Name           string  `json:"name"`
+	Wages          float64 `json:"wages"` // Medicare wages, equity income included
+	SocialSecTax   float64 `json:"socialSecTax,omitempty"`
+	MedicareSurtax float64 `json:"medicareSurtax,omitempty"`
+}
+
+// EmployerLine is one employer's wages with the withholding used for it
+type EmployerLine struct {
+	Name           string  `json:"name"`
+	Wages          float64 `json:"wages"`
+	SocialSecTax   float64 `json:"socialSecTax"`
+	MedicareSurtax float64 `json:"medicareSurtax"`
+	Estimated      bool    `json:"estimated,omitempty"` // Some withholding was worked out, not entered
+}
+
+// EmployerYear compares what several employers withheld, each on its own
+// wages, with what the combined wages owe on the return
+type EmployerYear struct {
+	Employers []EmployerLine `json:"employers"`
+	Wages     float64        `json:"wages"`
+
+	// Social Security beyond the tax on one wage base is refunded as a credit
+	// on the return (Schedule 3), not by the employers
+	SocialSecWithheld float64 `json:"socialSecWithheld"`
+	SocialSecDue      float64 `json:"socialSecDue"`
+	ExcessSocialSec   float64 `json:"excessSocialSec"`
+
+	// The Additional Medicare Tax applies to the combined wages above the
+	// filing status's threshold (Form 8959); negative owed is a refund
+	SurtaxThreshold float64 `json:"surtaxThreshold"`
+	SurtaxWithheld  float64 `json:"surtaxWithheld"`
+	SurtaxDue       float64 `json:"surtaxDue"`
+	SurtaxOwed      float64 `json:"surtaxOwed"`
+
+	Notes []string `json:"notes,omitempty"`
+}
+
+// surtaxThresholds are the Additional Medicare Tax thresholds on the return;
+// employers withhold above MedicareSurtaxThreshold whatever the status
+var surtaxThresholds = map[amt.FilingStatus]float64{
+	amt.Single:          200000,
+	amt.MarriedJoint:    250000,
+	amt.MarriedSeparate: 125000,
+	amt.HeadOfHousehold: 200000,
+}
+
+// CombineEmployers totals a year with several employers. Each employer
+// withholds Social Security up to its own wage base and the Additional
+// Medicare Tax above its own threshold, so a job change can withhold too much
+// of the first and too little of the second. A blank status is Single.
+func (c *Calculator) CombineEmployers(employers []EmployerWages, status amt.FilingStatus) EmployerYear {
+	c = c.snapshot()
+	if c.config.SocialSecWageBase == 0 {
+		c.config.SocialSecWageBase = SocialSecWageBase
+	}
+	rates := c.config.TaxRates
+	var y EmployerYear
+	for _, e := range employers {
+		line := EmployerLine{Name: e.Name, Wages: e.Wages, SocialSecTax: e.SocialSecTax, MedicareSurtax: e.MedicareSurtax}
+		if line.SocialSecTax == 0 {
+			line.SocialSecTax = c.socialSecTax(e.Wages, 0)
+			line.Estimated = line.SocialSecTax > 0
+		}
+		if line.MedicareSurtax == 0 {
+			line.MedicareSurtax = c.medicareSurtax(e.Wages, 0)
+			line.Estimated = line.Estimated || line.MedicareSurtax > 0
+		}
+		y.Employers = append(y.Employers, line)
+		y.Wages = sumMoney(y.Wages, e.Wages)
+		y.SocialSecWithheld = sumMoney(y.SocialSecWithheld, line.SocialSecTax)
+		y.SurtaxWithheld = sumMoney(y.SurtaxWithheld, line.MedicareSurtax)
+	}
+
+	y.SocialSecDue = c.socialSecTax(y.Wages, 0)
+	y.ExcessSocialSec = roundMoney(math.Max(y.SocialSecWithheld-y.SocialSecDue, 0))
+
+	if status == "" {
+		status = amt.Single
+	}
+	y.SurtaxThreshold = surtaxThresholds[status]
+	y.SurtaxDue = roundMoney(math.Max(y.Wages-y.SurtaxThreshold, 0) * rates.MedicareSurtax)
+	y.SurtaxOwed = roundMoney(y.SurtaxDue - y.SurtaxWithheld)
+
+	if len(y.Employers) > 1 && y.ExcessSocialSec > 0 {
+		y.Notes = append(y.Notes, fmt.Sprintf("Your employers withheld $%.2f more Social Security than one wage base owes. "+
+			"Claim it back as excess Social Security on your return (Schedule 3); the employers will not refund it.", y.ExcessSocialSec))
+	}
+	switch {
+	case y.SurtaxOwed > 0:
+		y.Notes = append(y.Notes, fmt.Sprintf("Your combined wages owe $%.2f more Additional Medicare Tax than was withheld; "+
+			"it is due with your return (Form 8959).", y.SurtaxOwed))
+	case y.SurtaxOwed < 0:
+		y.Notes = append(y.Notes, fmt.Sprintf("$%.2f of the Additional Medicare Tax withheld is credited back on your return (Form 8959).",
+			-y.SurtaxOwed))
+	}
+	return y
+}
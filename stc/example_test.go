@@ -0,0 +1,42 @@
+package stc_test
+
+import (
+	"fmt"
+
+	"fynance/stc"
+)
+
+func ExampleNew() {
+	calc := stc.New(
+		stc.WithBrokerPreset(stc.PresetZeroCommission),
+		stc.WithSharePolicy(stc.ShareRoundNearest),
+	)
+	r := calc.Calculate(stc.Input{ExercisePrice: 5, ExercisedShares: 1000, FMV: 50})
+	fmt.Printf("sell %g shares, fees $%.2f, residual $%.2f\n", r.SharesToSell, r.BrokerFees, r.Residual)
+	// Output:
+	// sell 367 shares, fees $0.00, residual $6.91
+}
+
+func ExampleCalculator_Calculate() {
+	calc := stc.NewDefaultCalculator()
+	r := calc.Calculate(stc.Input{ExercisePrice: 5, ExercisedShares: 1000, FMV: 50})
+	fmt.Printf("gain $%.2f, tax $%.2f\n", r.TaxableGain, r.TotalTax)
+	fmt.Printf("sell %g shares for $%.2f, keep %g\n", r.SharesToSell, r.EstGrossProceeds, r.NetShares)
+	fmt.Printf("residual $%.2f\n", r.Residual)
+	// Output:
+	// gain $45000.00, tax $13342.50
+	// sell 368 shares for $18400.00, keep 632
+	// residual $31.91
+}
+
+func ExampleCalculator_CalculateRSU() {
+	calc := stc.NewDefaultCalculator()
+	r := calc.CalculateRSU(stc.RSUInput{SharesReleased: 100, VestPrice: 150, SalePrice: 150})
+	fmt.Printf("income $%.2f, tax $%.2f\n", r.TaxableGain, r.TotalTax)
+	fmt.Printf("sell %g shares, keep %g\n", r.SharesToSell, r.NetShares)
+	fmt.Printf("residual $%.2f\n", r.Residual)
+	// Output:
+	// income $15000.00, tax $4447.50
+	// sell 30 shares, keep 70
+	// residual $27.36
+}
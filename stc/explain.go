@@ -0,0 +1,86 @@
+package stc
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Explain lists the steps of the calculation in the order they were taken:
+// each node of Graph with its formula, the solver's iterations under the
+// shares sold, and the itemized state and local taxes under theirs, for
+// showing or logging how the result was reached
+func (r Result) Explain() []string {
+	return explain(r.Graph(), r.Mode, r.TaxableGain, r.StateLines, r.LocalLines, r.Trace)
+}
+
+// Explain lists the steps of the RSU calculation in the order they were
+// taken; see Result.Explain
+func (r RSUResult) Explain() []string {
+	return explain(r.Graph(), r.Mode, r.TaxableGain, r.StateLines, r.LocalLines, r.Trace)
+}
+
+// shareNodes are the graph nodes counted in shares rather than dollars
+var shareNodes = []string{"exercisedShares", "sharesReleased", "dividendEquivalentShares", "sharePool", "sharesToSell", "netShares"}
+
+// optionalNodes are left out of the steps when they are zero
+var optionalNodes = []string{"grossUp", "medicareSurtax", "localSdiTax", "cashTopUp"}
+
+// explain writes one step per node of g, inputs first as the graph lists them
+func explain(g Graph, mode SaleMode, gain float64, stateLines, localLines []TaxLine, trace []SolverStep) []string {
+	var steps []string
+	for _, n := range g.Nodes {
+		if n.Value == 0 && slices.Contains(optionalNodes, n.ID) {
+			continue
+		}
+		value := fmt.Sprintf("$%.2f", n.Value)
+		if slices.Contains(shareNodes, n.ID) {
+			value = strconv.FormatFloat(n.Value, 'f', -1, 64)
+		}
+		if n.Formula == "" {
+			steps = append(steps, fmt.Sprintf("%s: %s", n.Label, value))
+			continue
+		}
+		step := fmt.Sprintf("%s: %s = %s", n.Label, n.Formula, value)
+		if gain > 0 && slices.Contains(taxIDs, n.ID) {
+			step += fmt.Sprintf(" (%.2f%% of the taxable gain)", n.Value/gain*100)
+		}
+		steps = append(steps, step)
+
+		switch n.ID {
+		case "stateTax":
+			steps = append(steps, explainTaxLines(stateLines)...)
+		case "localSdiTax":
+			steps = append(steps, explainTaxLines(localLines)...)
+		case "sharesToSell":
+			if mode != WithholdToCover {
+				steps = append(steps, explainSolver(trace)...)
+			}
+		}
+	}
+	return steps
+}
+
+// explainTaxLines lists the state or local taxes when there are several
+func explainTaxLines(lines []TaxLine) []string {
+	var steps []string
+	for _, l := range lines {
+		steps = append(steps, fmt.Sprintf("  %s: $%.2f × %.2f%% = $%.2f", l.Name, l.Income, l.Rate*100, l.Amount))
+	}
+	return steps
+}
+
+// explainSolver describes each iteration of the fee solver
+func explainSolver(trace []SolverStep) []string {
+	var steps []string
+	for _, st := range trace {
+		if st.NextShares == st.SharesToSell {
+			steps = append(steps, fmt.Sprintf("  Iteration %d: %g shares cost $%.2f in fees and cover the $%.2f required; stable",
+				st.Iteration, st.SharesToSell, st.Fees, st.TotalRequired))
+			continue
+		}
+		steps = append(steps, fmt.Sprintf("  Iteration %d: %g shares cost $%.2f in fees, so $%.2f is required, needing %g shares",
+			st.Iteration, st.SharesToSell, st.Fees, st.TotalRequired, st.NextShares))
+	}
+	return steps
+}
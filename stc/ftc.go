@@ -0,0 +1,52 @@
+package stc
+
+import "math"
+
+// FTCInput describes one equity event taxed by both a home and a host country
+type FTCInput struct {
+	Income          float64 `json:"income"`          // Taxable equity income, in home currency
+	ForeignFraction float64 `json:"foreignFraction"` // Share of income sourced to the host country (0-1), e.g. by workdays
+	HomeWithheld    float64 `json:"homeWithheld"`    // Tax already withheld by the home employer
+	Home            Config  `json:"home"`            // Home (residence/citizenship) country tax model
+	Host            Config  `json:"host"`            // Host (work location) country tax model
+}
+
+// FTCResult estimates the foreign tax credit and remaining home-country liability
+type FTCResult struct {
+	HomeTax       float64 `json:"homeTax"`       // Home tax on the full income before credits
+	HostTax       float64 `json:"hostTax"`       // Host tax on the foreign-sourced portion
+	Limitation    float64 `json:"limitation"`    // Maximum creditable amount
+	Credit        float64 `json:"credit"`        // Foreign tax credit claimed
+	Excess        float64 `json:"excess"`        // Host tax above the limitation (carryforward candidate)
+	NetAdditional float64 `json:"netAdditional"` // Home tax still due after the credit and withholding (negative is a refund)
+	TotalBurden   float64 `json:"totalBurden"`   // Combined tax paid to both countries
+}
+
+// TaxOn returns the total withholding this calculator's model applies to a gain
+func (c *Calculator) TaxOn(gain float64) float64 {
+	c = c.snapshot()
+	_, state, _, local := c.regionalTax(gain, zeroTime, zeroTime)
+	return c.federalTax(gain, 0, 0) +
+		roundMoney(gain*c.config.TaxRates.Medicare) +
+		c.medicareSurtax(gain, 0) +
+		roundMoney(gain*c.config.TaxRates.SocialSec) +
+		state + local
+}
+
+// EstimateForeignTaxCredit applies the standard credit limitation: the home country
+// credits host tax up to the home tax attributable to foreign-source income.
+func EstimateForeignTaxCredit(in FTCInput) FTCResult {
+	fraction := math.Min(math.Max(in.ForeignFraction, 0), 1)
+
+	res := FTCResult{
+		HomeTax: NewCalculator(in.Home).TaxOn(in.Income),
+		HostTax: NewCalculator(in.Host).TaxOn(roundMoney(in.Income * fraction)),
+	}
+	res.Limitation = roundMoney(res.HomeTax * fraction)
+	res.Credit = math.Min(res.HostTax, res.Limitation)
+	res.Excess = roundMoney(res.HostTax - res.Credit)
+	res.NetAdditional = roundMoney(res.HomeTax - res.Credit - in.HomeWithheld)
+	res.TotalBurden = roundMoney(res.HomeTax - res.Credit + res.HostTax)
+
+	return res
+}
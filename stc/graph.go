@@ -0,0 +1,201 @@
+package stc
+
+import "encoding/json"
+
+// Node is one quantity in a calculation graph
+type Node struct {
+	ID      string   `json:"id"`
+	Label   string   `json:"label"`
+	Value   float64  `json:"value"`
+	Formula string   `json:"formula,omitempty"` // Human-readable; blank for inputs
+	Inputs  []string `json:"inputs,omitempty"`  // IDs of the nodes this one is computed from
+}
+
+// Graph is the dependency DAG of a calculation, listed so that every node
+// appears after the nodes it depends on
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+}
+
+// Node looks up a node by ID
+func (g Graph) Node(id string) (Node, bool) {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return Node{}, false
+}
+
+// Dependents returns the IDs of nodes computed directly from id
+func (g Graph) Dependents(id string) []string {
+	var out []string
+	for _, n := range g.Nodes {
+		for _, in := range n.Inputs {
+			if in == id {
+				out = append(out, n.ID)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ToJSON converts the graph to a JSON string
+func (g Graph) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// taxNodes lists the per-tax nodes shared by both calculations
+func taxNodes(federal, medicare, surtax, socialSec, state, local float64) []Node {
+	return []Node{
+		{ID: "federalTax", Label: "Federal Tax", Value: federal, Formula: "taxableGain × federal rate (37% on supplemental wages above $1M), or marginal brackets above YTD income", Inputs: []string{"taxableGain"}},
+		{ID: "medicareTax", Label: "Medicare Tax", Value: medicare, Formula: "taxableGain × Medicare rate", Inputs: []string{"taxableGain"}},
+		{ID: "medicareSurtax", Label: "Additional Medicare Tax", Value: surtax, Formula: "taxableGain above the YTD wage threshold × surtax rate", Inputs: []string{"taxableGain"}},
+		{ID: "socialSecTax", Label: "Social Security Tax", Value: socialSec, Formula: "taxableGain × Social Security rate", Inputs: []string{"taxableGain"}},
+		{ID: "stateTax", Label: "State Tax", Value: state, Formula: "taxableGain × state rate(s)", Inputs: []string{"taxableGain"}},
+		{ID: "localSdiTax", Label: "Local/SDI Tax", Value: local, Formula: "taxableGain × local rate(s)", Inputs: []string{"taxableGain"}},
+	}
+}
+
+var taxIDs = []string{"federalTax", "medicareTax", "medicareSurtax", "socialSecTax", "stateTax", "localSdiTax"}
+
+// coverFormula describes the shares the solver sells to cover an amount
+// under policy, e.g. "fewest whole shares where shares × fmv covers totalTax"
+func coverFormula(policy SharePolicy, price, covers string) string {
+	switch policy {
+	case ShareFractional:
+		return "fewest shares, to the millionth, where shares × " + price + " covers " + covers
+	case ShareRoundNearest:
+		return "nearest whole number of shares to " + covers + " ÷ " + price
+	default:
+		return "fewest whole shares where shares × " + price + " covers " + covers
+	}
+}
+
+// grossUpNode is the employer's cash covering the tax on income and on itself
+func grossUpNode(grossUp float64, inputs ...string) Node {
+	return Node{ID: "grossUp", Label: "Employer Gross-Up", Value: grossUp,
+		Formula: "tax on the income and on the gross-up itself, paid by the employer", Inputs: inputs}
+}
+
+// Graph returns every intermediate quantity of the options calculation and
+// what it was derived from. The sell-to-cover solve is a single node since
+// shares sold and broker fees depend on each other.
+func (r Result) Graph() Graph {
+	nodes := []Node{
+		{ID: "exercisePrice", Label: "Exercise Price", Value: r.ExercisePrice},
+		{ID: "exercisedShares", Label: "Exercised Shares", Value: r.ExercisedShares},
+		{ID: "fmv", Label: "FMV", Value: r.FMV},
+		{ID: "optionCost", Label: "Option Cost", Value: r.OptionCost, Formula: "exercisedShares × exercisePrice", Inputs: []string{"exercisedShares", "exercisePrice"}},
+	}
+	gain := Node{ID: "taxableGain", Label: "Taxable Gain", Value: r.TaxableGain, Formula: "exercisedShares × (fmv − exercisePrice)", Inputs: []string{"exercisedShares", "fmv", "exercisePrice"}}
+	if r.GrossUp > 0 {
+		nodes = append(nodes, grossUpNode(r.GrossUp, gain.Inputs...))
+		gain.Formula, gain.Inputs = gain.Formula+" + grossUp", append(gain.Inputs, "grossUp")
+	}
+	nodes = append(nodes, gain)
+	if r.AMT != nil {
+		nodes[len(nodes)-1].Formula = "0 for ISOs; the spread is an AMT preference"
+		nodes = append(nodes, Node{ID: "amtLiability", Label: "Est. AMT", Value: r.AMT.Liability,
+			Formula: "tentative minimum tax on YTD income + spread − regular tax", Inputs: []string{"exercisedShares", "fmv", "exercisePrice"}})
+	}
+	// Shares sold away from the FMV are priced at the sale price
+	sale := "fmv"
+	if r.SalePrice > 0 && r.Mode != WithholdToCover {
+		sale = "salePrice"
+		nodes = append(nodes, Node{ID: sale, Label: "Sale Price", Value: r.SalePrice})
+	}
+	nodes = append(nodes, taxNodes(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax)...)
+	sharesToSell := Node{ID: "sharesToSell", Label: "Shares To Sell", Value: r.SharesToSell,
+		Formula: coverFormula(r.Meta.SharePolicy, sale, "optionCost + totalTax + fees(shares)"),
+		Inputs:  []string{"optionCost", "totalTax", sale}}
+	if r.ExtraShares > 0 {
+		sharesToSell.Formula += ", plus extra shares"
+	}
+	if r.Mode == SellAll {
+		sharesToSell.Formula, sharesToSell.Inputs = "exercisedShares (sell all)", []string{"exercisedShares"}
+	}
+	if r.Mode == WithholdToCover {
+		sharesToSell.Label, sharesToSell.Formula = "Shares Withheld", coverFormula(r.Meta.SharePolicy, "fmv", "optionCost + totalTax")+" (no fees)"
+	}
+	if r.Mode == PayCash {
+		sharesToSell.Formula, sharesToSell.Inputs = "0 (costs paid in cash)", nil
+	}
+	nodes = append(nodes,
+		Node{ID: "totalTax", Label: "Total Tax", Value: r.TotalTax, Formula: "sum of taxes", Inputs: taxIDs},
+		sharesToSell,
+		Node{ID: "estGrossProceeds", Label: "Sale Proceeds", Value: r.EstGrossProceeds, Formula: "sharesToSell × " + sale, Inputs: []string{"sharesToSell", sale}},
+		Node{ID: "brokerFees", Label: "Broker Fees", Value: r.BrokerFees, Formula: "min(max(commission × sharesToSell + tier commission, minimum fee), annual cap left) + processing fee", Inputs: []string{"sharesToSell"}},
+		Node{ID: "secFee", Label: "SEC Fee", Value: r.SECFee, Formula: "secRate × estGrossProceeds, rounded up to the cent", Inputs: []string{"estGrossProceeds"}},
+		Node{ID: "taf", Label: "FINRA TAF", Value: r.TAF, Formula: "min(tafRate × sharesToSell rounded up to the cent, tafMax)", Inputs: []string{"sharesToSell"}},
+		Node{ID: "totalCosts", Label: "Total Costs", Value: r.TotalCosts, Formula: "optionCost + totalTax + brokerFees + secFee + taf", Inputs: []string{"optionCost", "totalTax", "brokerFees", "secFee", "taf"}},
+		Node{ID: "cashTopUp", Label: "Cash Top-Up", Value: r.CashTopUp, Formula: "max(totalCosts − estGrossProceeds, 0) when paying the shortfall in cash", Inputs: []string{"totalCosts", "estGrossProceeds"}},
+		Node{ID: "residual", Label: "Residual", Value: r.Residual, Formula: "estGrossProceeds + cashTopUp − totalCosts", Inputs: []string{"estGrossProceeds", "cashTopUp", "totalCosts"}},
+		Node{ID: "stcGainLoss", Label: "ST Gain/Loss", Value: r.STCGainLoss, Formula: "estGrossProceeds − brokerFees − secFee − taf − sharesToSell × fmv", Inputs: []string{"estGrossProceeds", "brokerFees", "secFee", "taf", "sharesToSell", "fmv"}},
+		Node{ID: "netShares", Label: "Net Shares", Value: r.NetShares, Formula: "exercisedShares − sharesToSell", Inputs: []string{"exercisedShares", "sharesToSell"}},
+	)
+	return Graph{Nodes: nodes}
+}
+
+// Graph returns every intermediate quantity of the RSU calculation and what
+// it was derived from
+func (r RSUResult) Graph() Graph {
+	nodes := []Node{
+		{ID: "sharesReleased", Label: "Shares Released", Value: r.SharesReleased},
+		{ID: "vestPrice", Label: "Vest Price", Value: r.VestPrice},
+		{ID: "salePrice", Label: "Sale Price", Value: r.SalePrice},
+	}
+	// Dividend equivalents release with the units, so both form the pool
+	pool := "sharesReleased"
+	if r.DividendEquivalentShares > 0 {
+		pool = "sharePool"
+		nodes = append(nodes,
+			Node{ID: "dividendEquivalentShares", Label: "Dividend Equivalents", Value: r.DividendEquivalentShares},
+			Node{ID: pool, Label: "Share Pool", Value: r.SharesReleased + r.DividendEquivalentShares, Formula: "sharesReleased + dividendEquivalentShares", Inputs: []string{"sharesReleased", "dividendEquivalentShares"}},
+		)
+	}
+	gain := Node{ID: "taxableGain", Label: "Taxable Gain", Value: r.TaxableGain, Formula: pool + " × vestPrice", Inputs: []string{pool, "vestPrice"}}
+	if r.GrossUp > 0 {
+		nodes = append(nodes, grossUpNode(r.GrossUp, gain.Inputs...))
+		gain.Formula, gain.Inputs = gain.Formula+" + grossUp", append(gain.Inputs, "grossUp")
+	}
+	nodes = append(nodes, gain)
+	nodes = append(nodes, taxNodes(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax)...)
+	sharesToSell := Node{ID: "sharesToSell", Label: "Shares To Sell", Value: r.SharesToSell,
+		Formula: coverFormula(r.Meta.SharePolicy, "salePrice", "totalTax + fees(shares)"),
+		Inputs:  []string{"totalTax", "salePrice"}}
+	if r.ExtraShares > 0 {
+		sharesToSell.Formula += ", plus extra shares"
+	}
+	if r.Mode == SellAll {
+		sharesToSell.Formula, sharesToSell.Inputs = pool+" (sell all)", []string{pool}
+	}
+	if r.Mode == WithholdToCover {
+		sharesToSell.Label, sharesToSell.Formula = "Shares Withheld", coverFormula(r.Meta.SharePolicy, "vestPrice", "totalTax")+" (no fees)"
+		sharesToSell.Inputs = []string{"totalTax", "vestPrice"}
+	}
+	if r.Mode == PayCash {
+		sharesToSell.Formula, sharesToSell.Inputs = "0 (taxes paid in cash)", nil
+	}
+	nodes = append(nodes,
+		Node{ID: "totalTax", Label: "Total Tax", Value: r.TotalTax, Formula: "sum of taxes", Inputs: taxIDs},
+		sharesToSell,
+		Node{ID: "brokerCommission", Label: "Broker Commission", Value: r.BrokerCommission, Formula: "min(max(commission × sharesToSell + tier commission, minimum fee), annual cap left)", Inputs: []string{"sharesToSell"}},
+		Node{ID: "flatFee", Label: "Processing Fee", Value: r.FlatFee},
+		Node{ID: "secFee", Label: "SEC Fee", Value: r.SECFee, Formula: "secRate × sharesToSell × salePrice, rounded up to the cent", Inputs: []string{"sharesToSell", "salePrice"}},
+		Node{ID: "taf", Label: "FINRA TAF", Value: r.TAF, Formula: "min(tafRate × sharesToSell rounded up to the cent, tafMax)", Inputs: []string{"sharesToSell"}},
+		Node{ID: "totalFees", Label: "Total Fees", Value: r.TotalFees, Formula: "brokerCommission + flatFee + secFee + taf", Inputs: []string{"brokerCommission", "flatFee", "secFee", "taf"}},
+		Node{ID: "totalCosts", Label: "Total Costs", Value: r.TotalCosts, Formula: "totalTax + totalFees", Inputs: []string{"totalTax", "totalFees"}},
+		Node{ID: "cashTopUp", Label: "Cash Top-Up", Value: r.CashTopUp, Formula: "max(totalCosts − sharesToSell × salePrice, 0) when paying the shortfall in cash", Inputs: []string{"totalCosts", "sharesToSell", "salePrice"}},
+		Node{ID: "residual", Label: "Residual", Value: r.Residual, Formula: "sharesToSell × salePrice + cashTopUp − totalCosts", Inputs: []string{"sharesToSell", "salePrice", "cashTopUp", "totalCosts"}},
+		Node{ID: "stcGainLoss", Label: "ST Gain/Loss", Value: r.STCGainLoss, Formula: "sharesToSell × (salePrice − vestPrice) − totalFees", Inputs: []string{"sharesToSell", "salePrice", "vestPrice", "totalFees"}},
+		Node{ID: "netShares", Label: "Net Shares", Value: r.NetShares, Formula: pool + " − sharesToSell", Inputs: []string{pool, "sharesToSell"}},
+		Node{ID: "estGrossProceeds", Label: "Retained Value", Value: r.EstGrossProceeds, Formula: "netShares × salePrice", Inputs: []string{"netShares", "salePrice"}},
+	)
+	return Graph{Nodes: nodes}
+}
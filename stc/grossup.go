@@ -0,0 +1,39 @@
+package stc
+
+import "time"
+
+// maxGrossUpIterations bounds the tax-on-tax iteration; it settles to the
+// cent in a handful of steps unless the combined rate is close to 100%
+const maxGrossUpIterations = 100
+
+// withheld totals the tax settle and CalculateRSU withhold on gain
+func (c *Calculator) withheld(gain, ytdIncome, ytdWages, ytdSupplemental float64, start, end time.Time) float64 {
+	_, state, _, local := c.regionalTax(gain, start, end)
+	return sumMoney(c.federalTax(gain, ytdIncome, ytdSupplemental),
+		roundMoney(gain*c.config.TaxRates.Medicare),
+		c.medicareSurtax(gain, ytdWages),
+		c.socialSecTax(gain, ytdWages),
+		state, local)
+}
+
+// grossUp returns the cash an employer adds so that it pays the tax on
+// covered income and on the cash itself, which is taxed as more income.
+// other is income in the same event the employer does not cover; only the
+// tax the covered income and gross-up add on top of it is paid. The
+// gross-up g solves g = taxOn(other + covered + g) - taxOn(other), found by
+// iterating from g = 0 until it is stable to the cent.
+func grossUp(other, covered float64, taxOn func(gain float64) float64) float64 {
+	if covered <= 0 {
+		return 0
+	}
+	base := taxOn(other)
+	var g float64
+	for range maxGrossUpIterations {
+		next := roundMoney(taxOn(roundMoney(other+covered+g)) - base)
+		if next == g {
+			break
+		}
+		g = next
+	}
+	return g
+}
@@ -0,0 +1,198 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+)
+
+// Violation is a broken invariant found while checking a result
+type Violation struct {
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Detail
+}
+
+// maxReferenceSearch bounds how far past the fee-free estimate the reference solver looks
+const maxReferenceSearch = 1e6
+
+// referenceShares is an independent, brute-force solver: the fewest whole
+// shares that settle costAt(shares) at price. Whole shares settle once their
+// sale covers the costs; rounding to the nearest share settles once the costs
+// are under half a share more. It counts up from the fee-free lower bound, so
+// it never settles on a larger fixed point.
+func referenceShares(price float64, costAt func(shares float64) float64, policy SharePolicy) float64 {
+	if price <= 0 {
+		return 0
+	}
+	// Amounts are compared in millionths so float64 error cannot split an exact tie
+	micros := func(v float64) float64 { return math.Round(v * 1e6) }
+	settled := func(shares float64) bool { return micros(costAt(shares)) <= micros(shares*price) }
+	if policy == ShareRoundNearest {
+		settled = func(shares float64) bool { return micros(costAt(shares)) < micros((shares+0.5)*price) }
+	}
+	// Fees that grow faster than the price can never be covered; give up after
+	// a bounded search instead of looping forever
+	shares := math.Max(math.Floor(costAt(0)/price), 0)
+	for limit := shares + maxReferenceSearch; !settled(shares); shares++ {
+		if shares >= limit {
+			return math.Inf(1)
+		}
+	}
+	return shares
+}
+
+// referenceFee is the float64 commission and regulatory fees used by the reference solver,
+// independent of the fixed-point arithmetic in Calculate
+func (c *Calculator) referenceFee(shares, price float64) float64 {
+	fees := c.config.BrokerFees
+	rate, minimum := c.volumeRates()
+	commission := shares * rate
+	if shares > 0 {
+		if t, ok := fees.tierFor(shares * price); ok {
+			commission += t.Flat + shares*price*t.Rate
+		}
+	}
+	reg := c.config.RegulatoryFees
+	var sec, taf float64
+	if shares > 0 {
+		// Rounded to the millionth before the cent, as Money does
+		ceilCents := func(v float64) float64 { return math.Ceil(math.Round(v*1e6)/1e4) / 100 }
+		sec = ceilCents(shares * price * reg.SECRate)
+		taf = ceilCents(shares * reg.TAFRate)
+		if reg.TAFMax > 0 {
+			taf = math.Min(taf, reg.TAFMax)
+		}
+	}
+	fee := math.Max(commission, minimum)
+	if fees.AnnualCap > 0 {
+		fee = math.Min(fee, math.Max(fees.AnnualCap-c.ytd.Fees, 0))
+	}
+	return fee + sec + taf
+}
+
+// CheckResult verifies an options result against the invariants every
+// calculation must satisfy, and against the reference solver. Money
+// comparisons allow tolerance dollars of rounding.
+func (c *Calculator) CheckResult(in Input, r Result, tolerance float64) []Violation {
+	c = c.snapshot().forYear(in.BrokerYTD)
+	due := math.Max(roundMoney(r.TotalTax-r.GrossUp), 0)
+	costAt := func(shares float64) float64 {
+		if in.Mode == WithholdToCover || in.Mode == PayCash {
+			return r.OptionCost + due
+		}
+		return r.OptionCost + due + c.referenceFee(shares, in.salePrice()) + c.config.BrokerFees.FlatFee
+	}
+	fixed := fixedShares(in.Mode, in.ExercisedShares)
+	if in.Mode == GrossUp && r.OptionCost+due == 0 {
+		costAt, fixed = func(float64) float64 { return 0 }, 0
+	}
+	// Sales are sized at the haircut sale price; withheld shares at FMV
+	sizing := in.FMV
+	if in.Mode != WithholdToCover {
+		sizing = c.sizingPrice(NewMoney(in.salePrice())).Float64()
+	}
+	return c.check(r.SharesToSell, r.ExtraShares, sizing, r.TotalTax,
+		r.FederalTax+r.MedicareTax+r.MedicareSurtax+r.SocialSecTax+r.StateTax+r.LocalSDITax,
+		r.TotalCosts, r.EstGrossProceeds, r.CashTopUp, r.Residual, r.Trace, costAt, tolerance, fixed)
+}
+
+// CheckRSUResult verifies an RSU result; see CheckResult
+func (c *Calculator) CheckRSUResult(in RSUInput, r RSUResult, tolerance float64) []Violation {
+	c = c.snapshot().forYear(in.BrokerYTD)
+	due := math.Max(roundMoney(r.TotalTax-r.GrossUp), 0)
+	costAt := func(shares float64) float64 {
+		return due + c.referenceFee(shares, in.SalePrice) + c.config.BrokerFees.FlatFee
+	}
+	price := in.SalePrice
+	sizing := c.sizingPrice(NewMoney(price)).Float64()
+	fixed := fixedShares(in.Mode, in.released())
+	switch {
+	case in.Mode == WithholdToCover:
+		costAt = func(float64) float64 { return due }
+		price, sizing = in.VestPrice, in.VestPrice
+	case in.Mode == PayCash:
+		costAt = func(float64) float64 { return due }
+	case in.Mode == GrossUp && due <= 0:
+		costAt, fixed = func(float64) float64 { return 0 }, 0
+	}
+	// RSU EstGrossProceeds is the retained value, so proceeds are recomputed
+	return c.check(r.SharesToSell, r.ExtraShares, sizing, r.TotalTax,
+		r.FederalTax+r.MedicareTax+r.MedicareSurtax+r.SocialSecTax+r.StateTax+r.LocalSDITax,
+		r.TotalCosts, r.SharesToSell*price, r.CashTopUp, r.Residual, r.Trace, costAt, tolerance, fixed)
+}
+
+// fixedShares returns the shares a mode that skips the solver must sell: all
+// of them for SellAll, none for PayCash. It returns -1 when the solver decides.
+func fixedShares(mode SaleMode, shares float64) float64 {
+	switch mode {
+	case SellAll:
+		return shares
+	case PayCash:
+		return 0
+	}
+	return -1
+}
+
+func (c *Calculator) check(shares, extra, price, totalTax, taxSum, totalCosts, proceeds, cashTopUp, residual float64,
+	trace []SolverStep, costAt func(float64) float64, tolerance, fixed float64) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...any) {
+		out = append(out, Violation{Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if math.IsNaN(residual) || math.IsInf(residual, 0) || math.IsNaN(shares) {
+		add("finite", "residual %v, shares %v", residual, shares)
+		return out
+	}
+	policy := c.config.SharePolicy
+	// Selling every share released may include a fractional dividend equivalent
+	if shares < 0 || (policy != ShareFractional && shares != math.Trunc(shares) && shares != fixed) {
+		add("whole-shares", "sells %v shares", shares)
+	}
+	if math.Abs(totalTax-taxSum) > tolerance {
+		add("tax-sum", "total tax $%.2f but components sum to $%.2f", totalTax, taxSum)
+	}
+	if math.Abs(totalCosts-costAt(shares)) > tolerance {
+		add("cost-sum", "total costs $%.2f but costs at %v shares are $%.2f", totalCosts, shares, costAt(shares))
+	}
+	if got := proceeds + cashTopUp - totalCosts; math.Abs(residual-got) > tolerance {
+		add("residual", "residual $%.2f but proceeds + top-up − costs is $%.2f", residual, got)
+	}
+	// Rounding to the nearest share may under-sell unless the shortfall is paid in
+	// cash; selling everything can still fall short when fees outweigh the spread
+	if residual < -tolerance && fixed < 0 && (policy != ShareRoundNearest || c.config.CashTopUp) {
+		add("covered", "sale leaves a $%.2f shortfall", -residual)
+	}
+
+	// Selling everything or nothing skips the solver
+	if fixed >= 0 {
+		if shares != fixed {
+			add("fixed-shares", "sells %v shares instead of %v", shares, fixed)
+		}
+		return out
+	}
+
+	if n := len(trace); n == 0 || trace[n-1].NextShares != trace[n-1].SharesToSell {
+		add("converged", "solver stopped after %d iterations without settling", n)
+	}
+
+	// Fractional sales are minimal when they are within a millionth of a share of
+	// the costs; float64 noise rules out an exact comparison
+	if policy == ShareFractional {
+		if covering := costAt(shares) / price; shares-extra > covering+2e-6 {
+			add("minimal", "sells %v shares but %v cover the costs", shares, covering+extra)
+		}
+		return out
+	}
+	// Cash top-up of whole shares sells one share fewer than the solver found, by design
+	if c.config.CashTopUp && policy != ShareRoundNearest {
+		return out
+	}
+	if want := referenceShares(price, costAt, policy) + extra; shares != want {
+		add("minimal", "sells %v shares but %v cover the costs", shares, want)
+	}
+	return out
+}
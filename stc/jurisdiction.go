@@ -0,0 +1,72 @@
+package stc
+
+import "time"
+
+// zeroTime is passed where no service period applies
+var zeroTime time.Time
+
+// JurisdictionKind groups a jurisdiction's tax into the state or local total
+type JurisdictionKind string
+
+const (
+	JurisdictionState JurisdictionKind = "state"
+	JurisdictionLocal JurisdictionKind = "local"
+)
+
+// Jurisdiction is a named withholding rate, e.g. CA state, NYC, or Yonkers
+type Jurisdiction struct {
+	Name string           `json:"name"`
+	Kind JurisdictionKind `json:"kind"`
+	Rate float64          `json:"rate"`
+}
+
+// jurisdictionLines itemizes every configured jurisdiction of the given kind
+func (c *Calculator) jurisdictionLines(gain float64, kind JurisdictionKind) ([]TaxLine, float64) {
+	var lines []TaxLine
+	total := 0.0
+	for _, j := range c.config.TaxRates.Jurisdictions {
+		if j.Kind != kind {
+			continue
+		}
+		line := TaxLine{
+			Name:   j.Name,
+			Income: gain,
+			Rate:   j.Rate,
+			Amount: roundMoney(gain * j.Rate),
+		}
+		lines = append(lines, line)
+		total += line.Amount
+	}
+	return lines, total
+}
+
+// hasJurisdictions reports whether any jurisdiction of the given kind is configured
+func (c *Calculator) hasJurisdictions(kind JurisdictionKind) bool {
+	for _, j := range c.config.TaxRates.Jurisdictions {
+		if j.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// regionalTax computes state and local withholding on a gain. Residency periods take
+// precedence for state tax; named jurisdictions replace the flat State and LocalSDI rates.
+func (c *Calculator) regionalTax(gain float64, serviceStart, serviceEnd time.Time) (stateLines []TaxLine, stateTax float64, localLines []TaxLine, localTax float64) {
+	switch {
+	case len(c.config.Residency) > 0 && !serviceStart.IsZero() && !serviceEnd.IsZero():
+		stateLines, stateTax = c.residencyLines(gain, serviceStart, serviceEnd)
+	case c.hasJurisdictions(JurisdictionState):
+		stateLines, stateTax = c.jurisdictionLines(gain, JurisdictionState)
+	default:
+		stateTax = roundMoney(gain * c.config.TaxRates.State)
+	}
+
+	if c.hasJurisdictions(JurisdictionLocal) {
+		localLines, localTax = c.jurisdictionLines(gain, JurisdictionLocal)
+	} else {
+		localTax = roundMoney(gain * c.config.TaxRates.LocalSDI)
+	}
+
+	return stateLines, stateTax, localLines, localTax
+}
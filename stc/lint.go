@@ -0,0 +1,138 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Warning is a non-blocking note about a config value that is probably a
+// mistake, or about a result that needs a second look
+type Warning struct {
+	Code    WarningCode `json:"code,omitempty"` // Set on result warnings; see WarningCode
+	Field   string      `json:"field"`
+	Message string      `json:"message"`
+}
+
+func (w Warning) String() string {
+	return w.Field + ": " + w.Message
+}
+
+// Thresholds above which a setting is flagged
+const (
+	maxPlausibleRate       = 1.0  // Rates are fractions; 9.3 was almost certainly meant as 0.093
+	maxPlausibleTotalRate  = 0.6  // Combined withholding above 60% is unheard of
+	maxPlausibleCommission = 0.10 // Dollars per share sold
+	maxPlausibleHaircut    = 0.2  // Brokers assume a fill a few percent below the quote
+)
+
+// ConfigLint flags settings that are valid but suspicious, such as a state
+// rate entered as a percentage or a minimum fee below the flat fee
+func ConfigLint(cfg Config) []Warning {
+	var warnings []Warning
+	add := func(field, format string, args ...any) {
+		warnings = append(warnings, Warning{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	type fieldRate struct {
+		field string
+		rate  float64
+	}
+	rates := []fieldRate{ // The five flat rates come first
+		{"Federal", cfg.TaxRates.Federal},
+		{"Medicare", cfg.TaxRates.Medicare},
+		{"Social Sec", cfg.TaxRates.SocialSec},
+		{"State", cfg.TaxRates.State},
+		{"Local/SDI", cfg.TaxRates.LocalSDI},
+		{"Medicare Surtax", cfg.TaxRates.MedicareSurtax},
+	}
+	for _, j := range cfg.TaxRates.Jurisdictions {
+		rates = append(rates, fieldRate{j.Name, j.Rate})
+	}
+	for _, p := range cfg.Residency {
+		rates = append(rates, fieldRate{"Residency " + p.State, p.Rate})
+	}
+	for _, b := range cfg.FederalBrackets {
+		rates = append(rates, fieldRate{fmt.Sprintf("Bracket from $%.0f", b.Threshold), b.Rate})
+	}
+
+	// Residency periods are sequential, so only the flat rates are summed
+	total, percentages := 0.0, false
+	for i, r := range rates {
+		switch {
+		case r.rate < 0:
+			add(r.field, "rate is negative")
+		case r.rate > maxPlausibleRate:
+			add(r.field, "%g looks like a percentage; rates are fractions, e.g. %g", r.rate, r.rate/100)
+			percentages = true
+		}
+		if i < 5 {
+			total += r.rate
+		}
+	}
+	// Only worth mentioning when no single rate already explains it
+	if total > maxPlausibleTotalRate && !percentages {
+		add("Taxes", "combined rates withhold %.0f%% of the gain", total*100)
+	}
+
+	fees := cfg.BrokerFees
+	switch {
+	case fees.CommissionRate < 0:
+		add("Commission Rate", "commission is negative")
+	case fees.CommissionRate > maxPlausibleCommission:
+		add("Commission Rate", "commission is charged per share sold; %g means $%.2f on every share", fees.CommissionRate, fees.CommissionRate)
+	}
+	if fees.MinimumFee < 0 || fees.FlatFee < 0 {
+		add("Broker Fees", "fees are negative")
+	}
+	if fees.MinimumFee > 0 && fees.MinimumFee < fees.FlatFee {
+		add("Minimum Fee", "minimum fee $%.2f is below the $%.2f processing fee", fees.MinimumFee, fees.FlatFee)
+	}
+	for i, t := range fees.CommissionTiers {
+		switch {
+		case t.UpTo < 0 || t.Flat < 0 || t.Rate < 0:
+			add("Commission Tiers", "tier %d is negative", i+1)
+		case t.Rate > maxPlausibleRate:
+			add("Commission Tiers", "tier %d rate %g looks like a percentage; rates are fractions, e.g. %g", i+1, t.Rate, t.Rate/100)
+		}
+		if i == len(fees.CommissionTiers)-1 {
+			break
+		}
+		next := fees.CommissionTiers[i+1]
+		if t.UpTo == 0 || (next.UpTo != 0 && next.UpTo <= t.UpTo) {
+			add("Commission Tiers", "tiers are matched in order, so limits must increase; tier %d is never reached", i+2)
+			continue
+		}
+		// A trade just over the limit should not cost less than one at it
+		below, above := t.Flat+t.Rate*t.UpTo, next.Flat+next.Rate*t.UpTo
+		if above < below {
+			add("Commission Tiers", "commission drops from $%.2f to $%.2f above $%.2f, so selling more can cost less", below, above, t.UpTo)
+		}
+	}
+	if fees.ExtraShares < 0 || fees.ExtraShares != math.Trunc(fees.ExtraShares) {
+		add("Extra Shares", "brokers sell whole extra shares; %g will be used as-is", fees.ExtraShares)
+	}
+	if cfg.CashTopUp && fees.ExtraShares > 0 {
+		add("Extra Shares", "ignored when the shortfall is paid in cash")
+	}
+	switch cfg.SharePolicy {
+	case "", ShareWhole, ShareFractional, ShareRoundNearest:
+	default:
+		add("Share Policy", "unknown policy %q; whole shares are sold", cfg.SharePolicy)
+	}
+	switch cfg.Solver {
+	case SolverIterative, SolverClosedForm:
+	default:
+		add("Solver", "unknown solver %q; the iterative solver is used", cfg.Solver)
+	}
+	if cfg.PriceHaircut > maxPlausibleHaircut && cfg.PriceHaircut < 1 {
+		add("Price Haircut", "%g sizes the sale %.0f%% below the quote; brokers assume a few percent, e.g. 0.05", cfg.PriceHaircut, cfg.PriceHaircut*100)
+	}
+	if cfg.TimeZone != "" {
+		if _, err := time.LoadLocation(cfg.TimeZone); err != nil {
+			add("Time Zone", "unknown zone %q; tax years use the local zone", cfg.TimeZone)
+		}
+	}
+
+	return warnings
+}
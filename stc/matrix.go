@@ -0,0 +1,31 @@
+package stc
+
+// MatrixResult holds the cross-product of a set of inputs and configs
+type MatrixResult struct {
+	Inputs  []Input
+	Configs []Config
+	Results [][]Result // Results[inputIdx][configIdx]
+}
+
+// Matrix calculates every input under every config, e.g. a range of FMVs
+// against a range of federal rates
+func Matrix(inputs []Input, configs []Config) MatrixResult {
+	calculators := make([]*Calculator, len(configs))
+	for j, cfg := range configs {
+		calculators[j] = NewCalculator(cfg)
+	}
+
+	results := make([][]Result, len(inputs))
+	for i, input := range inputs {
+		results[i] = make([]Result, len(configs))
+		for j, calc := range calculators {
+			results[i][j] = calc.Calculate(input)
+		}
+	}
+	return MatrixResult{Inputs: inputs, Configs: configs, Results: results}
+}
+
+// At returns the result for one input and config
+func (m MatrixResult) At(inputIdx, configIdx int) Result {
+	return m.Results[inputIdx][configIdx]
+}
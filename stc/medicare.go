@@ -0,0 +1,20 @@
+package stc
+
+import "math"
+
+// MedicareSurtaxThreshold is the year-to-date wage level above which the
+// Additional Medicare Tax applies. Employers must withhold above $200,000
+// regardless of filing status.
+const MedicareSurtaxThreshold = 200000
+
+// medicareSurtax applies TaxRates.MedicareSurtax to the part of gain that
+// pushes year-to-date wages above the threshold
+func (c *Calculator) medicareSurtax(gain, ytdWages float64) float64 {
+	rate := c.config.TaxRates.MedicareSurtax
+	if rate == 0 || gain <= 0 {
+		return 0
+	}
+	ytd := math.Max(ytdWages, 0)
+	above := math.Max(ytd+gain, MedicareSurtaxThreshold) - math.Max(ytd, MedicareSurtaxThreshold)
+	return roundMoney(above * rate)
+}
@@ -0,0 +1,72 @@
+package stc
+
+import "time"
+
+// Model component versions stamped on every result. Bump the matching entry
+// whenever a component's math changes so exported numbers stay interpretable.
+const (
+	SolverModelVersion   = "iterative/1"
+	FederalModelVersion  = "flat/1"
+	BracketModelVersion  = "brackets/1"
+	RegionalModelVersion = "jurisdictions/1"
+)
+
+// Metadata records the context a result was computed in, for later audit
+type Metadata struct {
+	TaxYear       int               `json:"taxYear"`
+	Country       string            `json:"country"`
+	Jurisdictions []string          `json:"jurisdictions"` // Every jurisdiction that produced a tax line
+	ModelVersions map[string]string `json:"modelVersions"`
+	ComputedAt    time.Time         `json:"computedAt"`
+	SharePolicy   SharePolicy       `json:"sharePolicy,omitempty"` // How the shares sold were rounded; blank is whole shares
+}
+
+// metadata builds the audit stamp for a calculation whose income was recognized at taxDate
+func (c *Calculator) metadata(taxDate time.Time, stateLines, localLines []TaxLine) Metadata {
+	now := time.Now()
+
+	year := c.config.TaxYear
+	if year == 0 {
+		year = c.config.TaxYearOf(now)
+		if !taxDate.IsZero() {
+			year = c.config.TaxYearOf(taxDate)
+		}
+	}
+
+	country := c.config.Country
+	if country == "" {
+		country = "US"
+	}
+
+	jurisdictions := []string{country + "-Federal"}
+	if len(stateLines) == 0 && c.config.TaxRates.State != 0 {
+		jurisdictions = append(jurisdictions, "State")
+	}
+	for _, l := range stateLines {
+		jurisdictions = append(jurisdictions, l.Name)
+	}
+	if len(localLines) == 0 && c.config.TaxRates.LocalSDI != 0 {
+		jurisdictions = append(jurisdictions, "Local/SDI")
+	}
+	for _, l := range localLines {
+		jurisdictions = append(jurisdictions, l.Name)
+	}
+
+	federal := FederalModelVersion
+	if c.config.TaxModel == TaxModelBrackets {
+		federal = BracketModelVersion
+	}
+
+	return Metadata{
+		TaxYear:       year,
+		Country:       country,
+		Jurisdictions: jurisdictions,
+		ModelVersions: map[string]string{
+			"solver":   SolverModelVersion,
+			"federal":  federal,
+			"regional": RegionalModelVersion,
+		},
+		ComputedAt:  now,
+		SharePolicy: c.config.SharePolicy,
+	}
+}
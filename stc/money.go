@@ -0,0 +1,213 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Money is a fixed-point decimal with six places, stored as an integer count
+// of millionths. Sums and products of Money are exact up to that precision,
+// so the solver's repeated cost sums do not drift the way float64 sums do.
+// Share counts and rates use the same type when they meet money in a product.
+type Money int64
+
+// moneyScale is the number of Money units in 1.0
+const moneyScale = 1_000_000
+
+// moneyPlaces is the number of decimal places Money holds
+const moneyPlaces = 6
+
+// errMoneyOverflow is the panic of a product too large for Money. The
+// checked calculations recover it as ErrTooLarge.
+var errMoneyOverflow = fmt.Errorf("stc: Money overflow: %w", ErrTooLarge)
+
+// NewMoney converts a float64, rounding to the nearest millionth
+func NewMoney(f float64) Money {
+	return Money(math.Round(f * moneyScale))
+}
+
+// ParseMoney parses a decimal string exactly, formatted as in exported
+// spreadsheets: "$1,234.50", "(25.00)" for negatives, or "-3". Digits past
+// the sixth decimal place are rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	clean := strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(clean, "(") && strings.HasSuffix(clean, ")") {
+		negative = true
+		clean = strings.TrimSpace(clean[1 : len(clean)-1])
+	}
+	if strings.HasPrefix(clean, "-") {
+		negative = !negative
+		clean = strings.TrimSpace(clean[1:])
+	}
+	clean = strings.TrimPrefix(clean, "$")
+	clean = strings.ReplaceAll(clean, ",", "")
+
+	whole, frac, _ := strings.Cut(clean, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	for _, part := range []string{whole, frac} {
+		if strings.Trim(part, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	roundUp := len(frac) > moneyPlaces && frac[moneyPlaces] >= '5'
+	if len(frac) > moneyPlaces {
+		frac = frac[:moneyPlaces]
+	}
+	frac += strings.Repeat("0", moneyPlaces-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > math.MaxInt64/moneyScale-1 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+
+	m := Money(w*moneyScale + f)
+	if roundUp {
+		m++
+	}
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// Float64 returns the value as a float64, for the float fields of results
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+// Mul multiplies two fixed-point values, rounding half away from zero
+func (m Money) Mul(n Money) Money {
+	negative := (m < 0) != (n < 0)
+	hi, lo := bits.Mul64(abs64(m), abs64(n))
+	// Adding half the scale before the division rounds the magnitude
+	lo, carry := bits.Add64(lo, moneyScale/2, 0)
+	hi += carry
+	if hi >= moneyScale {
+		panic(errMoneyOverflow)
+	}
+	q, _ := bits.Div64(hi, lo, moneyScale)
+	return signed(q, negative)
+}
+
+// MulFloat multiplies by a float64 factor such as a tax rate, which is first
+// rounded to six decimal places
+func (m Money) MulFloat(f float64) Money {
+	return m.Mul(NewMoney(f))
+}
+
+// CeilDiv returns the smallest whole number n with n×d ≥ m, for positive d.
+// It is the fewest whole shares at price d that cover an amount m.
+func (m Money) CeilDiv(d Money) Money {
+	if d <= 0 {
+		return 0
+	}
+	q := m / d
+	if m%d > 0 {
+		q++
+	}
+	return q * moneyScale
+}
+
+// DivUp divides by a positive d, rounding up to the millionth. It is the
+// fewest fractional shares at price d that cover an amount m.
+func (m Money) DivUp(d Money) Money {
+	if d <= 0 || m <= 0 {
+		return 0
+	}
+	hi, lo := bits.Mul64(uint64(m), moneyScale)
+	// Adding d−1 before the division rounds up
+	lo, carry := bits.Add64(lo, uint64(d)-1, 0)
+	hi += carry
+	if hi >= uint64(d) {
+		panic(errMoneyOverflow)
+	}
+	q, _ := bits.Div64(hi, lo, uint64(d))
+	return signed(q, false)
+}
+
+// RoundDiv returns the whole number nearest m/d, rounding halves up, for positive d
+func (m Money) RoundDiv(d Money) Money {
+	if d <= 0 {
+		return 0
+	}
+	q, r := m/d, m%d
+	if r*2 >= d {
+		q++
+	}
+	return q * moneyScale
+}
+
+// Round rounds to the given number of decimal places, half away from zero
+func (m Money) Round(places int) Money {
+	if places >= moneyPlaces {
+		return m
+	}
+	unit := Money(math.Pow10(moneyPlaces - places))
+	q, r := m/unit, m%unit
+	switch {
+	case r*2 >= unit:
+		q++
+	case r*2 <= -unit:
+		q--
+	}
+	return q * unit
+}
+
+// Max returns the larger of m and n
+func (m Money) Max(n Money) Money {
+	if n > m {
+		return n
+	}
+	return m
+}
+
+// Format renders the value with the given number of decimal places
+func (m Money) Format(places int) string {
+	r := m.Round(places)
+	sign := ""
+	if r < 0 {
+		sign, r = "-", -r
+	}
+	s := fmt.Sprintf("%s%d", sign, r/moneyScale)
+	if places > 0 {
+		frac := fmt.Sprintf("%0*d", moneyPlaces, r%moneyScale)
+		s += "." + frac[:min(places, moneyPlaces)] + strings.Repeat("0", max(places-moneyPlaces, 0))
+	}
+	return s
+}
+
+func (m Money) String() string {
+	return m.Format(2)
+}
+
+// signed applies a sign to a magnitude, panicking when the result falls
+// outside MinInt64 to MaxInt64 millionths
+func signed(q uint64, negative bool) Money {
+	if negative {
+		if q > 1<<63 {
+			panic(errMoneyOverflow)
+		}
+		return Money(-q)
+	}
+	if q > math.MaxInt64 {
+		panic(errMoneyOverflow)
+	}
+	return Money(q)
+}
+
+func abs64(m Money) uint64 {
+	if m < 0 {
+		return uint64(-m)
+	}
+	return uint64(m)
+}
@@ -0,0 +1,120 @@
+package stc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// overflows reports whether f panics with the Money overflow
+func overflows(f func()) (overflow bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			overflow = ok && errors.Is(err, ErrTooLarge)
+		}
+	}()
+	f()
+	return false
+}
+
+func TestMoneyMul(t *testing.T) {
+	tests := []struct {
+		name     string
+		m, n     Money
+		want     Money
+		overflow bool
+	}{
+		{"whole", 2 * moneyScale, 3 * moneyScale, 6 * moneyScale, false},
+		{"half rounds up", 1, moneyScale / 2, 1, false},
+		{"below half rounds down", 1, moneyScale/2 - 1, 0, false},
+		{"negative half rounds away", -1, moneyScale / 2, -1, false},
+		{"both negative", -2 * moneyScale, -moneyScale / 4, moneyScale / 2, false},
+		{"max by one", math.MaxInt64, moneyScale, math.MaxInt64, false},
+		{"min by one", math.MinInt64, moneyScale, math.MinInt64, false},
+		{"max negated", math.MaxInt64, -moneyScale, -math.MaxInt64, false},
+		{"just past max", math.MaxInt64/2 + 1, 2 * moneyScale, 0, true},
+		{"min negated", math.MinInt64, -moneyScale, 0, true},
+		{"rounds past max", math.MaxInt64, moneyScale + 1, 0, true},
+		{"far past max", math.MaxInt64, math.MaxInt64, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			if overflow := overflows(func() { got = tt.m.Mul(tt.n) }); overflow != tt.overflow {
+				t.Fatalf("%d.Mul(%d) overflow = %v, want %v", tt.m, tt.n, overflow, tt.overflow)
+			}
+			if !tt.overflow && got != tt.want {
+				t.Errorf("%d.Mul(%d) = %d, want %d", tt.m, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyDivUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		m, d     Money
+		want     Money
+		overflow bool
+	}{
+		{"exact", 6 * moneyScale, 3 * moneyScale, 2 * moneyScale, false},
+		{"rounds up", moneyScale, 3 * moneyScale, 333334, false},
+		{"zero amount", 0, moneyScale, 0, false},
+		{"negative amount", -moneyScale, moneyScale, 0, false},
+		{"zero divisor", moneyScale, 0, 0, false},
+		{"max by one", math.MaxInt64, moneyScale, math.MaxInt64, false},
+		{"max by just under one", math.MaxInt64, moneyScale - 1, 0, true},
+		{"by a millionth", math.MaxInt64 / moneyScale, 1, math.MaxInt64 / moneyScale * moneyScale, false},
+		{"past max by a millionth", math.MaxInt64/moneyScale + 1, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			if overflow := overflows(func() { got = tt.m.DivUp(tt.d) }); overflow != tt.overflow {
+				t.Fatalf("%d.DivUp(%d) overflow = %v, want %v", tt.m, tt.d, overflow, tt.overflow)
+			}
+			if !tt.overflow && got != tt.want {
+				t.Errorf("%d.DivUp(%d) = %d, want %d", tt.m, tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"1234.5", 1234_500000, false},
+		{"$1,234.50", 1234_500000, false},
+		{"(25.00)", -25 * moneyScale, false},
+		{"-3", -3 * moneyScale, false},
+		{".5", moneyScale / 2, false},
+		{"0.0000005", 1, false},
+		{"0.0000004999", 0, false},
+		{"-0.0000005", -1, false},
+		{"61.1000000001", 61_100000, false},
+		{"1.9999995", 2 * moneyScale, false},
+		{"9223372036853.999999", 9223372036853_999999, false},
+		{"9223372036854", 0, true},
+		{"99999999999999999999", 0, true},
+		{"", 0, true},
+		{"$", 0, true},
+		{"1e6", 0, true},
+		{"1.2.3", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMoney(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package stc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LotKind says how a lot in a multi-lot event is taxed
+type LotKind string
+
+const (
+	LotOption LotKind = "option" // Nonqualified options: the spread over the strike is income
+	LotRSU    LotKind = "rsu"    // Restricted stock: the whole value is income
+)
+
+// EventLot is one grant exercised or released in a multi-lot event
+type EventLot struct {
+	Label         string  `json:"label,omitempty"`
+	Kind          LotKind `json:"kind"`
+	Shares        float64 `json:"shares"`
+	ExercisePrice float64 `json:"exercisePrice,omitempty"` // Options only
+	GrossUp       bool    `json:"grossUp,omitempty"`       // The employer grosses up this lot's tax; implied for every lot by the GrossUp mode
+}
+
+// MultiLotInput is several lots exercised or released together at one FMV
+// and settled with a single sale
+type MultiLotInput struct {
+	Lots                 []EventLot `json:"lots"`
+	FMV                  float64    `json:"fmv"`
+	SalePrice            float64    `json:"salePrice,omitempty"` // Price the shares sold fetch; 0 sells at FMV
+	Mode                 SaleMode   `json:"mode,omitempty"`
+	YTDIncome            float64    `json:"ytdIncome,omitempty"`
+	YTDWages             float64    `json:"ytdWages,omitempty"`
+	YTDSupplementalWages float64    `json:"ytdSupplementalWages,omitempty"`
+	BrokerYTD            BrokerYTD  `json:"brokerYtd,omitzero"`
+
+	// Service period (grant to vest) used to apportion income across Config.Residency
+	ServiceStart time.Time `json:"serviceStart,omitzero"`
+	ServiceEnd   time.Time `json:"serviceEnd,omitzero"`
+
+	Dates TransactionDates `json:"dates,omitzero"`
+}
+
+// LotAttribution is one lot's share of a multi-lot result. Taxes follow the
+// lot's income, shares sold follow what the lot must pay for, and fees
+// follow the shares sold.
+type LotAttribution struct {
+	EventLot
+	OptionCost  float64 `json:"optionCost"`
+	TaxableGain float64 `json:"taxableGain"`       // Including the lot's gross-up
+	GrossUp     float64 `json:"grossUp,omitempty"` // Employer cash toward the lot's tax
+	Tax         float64 `json:"tax"`
+	Fees        float64 `json:"fees"`
+	SharesSold  float64 `json:"sharesSold"`
+	NetShares   float64 `json:"netShares"`
+}
+
+// MultiLotResult is the combined calculation of a multi-lot event, with
+// each lot's share of it
+type MultiLotResult struct {
+	Result
+	Lots []LotAttribution `json:"lots"`
+}
+
+// CalculateMultiLot solves one sale for several lots at once. The lots'
+// option costs and income are added up and settled like a single exercise,
+// so minimum fees and share rounding apply once. ExercisePrice in the
+// result is the average over every share, counting RSUs at zero.
+func (c *Calculator) CalculateMultiLot(input MultiLotInput) MultiLotResult {
+	c = c.snapshot()
+	agg := Input{
+		FMV:                  input.FMV,
+		SalePrice:            input.SalePrice,
+		Mode:                 input.Mode,
+		YTDIncome:            input.YTDIncome,
+		YTDWages:             input.YTDWages,
+		YTDSupplementalWages: input.YTDSupplementalWages,
+		BrokerYTD:            input.BrokerYTD,
+		ServiceStart:         input.ServiceStart,
+		ServiceEnd:           input.ServiceEnd,
+		Dates:                input.Dates,
+	}
+
+	costs := make([]Money, len(input.Lots))
+	gains := make([]Money, len(input.Lots))
+	var shares, optionCost, gain Money
+	var warnings []Warning
+	for i, l := range input.Lots {
+		shares += NewMoney(l.Shares)
+		if l.Kind == LotRSU {
+			gains[i] = NewMoney(roundMoney(l.Shares * input.FMV))
+		} else {
+			costs[i] = NewMoney(roundMoney(l.Shares * l.ExercisePrice))
+			label := l.Label
+			if label == "" {
+				label = fmt.Sprintf("Lot %d", i+1)
+			}
+			if w, ok := underwaterWarning(label, l.ExercisePrice, input.FMV); ok {
+				warnings = append(warnings, w)
+			}
+			gains[i] = NewMoney(roundMoney((input.FMV - l.ExercisePrice) * l.Shares))
+		}
+		optionCost += costs[i]
+		gain += gains[i]
+	}
+	agg.ExercisedShares = shares.Float64()
+
+	result := Result{
+		ExercisedShares: agg.ExercisedShares,
+		FMV:             input.FMV,
+		SalePrice:       input.SalePrice,
+		Mode:            input.Mode,
+		OptionCost:      optionCost.Float64(),
+		TaxableGain:     gain.Float64(),
+		Warnings:        warnings,
+	}
+	if shares > 0 {
+		result.ExercisePrice = roundMoney(optionCost.Float64() / shares.Float64())
+	}
+
+	// Grossed-up lots are covered together: the employer pays the tax they
+	// and the gross-up add on top of the other lots' income
+	covered := make([]Money, len(input.Lots))
+	var coveredGain Money
+	for i, l := range input.Lots {
+		if l.GrossUp || input.Mode == GrossUp {
+			covered[i] = gains[i]
+			coveredGain += gains[i]
+		}
+	}
+	if coveredGain > 0 {
+		result.GrossUp = grossUp((gain - coveredGain).Float64(), coveredGain.Float64(), func(g float64) float64 {
+			return c.withheld(g, agg.YTDIncome, agg.YTDWages, agg.YTDSupplementalWages, agg.ServiceStart, agg.ServiceEnd)
+		})
+		result.TaxableGain = roundMoney(result.TaxableGain + result.GrossUp)
+	}
+	result = c.settle(result, agg, 0)
+
+	// Each lot sells toward its own option cost and the tax its gross-up,
+	// if any, leaves
+	grossUps := apportion(NewMoney(result.GrossUp), covered, 2)
+	for i := range gains {
+		gains[i] += grossUps[i]
+	}
+	taxes := apportion(NewMoney(result.TotalTax), gains, 2)
+	needs := make([]Money, len(input.Lots))
+	for i := range needs {
+		needs[i] = (costs[i] + taxes[i] - grossUps[i]).Max(0)
+	}
+	sold := apportion(NewMoney(result.SharesToSell), needs, moneyPlaces)
+	fees := apportion(NewMoney(result.BrokerFees+result.SECFee+result.TAF), sold, 2)
+
+	out := MultiLotResult{Result: result, Lots: make([]LotAttribution, len(input.Lots))}
+	for i, l := range input.Lots {
+		out.Lots[i] = LotAttribution{
+			EventLot:    l,
+			OptionCost:  costs[i].Float64(),
+			TaxableGain: gains[i].Float64(),
+			GrossUp:     grossUps[i].Float64(),
+			Tax:         taxes[i].Float64(),
+			Fees:        fees[i].Float64(),
+			SharesSold:  sold[i].Float64(),
+			NetShares:   (NewMoney(l.Shares) - sold[i]).Float64(),
+		}
+	}
+	return out
+}
+
+// apportion splits total across weights in proportion, rounded to places,
+// with the last weighted share taking the rounding remainder so the parts
+// add up to total. Zero weights get nothing.
+func apportion(total Money, weights []Money, places int) []Money {
+	parts := make([]Money, len(weights))
+	var sum Money
+	last := -1
+	for i, w := range weights {
+		if w > 0 {
+			sum += w
+			last = i
+		}
+	}
+	if sum == 0 {
+		return parts
+	}
+	var given Money
+	for i, w := range weights {
+		if w <= 0 || i == last {
+			continue
+		}
+		parts[i] = NewMoney(total.Float64() * w.Float64() / sum.Float64()).Round(places)
+		given += parts[i]
+	}
+	parts[last] = total - given
+	return parts
+}
+
+// ToJSON converts the multi-lot result to JSON string
+func (r MultiLotResult) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
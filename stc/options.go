@@ -0,0 +1,151 @@
+package stc
+
+import "slices"
+
+// Option adjusts the configuration of a Calculator built by New or With
+type Option func(*Config)
+
+// WithConfig replaces the whole configuration; later options adjust it
+func WithConfig(cfg Config) Option {
+	return func(c *Config) { *c = cfg.clone() }
+}
+
+// WithTaxRates sets the withholding rates
+func WithTaxRates(r TaxRates) Option {
+	return func(c *Config) {
+		c.TaxRates = r
+		c.TaxRates.Jurisdictions = slices.Clone(r.Jurisdictions)
+	}
+}
+
+// WithBrokerFees sets the commission and fee schedule
+func WithBrokerFees(f BrokerFees) Option {
+	return func(c *Config) {
+		c.BrokerFees = f
+		c.BrokerFees.CommissionTiers = slices.Clone(f.CommissionTiers)
+		c.BrokerFees.VolumeTiers = slices.Clone(f.VolumeTiers)
+	}
+}
+
+// WithBrokerPreset sets the fee schedule of a named preset; an unknown
+// preset leaves the fees unchanged
+func WithBrokerPreset(p BrokerPreset) Option {
+	return func(c *Config) {
+		if fees, ok := p.Fees(); ok {
+			c.BrokerFees = fees
+		}
+	}
+}
+
+// WithSharePolicy sets how the shares sold are rounded
+func WithSharePolicy(p SharePolicy) Option {
+	return func(c *Config) { c.SharePolicy = p }
+}
+
+// WithSolver selects how the shares to sell are found
+func WithSolver(s Solver) Option {
+	return func(c *Config) { c.Solver = s }
+}
+
+// WithDecimalCore is kept for callers written against the float64 solver.
+// Every calculation now works in fixed-point Money, so it changes nothing.
+func WithDecimalCore() Option {
+	return func(*Config) {}
+}
+
+// WithPriceHaircut sizes sales as if they filled h below the quoted price
+func WithPriceHaircut(h float64) Option {
+	return func(c *Config) { c.PriceHaircut = h }
+}
+
+// WithCashTopUp pays the final rounding shortfall in cash instead of shares
+func WithCashTopUp(on bool) Option {
+	return func(c *Config) { c.CashTopUp = on }
+}
+
+// WithResidency apportions state tax across part-year residency periods
+func WithResidency(periods ...ResidencyPeriod) Option {
+	return func(c *Config) { c.Residency = slices.Clone(periods) }
+}
+
+// WithTaxModel selects flat or bracket-based federal tax. Brackets use
+// DefaultFederalBrackets unless WithFederalBrackets supplies a schedule.
+func WithTaxModel(m TaxModel) Option {
+	return func(c *Config) { c.TaxModel = m }
+}
+
+// WithFederalBrackets selects bracket-based federal tax with the given
+// schedule; an empty schedule uses DefaultFederalBrackets
+func WithFederalBrackets(schedule BracketSchedule) Option {
+	return func(c *Config) {
+		c.TaxModel = TaxModelBrackets
+		c.FederalBrackets = slices.Clone(schedule)
+	}
+}
+
+// WithRegulatoryFees sets the SEC and FINRA fee rates; the zero value charges none
+func WithRegulatoryFees(f RegulatoryFees) Option {
+	return func(c *Config) { c.RegulatoryFees = f }
+}
+
+// WithTaxYear fixes the tax year instead of deriving it from the service dates
+func WithTaxYear(year int) Option {
+	return func(c *Config) { c.TaxYear = year }
+}
+
+// WithTimeZone sets the IANA zone of the tax home, e.g. "America/New_York"
+func WithTimeZone(zone string) Option {
+	return func(c *Config) { c.TimeZone = zone }
+}
+
+// New builds a calculator from DefaultConfig, then applies opts in order
+func New(opts ...Option) *Calculator {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Calculator{config: cfg}
+}
+
+// With returns a new calculator with opts applied on top of this one's
+// configuration. The receiver is not changed.
+func (c *Calculator) With(opts ...Option) *Calculator {
+	cfg := c.GetConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Calculator{config: cfg}
+}
+
+// DefaultConfig is the configuration of NewDefaultCalculator: 22%
+// supplemental federal withholding, payroll taxes up to the Social Security
+// wage base, no state tax, a 3% commission with a $25 minimum, and the
+// current regulatory fees
+func DefaultConfig() Config {
+	return Config{
+		TaxRates: TaxRates{
+			Federal:   0.22,
+			Medicare:  0.0145,
+			SocialSec: 0.062,
+			State:     0.0,
+			LocalSDI:  0.0,
+		},
+		BrokerFees: BrokerFees{
+			CommissionRate: 0.03,
+			MinimumFee:     25.0,
+			FlatFee:        0.0,
+		},
+		RegulatoryFees:    CurrentRegulatoryFees,
+		SocialSecWageBase: SocialSecWageBase,
+	}
+}
+
+// clone copies the configuration so the copy shares no slices with it
+func (c Config) clone() Config {
+	c.TaxRates.Jurisdictions = slices.Clone(c.TaxRates.Jurisdictions)
+	c.BrokerFees.CommissionTiers = slices.Clone(c.BrokerFees.CommissionTiers)
+	c.BrokerFees.VolumeTiers = slices.Clone(c.BrokerFees.VolumeTiers)
+	c.Residency = slices.Clone(c.Residency)
+	c.FederalBrackets = slices.Clone(c.FederalBrackets)
+	return c
+}
@@ -0,0 +1,111 @@
+package stc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrOverrideDenied is returned when overrides touch fields the policy refuses
+var ErrOverrideDenied = errors.New("config override not allowed")
+
+// OverridePolicy limits which config fields a caller may override. Fields
+// are named by their JSON path, e.g. "taxRates.state", and a path covers
+// every field beneath it, so "brokerFees" covers "brokerFees.minimumFee".
+type OverridePolicy struct {
+	Allow []string `json:"allow,omitempty"` // Only these paths may change; empty allows every path
+	Deny  []string `json:"deny,omitempty"`  // These paths may never change, even when allowed
+}
+
+// permits reports whether the policy lets path change
+func (p OverridePolicy) permits(path string) bool {
+	covers := func(prefix string) bool {
+		return path == prefix || strings.HasPrefix(path, prefix+".")
+	}
+	if slices.ContainsFunc(p.Deny, covers) {
+		return false
+	}
+	return len(p.Allow) == 0 || slices.ContainsFunc(p.Allow, covers)
+}
+
+// Override merges a partial config in JSON over c, e.g. a tenant default
+// with {"taxRates":{"state":0.0685}} for one employee. Objects merge field
+// by field; lists and values replace what they override. Every field the
+// overrides set must be permitted by policy, or ErrOverrideDenied is
+// returned naming them. A merged config that fails Validate is rejected.
+// c is not changed.
+func (c Config) Override(overrides []byte, policy OverridePolicy) (Config, error) {
+	var patch map[string]any
+	if err := json.Unmarshal(overrides, &patch); err != nil {
+		return Config{}, fmt.Errorf("invalid config overrides: %w", err)
+	}
+	var denied []string
+	for _, path := range overridePaths("", patch) {
+		if !policy.permits(path) {
+			denied = append(denied, path)
+		}
+	}
+	if len(denied) > 0 {
+		slices.Sort(denied)
+		return Config{}, fmt.Errorf("%w: %s", ErrOverrideDenied, strings.Join(denied, ", "))
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var base map[string]any
+	if err := json.Unmarshal(data, &base); err != nil {
+		return Config{}, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if data, err = json.Marshal(mergeJSON(base, patch)); err != nil {
+		return Config{}, fmt.Errorf("failed to merge config overrides: %w", err)
+	}
+
+	// Decoding strictly rejects misspelled fields instead of ignoring them
+	var merged Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&merged); err != nil {
+		return Config{}, fmt.Errorf("invalid config overrides: %w", err)
+	}
+	if err := merged.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config overrides: %w", err)
+	}
+	return merged, nil
+}
+
+// overridePaths lists the dotted path of every value a patch sets. Objects
+// are descended into, so each path is a value the patch replaces.
+func overridePaths(prefix string, patch map[string]any) []string {
+	var paths []string
+	for key, v := range patch {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if obj, ok := v.(map[string]any); ok && len(obj) > 0 {
+			paths = append(paths, overridePaths(path, obj)...)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// mergeJSON applies patch over base, merging nested objects
+func mergeJSON(base, patch map[string]any) map[string]any {
+	for key, v := range patch {
+		if obj, ok := v.(map[string]any); ok {
+			if sub, ok := base[key].(map[string]any); ok {
+				base[key] = mergeJSON(sub, obj)
+				continue
+			}
+		}
+		base[key] = v
+	}
+	return base
+}
@@ -0,0 +1,212 @@
+package stc
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// PaystubInput is one pay period in which regular wages are paid alongside
+// an RSU release, as payroll reports both on a single paystub
+type PaystubInput struct {
+	RegularWages float64 `json:"regularWages"` // Gross regular pay for the period
+
+	// Income tax withheld from the regular pay, as on a paystub without a
+	// release. It depends on the W-4, which is not modeled.
+	RegularFederal float64 `json:"regularFederal,omitempty"`
+	RegularState   float64 `json:"regularState,omitempty"`
+	RegularLocal   float64 `json:"regularLocal,omitempty"`
+
+	Release RSUInput `json:"release"` // YTD amounts are as of the start of the period
+
+	// Split spreads the release's withholding over this period and the next
+	Split *PaystubSplit `json:"split,omitempty"`
+}
+
+// PaystubSplit is a payroll that reports part of a release on one paystub
+// and the rest on the next, withholding each part in its own period
+type PaystubSplit struct {
+	FirstShare float64 `json:"firstShare"` // Fraction of the release on the first paystub, e.g. 0.5
+
+	// The second period's regular pay and the income tax withheld from it
+	RegularWages   float64 `json:"regularWages"`
+	RegularFederal float64 `json:"regularFederal,omitempty"`
+	RegularState   float64 `json:"regularState,omitempty"`
+	RegularLocal   float64 `json:"regularLocal,omitempty"`
+}
+
+// PaystubColumn is the amounts on one version of the paystub
+type PaystubColumn struct {
+	RegularWages float64 `json:"regularWages"`
+	EquityIncome float64 `json:"equityIncome"`      // Value of the shares released
+	GrossUp      float64 `json:"grossUp,omitempty"` // Employer cash toward the tax on the release
+	Gross        float64 `json:"gross"`
+
+	EquityShares float64 `json:"equityShares,omitempty"` // Shares released on this paystub
+
+	FederalTax     float64 `json:"federalTax"`
+	SocialSecTax   float64 `json:"socialSecTax"`
+	MedicareTax    float64 `json:"medicareTax"`
+	MedicareSurtax float64 `json:"medicareSurtax,omitempty"`
+	StateTax       float64 `json:"stateTax"`
+	LocalSDITax    float64 `json:"localSdiTax"`
+	TotalTax       float64 `json:"totalTax"`
+
+	// The shares are not paid in cash, so payroll takes their value back out,
+	// then credits the tax the shares sold or withheld paid
+	StockOffset  float64 `json:"stockOffset"`
+	SharesCredit float64 `json:"sharesCredit"`
+	CashTopUp    float64 `json:"cashTopUp,omitempty"` // Tax the shares did not cover, taken from pay
+
+	NetPay float64 `json:"netPay"`
+}
+
+// PaystubResult shows how a release changes a paystub: the period without
+// it, the period with it, and the release's own calculation
+type PaystubResult struct {
+	WithoutRelease PaystubColumn `json:"withoutRelease"`
+	WithRelease    PaystubColumn `json:"withRelease"`
+	Release        RSUResult     `json:"release"`
+
+	// The next pay period, when the release's withholding is split
+	SecondWithoutRelease *PaystubColumn `json:"secondWithoutRelease,omitempty"`
+	SecondWithRelease    *PaystubColumn `json:"secondWithRelease,omitempty"`
+}
+
+// Paystub reconstructs a paystub with a release in it. Regular Social
+// Security and Medicare come from the configured rates; regular income tax
+// is as entered. The release is taxed after the period's regular pay, so it
+// sees that pay in its year-to-date totals. With a Split, each period
+// withholds on its part of the release, Social Security and Medicare
+// included, after everything paid before it.
+func (c *Calculator) Paystub(in PaystubInput) PaystubResult {
+	c = c.snapshot()
+	rel := in.Release
+	regular := c.regularColumn(in.RegularWages, in.RegularFederal, in.RegularState, in.RegularLocal, rel.YTDWages)
+
+	release := rel
+	release.YTDIncome += in.RegularWages
+	release.YTDWages += in.RegularWages
+	r := c.CalculateRSU(release)
+
+	// The gross-up pays its share of the tax in cash, through the gross; the
+	// shares cover the rest but any top-up
+	due := math.Max(r.TotalTax-r.GrossUp, 0)
+	covered := roundMoney(due - math.Min(r.CashTopUp, due))
+	result := PaystubResult{WithoutRelease: regular, Release: r}
+	if in.Split == nil {
+		result.WithRelease, _ = withRelease(regular, releasePart(r), covered)
+		return result
+	}
+
+	// Each paystub reports its part of the shares, and of the gross-up
+	sp := in.Split
+	released := NewMoney(rel.released())
+	firstShares := NewMoney(released.Float64() * sp.FirstShare)
+	firstGain := roundMoney(firstShares.Float64() * rel.VestPrice)
+	firstGrossUp := roundMoney(r.GrossUp * sp.FirstShare)
+	part := c.releasePartOf(firstShares.Float64(), roundMoney(firstGain+firstGrossUp), firstGrossUp,
+		release.YTDIncome, release.YTDWages, release.YTDSupplementalWages, rel)
+	result.WithRelease, covered = withRelease(regular, part, covered)
+
+	// The second period follows the first period's regular pay and, with the
+	// release, its part too
+	ytdWages := release.YTDWages
+	second := c.regularColumn(sp.RegularWages, sp.RegularFederal, sp.RegularState, sp.RegularLocal, ytdWages)
+	ytdWages += part.Gross
+	secondRegular := c.regularColumn(sp.RegularWages, sp.RegularFederal, sp.RegularState, sp.RegularLocal, ytdWages)
+	ytdIncome := release.YTDIncome + part.Gross + sp.RegularWages
+	part = c.releasePartOf((released - firstShares).Float64(), roundMoney(r.TaxableGain-part.Gross), roundMoney(r.GrossUp-firstGrossUp),
+		ytdIncome, ytdWages+sp.RegularWages, rel.YTDSupplementalWages+part.Gross, rel)
+	secondWith, _ := withRelease(secondRegular, part, covered)
+	result.SecondWithoutRelease, result.SecondWithRelease = &second, &secondWith
+	return result
+}
+
+// regularColumn is a paystub with regular pay alone; ytdWages are the
+// Medicare wages paid before it
+func (c *Calculator) regularColumn(wages, federal, state, local, ytdWages float64) PaystubColumn {
+	col := PaystubColumn{
+		RegularWages:   wages,
+		Gross:          wages,
+		FederalTax:     federal,
+		SocialSecTax:   c.socialSecTax(wages, ytdWages),
+		MedicareTax:    roundMoney(wages * c.config.TaxRates.Medicare),
+		MedicareSurtax: c.medicareSurtax(wages, ytdWages),
+		StateTax:       state,
+		LocalSDITax:    local,
+	}
+	col.TotalTax = sumMoney(col.FederalTax, col.SocialSecTax, col.MedicareTax, col.MedicareSurtax,
+		col.StateTax, col.LocalSDITax)
+	col.NetPay = roundMoney(col.Gross - col.TotalTax)
+	return col
+}
+
+// releasePart is the whole release's income and withholding as a column
+func releasePart(r RSUResult) PaystubColumn {
+	return PaystubColumn{
+		EquityIncome:   roundMoney(r.TaxableGain - r.GrossUp),
+		GrossUp:        r.GrossUp,
+		EquityShares:   r.SharesReleased + r.DividendEquivalentShares,
+		Gross:          r.TaxableGain,
+		FederalTax:     r.FederalTax,
+		SocialSecTax:   r.SocialSecTax,
+		MedicareTax:    r.MedicareTax,
+		MedicareSurtax: r.MedicareSurtax,
+		StateTax:       r.StateTax,
+		LocalSDITax:    r.LocalSDITax,
+		TotalTax:       r.TotalTax,
+	}
+}
+
+// releasePartOf withholds on gain, the part of a release paid in shares
+// plus grossUp, after the year-to-date amounts given
+func (c *Calculator) releasePartOf(shares, gain, grossUp, ytdIncome, ytdWages, ytdSupplemental float64, in RSUInput) PaystubColumn {
+	part := PaystubColumn{
+		EquityIncome:   roundMoney(gain - grossUp),
+		GrossUp:        grossUp,
+		EquityShares:   shares,
+		Gross:          gain,
+		FederalTax:     c.federalTax(gain, ytdIncome, ytdSupplemental),
+		SocialSecTax:   c.socialSecTax(gain, ytdWages),
+		MedicareTax:    roundMoney(gain * c.config.TaxRates.Medicare),
+		MedicareSurtax: c.medicareSurtax(gain, ytdWages),
+	}
+	_, part.StateTax, _, part.LocalSDITax = c.regionalTax(gain, in.ServiceStart, in.ServiceEnd)
+	part.TotalTax = sumMoney(part.FederalTax, part.SocialSecTax, part.MedicareTax, part.MedicareSurtax,
+		part.StateTax, part.LocalSDITax)
+	return part
+}
+
+// withRelease adds part of a release to a regular paystub. The shares
+// credit up to covered of the tax not grossed up; the rest is a cash top-up
+// from this pay. It returns the credit still left for a later period.
+func withRelease(regular, part PaystubColumn, covered float64) (PaystubColumn, float64) {
+	with := regular
+	with.EquityIncome = part.EquityIncome
+	with.GrossUp = part.GrossUp
+	with.EquityShares = part.EquityShares
+	with.Gross = sumMoney(regular.Gross, part.Gross)
+	with.FederalTax = sumMoney(regular.FederalTax, part.FederalTax)
+	with.SocialSecTax = sumMoney(regular.SocialSecTax, part.SocialSecTax)
+	with.MedicareTax = sumMoney(regular.MedicareTax, part.MedicareTax)
+	with.MedicareSurtax = sumMoney(regular.MedicareSurtax, part.MedicareSurtax)
+	with.StateTax = sumMoney(regular.StateTax, part.StateTax)
+	with.LocalSDITax = sumMoney(regular.LocalSDITax, part.LocalSDITax)
+	with.TotalTax = sumMoney(regular.TotalTax, part.TotalTax)
+	with.StockOffset = with.EquityIncome
+
+	due := math.Max(part.TotalTax-part.GrossUp, 0)
+	with.SharesCredit = roundMoney(math.Min(due, covered))
+	with.CashTopUp = roundMoney(due - with.SharesCredit)
+	with.NetPay = roundMoney(with.Gross - with.TotalTax - with.StockOffset + with.SharesCredit)
+	return with, roundMoney(covered - with.SharesCredit)
+}
+
+// ToJSON converts the paystub to JSON string
+func (r PaystubResult) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,26 @@
+package stc
+
+// BrokerPreset names a common broker fee schedule
+type BrokerPreset string
+
+const (
+	PresetStandard       BrokerPreset = "standard"        // 3% per share with a $25 minimum, the default
+	PresetZeroCommission BrokerPreset = "zero-commission" // No commission, as most retail brokers now charge
+	PresetTiered         BrokerPreset = "tiered"          // $19.95 up to $5,000, then 0.5% of the trade value
+)
+
+// BrokerPresets lists every preset, in the order a picker shows them
+var BrokerPresets = []BrokerPreset{PresetStandard, PresetZeroCommission, PresetTiered}
+
+// Fees returns the schedule of the preset, and false if it is unknown
+func (p BrokerPreset) Fees() (BrokerFees, bool) {
+	switch p {
+	case PresetStandard:
+		return BrokerFees{CommissionRate: 0.03, MinimumFee: 25}, true
+	case PresetZeroCommission:
+		return BrokerFees{}, true
+	case PresetTiered:
+		return BrokerFees{CommissionTiers: []Tier{{UpTo: 5000, Flat: 19.95}, {Rate: 0.005}}}, true
+	}
+	return BrokerFees{}, false
+}
@@ -0,0 +1,50 @@
+package stc
+
+import (
+	"math"
+	"time"
+)
+
+// GrowthAssumption projects the share price forward at a constant annual rate
+type GrowthAssumption struct {
+	StartPrice   float64   `json:"startPrice"`
+	StartDate    time.Time `json:"startDate"`
+	AnnualGrowth float64   `json:"annualGrowth"` // e.g. 0.10 for 10% per year
+}
+
+// PriceAt returns the compounded price on the given date
+func (g GrowthAssumption) PriceAt(t time.Time) float64 {
+	years := t.Sub(g.StartDate).Hours() / (24 * 365.25)
+	return g.StartPrice * math.Pow(1+g.AnnualGrowth, years)
+}
+
+// VestProjection is the projected outcome of a single future vest
+type VestProjection struct {
+	Vest      Vest    `json:"vest"`
+	Price     float64 `json:"price"`     // Projected share price at vest
+	PreTax    float64 `json:"preTax"`    // Gross value of the released shares
+	TotalTax  float64 `json:"totalTax"`  // Withholding on the release
+	TotalFees float64 `json:"totalFees"` // Sell-to-cover fees
+	NetShares float64 `json:"netShares"` // Shares kept after the sell-to-cover
+	PostTax   float64 `json:"postTax"`   // Value kept: net shares at the projected price plus residual cash
+}
+
+// ProjectVests runs a sell-to-cover for every vest at its projected price
+func (c *Calculator) ProjectVests(vests []Vest, growth GrowthAssumption) []VestProjection {
+	c = c.snapshot()
+	projections := make([]VestProjection, 0, len(vests))
+	for _, v := range vests {
+		price := roundMoney(growth.PriceAt(v.Date))
+		res := c.CalculateRSU(RSUInput{SharesReleased: v.Shares, VestPrice: price, SalePrice: price})
+		projections = append(projections, VestProjection{
+			Vest:      v,
+			Price:     price,
+			PreTax:    res.TaxableGain,
+			TotalTax:  res.TotalTax,
+			TotalFees: res.TotalFees,
+			NetShares: res.NetShares,
+			PostTax:   roundMoney(res.NetShares*price + res.Residual),
+		})
+	}
+	return projections
+}
@@ -0,0 +1,29 @@
+package stc
+
+import "math"
+
+// ratio is part over whole to six places, or 0 when whole is not positive
+func ratio(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*1e6) / 1e6
+}
+
+// setRatios fills the headline ratios of an exercise of shares. The fees
+// are those of the sale, so a withhold-to-cover or pay-cash exercise has
+// none.
+func (r *Result) setRatios(shares float64) {
+	r.EffectiveWithholdingRate = ratio(r.TotalTax, r.TaxableGain)
+	r.FeesAsPercentOfProceeds = ratio(sumMoney(r.BrokerFees, r.SECFee, r.TAF), r.EstGrossProceeds)
+	r.PercentOfSharesSold = ratio(r.SharesToSell, shares)
+}
+
+// setRatios fills the headline ratios of a release of shares. Unlike an
+// exercise, EstGrossProceeds values the shares kept, so the proceeds of the
+// sale are worked out from the shares sold.
+func (r *RSUResult) setRatios(shares float64) {
+	r.EffectiveWithholdingRate = ratio(r.TotalTax, r.TaxableGain)
+	r.FeesAsPercentOfProceeds = ratio(r.TotalFees, NewMoney(r.SharesToSell).Mul(NewMoney(r.SalePrice)).Float64())
+	r.PercentOfSharesSold = ratio(r.SharesToSell, shares)
+}
@@ -0,0 +1,109 @@
+package stc
+
+import (
+	"fmt"
+	"math"
+)
+
+// Confirmation is what a broker reported for a sell-to-cover
+type Confirmation struct {
+	SharesSold float64 `json:"sharesSold"`
+	SalePrice  float64 `json:"salePrice"`
+	NetCash    float64 `json:"netCash"`        // Cash paid out after withholding and fees
+	Fees       float64 `json:"fees,omitempty"` // Commission and fees as itemized; 0 assumes the configured schedule
+}
+
+// ReconcileLine compares one quantity of the expected calculation with the
+// value implied by a confirmation
+type ReconcileLine struct {
+	ID       string  `json:"id"`
+	Label    string  `json:"label"`
+	Expected float64 `json:"expected"`
+	Actual   float64 `json:"actual"`
+}
+
+// Delta is the confirmed value less the expected one
+func (l ReconcileLine) Delta() float64 {
+	return l.Actual - l.Expected
+}
+
+func (l ReconcileLine) String() string {
+	return fmt.Sprintf("%s: expected %.4f, confirmed %.4f", l.Label, l.Expected, l.Actual)
+}
+
+// Reconciliation back-solves the withholding and fees a broker applied and
+// compares them with the configured calculation
+type Reconciliation struct {
+	Confirmation Confirmation    `json:"confirmation"`
+	TaxableGain  float64         `json:"taxableGain"`
+	ImpliedTax   float64         `json:"impliedTax"`  // Proceeds less option cost, fees, and net cash
+	ImpliedFees  float64         `json:"impliedFees"` // As itemized, or the configured schedule on the shares sold
+	FeesAssumed  bool            `json:"feesAssumed,omitempty"`
+	ExpectedRate float64         `json:"expectedRate"` // Configured tax as a fraction of the taxable gain
+	ImpliedRate  float64         `json:"impliedRate"`  // Implied tax as a fraction of the taxable gain
+	Lines        []ReconcileLine `json:"lines"`        // Shares, tax, fees, and net cash, expected against confirmed
+}
+
+// Reconcile infers the withholding behind an options confirmation. Tax is
+// what remains of the proceeds after the option cost, fees, and net cash.
+func (c *Calculator) Reconcile(input Input, conf Confirmation) Reconciliation {
+	c = c.snapshot()
+	input.Mode = SellToCover
+	r := c.Calculate(input)
+	return c.reconcile(conf, r.TaxableGain, r.OptionCost, r.SharesToSell, r.TotalTax, r.BrokerFees+r.SECFee+r.TAF, r.Residual, NewMoney(c.config.BrokerFees.FlatFee))
+}
+
+// ReconcileMultiLot infers the withholding behind the confirmation of a multi-lot sale
+func (c *Calculator) ReconcileMultiLot(input MultiLotInput, conf Confirmation) Reconciliation {
+	c = c.snapshot()
+	input.Mode = SellToCover
+	r := c.CalculateMultiLot(input)
+	return c.reconcile(conf, r.TaxableGain, r.OptionCost, r.SharesToSell, r.TotalTax, r.BrokerFees+r.SECFee+r.TAF, r.Residual, NewMoney(c.config.BrokerFees.FlatFee))
+}
+
+// ReconcileRSU infers the withholding behind an RSU release confirmation
+func (c *Calculator) ReconcileRSU(input RSUInput, conf Confirmation) Reconciliation {
+	c = c.snapshot()
+	input.Mode = SellToCover
+	r := c.CalculateRSU(input)
+	return c.reconcile(conf, r.TaxableGain, 0, r.SharesToSell, r.TotalTax, r.TotalFees, r.Residual, NewMoney(c.config.BrokerFees.FlatFee))
+}
+
+// reconcile compares a confirmation with the expected calculation. flatFee
+// is the processing fee the calculation charges on the sale.
+func (c *Calculator) reconcile(conf Confirmation, gain, optionCost, shares, tax, fees, residual float64, flatFee Money) Reconciliation {
+	rec := Reconciliation{Confirmation: conf, TaxableGain: gain, ImpliedFees: conf.Fees}
+	if rec.ImpliedFees == 0 {
+		rec.ImpliedFees = (c.saleFees(NewMoney(conf.SharesSold), NewMoney(conf.SalePrice)) + flatFee).Float64()
+		rec.FeesAssumed = true
+	}
+	proceeds := NewMoney(conf.SharesSold).Mul(NewMoney(conf.SalePrice))
+	rec.ImpliedTax = (proceeds - NewMoney(optionCost) - NewMoney(rec.ImpliedFees) - NewMoney(conf.NetCash)).Float64()
+
+	rate := func(tax float64) float64 {
+		if gain == 0 {
+			return 0
+		}
+		return math.Round(tax/gain*1e6) / 1e6
+	}
+	rec.ExpectedRate, rec.ImpliedRate = rate(tax), rate(rec.ImpliedTax)
+	rec.Lines = []ReconcileLine{
+		{"sharesSold", "Shares Sold", shares, conf.SharesSold},
+		{"totalTax", "Tax Withheld", tax, rec.ImpliedTax},
+		{"fees", "Fees", fees, rec.ImpliedFees},
+		{"netCash", "Net Cash", residual, conf.NetCash},
+	}
+	return rec
+}
+
+// Discrepancies lists the lines whose confirmed value differs from the
+// expected one by more than tolerance dollars or shares
+func (r Reconciliation) Discrepancies(tolerance float64) []ReconcileLine {
+	var out []ReconcileLine
+	for _, l := range r.Lines {
+		if math.Abs(l.Delta()) > tolerance {
+			out = append(out, l)
+		}
+	}
+	return out
+}
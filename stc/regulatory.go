@@ -0,0 +1,49 @@
+package stc
+
+// RegulatoryFees are the transaction fees a broker passes through on every
+// sale: the SEC Section 31 fee and FINRA's Trading Activity Fee (TAF)
+type RegulatoryFees struct {
+	SECRate float64 `json:"secRate"` // Dollars per dollar of proceeds, e.g. 0.0000278 ($27.80 per million)
+	TAFRate float64 `json:"tafRate"` // Dollars per share sold
+	TAFMax  float64 `json:"tafMax"`  // Cap on the TAF for one trade; 0 means no cap
+}
+
+// CurrentRegulatoryFees are the SEC and FINRA rates for 2025. Both are
+// revised periodically, so a config can carry its own.
+var CurrentRegulatoryFees = RegulatoryFees{
+	SECRate: 0.0000278,
+	TAFRate: 0.000166,
+	TAFMax:  8.30,
+}
+
+// regulatoryFees returns the SEC fee and TAF on selling shares at price,
+// each rounded up to the cent as brokers charge them
+func (c *Calculator) regulatoryFees(shares, price Money) (sec, taf Money) {
+	fees := c.config.RegulatoryFees
+	if shares <= 0 {
+		return 0, 0
+	}
+	// The rates are finer than MulFloat's six decimals, so multiply in float64
+	sec = ceilCents(NewMoney(shares.Mul(price).Float64() * fees.SECRate))
+	taf = ceilCents(NewMoney(shares.Float64() * fees.TAFRate))
+	if limit := NewMoney(fees.TAFMax); limit > 0 && taf > limit {
+		taf = limit
+	}
+	return sec, taf
+}
+
+// saleFees returns every fee charged for selling shares at price: the
+// broker's commission and the regulatory fees
+func (c *Calculator) saleFees(shares, price Money) Money {
+	sec, taf := c.regulatoryFees(shares, price)
+	return c.brokerFee(shares, price) + sec + taf
+}
+
+// ceilCents rounds a non-negative amount up to the cent
+func ceilCents(m Money) Money {
+	const cent = moneyScale / 100
+	if r := m % cent; r > 0 {
+		m += cent - r
+	}
+	return m
+}
@@ -0,0 +1,125 @@
+package stc
+
+import "strings"
+
+// AuthorityIRS collects federal income tax, Social Security, and Medicare
+const AuthorityIRS = "Internal Revenue Service"
+
+// Remittance is one amount of withheld tax and the authority it is paid to,
+// e.g. the federal income tax to the IRS or a state's tax to its revenue
+// department
+type Remittance struct {
+	Authority string  `json:"authority"`
+	Tax       string  `json:"tax"` // What the amount is, e.g. "Social Security"
+	Amount    float64 `json:"amount"`
+}
+
+// stateAuthorities names the agency that collects each state's withholding.
+// Keys are upper-case postal codes or names as entered for a jurisdiction.
+var stateAuthorities = map[string]string{
+	"AZ": "Arizona Department of Revenue",
+	"CA": "California Employment Development Department",
+	"CO": "Colorado Department of Revenue",
+	"GA": "Georgia Department of Revenue",
+	"IL": "Illinois Department of Revenue",
+	"MA": "Massachusetts Department of Revenue",
+	"MD": "Comptroller of Maryland",
+	"MN": "Minnesota Department of Revenue",
+	"NC": "North Carolina Department of Revenue",
+	"NJ": "New Jersey Division of Taxation",
+	"NY": "New York State Department of Taxation and Finance",
+	"OR": "Oregon Department of Revenue",
+	"PA": "Pennsylvania Department of Revenue",
+	"UT": "Utah State Tax Commission",
+	"VA": "Virginia Department of Taxation",
+	"WA": "Washington Employment Security Department",
+}
+
+// localAuthorities names the agency that collects local and disability
+// withholding. New York City and Yonkers tax is paid with the state's.
+var localAuthorities = map[string]string{
+	"NYC":     "New York State Department of Taxation and Finance",
+	"YONKERS": "New York State Department of Taxation and Finance",
+	"CA SDI":  "California Employment Development Department",
+	"SDI":     "State Disability Insurance Fund",
+	"NJ SDI":  "New Jersey Division of Taxation",
+	"PFML":    "Paid Family and Medical Leave Fund",
+}
+
+// Authority returns the agency a jurisdiction's withholding is paid to. A
+// name it does not know is taken as the authority's own name, e.g. "Ohio
+// Department of Taxation" or "Philadelphia".
+func Authority(name string, kind JurisdictionKind) string {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	table := stateAuthorities
+	if kind == JurisdictionLocal {
+		table = localAuthorities
+	}
+	if a, ok := table[key]; ok {
+		return a
+	}
+	if kind == JurisdictionState && len(key) == 2 {
+		return key + " Department of Revenue"
+	}
+	return strings.TrimSpace(name)
+}
+
+// remittances lists the withholding of a calculation by authority. Federal
+// income tax, Social Security, and Medicare all go to the IRS; state and
+// local lines go to the authority of each jurisdiction.
+func remittances(federal, medicare, surtax, socialSec, state, local float64, stateLines, localLines []TaxLine) []Remittance {
+	var out []Remittance
+	add := func(authority, tax string, amount float64) {
+		if amount != 0 {
+			out = append(out, Remittance{Authority: authority, Tax: tax, Amount: amount})
+		}
+	}
+	add(AuthorityIRS, "Federal income tax", federal)
+	add(AuthorityIRS, "Social Security", socialSec)
+	add(AuthorityIRS, "Medicare", medicare)
+	add(AuthorityIRS, "Additional Medicare", surtax)
+	if len(stateLines) == 0 {
+		add("State Department of Revenue", "State income tax", state)
+	}
+	for _, l := range stateLines {
+		add(Authority(l.Name, JurisdictionState), l.Name+" income tax", l.Amount)
+	}
+	if len(localLines) == 0 {
+		add("Local Tax / SDI Fund", "Local/SDI", local)
+	}
+	for _, l := range localLines {
+		add(Authority(l.Name, JurisdictionLocal), l.Name, l.Amount)
+	}
+	return out
+}
+
+// Remittances lists the tax withheld on the exercise by the authority it
+// is paid to. An ISO exercise withholds nothing.
+func (r Result) Remittances() []Remittance {
+	return remittances(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax,
+		r.StateLines, r.LocalLines)
+}
+
+// Remittances lists the tax withheld on the release by the authority it is
+// paid to
+func (r RSUResult) Remittances() []Remittance {
+	return remittances(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax,
+		r.StateLines, r.LocalLines)
+}
+
+// RemittanceTotals sums lines by authority, in the order each authority
+// first appears
+func RemittanceTotals(lines []Remittance) []Remittance {
+	var out []Remittance
+	index := map[string]int{}
+	for _, l := range lines {
+		i, ok := index[l.Authority]
+		if !ok {
+			i = len(out)
+			index[l.Authority] = i
+			out = append(out, Remittance{Authority: l.Authority, Tax: "Total"})
+		}
+		out[i].Amount = roundMoney(out[i].Amount + l.Amount)
+	}
+	return out
+}
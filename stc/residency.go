@@ -0,0 +1,96 @@
+package stc
+
+import (
+	"time"
+)
+
+// TaxLine is one itemized tax in a result, such as a single state's share of state tax
+type TaxLine struct {
+	Name   string  `json:"name"`
+	Income float64 `json:"income"` // Portion of the taxable gain allocated to this line
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+// ResidencyPeriod assigns a state (and its withholding rate) to a date range, inclusive
+type ResidencyPeriod struct {
+	State string    `json:"state"`
+	Rate  float64   `json:"rate"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Allocation is the share of a service period worked in one state
+type Allocation struct {
+	State    string  `json:"state"`
+	Rate     float64 `json:"rate"`
+	Workdays int     `json:"workdays"`
+	Fraction float64 `json:"fraction"`
+}
+
+// Workdays counts weekdays (Monday-Friday) between start and end, inclusive
+func Workdays(start, end time.Time) int {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		return 0
+	}
+
+	days := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// AllocateByWorkdays splits a service period (grant to vest) across residency
+// periods in proportion to the workdays spent in each state, the method most
+// states use to source equity compensation for part-year residents.
+func AllocateByWorkdays(periods []ResidencyPeriod, serviceStart, serviceEnd time.Time) []Allocation {
+	total := Workdays(serviceStart, serviceEnd)
+	if total == 0 {
+		return nil
+	}
+
+	var allocations []Allocation
+	for _, p := range periods {
+		start, end := p.Start, p.End
+		if start.Before(serviceStart) {
+			start = serviceStart
+		}
+		if end.IsZero() || end.After(serviceEnd) {
+			end = serviceEnd
+		}
+		days := Workdays(start, end)
+		if days == 0 {
+			continue
+		}
+		allocations = append(allocations, Allocation{
+			State:    p.State,
+			Rate:     p.Rate,
+			Workdays: days,
+			Fraction: float64(days) / float64(total),
+		})
+	}
+	return allocations
+}
+
+// residencyLines itemizes state withholding on a gain with one line per state of residence
+func (c *Calculator) residencyLines(gain float64, serviceStart, serviceEnd time.Time) ([]TaxLine, float64) {
+	var lines []TaxLine
+	total := 0.0
+	for _, a := range AllocateByWorkdays(c.config.Residency, serviceStart, serviceEnd) {
+		income := roundMoney(gain * a.Fraction)
+		line := TaxLine{
+			Name:   a.State,
+			Income: income,
+			Rate:   a.Rate,
+			Amount: roundMoney(income * a.Rate),
+		}
+		lines = append(lines, line)
+		total += line.Amount
+	}
+	return lines, total
+}
@@ -1,85 +1,148 @@
 package stc
 
 import (
-	"math"
+	"encoding/json"
 )
 
 // CalculateRSU performs the STC calculation for Restricted Stock Units
 func (c *Calculator) CalculateRSU(input RSUInput) RSUResult {
+	c = c.snapshot().forYear(input.BrokerYTD)
 	result := RSUResult{
 		SharesReleased: input.SharesReleased,
 		VestPrice:      input.VestPrice,
 		SalePrice:      input.SalePrice,
+		Mode:           input.Mode,
+
+		DividendEquivalentShares: input.DividendEquivalentShares,
 	}
 
-	// 1. Calculate Taxable Gain (Basis is FMV at Vest)
-	result.TaxableGain = roundMoney(input.SharesReleased * input.VestPrice)
+	// 1. Calculate Taxable Gain (Basis is FMV at Vest), dividend equivalents included
+	result.TaxableGain = roundMoney(input.released() * input.VestPrice)
+	if input.Mode == GrossUp {
+		// The gross-up is itself taxed, so it joins the income
+		result.GrossUp = grossUp(0, result.TaxableGain, func(gain float64) float64 {
+			return c.withheld(gain, input.YTDIncome, input.YTDWages, input.YTDSupplementalWages, input.ServiceStart, input.ServiceEnd)
+		})
+		result.TaxableGain = roundMoney(result.TaxableGain + result.GrossUp)
+	}
 
 	// 2. Calculate Taxes
-	result.FederalTax = roundMoney(result.TaxableGain * c.config.TaxRates.Federal)
+	result.FederalTax = c.federalTax(result.TaxableGain, input.YTDIncome, input.YTDSupplementalWages)
 	result.MedicareTax = roundMoney(result.TaxableGain * c.config.TaxRates.Medicare)
-	result.SocialSecTax = roundMoney(result.TaxableGain * c.config.TaxRates.SocialSec)
-	result.StateTax = roundMoney(result.TaxableGain * c.config.TaxRates.State)
-	result.LocalSDITax = roundMoney(result.TaxableGain * c.config.TaxRates.LocalSDI)
-
-	result.TotalTax = result.FederalTax + result.MedicareTax + result.SocialSecTax +
-		result.StateTax + result.LocalSDITax
-
-	// 3. Base Liability (Taxes + Flat Fees)
-	// Processing fees are added to the liability we must cover by selling shares.
-	// baseLiability := result.TotalTax + c.config.BrokerFees.FlatFee
-	// baseFee := c.config.BrokerFees.FlatFee
-	baseBurden := result.TotalTax
-	// 4. Iterative Solver for Shares to Sell
-	// We need to cover: BaseLiability + Commission
-	// Commission depends on Gross Proceeds (SharesSold * SalePrice)
-
-	sharesToSell := 0.0
-
-	// Initial guess
-	if input.SalePrice > 0 {
-		sharesToSell = math.Ceil(baseBurden / input.SalePrice)
+	result.MedicareSurtax = c.medicareSurtax(result.TaxableGain, input.YTDWages)
+	result.SocialSecTax = c.socialSecTax(result.TaxableGain, input.YTDWages)
+	result.StateLines, result.StateTax, result.LocalLines, result.LocalSDITax =
+		c.regionalTax(result.TaxableGain, input.ServiceStart, input.ServiceEnd)
+
+	result.TotalTax = sumMoney(result.FederalTax, result.MedicareTax, result.MedicareSurtax, result.SocialSecTax,
+		result.StateTax, result.LocalSDITax)
+
+	// 3. Iterative Solver for Shares to Sell, in fixed-point Money
+	// We need to cover: Taxes + Commission + Flat Fee
+	// Commission depends on the shares sold, so iterate until the share count is stable
+	price := NewMoney(input.SalePrice)
+	if input.Mode == WithholdToCover {
+		// Withheld shares are valued at the vest price, not sold
+		price = NewMoney(input.VestPrice)
 	}
+	released := NewMoney(input.released())
+	// The employee covers only the tax the employer's gross-up does not
+	totalTax := (NewMoney(result.TotalTax) - NewMoney(result.GrossUp)).Max(0)
+	fees := c.config.BrokerFees
+	flatFee := NewMoney(fees.FlatFee)
+
+	var solvedShares, commission, totalFees, totalCosts, cashTopUp Money
+	if input.Mode == SellAll {
+		// Same-day sale: every released share is sold and the costs come out of the proceeds
+		solvedShares = released
+		commission = c.brokerFee(solvedShares, price)
+		totalFees = commission + flatFee
+		totalCosts = totalTax + totalFees
+		result.FlatFee = fees.FlatFee
+	} else if input.Mode == PayCash {
+		// Taxes paid in cash: nothing is sold, so there are no fees
+		totalCosts = totalTax
+		cashTopUp = totalCosts
+	} else if input.Mode == GrossUp && totalTax == 0 {
+		// The gross-up covers the tax, so nothing is sold
+	} else if input.Mode == WithholdToCover {
+		// Net settlement: no broker, so no commission or processing fee
+		totalCosts = totalTax
+		solvedShares = c.sharesFor(totalCosts, price)
+		result.Trace = append(result.Trace, SolverStep{
+			Iteration:     1,
+			SharesToSell:  solvedShares.Float64(),
+			TotalRequired: totalCosts.Float64(),
+			NextShares:    solvedShares.Float64(),
+		})
+		if c.config.CashTopUp {
+			solvedShares, cashTopUp = c.coverWithCash(solvedShares, price, func(Money) Money { return totalCosts })
+		}
+	} else {
+		// Initial guess, sized at the haircut price
+		sizing := c.sizingPrice(price)
+		sharesToSell := c.sharesFor(totalTax, sizing)
+
+		// Adjust for the commission and fees on the shares sold, per Config.Solver
+		shares, trace, ok := c.solveShares(totalTax, flatFee, sharesToSell, price, sizing)
+		result.Trace = append(result.Trace, trace...)
+		sharesToSell = shares
+		if ok {
+			solvedShares, commission = shares, c.brokerFee(shares, price)
+			totalFees = commission + flatFee
+			totalCosts = totalTax + totalFees
+			result.FlatFee = fees.FlatFee
+		}
 
-	const maxIterations = 100
-	for i := 0; i < maxIterations; i++ {
-		// Calculate Gross Proceeds for current guess
-		sharesReleased := input.SharesReleased
-		grossProceeds := (sharesReleased - sharesToSell) * input.SalePrice
-
-		// Calculate Commission
-		// Assumes CommissionRate is a percentage (e.g., 0.03 for 3%)
-		commission := sharesToSell * c.config.BrokerFees.CommissionRate
-
-		// Apply Minimum Fee Logic
-		finalCommission := math.Max(commission, c.config.BrokerFees.MinimumFee)
-
-		// Total Transaction Costs for this batch
-		totalTransactionCosts := finalCommission + c.config.BrokerFees.FlatFee
-
-		// Total Cash Required
-		totalRequired := result.TotalTax + totalTransactionCosts
-
-		// New Shares Needed (Round UP)
-		newSharesToSell := math.Ceil(totalRequired / input.SalePrice)
-
-		if newSharesToSell == sharesToSell {
-			// Stabilized
-			result.SharesToSell = sharesToSell
-			result.BrokerCommission = finalCommission
-			result.FlatFee = c.config.BrokerFees.FlatFee
-			result.TotalFees = totalTransactionCosts
-			result.TotalCosts = totalRequired
-			result.EstGrossProceeds = grossProceeds
-			break
+		if c.config.CashTopUp {
+			// Pay the rounding difference in cash rather than in shares
+			solvedShares, cashTopUp = c.coverWithCash(sharesToSell, sizing, func(shares Money) Money {
+				return totalTax + c.saleFees(shares, price) + flatFee
+			})
+			commission = c.brokerFee(solvedShares, price)
+			totalFees = commission + flatFee
+			totalCosts = totalTax + totalFees
+		} else if extra := fees.ExtraShares; extra > 0 {
+			// Broker buffer policy: sell extra whole shares and re-apply commission
+			solvedShares = sharesToSell + NewMoney(extra)
+			result.ExtraShares = extra
+			commission = c.brokerFee(solvedShares, price)
+			totalFees = commission + flatFee
+			totalCosts = totalTax + totalFees
 		}
+	}
 
-		sharesToSell = newSharesToSell
+	if input.Mode != WithholdToCover && input.Mode != PayCash {
+		// Regulatory fees are charged on whatever is finally sold
+		sec, taf := c.regulatoryFees(solvedShares, price)
+		result.SECFee, result.TAF = sec.Float64(), taf.Float64()
+		totalFees += sec + taf
+		totalCosts += sec + taf
 	}
 
-	// 5. Finalize Results
-	result.Residual = (sharesToSell * input.SalePrice) - result.TotalCosts
-	result.NetShares = result.SharesReleased - result.SharesToSell
+	// 4. Finalize Results
+	result.SharesToSell = solvedShares.Float64()
+	result.BrokerCommission = commission.Float64()
+	result.TotalFees = totalFees.Float64()
+	result.TotalCosts = totalCosts.Float64()
+	result.CashTopUp = cashTopUp.Float64()
+	result.EstGrossProceeds = (released - solvedShares).Mul(price).Float64()
+	result.Residual = (solvedShares.Mul(price) + cashTopUp - totalCosts).Float64()
+	result.NetCash = (solvedShares.Mul(price) - totalCosts).Float64()
+	result.NetShares = input.released() - result.SharesToSell
+	result.STCGainLoss = stcGainLoss(input.Mode, solvedShares.Mul(price), totalFees, solvedShares, input.VestPrice)
+	result.Warnings = c.saleWarnings(input.Mode, solvedShares, released, price, commission)
+	result.setRatios(input.released())
+	result.Meta = c.metadata(input.Dates.taxDate(input.ServiceEnd), result.StateLines, result.LocalLines)
 
 	return result
 }
+
+// ToJSON converts the RSU result to JSON string
+func (r RSUResult) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -0,0 +1,106 @@
+package stc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ScenarioVersion is the format version written by Scenario.Marshal
+const ScenarioVersion = 1
+
+// Scenario is one complete calculation, the config and its inputs without
+// the result, so it can be saved, shared, re-run, and compared. Exactly one
+// of Options and RSU is set.
+type Scenario struct {
+	Version int       `json:"version"`
+	Label   string    `json:"label"`
+	Config  Config    `json:"config"`
+	Options *Input    `json:"options,omitempty"`
+	RSU     *RSUInput `json:"rsu,omitempty"`
+}
+
+// Validate checks the config and the one set input
+func (s Scenario) Validate() error {
+	if (s.Options == nil) == (s.RSU == nil) {
+		return fmt.Errorf("scenario %q must have one of options or rsu inputs", s.Label)
+	}
+	if err := s.Config.Validate(); err != nil {
+		return fmt.Errorf("scenario %q has an invalid config: %w", s.Label, err)
+	}
+	var err error
+	if s.Options != nil {
+		err = s.Options.Validate()
+	} else {
+		err = s.RSU.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("scenario %q has invalid inputs: %w", s.Label, err)
+	}
+	return nil
+}
+
+// Run calculates the scenario and returns it as a session entry with the
+// result
+func (s Scenario) Run() (SessionEntry, error) {
+	if err := s.Validate(); err != nil {
+		return SessionEntry{}, err
+	}
+	return s.Entry().Run(s.Config), nil
+}
+
+// Entry returns the scenario as a session entry without a result
+func (s Scenario) Entry() SessionEntry {
+	return SessionEntry{Title: s.Label, Config: s.Config, Options: s.Options, RSU: s.RSU}
+}
+
+// Diff runs both scenarios and lists the quantities that differ between s
+// and other. Only scenarios of the same kind compare.
+func (s Scenario) Diff(other Scenario) ([]Change, error) {
+	if (s.Options == nil) != (other.Options == nil) {
+		return nil, fmt.Errorf("cannot compare an exercise with a release")
+	}
+	before, err := s.Run()
+	if err != nil {
+		return nil, err
+	}
+	after, err := other.Run()
+	if err != nil {
+		return nil, err
+	}
+	return Diff(before.Graph(), after.Graph()), nil
+}
+
+// Scenario returns the entry's config and inputs. ok is false for a
+// multi-lot entry or one solved for a cash target, which a scenario does not
+// describe.
+func (e SessionEntry) Scenario() (s Scenario, ok bool) {
+	if e.MultiLot != nil || e.TargetCash > 0 {
+		return Scenario{}, false
+	}
+	return Scenario{Version: ScenarioVersion, Label: e.Title, Config: e.Config, Options: e.Options, RSU: e.RSU}, true
+}
+
+// Marshal encodes the scenario as indented JSON
+func (s Scenario) Marshal() ([]byte, error) {
+	s.Version = ScenarioVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write scenario: %w", err)
+	}
+	return data, nil
+}
+
+// UnmarshalScenario decodes and validates a scenario written by Marshal
+func UnmarshalScenario(data []byte) (Scenario, error) {
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Scenario{}, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	if s.Version > ScenarioVersion {
+		return Scenario{}, fmt.Errorf("scenario version %d is newer than this version supports (%d)", s.Version, ScenarioVersion)
+	}
+	if err := s.Validate(); err != nil {
+		return Scenario{}, err
+	}
+	return s, nil
+}
@@ -0,0 +1,22 @@
+package stc
+
+// SaleMode selects how many shares a transaction sells
+type SaleMode string
+
+const (
+	SellToCover SaleMode = "sell-to-cover" // Sell just enough shares to cover the costs (the default)
+	SellAll     SaleMode = "sell-all"      // Same-day sale of every share, leaving only cash
+
+	// WithholdToCover is net share settlement: the employer keeps shares worth
+	// the costs at FMV (the vest price for RSUs), so no broker fees are charged
+	WithholdToCover SaleMode = "withhold-to-cover"
+
+	// PayCash is a cash exercise: the employee pays every cost out of pocket
+	// and keeps every share
+	PayCash SaleMode = "pay-cash"
+
+	// GrossUp is an employer gross-up: the employer pays the tax in cash,
+	// grossed up for the tax on that cash, and shares are sold only for any
+	// other costs, such as the option cost and fees
+	GrossUp SaleMode = "gross-up"
+)
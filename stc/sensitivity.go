@@ -0,0 +1,56 @@
+package stc
+
+import "math"
+
+// MaxSensitivityPoints caps how many prices one sweep calculates
+const MaxSensitivityPoints = 1000
+
+// PriceRange is an inclusive range of share prices
+type PriceRange struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// prices lists From, From+step, ... up to To, rounded to the cent. A range
+// that is empty or has no positive step holds only From.
+func (r PriceRange) prices(step float64) []float64 {
+	n := 1
+	if step > 0 && r.To > r.From {
+		// The tolerance keeps To when the range is a whole number of steps
+		n = int(math.Floor((r.To-r.From)/step+1e-9)) + 1
+	}
+	n = min(n, MaxSensitivityPoints)
+	prices := make([]float64, n)
+	for i := range prices {
+		prices[i] = roundMoney(r.From + float64(i)*step)
+	}
+	return prices
+}
+
+// Sensitivity calculates an exercise at every price in the range, stepping
+// by step, to show how the shares sold and the residual move with the
+// stock. Options are sold at the FMV they are exercised at, ignoring any
+// SalePrice, so each price changes the taxable spread as well as the sale.
+func (c *Calculator) Sensitivity(input Input, prices PriceRange, step float64) []Result {
+	c = c.snapshot()
+	var results []Result
+	for _, p := range prices.prices(step) {
+		input.FMV, input.SalePrice = p, 0
+		results = append(results, c.calculate(input, 0))
+	}
+	return results
+}
+
+// SensitivityRSU calculates a release at every sale price in the range,
+// stepping by step. The vest price, and so the tax, stays fixed: only the
+// price the shares are sold at moves, as when the stock moves between the
+// release and the sale.
+func (c *Calculator) SensitivityRSU(input RSUInput, prices PriceRange, step float64) []RSUResult {
+	c = c.snapshot()
+	var results []RSUResult
+	for _, p := range prices.prices(step) {
+		input.SalePrice = p
+		results = append(results, c.CalculateRSU(input))
+	}
+	return results
+}
@@ -0,0 +1,135 @@
+package stc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SessionVersion is the format version written by Session.Save
+const SessionVersion = 1
+
+// SessionEntry is one saved calculation: its inputs, the config it ran
+// under, and the result. Exactly one of Options, RSU, and MultiLot is set;
+// a multi-lot entry keeps its combined result in Result.
+type SessionEntry struct {
+	Title      string         `json:"title"`
+	Config     Config         `json:"config"`
+	Options    *Input         `json:"options,omitempty"`
+	RSU        *RSUInput      `json:"rsu,omitempty"`
+	MultiLot   *MultiLotInput `json:"multiLot,omitempty"`
+	TargetCash float64        `json:"targetCash,omitempty"` // Options solved with SolveForCash
+	Reconciled bool           `json:"reconciled,omitempty"` // Shares were kept, so the numbers describe a real transaction
+	Result     *Result        `json:"result,omitempty"`
+	RSUResult  *RSUResult     `json:"rsuResult,omitempty"`
+}
+
+// Run recomputes the entry under cfg and returns it with the new config and result
+func (e SessionEntry) Run(cfg Config) SessionEntry {
+	e.Config = cfg
+	c := NewCalculator(cfg)
+	switch {
+	case e.Options != nil && e.TargetCash > 0:
+		r := c.SolveForCash(*e.Options, e.TargetCash)
+		e.Result = &r
+	case e.Options != nil:
+		r := c.Calculate(*e.Options)
+		e.Result = &r
+	case e.RSU != nil:
+		r := c.CalculateRSU(*e.RSU)
+		e.RSUResult = &r
+	case e.MultiLot != nil:
+		r := c.CalculateMultiLot(*e.MultiLot)
+		e.Result = &r.Result
+	}
+	return e
+}
+
+// Graph returns the calculation graph of the stored result
+func (e SessionEntry) Graph() Graph {
+	switch {
+	case e.Result != nil:
+		return e.Result.Graph()
+	case e.RSUResult != nil:
+		return e.RSUResult.Graph()
+	}
+	return Graph{}
+}
+
+// Session is a saved list of calculations, shared by the GUI and the
+// recompute command
+type Session struct {
+	Version int            `json:"version"`
+	Saved   time.Time      `json:"saved"`
+	Entries []SessionEntry `json:"entries"`
+}
+
+// Rebase returns c with the assumptions of updated: rates and
+// jurisdictions, tax model and brackets, Social Security wage base, fees,
+// solver, cash top-up, share policy, and price haircut. Residency, the tax
+// year, country, and time zone describe the transaction and are kept.
+func (c Config) Rebase(updated Config) Config {
+	c.TaxRates = updated.TaxRates
+	c.TaxModel = updated.TaxModel
+	c.FederalBrackets = updated.FederalBrackets
+	c.SocialSecWageBase = updated.SocialSecWageBase
+	c.BrokerFees = updated.BrokerFees
+	c.RegulatoryFees = updated.RegulatoryFees
+	c.Solver = updated.Solver
+	c.CashTopUp = updated.CashTopUp
+	c.SharePolicy = updated.SharePolicy
+	c.PriceHaircut = updated.PriceHaircut
+	return c
+}
+
+// Recompute reruns every entry under its config rebased on updated.
+// Reconciled entries are left alone unless all is set.
+func (s Session) Recompute(updated Config, all bool) Session {
+	out := Session{Version: SessionVersion, Saved: s.Saved, Entries: make([]SessionEntry, len(s.Entries))}
+	for i, e := range s.Entries {
+		if e.Reconciled && !all {
+			out.Entries[i] = e
+			continue
+		}
+		out.Entries[i] = e.Run(e.Config.Rebase(updated))
+	}
+	return out
+}
+
+// LoadSession reads a session saved by Save
+func LoadSession(r io.Reader) (Session, error) {
+	var s Session
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Session{}, fmt.Errorf("failed to read session: %w", err)
+	}
+	if s.Version > SessionVersion {
+		return Session{}, fmt.Errorf("session version %d is newer than this version supports (%d)", s.Version, SessionVersion)
+	}
+	for i, e := range s.Entries {
+		kinds := 0
+		for _, set := range []bool{e.Options != nil, e.RSU != nil, e.MultiLot != nil} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return Session{}, fmt.Errorf("session entry %d (%q) must have one of options, rsu, or multiLot inputs", i+1, e.Title)
+		}
+		if err := e.Config.Validate(); err != nil {
+			return Session{}, fmt.Errorf("session entry %d (%q) has an invalid config: %w", i+1, e.Title, err)
+		}
+	}
+	return s, nil
+}
+
+// Save writes the session as indented JSON
+func (s Session) Save(w io.Writer) error {
+	s.Version = SessionVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package stc
+
+// SharePolicy controls how the solvers round the number of shares sold
+type SharePolicy string
+
+const (
+	ShareWhole        SharePolicy = "whole"      // Round up to whole shares (the default)
+	ShareFractional   SharePolicy = "fractional" // Sell the exact fraction, to the millionth of a share
+	ShareRoundNearest SharePolicy = "nearest"    // Round to the nearest whole share, which may leave a small shortfall
+)
+
+// SharePolicies lists every policy, default first
+var SharePolicies = []SharePolicy{ShareWhole, ShareFractional, ShareRoundNearest}
+
+// sharesFor returns the shares to sell at price to raise amount under the configured policy
+func (c *Calculator) sharesFor(amount, price Money) Money {
+	switch c.config.SharePolicy {
+	case ShareFractional:
+		return amount.DivUp(price)
+	case ShareRoundNearest:
+		return amount.RoundDiv(price)
+	default:
+		return amount.CeilDiv(price)
+	}
+}
+
+// sizingPrice is the execution price a sale is sized at: price less
+// Config.PriceHaircut, so the order still covers the costs if it fills a
+// little below the quote. Proceeds are still reported at price.
+func (c *Calculator) sizingPrice(price Money) Money {
+	if h := c.config.PriceHaircut; h > 0 && h < 1 {
+		return price.Mul(NewMoney(1 - h))
+	}
+	return price
+}
+
+// coverWithCash pays a converged solution's rounding in cash and returns the
+// shares to sell and the cash needed. costAt reports total costs for a given
+// number of shares sold. Whole shares drop the final rounded-up share; rounding
+// to the nearest share tops up any shortfall; fractional sales need no cash.
+func (c *Calculator) coverWithCash(shares, price Money, costAt func(shares Money) Money) (Money, Money) {
+	switch c.config.SharePolicy {
+	case ShareFractional:
+		return shares, 0
+	case ShareRoundNearest:
+		return shares, (costAt(shares) - shares.Mul(price)).Round(2).Max(0)
+	}
+	if shares <= 0 {
+		return shares, 0
+	}
+	fewer := shares - moneyScale
+	shortfall := (costAt(fewer) - fewer.Mul(price)).Round(2)
+	if shortfall <= 0 {
+		return fewer, 0
+	}
+	return fewer, shortfall
+}
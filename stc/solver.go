@@ -0,0 +1,179 @@
+package stc
+
+import (
+	"math"
+	"sort"
+)
+
+// Solver selects how the shares to sell are found
+type Solver string
+
+const (
+	// SolverIterative starts from the shares that cover the costs before
+	// fees and adds the fees of each guess until the count stops changing
+	// (the default)
+	SolverIterative Solver = ""
+
+	// SolverClosedForm solves the fee schedule's linear pieces directly,
+	// the minimum-fee breakpoint included, then checks the answer against the
+	// exact, cent-rounded fees. It settles on the same share count as the
+	// iterative solver, usually in one step, whenever fees do not fall as a
+	// sale grows (ConfigLint flags tiers where they do).
+	SolverClosedForm Solver = "closed-form"
+)
+
+// Solvers lists every solver, default first
+var Solvers = []Solver{SolverIterative, SolverClosedForm}
+
+// maxSolverIterations bounds the search for a stable share count
+const maxSolverIterations = 100
+
+// solveShares finds the shares to sell at price, sized at sizing, that cover
+// base plus the fees of the sale itself; fixed is a fee charged on every
+// sale. start is the iterative solver's first guess. Each guess is recorded
+// in the trace, and ok is false when the count never settles.
+func (c *Calculator) solveShares(base, fixed, start, price, sizing Money) (shares Money, trace []SolverStep, ok bool) {
+	if c.config.Solver == SolverClosedForm {
+		if s, found := c.closedFormShares(base, fixed, price, sizing); found {
+			start = s
+		}
+	}
+	shares = start
+	for i := 0; i < maxSolverIterations; i++ {
+		fees := c.saleFees(shares, price) + fixed
+		required := base + fees
+		next := c.sharesFor(required, sizing)
+		trace = append(trace, SolverStep{
+			Iteration:     i + 1,
+			SharesToSell:  shares.Float64(),
+			Fees:          fees.Float64(),
+			TotalRequired: required.Float64(),
+			NextShares:    next.Float64(),
+		})
+		if next == shares {
+			return shares, trace, true
+		}
+		shares = next
+	}
+	return shares, trace, false
+}
+
+// closedFormShares returns the fewest shares, on the share policy's grid,
+// whose proceeds can cover base and the fees of selling them, ignoring the
+// cent rounding of the fees. Those roundings only add cost, so the answer is
+// never above the stable share count and solveShares climbs to it from
+// here; a guess the exact fees already cover with room to spare is rejected.
+func (c *Calculator) closedFormShares(base, fixed, price, sizing Money) (Money, bool) {
+	p, q := sizing.Float64(), price.Float64()
+	if p <= 0 {
+		return 0, false
+	}
+	fees, reg := c.config.BrokerFees, c.config.RegulatoryFees
+	rate, minimum := c.volumeRates()
+	room := math.Inf(1)
+	if fees.AnnualCap > 0 {
+		room = math.Max(fees.AnnualCap-c.ytd.Fees, 0)
+	}
+	// Rounding to the nearest share covers anything above half a share short
+	target := (base + fixed).Float64()
+	if c.config.SharePolicy == ShareRoundNearest {
+		target -= p / 2
+	}
+
+	// The commission tier and TAF cap change the fee's slope at fixed share counts
+	breaks := []float64{0}
+	for _, t := range fees.CommissionTiers {
+		if t.UpTo > 0 && q > 0 {
+			breaks = append(breaks, t.UpTo/q)
+		}
+	}
+	if reg.TAFRate > 0 && reg.TAFMax > 0 {
+		breaks = append(breaks, reg.TAFMax/reg.TAFRate)
+	}
+	sort.Float64s(breaks)
+
+	for i, lo := range breaks {
+		hi := math.Inf(1)
+		if i+1 < len(breaks) {
+			hi = breaks[i+1]
+		}
+		if hi <= lo {
+			continue
+		}
+		tier, _ := fees.tierFor(midpoint(lo, hi) * q)
+		perShare := rate + tier.Rate*q
+
+		// Within a tier the minimum fee and the annual cap are the breakpoints
+		pieces := []float64{lo}
+		for _, level := range []float64{minimum, room} {
+			if perShare > 0 && !math.IsInf(level, 1) {
+				if s := (level - tier.Flat) / perShare; s > lo && s < hi {
+					pieces = append(pieces, s)
+				}
+			}
+		}
+		sort.Float64s(pieces)
+		pieces = append(pieces, hi)
+
+		for j := 0; j+1 < len(pieces); j++ {
+			a, b := pieces[j], pieces[j+1]
+			mid := midpoint(a, b)
+
+			// The fees are fixed + slope × shares on this piece
+			fixedFee, slope := tier.Flat, perShare
+			if tier.Flat+perShare*mid < minimum {
+				fixedFee, slope = minimum, 0
+			}
+			if fixedFee+slope*mid > room {
+				fixedFee, slope = room, 0
+			}
+			slope += reg.SECRate * q
+			if reg.TAFMax > 0 && reg.TAFRate*mid > reg.TAFMax {
+				fixedFee += reg.TAFMax
+			} else {
+				slope += reg.TAFRate
+			}
+
+			// Proceeds s × p must reach target + fixedFee + slope × s
+			var s float64
+			switch {
+			case p > slope:
+				s = math.Max((target+fixedFee)/(p-slope), a)
+				if s > b {
+					continue
+				}
+			case a*p >= target+fixedFee+slope*a:
+				s = a
+			default:
+				continue
+			}
+			shares := c.floorShares(s)
+			// A guess the fees already cover is past the fewest shares
+			if next := c.sharesFor(base+fixed+c.saleFees(shares, price), sizing); next < shares {
+				return 0, false
+			}
+			return shares, true
+		}
+	}
+	return 0, false
+}
+
+// midpoint returns a point inside [lo, hi), which may be unbounded
+func midpoint(lo, hi float64) float64 {
+	if math.IsInf(hi, 1) {
+		return lo + 1
+	}
+	return (lo + hi) / 2
+}
+
+// floorShares rounds s down to the share policy's grid, allowing for the
+// float error of the closed-form solution
+func (c *Calculator) floorShares(s float64) Money {
+	if s <= 0 {
+		return 0
+	}
+	if c.config.SharePolicy == ShareFractional {
+		return NewMoney((math.Floor(s*moneyScale) - 1) / moneyScale).Max(0)
+	}
+	return NewMoney(math.Floor(s - 1e-9)).Max(0)
+}
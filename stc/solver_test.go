@@ -0,0 +1,24 @@
+package stc
+
+import "testing"
+
+// TestSolversAgreeWithFlatFee covers the processing fee, which the closed-form
+// solver once left out of the options sale
+func TestSolversAgreeWithFlatFee(t *testing.T) {
+	config := Config{
+		TaxRates:   TaxRates{Federal: 0.22, Medicare: 0.0145, SocialSec: 0.062, State: 0.002},
+		BrokerFees: BrokerFees{CommissionRate: 0.01, FlatFee: 10},
+	}
+	input := Input{ExercisePrice: 5.31, ExercisedShares: 1090, FMV: 83.58}
+
+	calc := NewCalculator(config)
+	iterative := calc.Calculate(input)
+	if v := calc.CheckResult(input, iterative, 0.01); len(v) > 0 {
+		t.Errorf("iterative result violates %v", v)
+	}
+	config.Solver = SolverClosedForm
+	closedForm := NewCalculator(config).Calculate(input)
+	if iterative.SharesToSell != 375 || closedForm.SharesToSell != 375 {
+		t.Errorf("iterative sells %v shares, closed-form %v; want 375", iterative.SharesToSell, closedForm.SharesToSell)
+	}
+}
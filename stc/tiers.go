@@ -0,0 +1,41 @@
+package stc
+
+// Tier is one band of a tiered commission schedule. A trade whose value is
+// at most UpTo falls in the band and pays Flat plus Rate of its value, on
+// top of the per-share CommissionRate.
+type Tier struct {
+	UpTo float64 `json:"upTo,omitempty"` // Trade value ceiling of the band; 0 means no ceiling
+	Flat float64 `json:"flat,omitempty"` // Fixed commission for a trade in the band, e.g. 19.95
+	Rate float64 `json:"rate,omitempty"` // Fraction of the trade value, e.g. 0.005
+}
+
+// tierFor returns the first band, in listed order, that a trade of the given
+// value falls in. A value above every ceiling uses the last band.
+func (f BrokerFees) tierFor(value float64) (Tier, bool) {
+	if len(f.CommissionTiers) == 0 {
+		return Tier{}, false
+	}
+	for _, t := range f.CommissionTiers {
+		if t.UpTo == 0 || value <= t.UpTo {
+			return t, true
+		}
+	}
+	return f.CommissionTiers[len(f.CommissionTiers)-1], true
+}
+
+// commission returns the commission for selling shares at price before the
+// minimum fee: the per-share rate for the year's volume plus the tier the
+// trade value falls in
+func (c *Calculator) commission(shares, price Money) Money {
+	fees := c.config.BrokerFees
+	rate, _ := c.volumeRates()
+	commission := shares.MulFloat(rate)
+	if shares <= 0 {
+		return commission
+	}
+	value := shares.Mul(price)
+	if t, ok := fees.tierFor(value.Float64()); ok {
+		commission += NewMoney(t.Flat) + value.MulFloat(t.Rate)
+	}
+	return commission
+}
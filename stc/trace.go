@@ -0,0 +1,10 @@
+package stc
+
+// SolverStep records one iteration of the sell-to-cover solver
+type SolverStep struct {
+	Iteration     int     `json:"iteration"`
+	SharesToSell  float64 `json:"sharesToSell"`  // Guess going into this iteration
+	Fees          float64 `json:"fees"`          // Broker fees at that guess
+	TotalRequired float64 `json:"totalRequired"` // Costs the sale must cover
+	NextShares    float64 `json:"nextShares"`    // Shares needed to cover TotalRequired; equal to the guess once stable
+}
@@ -0,0 +1,38 @@
+package stc
+
+import (
+	"math"
+
+	"fynance/stc/amt"
+)
+
+// TrueUp compares the federal income tax withheld on a transaction with
+// the tax its income adds at the marginal brackets, estimating what is
+// owed or refunded at filing. Flat supplemental withholding rarely matches.
+type TrueUp struct {
+	Income       float64 `json:"income"`       // Taxable income of the transaction
+	Withheld     float64 `json:"withheld"`     // Federal income tax withheld
+	Liability    float64 `json:"liability"`    // Tax the income adds on top of the year's other income
+	MarginalRate float64 `json:"marginalRate"` // Rate on the last dollar of the income
+	Shortfall    float64 `json:"shortfall"`    // Liability - Withheld; negative is a refund
+}
+
+// TrueUp estimates the federal liability of income stacked on ytd income,
+// using the configured brackets or else the 2025 schedule for status. It
+// ignores deductions and credits, so it is an advisory figure.
+func (c *Calculator) TrueUp(income, withheld, ytd float64, status amt.FilingStatus) TrueUp {
+	c = c.snapshot()
+	schedule := c.config.FederalBrackets
+	if len(schedule) == 0 {
+		schedule = FederalBracketsFor(status)
+	}
+	ytd = math.Max(ytd, 0)
+	t := TrueUp{Income: roundMoney(income), Withheld: roundMoney(withheld)}
+	if t.Income <= 0 {
+		return t
+	}
+	t.Liability = roundMoney(schedule.TaxOnTop(ytd, t.Income))
+	t.MarginalRate = schedule.MarginalRate(ytd + t.Income)
+	t.Shortfall = roundMoney(t.Liability - t.Withheld)
+	return t
+}
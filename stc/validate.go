@@ -0,0 +1,444 @@
+package stc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Errors returned by the checked calculations. Input problems are wrapped
+// in an InputError naming the field, so test for them with errors.Is.
+var (
+	ErrNegativeShares   = errors.New("shares must be a non-negative number")
+	ErrInvalidPrice     = errors.New("price must be a non-negative number")
+	ErrInvalidAmount    = errors.New("amount must be a non-negative number")
+	ErrInvalidRate      = errors.New("rate must be a fraction from 0 to 1")
+	ErrInvalidFee       = errors.New("fee must be a non-negative number")
+	ErrUnknownMode      = errors.New("unknown sale mode")
+	ErrUnknownStatus    = errors.New("unknown filing status")
+	ErrUnknownSetting   = errors.New("unknown setting")
+	ErrConfigConflict   = errors.New("settings contradict each other")
+	ErrSolverNoConverge = errors.New("solver did not settle on a number of shares")
+	ErrTooLarge         = errors.New("value is too large to calculate")
+)
+
+// MaxAmount is the largest share count, price or amount the checked
+// calculations accept, and the largest value of shares times price. Money
+// holds about 9.2e12, so this leaves room for the sums built from it.
+const MaxAmount = 1e12
+
+// InputError is a field that failed validation, e.g. a negative share count
+type InputError struct {
+	Field string  `json:"field"`
+	Value float64 `json:"value"`
+	Err   error   `json:"-"`
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%s: %v (got %g)", e.Field, e.Err, e.Value)
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// validator collects every invalid field rather than stopping at the first
+type validator struct {
+	errs []error
+}
+
+// check records field as invalid unless v is a finite number from 0 to limit
+func (v *validator) check(field string, value, limit float64, err error) {
+	if math.IsNaN(value) || value < 0 || value > limit {
+		v.errs = append(v.errs, &InputError{Field: field, Value: value, Err: err})
+	}
+}
+
+// bounded records field as invalid unless v is a number from 0 to MaxAmount
+func (v *validator) bounded(field string, value float64, err error) {
+	if value > MaxAmount {
+		v.errs = append(v.errs, &InputError{Field: field, Value: value, Err: ErrTooLarge})
+		return
+	}
+	v.check(field, value, MaxAmount, err)
+}
+
+// value records field as too large when shares at any of prices are worth
+// more than MaxAmount, since every sum of the calculation starts from it
+func (v *validator) value(field string, shares float64, prices ...float64) {
+	for _, p := range prices {
+		if shares <= MaxAmount && p <= MaxAmount && shares*p > MaxAmount {
+			v.errs = append(v.errs, &InputError{Field: field, Value: shares * p, Err: ErrTooLarge})
+			return
+		}
+	}
+}
+
+func (v *validator) shares(field string, value float64) {
+	v.bounded(field, value, ErrNegativeShares)
+}
+
+func (v *validator) price(field string, value float64) {
+	v.bounded(field, value, ErrInvalidPrice)
+}
+
+func (v *validator) amount(field string, value float64) {
+	v.bounded(field, value, ErrInvalidAmount)
+}
+
+func (v *validator) rate(field string, value float64) {
+	v.check(field, value, 1, ErrInvalidRate)
+}
+
+func (v *validator) fee(field string, value float64) {
+	v.bounded(field, value, ErrInvalidFee)
+}
+
+// settingKind decides the range Validate accepts for a setting
+type settingKind int
+
+const (
+	settingAmount  settingKind = iota // 0 to MaxAmount
+	settingShares                     // 0 to MaxAmount shares
+	settingFee                        // 0 to MaxAmount dollars
+	settingRate                       // A fraction from 0 to 1
+	settingHaircut                    // A fraction from 0 to just under 1
+)
+
+// configSettings classifies every number Config.Validate checks by its JSON
+// name. A name is the same kind wherever it appears, e.g. "rate" in each tier.
+var configSettings = map[string]settingKind{
+	"federal": settingRate, "medicare": settingRate, "medicareSurtax": settingRate, "socialSec": settingRate,
+	"state": settingRate, "localSdi": settingRate, "rate": settingRate, "commissionRate": settingRate,
+	"secRate": settingRate, "tafRate": settingRate, "priceHaircut": settingHaircut,
+	"minimumFee": settingFee, "flatFee": settingFee, "flat": settingFee, "annualCap": settingFee, "tafMax": settingFee,
+	"extraShares": settingShares, "threshold": settingAmount, "upTo": settingAmount, "after": settingAmount,
+	"socialSecWageBase": settingAmount,
+}
+
+// SettingMaximum returns the largest value Config.Validate accepts for the
+// setting with the given JSON name, for describing configs in a schema. It
+// returns false for names Validate does not check. The minimum is always 0.
+func SettingMaximum(name string) (float64, bool) {
+	kind, ok := configSettings[name]
+	switch {
+	case !ok:
+		return 0, false
+	case kind == settingRate:
+		return 1, true
+	case kind == settingHaircut:
+		// A haircut of 100% would leave nothing to size the sale at
+		return math.Nextafter(1, 0), true
+	}
+	return MaxAmount, true
+}
+
+// setting checks the Config setting with the given JSON name, reporting it as label
+func (v *validator) setting(name, label string, value float64) {
+	limit, _ := SettingMaximum(name)
+	switch configSettings[name] {
+	case settingRate, settingHaircut:
+		v.check(label, value, limit, ErrInvalidRate)
+	case settingFee:
+		v.fee(label, value)
+	case settingShares:
+		v.shares(label, value)
+	default:
+		v.amount(label, value)
+	}
+}
+
+func (v *validator) mode(mode SaleMode) {
+	switch mode {
+	case "", SellToCover, SellAll, WithholdToCover, PayCash, GrossUp:
+	default:
+		v.errs = append(v.errs, fmt.Errorf("%w %q", ErrUnknownMode, mode))
+	}
+}
+
+func (v *validator) brokerYTD(ytd BrokerYTD) {
+	v.amount("YTD Broker Fees", ytd.Fees)
+	v.amount("YTD Trades", float64(ytd.Trades))
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// Validate reports every rate and fee the calculations cannot use: negative
+// or NaN values, and rates outside 0 to 1. Unlike ConfigLint it only flags
+// values that would produce a meaningless result.
+func (c Config) Validate() error {
+	var v validator
+	r := c.TaxRates
+	v.setting("federal", "Federal", r.Federal)
+	v.setting("medicare", "Medicare", r.Medicare)
+	v.setting("medicareSurtax", "Medicare Surtax", r.MedicareSurtax)
+	v.setting("socialSec", "Social Sec", r.SocialSec)
+	v.setting("state", "State", r.State)
+	v.setting("localSdi", "Local/SDI", r.LocalSDI)
+	for _, j := range r.Jurisdictions {
+		v.setting("rate", j.Name, j.Rate)
+	}
+	for _, p := range c.Residency {
+		v.setting("rate", "Residency "+p.State, p.Rate)
+	}
+	for _, b := range c.FederalBrackets {
+		v.setting("threshold", "Bracket Threshold", b.Threshold)
+		v.setting("rate", fmt.Sprintf("Bracket from $%.0f", b.Threshold), b.Rate)
+	}
+
+	f := c.BrokerFees
+	v.setting("commissionRate", "Commission Rate", f.CommissionRate)
+	v.setting("minimumFee", "Minimum Fee", f.MinimumFee)
+	v.setting("flatFee", "Processing Fee", f.FlatFee)
+	v.setting("extraShares", "Extra Shares", f.ExtraShares)
+	for i, t := range f.CommissionTiers {
+		v.setting("upTo", fmt.Sprintf("Tier %d Limit", i+1), t.UpTo)
+		v.setting("flat", fmt.Sprintf("Tier %d Flat Fee", i+1), t.Flat)
+		v.setting("rate", fmt.Sprintf("Tier %d Rate", i+1), t.Rate)
+	}
+	v.setting("annualCap", "Annual Cap", f.AnnualCap)
+	for _, t := range f.VolumeTiers {
+		v.setting("after", fmt.Sprintf("Volume Tier %d Trades", t.After), float64(t.After))
+		v.setting("commissionRate", fmt.Sprintf("Volume Tier %d Rate", t.After), t.CommissionRate)
+		v.setting("minimumFee", fmt.Sprintf("Volume Tier %d Minimum", t.After), t.MinimumFee)
+	}
+	v.setting("priceHaircut", "Price Haircut", c.PriceHaircut)
+	// The SEC rate is per dollar of proceeds and the TAF rate per share sold;
+	// neither comes near a dollar
+	v.setting("secRate", "SEC Fee Rate", c.RegulatoryFees.SECRate)
+	v.setting("tafRate", "TAF Rate", c.RegulatoryFees.TAFRate)
+	v.setting("tafMax", "TAF Maximum", c.RegulatoryFees.TAFMax)
+	v.setting("socialSecWageBase", "Social Security Wage Base", c.SocialSecWageBase)
+
+	if c.SharePolicy != "" && !slices.Contains(SharePolicies, c.SharePolicy) {
+		v.errs = append(v.errs, fmt.Errorf("Share Policy: %w %q", ErrUnknownSetting, c.SharePolicy))
+	}
+	if !slices.Contains(Solvers, c.Solver) {
+		v.errs = append(v.errs, fmt.Errorf("Solver: %w %q", ErrUnknownSetting, c.Solver))
+	}
+	if c.TaxModel != "" && !slices.Contains(TaxModels, c.TaxModel) {
+		v.errs = append(v.errs, fmt.Errorf("Tax Model: %w %q", ErrUnknownSetting, c.TaxModel))
+	}
+	v.conflicts(c)
+	return v.err()
+}
+
+// conflicts records settings that are each valid but cannot all hold, such
+// as a commission tier no sale can reach
+func (v *validator) conflicts(c Config) {
+	conflict := func(field, format string, args ...any) {
+		v.errs = append(v.errs, fmt.Errorf("%s: %w: %s", field, ErrConfigConflict, fmt.Sprintf(format, args...)))
+	}
+	tiers := c.BrokerFees.CommissionTiers
+	for i := 0; i+1 < len(tiers); i++ {
+		if tiers[i].UpTo == 0 || (tiers[i+1].UpTo != 0 && tiers[i+1].UpTo <= tiers[i].UpTo) {
+			conflict("Commission Tiers", "limits must increase, so tier %d is never reached", i+2)
+		}
+	}
+	seen := map[int]bool{}
+	for _, t := range c.BrokerFees.VolumeTiers {
+		if seen[t.After] {
+			conflict("Volume Tiers", "two tiers start after %d trades", t.After)
+		}
+		seen[t.After] = true
+	}
+	for _, p := range c.Residency {
+		if !p.Start.IsZero() && !p.End.IsZero() && p.End.Before(p.Start) {
+			conflict("Residency "+p.State, "ends %s before it starts %s", p.End.Format(time.DateOnly), p.Start.Format(time.DateOnly))
+		}
+	}
+}
+
+// Validate reports every input the options calculation cannot use
+func (in Input) Validate() error {
+	var v validator
+	v.price("Exercise Price", in.ExercisePrice)
+	v.shares("Exercised Shares", in.ExercisedShares)
+	v.price("FMV", in.FMV)
+	v.price("Sale Price", in.SalePrice)
+	v.value("Exercised Shares", in.ExercisedShares, in.ExercisePrice, in.FMV, in.SalePrice)
+	v.mode(in.Mode)
+	v.amount("YTD Income", in.YTDIncome)
+	v.amount("YTD Wages", in.YTDWages)
+	v.amount("YTD Supplemental", in.YTDSupplementalWages)
+	v.brokerYTD(in.BrokerYTD)
+	v.amount("Prior AMT Credit", in.PriorAMTCredit)
+	if !in.FilingStatus.Valid() {
+		v.errs = append(v.errs, fmt.Errorf("%w %q", ErrUnknownStatus, in.FilingStatus))
+	}
+	return v.err()
+}
+
+// Validate reports every input the RSU calculation cannot use
+func (in RSUInput) Validate() error {
+	var v validator
+	v.shares("Shares Released", in.SharesReleased)
+	v.shares("Dividend Equivalents", in.DividendEquivalentShares)
+	v.price("Vest Price", in.VestPrice)
+	v.price("Sale Price", in.SalePrice)
+	v.value("Shares Released", in.SharesReleased+in.DividendEquivalentShares, in.VestPrice, in.SalePrice)
+	v.mode(in.Mode)
+	v.amount("YTD Income", in.YTDIncome)
+	v.amount("YTD Wages", in.YTDWages)
+	v.amount("YTD Supplemental", in.YTDSupplementalWages)
+	v.brokerYTD(in.BrokerYTD)
+	return v.err()
+}
+
+// Validate reports every input the cash award calculation cannot use
+func (in CashAwardInput) Validate() error {
+	var v validator
+	v.shares("Units", in.Units)
+	v.price("Unit Price", in.UnitPrice)
+	v.amount("Cash Amount", in.Amount)
+	v.value("Units", in.Units, in.UnitPrice)
+	v.value("Cash Amount", in.Units*in.UnitPrice+in.Amount, 1)
+	v.amount("YTD Income", in.YTDIncome)
+	v.amount("YTD Wages", in.YTDWages)
+	v.amount("YTD Supplemental", in.YTDSupplementalWages)
+	return v.err()
+}
+
+// Validate reports every input the multi-lot calculation cannot use
+func (in MultiLotInput) Validate() error {
+	var v validator
+	total := 0.0
+	for i, l := range in.Lots {
+		name := l.Label
+		if name == "" {
+			name = fmt.Sprintf("Lot %d", i+1)
+		}
+		v.shares(name+" Shares", l.Shares)
+		v.price(name+" Exercise Price", l.ExercisePrice)
+		total += l.Shares
+	}
+	v.price("FMV", in.FMV)
+	v.price("Sale Price", in.SalePrice)
+	v.value("Shares", total, in.FMV, in.SalePrice)
+	v.mode(in.Mode)
+	v.amount("YTD Income", in.YTDIncome)
+	v.amount("YTD Wages", in.YTDWages)
+	v.amount("YTD Supplemental", in.YTDSupplementalWages)
+	v.brokerYTD(in.BrokerYTD)
+	return v.err()
+}
+
+// Validate reports every input the paystub calculation cannot use
+func (in PaystubInput) Validate() error {
+	var v validator
+	v.amount("Regular Pay", in.RegularWages)
+	v.amount("Regular Federal", in.RegularFederal)
+	v.amount("Regular State", in.RegularState)
+	v.amount("Regular Local", in.RegularLocal)
+	if sp := in.Split; sp != nil {
+		v.rate("Split First Share", sp.FirstShare)
+		v.amount("Second Regular Pay", sp.RegularWages)
+		v.amount("Second Regular Federal", sp.RegularFederal)
+		v.amount("Second Regular State", sp.RegularState)
+		v.amount("Second Regular Local", sp.RegularLocal)
+	}
+	return errors.Join(v.err(), in.Release.Validate())
+}
+
+// recoverOverflow returns a Money overflow as ErrTooLarge from a checked
+// calculation. Inputs within MaxAmount can still overflow, e.g. when the tax
+// rates add up to nearly 100% and a gross-up grows without bound.
+func recoverOverflow(err *error) {
+	if e := recover(); e != nil {
+		if e != errMoneyOverflow {
+			panic(e)
+		}
+		*err = errMoneyOverflow
+	}
+}
+
+// converged reports whether a solver trace ended on a stable share count.
+// Modes that sell a fixed number of shares leave no trace.
+func converged(trace []SolverStep) error {
+	if n := len(trace); n > 0 && trace[n-1].NextShares != trace[n-1].SharesToSell {
+		return fmt.Errorf("%w after %d iterations", ErrSolverNoConverge, n)
+	}
+	return nil
+}
+
+// NewCalculatorChecked is NewCalculator with the config validated first, so
+// a bad config fails here instead of producing results that look plausible
+func NewCalculatorChecked(config Config) (*Calculator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return NewCalculator(config), nil
+}
+
+// CalculateChecked is Calculate with the config and input validated first.
+// Invalid values return InputErrors and no result; a solver that does not
+// settle returns ErrSolverNoConverge with its last result.
+func (c *Calculator) CalculateChecked(input Input) (_ Result, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	if err := errors.Join(c.config.Validate(), input.Validate()); err != nil {
+		return Result{}, err
+	}
+	r := c.calculate(input, 0)
+	return r, converged(r.Trace)
+}
+
+// SolveForCashChecked is SolveForCash with the config and input validated first
+func (c *Calculator) SolveForCashChecked(input Input, targetResidual float64) (_ Result, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	var v validator
+	v.amount("Target Cash", targetResidual)
+	if err := errors.Join(c.config.Validate(), input.Validate(), v.err()); err != nil {
+		return Result{}, err
+	}
+	r := c.SolveForCash(input, targetResidual)
+	return r, converged(r.Trace)
+}
+
+// CalculateRSUChecked is CalculateRSU with the config and input validated first
+func (c *Calculator) CalculateRSUChecked(input RSUInput) (_ RSUResult, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	if err := errors.Join(c.config.Validate(), input.Validate()); err != nil {
+		return RSUResult{}, err
+	}
+	r := c.CalculateRSU(input)
+	return r, converged(r.Trace)
+}
+
+// CalculateCashAwardChecked is CalculateCashAward with the config and input validated first
+func (c *Calculator) CalculateCashAwardChecked(input CashAwardInput) (_ CashAwardResult, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	if err := errors.Join(c.config.Validate(), input.Validate()); err != nil {
+		return CashAwardResult{}, err
+	}
+	return c.CalculateCashAward(input), nil
+}
+
+// CalculateMultiLotChecked is CalculateMultiLot with the config and input validated first
+func (c *Calculator) CalculateMultiLotChecked(input MultiLotInput) (_ MultiLotResult, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	if err := errors.Join(c.config.Validate(), input.Validate()); err != nil {
+		return MultiLotResult{}, err
+	}
+	r := c.CalculateMultiLot(input)
+	return r, converged(r.Trace)
+}
+
+// PaystubChecked is Paystub with the config and input validated first
+func (c *Calculator) PaystubChecked(input PaystubInput) (_ PaystubResult, err error) {
+	defer recoverOverflow(&err)
+	c = c.snapshot()
+	if err := errors.Join(c.config.Validate(), input.Validate()); err != nil {
+		return PaystubResult{}, err
+	}
+	r := c.Paystub(input)
+	return r, converged(r.Release.Trace)
+}
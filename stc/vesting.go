@@ -0,0 +1,103 @@
+package stc
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Vest is a single scheduled release of shares
+type Vest struct {
+	Date   time.Time `json:"date"`
+	Shares float64   `json:"shares"`
+}
+
+// VestingSchedule describes how a grant releases shares over time
+type VestingSchedule struct {
+	GrantDate   time.Time `json:"grantDate"`
+	TotalShares float64   `json:"totalShares"`
+	Months      int       `json:"months"`      // Total vesting period, e.g. 48
+	CliffMonths int       `json:"cliffMonths"` // First release, e.g. 12 (0 for no cliff)
+	EveryMonths int       `json:"everyMonths"` // Release frequency after the cliff, e.g. 3
+
+	// DateRule moves vests off weekends and holidays; Holidays adds plan closures to the US market holidays
+	DateRule DateRule    `json:"dateRule,omitempty"`
+	Holidays []time.Time `json:"holidays,omitempty"`
+}
+
+// Vests expands the schedule into dated releases of whole shares, each
+// moved to a business day by DateRule.
+// Fractional remainders are carried forward and released with the final vest.
+func (s VestingSchedule) Vests() []Vest {
+	if s.Months <= 0 || s.EveryMonths <= 0 || s.TotalShares <= 0 {
+		return nil
+	}
+
+	perMonth := s.TotalShares / float64(s.Months)
+	var vests []Vest
+	released := 0.0
+
+	start := s.EveryMonths
+	if s.CliffMonths > 0 {
+		start = s.CliffMonths
+	}
+
+	for m := start; m <= s.Months; m += s.EveryMonths {
+		shares := math.Floor(perMonth*float64(m)) - released
+		if m+s.EveryMonths > s.Months {
+			shares = s.TotalShares - released
+		}
+		if shares <= 0 {
+			continue
+		}
+		released += shares
+		vests = append(vests, Vest{
+			Date:   s.DateRule.Adjust(s.GrantDate.AddDate(0, m, 0), s.Holidays),
+			Shares: shares,
+		})
+	}
+
+	return vests
+}
+
+// BufferReport shows the extra residual cash created by a broker's ExtraShares policy
+type BufferReport struct {
+	ExtraShares   float64         `json:"extraShares"`
+	ByYear        map[int]float64 `json:"byYear"` // Additional residual per calendar year
+	Total         float64         `json:"total"`
+	AverageAnnual float64         `json:"averageAnnual"`
+}
+
+// BufferImpact runs every vest with and without the configured ExtraShares buffer
+// and reports how much additional residual (refunded cash) the buffer produces.
+func (c *Calculator) BufferImpact(vests []Vest, vestPrice, salePrice float64) BufferReport {
+	c = c.snapshot()
+	report := BufferReport{
+		ExtraShares: c.config.BrokerFees.ExtraShares,
+		ByYear:      make(map[int]float64),
+	}
+	if len(vests) == 0 {
+		return report
+	}
+
+	noBufferFees := c.config.BrokerFees
+	noBufferFees.ExtraShares = 0
+	noBuffer := c.With(WithBrokerFees(noBufferFees))
+
+	for _, v := range vests {
+		input := RSUInput{SharesReleased: v.Shares, VestPrice: vestPrice, SalePrice: salePrice, Dates: TransactionDates{Vest: v.Date}}
+		delta := c.CalculateRSU(input).Residual - noBuffer.CalculateRSU(input).Residual
+		report.ByYear[c.config.TaxYearOf(v.Date)] += roundMoney(delta)
+		report.Total += roundMoney(delta)
+	}
+
+	years := make([]int, 0, len(report.ByYear))
+	for y := range report.ByYear {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	span := years[len(years)-1] - years[0] + 1
+	report.AverageAnnual = roundMoney(report.Total / float64(span))
+
+	return report
+}
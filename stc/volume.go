@@ -0,0 +1,50 @@
+package stc
+
+// VolumeTier is a negotiated commission that applies once enough trades have
+// been made in the year, e.g. a lower rate from the eleventh sale on
+type VolumeTier struct {
+	After          int     `json:"after"`          // Trades already made this year before the tier applies
+	CommissionRate float64 `json:"commissionRate"` // Replaces BrokerFees.CommissionRate
+	MinimumFee     float64 `json:"minimumFee"`     // Replaces BrokerFees.MinimumFee
+}
+
+// BrokerYTD is the broker activity already this year, which decides where
+// a sale falls in BrokerFees.VolumeTiers and how much of AnnualCap is left
+type BrokerYTD struct {
+	Fees   float64 `json:"fees,omitempty"`   // Commissions already paid
+	Trades int     `json:"trades,omitempty"` // Sales already made
+}
+
+// forYear returns a copy of the calculator that charges commissions as they
+// stand after ytd
+func (c *Calculator) forYear(ytd BrokerYTD) *Calculator {
+	return &Calculator{config: c.config, ytd: ytd}
+}
+
+// volumeRates returns the per-share commission rate and minimum fee for the
+// next trade: those of the last volume tier reached, else the base schedule
+func (c *Calculator) volumeRates() (rate, minimum float64) {
+	fees := c.config.BrokerFees
+	rate, minimum = fees.CommissionRate, fees.MinimumFee
+	after := -1
+	for _, t := range fees.VolumeTiers {
+		if c.ytd.Trades >= t.After && t.After > after {
+			rate, minimum, after = t.CommissionRate, t.MinimumFee, t.After
+		}
+	}
+	return rate, minimum
+}
+
+// chargedFee floors a commission at the minimum fee and holds it to what is
+// left of the annual cap
+func (c *Calculator) chargedFee(commission Money) Money {
+	_, minimum := c.volumeRates()
+	fee := commission.Max(NewMoney(minimum))
+	if limit := c.config.BrokerFees.AnnualCap; limit > 0 {
+		room := (NewMoney(limit) - NewMoney(c.ytd.Fees)).Max(0)
+		if fee > room {
+			fee = room
+		}
+	}
+	return fee
+}
@@ -0,0 +1,46 @@
+package stc
+
+import "fmt"
+
+// WarningCode identifies a result warning, so callers can react to a
+// kind of warning without matching its message
+type WarningCode string
+
+const (
+	WarnUnderwater WarningCode = "underwater"  // FMV is below the exercise price
+	WarnMinimumFee WarningCode = "minimum-fee" // The broker's minimum fee outweighs the commission
+	WarnOversold   WarningCode = "oversold"    // Covering the costs takes more shares than are held
+)
+
+// minimumFeeShare is the share of the proceeds above which a minimum fee
+// that replaces the commission is flagged
+const minimumFeeShare = 0.01
+
+// underwaterWarning flags an option exercised for more than the shares are worth
+func underwaterWarning(field string, exercisePrice, fmv float64) (Warning, bool) {
+	if exercisePrice <= fmv {
+		return Warning{}, false
+	}
+	return Warning{Code: WarnUnderwater, Field: field, Message: fmt.Sprintf(
+		"FMV $%.2f is below the $%.2f exercise price, so exercising costs more than the shares are worth", fmv, exercisePrice)}, true
+}
+
+// saleWarnings flags a sale whose minimum fee dwarfs the commission and
+// one that needs more shares than held. charged is the broker fee after the
+// minimum; withheld shares are checked against held but pay no fee.
+func (c *Calculator) saleWarnings(mode SaleMode, sold, held, price, charged Money) []Warning {
+	var warnings []Warning
+	if mode != WithholdToCover && sold > 0 {
+		commission := c.commission(sold, price)
+		if proceeds := sold.Mul(price); charged > commission && charged.Float64() > proceeds.Float64()*minimumFeeShare {
+			warnings = append(warnings, Warning{Code: WarnMinimumFee, Field: "Minimum Fee", Message: fmt.Sprintf(
+				"the $%.2f minimum fee replaces a $%.2f commission and costs %.1f%% of the $%.2f sale",
+				charged.Float64(), commission.Float64(), charged.Float64()/proceeds.Float64()*100, proceeds.Float64())})
+		}
+	}
+	if sold > held {
+		warnings = append(warnings, Warning{Code: WarnOversold, Field: "Shares To Sell", Message: fmt.Sprintf(
+			"covering the costs takes %g shares but only %g are held, so the sale cannot be made", sold.Float64(), held.Float64())})
+	}
+	return warnings
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+
+	"fynance/portfolio"
+)
+
+const (
+	portfolioFile = "portfolio.json"
+	// mergeBaseFile is the portfolio as of the last backup or restore, the
+	// common ancestor for merging a backup back in
+	mergeBaseFile = "portfolio.base.json"
+)
+
+// loadPortfolio reads the saved portfolio from app storage, or returns an empty one
+func loadPortfolio(a fyne.App) *portfolio.Portfolio {
+	return loadPortfolioFile(a, portfolioFile)
+}
+
+// loadPortfolioFile reads a portfolio from the named file in app storage, or returns an empty one
+func loadPortfolioFile(a fyne.App, name string) *portfolio.Portfolio {
+	uri, err := storage.Child(a.Storage().RootURI(), name)
+	if err != nil {
+		return &portfolio.Portfolio{}
+	}
+	r, err := storage.Reader(uri)
+	if err != nil {
+		return &portfolio.Portfolio{}
+	}
+	defer r.Close()
+
+	p, err := portfolio.Load(r)
+	if err != nil {
+		fyne.LogError("Failed to load portfolio", err)
+		return &portfolio.Portfolio{}
+	}
+	return p
+}
+
+// savePortfolio writes the portfolio to app storage
+func savePortfolio(a fyne.App, p *portfolio.Portfolio) error {
+	return savePortfolioFile(a, portfolioFile, p)
+}
+
+// savePortfolioFile writes a portfolio to the named file in app storage
+func savePortfolioFile(a fyne.App, name string, p *portfolio.Portfolio) error {
+	uri, err := storage.Child(a.Storage().RootURI(), name)
+	if err != nil {
+		return err
+	}
+	w, err := storage.Writer(uri)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return p.Save(w)
+}
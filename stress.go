@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+
+	"fynance/stc"
+)
+
+// maxReportedViolations caps how many failing cases are printed in full
+const maxReportedViolations = 20
+
+// runStress generates random valid inputs and configs, runs both calculations,
+// and reports every invariant violation or disagreement with the reference solver
+func runStress(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("stress", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	n := fs.Float64("n", 10000, "number of random cases per calculation (1e6 is accepted)")
+	seed := fs.Uint64("seed", 1, "random seed; the same seed reproduces the same cases")
+	tolerance := fs.Float64("tolerance", 0.01, "allowed rounding difference in dollars")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *n < 1 || *n > math.MaxInt32 {
+		fmt.Fprintln(stderr, "stress: --n must be between 1 and 2^31")
+		return 2
+	}
+
+	rng := rand.New(rand.NewPCG(*seed, *seed))
+	cases := int(*n)
+	var optionFailures, rsuFailures, reported int
+
+	report := func(kind string, v []stc.Violation, input any, cfg stc.Config) {
+		if reported >= maxReportedViolations {
+			return
+		}
+		reported++
+		in, _ := json.Marshal(input)
+		c, _ := json.Marshal(cfg)
+		for _, violation := range v {
+			fmt.Fprintf(stdout, "[%s] %s\n", kind, violation)
+		}
+		fmt.Fprintf(stdout, "  input:  %s\n  config: %s\n", in, c)
+	}
+
+	for i := 0; i < cases; i++ {
+		cfg := randomConfig(rng)
+		calc := stc.NewCalculator(cfg)
+
+		closed := cfg
+		closed.Solver = stc.SolverClosedForm
+		closedCalc := stc.NewCalculator(closed)
+
+		opt := randomInput(rng)
+		optResult := calc.Calculate(opt)
+		v := calc.CheckResult(opt, optResult, *tolerance)
+		if r := closedCalc.Calculate(opt); r.SharesToSell != optResult.SharesToSell {
+			v = append(v, solverMismatch(optResult.SharesToSell, r.SharesToSell))
+		}
+		if len(v) > 0 {
+			optionFailures++
+			report("options", v, opt, cfg)
+		}
+
+		rsu := randomRSUInput(rng)
+		rsuResult := calc.CalculateRSU(rsu)
+		v = calc.CheckRSUResult(rsu, rsuResult, *tolerance)
+		if r := closedCalc.CalculateRSU(rsu); r.SharesToSell != rsuResult.SharesToSell {
+			v = append(v, solverMismatch(rsuResult.SharesToSell, r.SharesToSell))
+		}
+		if len(v) > 0 {
+			rsuFailures++
+			report("rsu", v, rsu, cfg)
+		}
+	}
+
+	fmt.Fprintf(stdout, "stress: %d cases each (seed %d): %d options and %d RSU cases violated invariants\n",
+		cases, *seed, optionFailures, rsuFailures)
+	if optionFailures+rsuFailures > 0 {
+		return 1
+	}
+	return 0
+}
+
+// solverMismatch reports the closed-form solver settling on a different
+// share count than the iterative one
+func solverMismatch(iterative, closedForm float64) stc.Violation {
+	return stc.Violation{
+		Rule:   "solvers agree",
+		Detail: fmt.Sprintf("iterative sells %g shares, closed-form %g", iterative, closedForm),
+	}
+}
+
+// between returns a uniform value in [lo, hi) rounded to the given decimals
+func between(rng *rand.Rand, lo, hi float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round((lo+rng.Float64()*(hi-lo))*scale) / scale
+}
+
+func randomConfig(rng *rand.Rand) stc.Config {
+	cfg := stc.Config{
+		TaxRates: stc.TaxRates{
+			Federal:        between(rng, 0.10, 0.37, 4),
+			Medicare:       0.0145,
+			MedicareSurtax: 0.009,
+			SocialSec:      between(rng, 0, 0.062, 4),
+			State:          between(rng, 0, 0.133, 4),
+			LocalSDI:       between(rng, 0, 0.05, 4),
+		},
+		BrokerFees: stc.BrokerFees{
+			CommissionRate: between(rng, 0, 0.05, 4),
+			MinimumFee:     between(rng, 0, 50, 2),
+			FlatFee:        between(rng, 0, 25, 2),
+			ExtraShares:    float64(rng.IntN(3)),
+		},
+		CashTopUp:   rng.IntN(5) == 0,
+		SharePolicy: stc.SharePolicies[rng.IntN(len(stc.SharePolicies))],
+	}
+	if rng.IntN(4) == 0 {
+		cfg.BrokerFees.CommissionTiers = randomTiers(rng)
+	}
+	if rng.IntN(2) == 0 {
+		cfg.RegulatoryFees = stc.CurrentRegulatoryFees
+	}
+	if rng.IntN(4) == 0 {
+		cfg.BrokerFees.AnnualCap = between(rng, 0, 500, 2)
+		cfg.BrokerFees.VolumeTiers = []stc.VolumeTier{{
+			After:          1 + rng.IntN(20),
+			CommissionRate: between(rng, 0, cfg.BrokerFees.CommissionRate, 4),
+			MinimumFee:     between(rng, 0, cfg.BrokerFees.MinimumFee, 2),
+		}}
+	}
+	if rng.IntN(4) == 0 {
+		cfg.PriceHaircut = between(rng, 0, 0.1, 3)
+	}
+	if rng.IntN(2) == 0 {
+		cfg.SocialSecWageBase = stc.SocialSecWageBase
+	}
+	return cfg
+}
+
+// randomBrokerYTD is the broker activity before the trade, zero in half the cases
+func randomBrokerYTD(rng *rand.Rand) stc.BrokerYTD {
+	if rng.IntN(2) == 0 {
+		return stc.BrokerYTD{}
+	}
+	return stc.BrokerYTD{Fees: between(rng, 0, 600, 2), Trades: rng.IntN(25)}
+}
+
+// randomTiers builds a flat-then-percentage schedule whose commission never
+// drops at the band edge, like the ones ConfigLint accepts
+func randomTiers(rng *rand.Rand) []stc.Tier {
+	upTo := between(rng, 1000, 20000, 0)
+	flat := between(rng, 0, 30, 2)
+	rate := between(rng, flat/upTo, 0.01, 5)
+	return []stc.Tier{{UpTo: upTo, Flat: flat}, {Rate: rate}}
+}
+
+// randomMode picks each alternative to sell-to-cover in one case out of seven
+func randomMode(rng *rand.Rand) stc.SaleMode {
+	switch rng.IntN(7) {
+	case 0:
+		return stc.SellAll
+	case 1:
+		return stc.WithholdToCover
+	case 2:
+		return stc.PayCash
+	case 3:
+		return stc.GrossUp
+	}
+	return stc.SellToCover
+}
+
+func randomInput(rng *rand.Rand) stc.Input {
+	strike := between(rng, 1, 200, 2)
+	in := stc.Input{
+		ExercisePrice:        strike,
+		ExercisedShares:      float64(1 + rng.IntN(100000)),
+		FMV:                  between(rng, strike*1.01, strike*5, 2),
+		YTDWages:             between(rng, 0, 400000, 2),
+		YTDSupplementalWages: between(rng, 0, 1500000, 2),
+		BrokerYTD:            randomBrokerYTD(rng),
+		Mode:                 randomMode(rng),
+	}
+	// Half the exercises sell away from the FMV, as a release can
+	if rng.IntN(2) == 0 {
+		in.SalePrice = between(rng, in.FMV*0.9, in.FMV*1.1, 2)
+	}
+	return in
+}
+
+func randomRSUInput(rng *rand.Rand) stc.RSUInput {
+	vest := between(rng, 1, 1000, 2)
+	in := stc.RSUInput{
+		SharesReleased:       float64(1 + rng.IntN(100000)),
+		VestPrice:            vest,
+		SalePrice:            between(rng, vest*0.9, vest*1.1, 2),
+		YTDWages:             between(rng, 0, 400000, 2),
+		YTDSupplementalWages: between(rng, 0, 1500000, 2),
+		BrokerYTD:            randomBrokerYTD(rng),
+		Mode:                 randomMode(rng),
+	}
+	// Half the releases carry dividend equivalents, some fractional
+	if rng.IntN(2) == 0 {
+		in.DividendEquivalentShares = between(rng, 0, 500, 3)
+	}
+	return in
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/notify"
+	"fynance/plan"
+	"fynance/stc"
+)
+
+const planSubscriptionKey = "plan.subscription"
+
+// loadPlanSubscription reads the saved subscription from preferences
+func loadPlanSubscription(a fyne.App) (plan.Subscription, bool) {
+	raw := a.Preferences().String(planSubscriptionKey)
+	if raw == "" {
+		return plan.Subscription{}, false
+	}
+	var sub plan.Subscription
+	if err := json.Unmarshal([]byte(raw), &sub); err != nil || sub.URL == "" {
+		return plan.Subscription{}, false
+	}
+	return sub, true
+}
+
+// savePlanSubscription persists the subscription to preferences
+func savePlanSubscription(a fyne.App, sub plan.Subscription) {
+	data, err := json.Marshal(sub)
+	if err != nil {
+		return
+	}
+	a.Preferences().SetString(planSubscriptionKey, string(data))
+}
+
+// makePlanMenu builds the "Plan" menu for subscribing to company plan templates.
+// apply is called with the template config whenever the user accepts one.
+func makePlanMenu(a fyne.App, win fyne.Window, apply func(stc.Config)) *fyne.Menu {
+	subscribe := fyne.NewMenuItem("Subscribe to Template...", func() {
+		urlEntry := widget.NewEntry()
+		urlEntry.SetPlaceHolder("https://example.com/plan.json")
+		pinEntry := widget.NewEntry()
+		pinEntry.SetPlaceHolder("Optional SHA-256 checksum")
+
+		items := []*widget.FormItem{
+			widget.NewFormItem("Template URL", urlEntry),
+			widget.NewFormItem("Checksum", pinEntry),
+		}
+		dialog.ShowForm("Subscribe to Plan Template", "Subscribe", "Cancel", items, func(ok bool) {
+			if !ok || strings.TrimSpace(urlEntry.Text) == "" {
+				return
+			}
+			url := strings.TrimSpace(urlEntry.Text)
+			pin := strings.TrimSpace(pinEntry.Text)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				defer cancel()
+				t, sum, err := plan.Fetch(ctx, nil, url, pin)
+				fyne.Do(func() {
+					if err != nil {
+						dialog.ShowError(err, win)
+						return
+					}
+					sub := plan.Subscription{URL: url}
+					sub.Accept(plan.Update{Template: t, Checksum: sum})
+					savePlanSubscription(a, sub)
+					apply(t.Config)
+					dialog.ShowInformation("Plan Template",
+						fmt.Sprintf("Subscribed to %s (%s).", t.Name, t.Company), win)
+				})
+			}()
+		}, win)
+	})
+
+	check := fyne.NewMenuItem("Check for Updates", func() {
+		checkPlanUpdates(a, win, apply, true)
+	})
+
+	unsubscribe := fyne.NewMenuItem("Unsubscribe", func() {
+		a.Preferences().RemoveValue(planSubscriptionKey)
+	})
+
+	return fyne.NewMenu("Plan", subscribe, check, unsubscribe)
+}
+
+// checkPlanUpdates fetches the subscribed template and offers to apply any changes.
+// When interactive is false nothing is shown unless the template changed.
+func checkPlanUpdates(a fyne.App, win fyne.Window, apply func(stc.Config), interactive bool) {
+	sub, ok := loadPlanSubscription(a)
+	if !ok {
+		if interactive {
+			dialog.ShowInformation("Plan Template", "No plan template subscription.", win)
+		}
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		update, err := sub.Check(ctx, nil)
+		fyne.Do(func() {
+			if err != nil {
+				if interactive {
+					dialog.ShowError(err, win)
+				}
+				return
+			}
+			if !update.Changed {
+				if interactive {
+					dialog.ShowInformation("Plan Template", "Your plan template is up to date.", win)
+				}
+				return
+			}
+
+			msg := fmt.Sprintf("%s published version %s.", update.Template.Company, update.Template.Version)
+			if len(update.FeeChanges) > 0 {
+				msg += "\n\nFee schedule changes:\n" + strings.Join(update.FeeChanges, "\n")
+				sendAlerts(a, notify.Message{Kind: notify.FeeChange, Title: "Fee schedule changed",
+					Body: update.Template.Company + " updated its broker fees."})
+			}
+			if len(update.RateChanges) > 0 {
+				msg += "\n\nTax rate changes:\n" + strings.Join(update.RateChanges, "\n")
+			}
+			dialog.ShowConfirm("Plan Template Updated", msg+"\n\nApply the new template?", func(accept bool) {
+				if !accept {
+					return
+				}
+				sub.Accept(update)
+				savePlanSubscription(a, sub)
+				apply(update.Template.Config)
+				if len(update.FeeChanges)+len(update.RateChanges) > 0 {
+					offerRecompute(win, update.Template.Config)
+				}
+			}, win)
+		})
+	}()
+}
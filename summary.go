@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"fynance/events"
+	"fynance/viewmodel"
+)
+
+const (
+	appTitle        = "Fynance"
+	titleSummaryKey = "title.summary"
+)
+
+// pinSummary mirrors the latest result in the window title and, on desktop,
+// a system tray menu, so the key figure stays visible from other apps.
+// It does nothing unless enabled in Settings.
+func pinSummary(a fyne.App, win fyne.Window, bus *events.Bus) {
+	summary := fyne.NewMenuItem("No result yet", nil)
+	summary.Disabled = true
+	var tray *fyne.Menu
+
+	bus.Subscribe(events.ResultReady, func(e events.Event) {
+		if !a.Preferences().Bool(titleSummaryKey) {
+			return
+		}
+		vm := e.Payload.(viewmodel.ViewModel)
+		text := fmt.Sprintf("Net %s sh / %s resid", vm.NetShares, vm.Residual)
+		win.SetTitle(appTitle + " — " + text)
+
+		desk, ok := a.(desktop.App)
+		if !ok {
+			return
+		}
+		summary.Label = text
+		if tray == nil {
+			tray = fyne.NewMenu(appTitle, summary, fyne.NewMenuItem("Show "+appTitle, win.Show))
+			desk.SetSystemTrayMenu(tray)
+		}
+		tray.Refresh()
+	})
+}
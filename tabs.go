@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	tabNameKey  = "tab.%s.name"  // Custom title of the tab with this id; "" keeps the default
+	tabColorKey = "tab.%s.color" // Name of the tab's accent color in tabColors; "" keeps the theme's
+)
+
+// noTabColor is the color choice that keeps the theme's primary color
+const noTabColor = "Default"
+
+// tabColors are the accent colors a tab can be given, e.g. one per employer
+// or family member, so the wrong configuration is harder to use by mistake
+var tabColors = []struct {
+	name  string
+	color color.NRGBA
+}{
+	{"Teal", color.NRGBA{R: 0, G: 150, B: 136, A: 255}},
+	{"Orange", color.NRGBA{R: 245, G: 124, B: 0, A: 255}},
+	{"Purple", color.NRGBA{R: 142, G: 68, B: 173, A: 255}},
+	{"Rose", color.NRGBA{R: 216, G: 27, B: 96, A: 255}},
+	{"Gold", color.NRGBA{R: 212, G: 172, B: 13, A: 255}},
+	{"Slate", color.NRGBA{R: 96, G: 125, B: 139, A: 255}},
+}
+
+// tabColor looks up an accent color by name
+func tabColor(name string) (color.Color, bool) {
+	for _, c := range tabColors {
+		if c.name == name {
+			return c.color, true
+		}
+	}
+	return nil, false
+}
+
+// accentTheme replaces the primary color of a theme, so the buttons and
+// highlights of one tab take its accent color
+type accentTheme struct {
+	fyne.Theme
+	accent color.Color
+}
+
+func (t accentTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if name == theme.ColorNamePrimary {
+		return t.accent
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// styledTab is a navigation tab the user can rename and give an accent
+// color. The color shows as a stripe along the top of the tab and in its
+// primary buttons.
+type styledTab struct {
+	id    string // Preference id, e.g. "exercise"
+	title string // Default title
+	item  *container.TabItem
+
+	stripe   *canvas.Rectangle
+	override *container.ThemeOverride
+}
+
+// newStyledTab wraps content in a tab whose name and color come from preferences
+func newStyledTab(a fyne.App, id, title string, icon fyne.Resource, content fyne.CanvasObject) *styledTab {
+	t := &styledTab{id: id, title: title}
+	t.stripe = canvas.NewRectangle(color.Transparent)
+	t.stripe.SetMinSize(fyne.NewSize(0, 4))
+	t.override = container.NewThemeOverride(content, a.Settings().Theme())
+	t.item = container.NewTabItemWithIcon(title, icon, container.NewBorder(t.stripe, nil, nil, nil, t.override))
+	t.apply(a)
+	return t
+}
+
+// apply reads the tab's name and color from preferences
+func (t *styledTab) apply(a fyne.App) {
+	prefs := a.Preferences()
+	t.item.Text = t.title
+	if name := strings.TrimSpace(prefs.String(fmt.Sprintf(tabNameKey, t.id))); name != "" {
+		t.item.Text = name
+	}
+
+	base := a.Settings().Theme()
+	if accent, ok := tabColor(prefs.String(fmt.Sprintf(tabColorKey, t.id))); ok {
+		t.stripe.FillColor = accent
+		t.stripe.Show()
+		t.override.Theme = accentTheme{Theme: base, accent: accent}
+	} else {
+		t.stripe.Hide()
+		t.override.Theme = base
+	}
+	t.stripe.Refresh()
+	t.override.Refresh()
+}
+
+// showTabStyleDialog renames tabs and picks their accent colors. A blank
+// name restores the default.
+func showTabStyleDialog(a fyne.App, win fyne.Window, tabs *container.AppTabs, styled []*styledTab) {
+	colorNames := []string{noTabColor}
+	for _, c := range tabColors {
+		colorNames = append(colorNames, c.name)
+	}
+
+	prefs := a.Preferences()
+	names := make([]*widget.Entry, len(styled))
+	colors := make([]*widget.Select, len(styled))
+	var items []*widget.FormItem
+	for i, t := range styled {
+		names[i] = widget.NewEntry()
+		names[i].SetPlaceHolder(t.title)
+		names[i].SetText(prefs.String(fmt.Sprintf(tabNameKey, t.id)))
+		colors[i] = widget.NewSelect(colorNames, nil)
+		colors[i].SetSelected(noTabColor)
+		if _, ok := tabColor(prefs.String(fmt.Sprintf(tabColorKey, t.id))); ok {
+			colors[i].SetSelected(prefs.String(fmt.Sprintf(tabColorKey, t.id)))
+		}
+		items = append(items, widget.NewFormItem(t.title, container.NewGridWithColumns(2, names[i], colors[i])))
+	}
+
+	dialog.ShowForm("Customize Tabs", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		for i, t := range styled {
+			prefs.SetString(fmt.Sprintf(tabNameKey, t.id), strings.TrimSpace(names[i].Text))
+			c := colors[i].Selected
+			if c == noTabColor {
+				c = ""
+			}
+			prefs.SetString(fmt.Sprintf(tabColorKey, t.id), c)
+			t.apply(a)
+		}
+		tabs.Refresh()
+	}, win)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+)
+
+// showForeignTaxCreditDialog estimates double-taxation relief for a cross-border vest
+func showForeignTaxCreditDialog(win fyne.Window) {
+	incomeEntry := widget.NewEntry()
+	incomeEntry.SetPlaceHolder("Vest income ($)")
+	fractionEntry := widget.NewEntry()
+	fractionEntry.SetText("0.5")
+	withheldEntry := widget.NewEntry()
+	withheldEntry.SetText("0.00")
+	homeFedEntry := widget.NewEntry()
+	homeFedEntry.SetText("0.22")
+	homeStateEntry := widget.NewEntry()
+	homeStateEntry.SetText("0.00")
+	hostCountryEntry := widget.NewEntry()
+	hostCountryEntry.SetPlaceHolder("GB")
+	hostRateEntry := widget.NewEntry()
+	hostRateEntry.SetText("0.40")
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Income ($)", incomeEntry),
+		widget.NewFormItem("Foreign Share (0-1)", fractionEntry),
+		widget.NewFormItem("Home Withheld ($)", withheldEntry),
+		widget.NewFormItem("Home Federal Rate", homeFedEntry),
+		widget.NewFormItem("Home State Rate", homeStateEntry),
+		widget.NewFormItem("Host Country", hostCountryEntry),
+		widget.NewFormItem("Host Rate", hostRateEntry),
+	}
+
+	dialog.ShowForm("Foreign Tax Credit", "Estimate", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		income, err1 := parseFloat(incomeEntry.Text)
+		fraction, err2 := parseFloat(fractionEntry.Text)
+		withheld, err3 := parseFloat(withheldEntry.Text)
+		homeFed, err4 := parseFloat(homeFedEntry.Text)
+		homeState, err5 := parseFloat(homeStateEntry.Text)
+		hostRate, err6 := parseFloat(hostRateEntry.Text)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil {
+			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
+			return
+		}
+
+		home := stc.NewDefaultCalculator().GetConfig()
+		home.TaxRates.Federal = homeFed
+		home.TaxRates.State = homeState
+		host := stc.Config{
+			Country:  strings.ToUpper(strings.TrimSpace(hostCountryEntry.Text)),
+			TaxRates: stc.TaxRates{Federal: hostRate},
+		}
+
+		res := stc.EstimateForeignTaxCredit(stc.FTCInput{
+			Income:          income,
+			ForeignFraction: fraction,
+			HomeWithheld:    withheld,
+			Home:            home,
+			Host:            host,
+		})
+
+		dialog.ShowInformation("Foreign Tax Credit", fmt.Sprintf(
+			"Home tax: $%.2f\n"+
+				"Host tax: $%.2f\n"+
+				"Credit limitation: $%.2f\n"+
+				"Credit claimed: $%.2f\n"+
+				"Excess host tax: $%.2f\n"+
+				"Additional home tax due: $%.2f\n"+
+				"Total tax burden: $%.2f",
+			res.HomeTax, res.HostTax, res.Limitation, res.Credit,
+			res.Excess, res.NetAdditional, res.TotalBurden,
+		), win)
+	}, win)
+}
@@ -3,111 +3,229 @@ package main
 import (
 	_ "embed"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
-	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"fynance/events"
+	"fynance/expr"
+	"fynance/portfolio"
 	"fynance/stc"
+	"fynance/stc/amt"
+	"fynance/viewmodel"
+	"fynance/widgets"
 )
 
-// --- CUSTOM WIDGET: SmartEntry ---
-// SmartEntry extends widget.Entry to handle Enter/Return keys for submission
-// while preserving standard shortcuts (Ctrl+A, Tab, etc.)
-type SmartEntry struct {
-	widget.Entry
-	onEnter func()
+// fieldCashTopUp identifies the unlabelled "Pay shortfall in cash" row
+const fieldCashTopUp = "Cash Top-Up"
+
+// fieldRegFees identifies the unlabelled "Include SEC and FINRA fees" row
+const fieldRegFees = "Regulatory Fees"
+
+// regulatoryFees returns the current SEC and FINRA rates, or none when excluded
+func regulatoryFees(include bool) stc.RegulatoryFees {
+	if include {
+		return stc.CurrentRegulatoryFees
+	}
+	return stc.RegulatoryFees{}
+}
+
+// sharePolicyLabels names each share policy in the broker forms
+var sharePolicyLabels = map[stc.SharePolicy]string{
+	stc.ShareWhole:        "Whole shares",
+	stc.ShareFractional:   "Fractional shares",
+	stc.ShareRoundNearest: "Nearest whole share",
 }
 
-func NewSmartEntry(placeholder string) *SmartEntry {
-	e := &SmartEntry{}
-	e.ExtendBaseWidget(e)
-	e.SetPlaceHolder(placeholder)
-	e.Text = placeholder
-	return e
+// newSharePolicySelect lists the share policies, starting on whole shares
+func newSharePolicySelect() *widget.Select {
+	var options []string
+	for _, p := range stc.SharePolicies {
+		options = append(options, sharePolicyLabels[p])
+	}
+	sel := widget.NewSelect(options, nil)
+	sel.SetSelectedIndex(0)
+	return sel
 }
 
-func (e *SmartEntry) SetOnEnter(f func()) {
-	e.onEnter = f
+// dateRuleLabels names each vest date rule in the grant forms
+var dateRuleLabels = map[stc.DateRule]string{
+	stc.VestAsScheduled:       "As scheduled",
+	stc.VestFollowing:         "Next business day",
+	stc.VestModifiedFollowing: "Next business day in the month",
+	stc.VestPreceding:         "Previous business day",
 }
 
-// TypedKey intercepts key presses to handle Enter/Return
-func (e *SmartEntry) TypedKey(key *fyne.KeyEvent) {
-	if key.Name == fyne.KeyReturn || key.Name == fyne.KeyEnter {
-		if e.onEnter != nil {
-			e.onEnter()
-		}
-		// We consume the event so it doesn't add a newline
-		return
+// newDateRuleSelect lists the vest date rules, starting on as scheduled
+func newDateRuleSelect() *widget.Select {
+	var options []string
+	for _, r := range stc.DateRules {
+		options = append(options, dateRuleLabels[r])
 	}
-	// Delegate to base implementation for all other keys (navigation, typing)
-	e.Entry.TypedKey(key)
+	sel := widget.NewSelect(options, nil)
+	sel.SetSelectedIndex(0)
+	return sel
 }
 
-// TypedShortcut ensures standard shortcuts (Copy/Paste/SelectAll) work
-func (e *SmartEntry) TypedShortcut(shortcut fyne.Shortcut) {
-	// Fyne's base Entry handles Cut/Copy/Paste/SelectAll.
-	// We strictly pass it through to ensure native OS behavior (Cmd+A / Ctrl+A).
-	e.Entry.TypedShortcut(shortcut)
+// saleModes and saleModeLabels are the choices of the Sale select
+var (
+	saleModes      = []stc.SaleMode{stc.SellToCover, stc.SellAll, stc.WithholdToCover, stc.PayCash, stc.GrossUp}
+	saleModeLabels = []string{"Sell to Cover", "Sell All", "Withhold to Cover", "Pay in Cash", "Employer Gross-Up"}
+)
+
+// newSaleModeSelect chooses how a transaction is settled, starting on sell-to-cover
+func newSaleModeSelect() *widget.Select {
+	sel := widget.NewSelect(saleModeLabels, nil)
+	sel.SetSelectedIndex(0)
+	return sel
 }
 
-// Ensure interface compliance
-var _ fyne.Focusable = (*SmartEntry)(nil)
-var _ fyne.Widget = (*SmartEntry)(nil)
-var _ desktop.Keyable = (*SmartEntry)(nil)
+// defaultTaxRates and defaultBrokerFees pre-fill the calculator forms
+var (
+	defaultTaxRates   = stc.TaxRates{Federal: 0.22, Medicare: 0.0145, MedicareSurtax: 0.009, SocialSec: 0.062}
+	defaultBrokerFees = stc.BrokerFees{CommissionRate: 0.03, MinimumFee: 25}
+)
 
 // --- TOOL 1: Sell To Cover (Options) ---
-func makeSTCTab(win fyne.Window) fyne.CanvasObject {
+func makeSTCTab(win fyne.Window, bus *events.Bus, onKeep func(portfolio.Lot),
+	valuationOn func(time.Time) (portfolio.Valuation, bool)) fyne.CanvasObject {
 	// --- INPUT FIELDS ---
 	// Using SmartEntry for "Enter to Calculate" support
-	exSharesEntry := NewSmartEntry("0")
-	exPriceEntry := NewSmartEntry("0.00")
-	fmvEntry := NewSmartEntry("0.00")
-
-	fedTaxEntry := NewSmartEntry("0.22")
-	medTaxEntry := NewSmartEntry("0.0145")
-	ssTaxEntry := NewSmartEntry("0.062")
-	stateTaxEntry := NewSmartEntry("0.00")
-	localTaxEntry := NewSmartEntry("0.00")
-	commRateEntry := NewSmartEntry("0.03")
-	minFeeEntry := NewSmartEntry("25.00")
-
-	// --- OUTPUT LABELS ---
-	lblNetShares := canvas.NewText("-", theme.PrimaryColor())
-	lblNetShares.TextSize = 24
-	lblNetShares.TextStyle = fyne.TextStyle{Bold: true}
-
-	lblResidual := canvas.NewText("-", theme.SuccessColor())
-	lblResidual.TextSize = 24
-	lblResidual.TextStyle = fyne.TextStyle{Bold: true}
-
-	lblSharesSold := widget.NewLabel("-")
-	lblTotalCost := widget.NewLabel("-")
-	lblGrossProceeds := widget.NewLabel("-")
-	lblTaxes := widget.NewLabel("-")
-	lblFees := widget.NewLabel("-")
+	exSharesEntry := widgets.NewSmartEntry("0")
+	exPriceEntry := widgets.NewSmartEntry("0.00")
+	fmvEntry := widgets.NewSmartEntry("0.00")
+	valuationDateEntry := newValuationDateEntry(win, valuationOn, fmvEntry)
+	exSalePriceEntry := widgets.NewSmartEntry("")
+	exSalePriceEntry.SetPlaceHolder("Optional: defaults to FMV")
+	// The AMT section only applies to ISOs, so it follows the grant type
+	amtSection := newAMTInputs()
+	amtSection.Content.Hide()
+	grantTypeSelect := widget.NewSelect([]string{"NSO", "ISO"}, func(s string) {
+		if s == "ISO" {
+			amtSection.Content.Show()
+		} else {
+			amtSection.Content.Hide()
+		}
+	})
+	grantTypeSelect.SetSelected("NSO")
+	saleModeSelect := newSaleModeSelect()
+	targetCashEntry := widgets.NewSmartEntry("")
+	targetCashEntry.SetPlaceHolder("Optional: cash to keep after costs")
+	serviceStartEntry := widgets.NewSmartEntry("")
+	serviceEndEntry := widgets.NewSmartEntry("")
+	// Further grants exercised or released in the same event; the form above is the first lot
+	lots := widgets.NewLotEditor()
+	lots.Vars = variables
+
+	taxes := widgets.NewTaxRatesForm(defaultTaxRates)
+	taxes.Vars = variables
+	ytdWagesEntry := widgets.NewSmartEntry("0.00")
+	ytdSupplementalEntry := widgets.NewSmartEntry("0.00")
+	residencyEntry := widget.NewMultiLineEntry()
+	residencyEntry.SetPlaceHolder("CA 0.093 2025-01-01 2025-06-30\nNY 0.0685 2025-07-01 2025-12-31")
+	fees := widgets.NewBrokerFeesForm(defaultBrokerFees)
+	fees.Vars = variables
+	cashTopUpCheck := widget.NewCheck("Pay shortfall in cash", nil)
+	regFeesCheck := widget.NewCheck("Include SEC and FINRA fees", nil)
+	regFeesCheck.SetChecked(true)
+	sharePolicySelect := newSharePolicySelect()
+	haircutEntry := widgets.NewSmartEntry("0")
+	hold := newHoldInputs()
+
+	// --- OUTPUT ---
+	residualHelp := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() { showHelpTopic(win, "residual") })
+	residualHelp.Importance = widget.LowImportance
+	resultCard := widgets.NewResultCard(widgets.OptionRows, residualHelp)
+
+	// Retained shares can be added to the portfolio once a result exists
+	var keepLot portfolio.Lot
+	var entry *stc.SessionEntry
+	keepBtn := widget.NewButtonWithIcon("Keep in Portfolio", theme.ContentAddIcon(), func() {
+		onKeep(keepLot)
+		entry.Reconciled = true
+		dialog.ShowInformation("Portfolio", fmt.Sprintf("Added %s shares to the portfolio.", displayLocale.Shares(keepLot.Shares)), win)
+	})
+	keepBtn.Disable()
+	// A broker confirmation can be checked against the latest result
+	var reconcile func(stc.Confirmation) stc.Reconciliation
+	var salePrice float64
+	reconcileBtn := widget.NewButtonWithIcon("Reconcile Confirmation...", theme.SearchIcon(), func() {
+		showReconcileDialog(win, salePrice, reconcile)
+	})
+	reconcileBtn.Disable()
+	resultCard.Append(newLayoutToggle(resultCard))
+	copyBtn, setCopyText := newCopyResultButton()
+	speakBtn, setSpokenText := newSpeakResultButton(func(err error) { dialog.ShowError(err, win) })
+	resultCard.Append(keepBtn)
+	resultCard.Append(reconcileBtn)
+	resultCard.Append(copyBtn)
+	resultCard.Append(speakBtn)
+	traceView, showTrace := newSolverTrace()
+	resultCard.Append(traceView)
+	explainView, showExplain := newExplainView()
+	resultCard.Append(explainView)
+
+	lblWarnings := widget.NewLabel("")
+	lblWarnings.Importance = widget.WarningImportance
+	lblWarnings.Wrapping = fyne.TextWrapWord
+	lblWarnings.Hide()
 
 	// --- LOGIC ---
 	calculateFunc := func() {
 		exPrice, err1 := parseFloat(exPriceEntry.Text)
 		fmv, err3 := parseFloat(fmvEntry.Text)
 		exShares, err2 := parseFloat(exSharesEntry.Text)
-
-		fed, _ := parseFloat(fedTaxEntry.Text)
-		med, _ := parseFloat(medTaxEntry.Text)
-		ss, _ := parseFloat(ssTaxEntry.Text)
-		state, _ := parseFloat(stateTaxEntry.Text)
-		local, _ := parseFloat(localTaxEntry.Text)
-
-		comm, _ := parseFloat(commRateEntry.Text)
-		minFee, _ := parseFloat(minFeeEntry.Text)
+		ytdWages, errWages := parseFloat(ytdWagesEntry.Text)
+		ytdSupplemental, errSupplemental := parseFloat(ytdSupplementalEntry.Text)
+
+		serviceStart, errStart := parseDate(serviceStartEntry.Text)
+		serviceEnd, errEnd := parseDate(serviceEndEntry.Text)
+		residency, errRes := parseResidency(residencyEntry.Text)
+		if errStart != nil || errEnd != nil || errRes != nil {
+			dialog.ShowError(fmt.Errorf("Service dates must be YYYY-MM-DD and residency lines \"ST rate start end\""), win)
+			return
+		}
+		if errWages != nil {
+			dialog.ShowError(fmt.Errorf("Please enter a valid amount for YTD Wages"), win)
+			return
+		}
+		if errSupplemental != nil {
+			dialog.ShowError(fmt.Errorf("Please enter a valid amount for YTD Supplemental"), win)
+			return
+		}
+		haircut, errHaircut := parseFloat(haircutEntry.Text)
+		if errHaircut != nil || haircut < 0 || haircut >= 1 {
+			dialog.ShowError(fmt.Errorf("Price Haircut must be a fraction from 0 to below 1, e.g. 0.05"), win)
+			return
+		}
+		rates, errRates := taxes.Rates()
+		if errRates != nil {
+			dialog.ShowError(errRates, win)
+			return
+		}
+		brokerFees, errFees := fees.Fees()
+		if errFees != nil {
+			dialog.ShowError(errFees, win)
+			return
+		}
+		plan, errHold := hold.Plan(rates)
+		if errHold != nil {
+			dialog.ShowError(errHold, win)
+			return
+		}
+		filingStatus, ytdIncome, priorCredit, errAMT := amtSection.Read(ytdWages)
+		if errAMT != nil {
+			dialog.ShowError(errAMT, win)
+			return
+		}
 
 		if err1 != nil || err2 != nil || err3 != nil {
 			dialog.ShowError(fmt.Errorf("Please enter valid numbers for Price, Shares, and FMV"), win)
@@ -118,112 +236,344 @@ func makeSTCTab(win fyne.Window) fyne.CanvasObject {
 			dialog.ShowError(fmt.Errorf("Price, Shares, and FMV must be greater than 0"), win)
 			return
 		}
+		extraLots, errLots := lots.Lots()
+		if errLots != nil {
+			dialog.ShowError(errLots, win)
+			return
+		}
+
+		// Shares may sell away from the FMV they are taxed at
+		exSalePrice, errSale := parseFloat(exSalePriceEntry.Text)
+		if errSale != nil || exSalePrice < 0 {
+			dialog.ShowError(fmt.Errorf("Sale Price must be a positive amount or blank"), win)
+			return
+		}
+
+		// A target turns the solver around: shares sold to keep that much cash
+		var targetCash float64
+		if strings.TrimSpace(targetCashEntry.Text) != "" {
+			var errTarget error
+			targetCash, errTarget = parseFloat(targetCashEntry.Text)
+			if errTarget != nil || targetCash < 0 {
+				dialog.ShowError(fmt.Errorf("Target Cash must be a positive amount"), win)
+				return
+			}
+			if targetCash > 0 && saleModes[saleModeSelect.SelectedIndex()] != stc.SellToCover {
+				dialog.ShowError(fmt.Errorf("Target Cash applies to sell-to-cover only"), win)
+				return
+			}
+		}
+		if len(extraLots) > 0 && (grantTypeSelect.Selected != "NSO" || targetCash > 0) {
+			dialog.ShowError(fmt.Errorf("Additional Lots apply to NSO exercises without Target Cash"), win)
+			return
+		}
 
 		config := stc.Config{
-			TaxRates: stc.TaxRates{
-				Federal:   fed,
-				Medicare:  med,
-				SocialSec: ss,
-				State:     state,
-				LocalSDI:  local,
-			},
-			BrokerFees: stc.BrokerFees{
-				CommissionRate: comm,
-				MinimumFee:     minFee,
-			},
+			TaxRates:   rates,
+			BrokerFees: brokerFees,
+			CashTopUp:  cashTopUpCheck.Checked,
+			Residency:  residency,
+			TimeZone:   taxHomeZone,
+
+			RegulatoryFees: regulatoryFees(regFeesCheck.Checked),
+
+			SharePolicy:  stc.SharePolicies[sharePolicySelect.SelectedIndex()],
+			PriceHaircut: haircut,
+
+			// YTD Wages are this employer's, so the wage base restarts after a job change
+			SocialSecWageBase: stc.SocialSecWageBase,
 		}
+		showConfigWarnings(lblWarnings, config)
 
 		calculator := stc.NewCalculator(config)
 		input := stc.Input{
-			ExercisePrice:   exPrice,
-			ExercisedShares: exShares,
-			FMV:             fmv,
+			ExercisePrice:        exPrice,
+			ExercisedShares:      exShares,
+			FMV:                  fmv,
+			SalePrice:            exSalePrice,
+			GrantType:            stc.GrantType(strings.ToLower(grantTypeSelect.Selected)),
+			Mode:                 saleModes[saleModeSelect.SelectedIndex()],
+			ServiceStart:         serviceStart,
+			ServiceEnd:           serviceEnd,
+			YTDWages:             ytdWages,
+			YTDSupplementalWages: ytdSupplemental,
+			YTDIncome:            ytdIncome,
+			FilingStatus:         filingStatus,
+			PriorAMTCredit:       priorCredit,
+			BrokerYTD:            brokerYTD(),
+		}
+
+		// Extra lots are settled together with the one on the form
+		var multi *stc.MultiLotInput
+		if len(extraLots) > 0 {
+			multi = &stc.MultiLotInput{
+				Lots:                 append([]stc.EventLot{{Kind: stc.LotOption, Shares: exShares, ExercisePrice: exPrice}}, extraLots...),
+				FMV:                  fmv,
+				SalePrice:            exSalePrice,
+				Mode:                 input.Mode,
+				YTDIncome:            input.YTDIncome,
+				YTDWages:             ytdWages,
+				YTDSupplementalWages: ytdSupplemental,
+				BrokerYTD:            input.BrokerYTD,
+				ServiceStart:         serviceStart,
+				ServiceEnd:           serviceEnd,
+			}
+		}
+		calculate := func(mode stc.SaleMode) stc.Result {
+			if multi != nil {
+				alt := *multi
+				alt.Mode = mode
+				return calculator.CalculateMultiLot(alt).Result
+			}
+			alt := input
+			alt.Mode = mode
+			return calculator.Calculate(alt)
+		}
+
+		var result stc.Result
+		var vm viewmodel.ViewModel
+		switch {
+		case multi != nil:
+			ml, err := calculator.CalculateMultiLotChecked(*multi)
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			result = ml.Result
+			h := viewmodel.ResultHeadline(result, displayLocale)
+			entry = recordHistory(stc.SessionEntry{
+				Title:    fmt.Sprintf("Exercise of %d lots (%s shares) @ %s", len(multi.Lots), h.Shares, h.Price),
+				Config:   config,
+				MultiLot: multi,
+				Result:   &result,
+			})
+			vm = viewmodel.FromMultiLotResultIn(ml, displayLocale)
+		default:
+			var err error
+			if targetCash > 0 {
+				result, err = calculator.SolveForCashChecked(input, targetCash)
+			} else {
+				result, err = calculator.CalculateChecked(input)
+			}
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			h := viewmodel.ResultHeadline(result, displayLocale)
+			entry = recordHistory(stc.SessionEntry{
+				Title:      fmt.Sprintf("Exercise of %s shares @ %s", h.Shares, h.Price),
+				Config:     config,
+				Options:    &input,
+				TargetCash: targetCash,
+				Result:     &result,
+			})
+			vm = viewmodel.FromResultIn(result, displayLocale)
+			if targetCash == 0 {
+				if b, ok := calculator.BreakEven(input); ok {
+					vm.Notes = append(vm.Notes, viewmodel.BreakEvenNote(b))
+				}
+			}
+		}
+
+		// Flat withholding rarely matches the marginal brackets
+		if t := calculator.TrueUp(result.TaxableGain, result.FederalTax, input.YTDIncome, input.FilingStatus); t.Income > 0 {
+			vm.Notes = append(vm.Notes, viewmodel.TrueUpNote(t))
 		}
 
-		result := calculator.Calculate(input)
+		// Weigh holding the shares kept against selling them at the FMV
+		if plan != nil && result.NetShares > 0 {
+			vm.Notes = append(vm.Notes, plan.note(result.NetShares, fmv))
+		}
 
-		lblNetShares.Text = fmt.Sprintf("%.0f", result.NetShares)
-		lblNetShares.Refresh()
-		lblResidual.Text = fmt.Sprintf("$%.2f", result.Residual)
-		lblResidual.Refresh()
-		lblSharesSold.SetText(fmt.Sprintf("%.0f", result.SharesToSell))
-		lblTotalCost.SetText(fmt.Sprintf("$%.2f", result.TotalCosts))
-		lblGrossProceeds.SetText(fmt.Sprintf("$%.2f", result.EstGrossProceeds))
-		lblTaxes.SetText(fmt.Sprintf("$%.2f", result.TotalTax))
-		lblFees.SetText(fmt.Sprintf("$%.2f", result.BrokerFees))
+		// Compare against every other way of settling the same exercise
+		for i, m := range saleModes {
+			if m == input.Mode {
+				continue
+			}
+			r := calculate(m)
+			vm.Notes = append(vm.Notes, viewmodel.SaleOutcome(saleModeLabels[i], r.NetShares, fmv, r.NetCash))
+		}
+		resultCard.ShowView(vm)
+		setCopyText(vm.Snapshot())
+		setSpokenText(vm.Spoken())
+		resultCard.ShowPayslip(viewmodel.PayslipFromResult(result))
+		showTrace(result.Trace)
+		showExplain(result.Explain())
+		bus.Publish(events.InputChanged, config)
+		bus.Publish(events.ResultReady, vm)
+
+		keepLot = portfolio.Lot{
+			Shares:    result.NetShares,
+			CostBasis: fmv,
+			Acquired:  time.Now(),
+			Source:    "Option",
+			Income:    result.TaxableGain,
+			Tax:       result.TotalTax,
+			Cash:      result.NetCash,
+		}
+		// A sell-all leaves nothing to keep
+		if keepLot.Shares > 0 {
+			keepBtn.Enable()
+		} else {
+			keepBtn.Disable()
+		}
+
+		reconcile = func(conf stc.Confirmation) stc.Reconciliation {
+			if multi != nil {
+				return calculator.ReconcileMultiLot(*multi, conf)
+			}
+			return calculator.Reconcile(input, conf)
+		}
+		salePrice = fmv
+		if exSalePrice > 0 {
+			salePrice = exSalePrice
+		}
+		reconcileBtn.Enable()
 	}
 
 	// Attach Enter key handler to all inputs
-	inputs := []*SmartEntry{
-		exSharesEntry, exPriceEntry, fmvEntry,
-		fedTaxEntry, medTaxEntry, ssTaxEntry, stateTaxEntry, localTaxEntry,
-		commRateEntry, minFeeEntry,
-	}
+	inputs := []*widgets.SmartEntry{exSharesEntry, exPriceEntry, fmvEntry, exSalePriceEntry, targetCashEntry, ytdWagesEntry, ytdSupplementalEntry}
+	inputs = append(inputs, taxes.Entries()...)
+	inputs = append(inputs, amtSection.Entries()...)
+	inputs = append(inputs, fees.Entries()...)
+	inputs = append(inputs, haircutEntry)
+	inputs = append(inputs, hold.Entries()...)
 	for _, e := range inputs {
 		e.SetOnEnter(calculateFunc)
 	}
 
+	// Profile switches load their rates into the form; price updates refresh the FMV
+	loadConfig := func(cfg stc.Config) {
+		taxes.SetRates(cfg.TaxRates)
+		fees.SetFees(cfg.BrokerFees)
+		cashTopUpCheck.SetChecked(cfg.CashTopUp)
+		regFeesCheck.SetChecked(cfg.RegulatoryFees != stc.RegulatoryFees{})
+		if cfg.SharePolicy != "" {
+			sharePolicySelect.SetSelected(sharePolicyLabels[cfg.SharePolicy])
+		}
+		haircutEntry.SetText(viewmodel.Entry(cfg.PriceHaircut, -1))
+	}
+	bus.Subscribe(events.ProfileSwitched, func(e events.Event) {
+		loadConfig(e.Payload.(stc.Config))
+	})
+	// Opened exercises fill the form and are recalculated under their own config
+	bus.Subscribe(events.EntryOpened, func(e events.Event) {
+		entry := e.Payload.(stc.SessionEntry)
+		var in stc.Input
+		var extra []stc.EventLot
+		switch {
+		case entry.Options != nil:
+			in = *entry.Options
+		case entry.MultiLot != nil && len(entry.MultiLot.Lots) > 0 && entry.MultiLot.Lots[0].Kind != stc.LotRSU:
+			ml := entry.MultiLot
+			in = stc.Input{
+				ExercisePrice:        ml.Lots[0].ExercisePrice,
+				ExercisedShares:      ml.Lots[0].Shares,
+				FMV:                  ml.FMV,
+				SalePrice:            ml.SalePrice,
+				Mode:                 ml.Mode,
+				YTDWages:             ml.YTDWages,
+				YTDSupplementalWages: ml.YTDSupplementalWages,
+				ServiceStart:         ml.ServiceStart,
+				ServiceEnd:           ml.ServiceEnd,
+			}
+			extra = ml.Lots[1:]
+		default:
+			return
+		}
+		loadConfig(entry.Config)
+		residencyEntry.SetText(formatResidency(entry.Config.Residency))
+		exPriceEntry.SetText(viewmodel.Entry(in.ExercisePrice, -1))
+		exSharesEntry.SetText(viewmodel.Entry(in.ExercisedShares, -1))
+		fmvEntry.SetText(viewmodel.Entry(in.FMV, -1))
+		exSalePriceEntry.SetText("")
+		if in.SalePrice > 0 {
+			exSalePriceEntry.SetText(viewmodel.Entry(in.SalePrice, -1))
+		}
+		grantTypeSelect.SetSelected("NSO")
+		if in.GrantType == stc.GrantISO {
+			grantTypeSelect.SetSelected("ISO")
+		}
+		amtSection.SetInput(in)
+		saleModeSelect.SetSelectedIndex(saleModeIndex(in.Mode))
+		targetCashEntry.SetText("")
+		if entry.TargetCash > 0 {
+			targetCashEntry.SetText(viewmodel.Entry(entry.TargetCash, -1))
+		}
+		ytdWagesEntry.SetText(viewmodel.Entry(in.YTDWages, -1))
+		ytdSupplementalEntry.SetText(viewmodel.Entry(in.YTDSupplementalWages, -1))
+		serviceStartEntry.SetText(formatDate(in.ServiceStart))
+		serviceEndEntry.SetText(formatDate(in.ServiceEnd))
+		lots.SetLots(extra)
+		calculateFunc()
+	})
+	bus.Subscribe(events.PriceFetched, func(e events.Event) {
+		fmvEntry.SetText(viewmodel.Entry(e.Payload.(events.Price).Price, 2))
+	})
+
 	// --- LAYOUT ---
 	calcBtn := widget.NewButtonWithIcon("CALCULATE", theme.ConfirmIcon(), calculateFunc)
 	calcBtn.Importance = widget.HighImportance
 
-	transForm := widget.NewForm(
-		widget.NewFormItem("Exercise Price ($)", exPriceEntry),
-		widget.NewFormItem("FMV ($)", fmvEntry),
-		widget.NewFormItem("Exercised Shares", exSharesEntry),
-	)
-
-	taxForm := widget.NewForm(
-		widget.NewFormItem("Federal", fedTaxEntry),
-		widget.NewFormItem("Medicare", medTaxEntry),
-		widget.NewFormItem("Social Sec", ssTaxEntry),
-		widget.NewFormItem("State", stateTaxEntry),
-		widget.NewFormItem("Local/SDI", localTaxEntry),
-	)
-
-	brokerForm := widget.NewForm(
-		widget.NewFormItem("Commission Rate", commRateEntry),
-		widget.NewFormItem("Minimum Fee ($)", minFeeEntry),
-	)
+	transForm := widgets.NewFieldSet()
+	transForm.Append("Exercise Price ($)", exPriceEntry)
+	transForm.Append("FMV ($)", withHelp(win, "fmv", fmvEntry))
+	transForm.Append(fieldValuationDate, valuationDateEntry)
+	transForm.Append("Sale Price ($)", exSalePriceEntry)
+	transForm.Append("Exercised Shares", exSharesEntry)
+	transForm.Append("Grant Type", grantTypeSelect)
+	transForm.Append("Sale", saleModeSelect)
+	transForm.Append("Target Cash ($)", targetCashEntry)
+	transForm.Append("Service Start", serviceStartEntry)
+	transForm.Append("Service End", serviceEndEntry)
+	transForm.Append("Additional Lots", lots.Content)
+	transForm.Append("Hold Estimate", hold.Content)
+
+	taxForm := widgets.NewFieldSet()
+	taxForm.Append("Federal", withHelp(win, "supplemental-withholding", taxes.Federal))
+	taxForm.Append("Medicare", taxes.Medicare)
+	taxForm.Append("Medicare Surtax", taxes.Surtax)
+	taxForm.Append("YTD Wages", ytdWagesEntry)
+	taxForm.Append("YTD Supplemental ($)", ytdSupplementalEntry)
+	taxForm.Append("Social Sec", taxes.SocialSec)
+	taxForm.Append("State", taxes.State)
+	taxForm.Append("Local/SDI", taxes.LocalSDI)
+	taxForm.Append("Jurisdictions", taxes.Jurisdictions.Content)
+	taxForm.Append("Residency", residencyEntry)
+
+	brokerForm := widgets.NewFieldSet()
+	brokerForm.Append("Commission Rate", withHelp(win, "broker-fees", fees.CommissionRate))
+	brokerForm.Append("Minimum Fee ($)", fees.MinimumFee)
+	brokerForm.Append("Commission Tiers", fees.Tiers.Content)
+	brokerForm.Append("Annual Fee Cap ($)", fees.AnnualCap)
+	brokerForm.Append("Volume Tiers", fees.VolumeTiers.Content)
+	brokerForm.Append("Extra Shares", fees.ExtraShares)
+	brokerForm.Append("Share Policy", sharePolicySelect)
+	brokerForm.Append("Price Haircut", haircutEntry)
+	brokerForm.AppendWithID(fieldCashTopUp, "", cashTopUpCheck)
+	brokerForm.AppendWithID(fieldRegFees, "", regFeesCheck)
 
 	inputTabs := container.NewAppTabs(
-		container.NewTabItem("Base", transForm),
+		container.NewTabItem("Base", container.NewVBox(transForm, amtSection.Content)),
 		container.NewTabItem("Taxes", taxForm),
 		container.NewTabItem("Service", brokerForm),
 	)
 
+	bus.Subscribe(events.FieldsHidden, func(e events.Event) {
+		hidden := e.Payload.(map[string]bool)
+		for _, f := range []*widgets.FieldSet{transForm, taxForm, brokerForm} {
+			f.SetHidden(hidden)
+		}
+		resultCard.SetHidden(hidden)
+	})
+
 	inputCard := widget.NewCard("Stock Options", "", container.NewVBox(
 		inputTabs,
 		layout.NewSpacer(),
 		calcBtn,
+		lblWarnings,
 	))
 
-	// Result Layout using Grid
-	summaryGrid := container.NewGridWithColumns(2,
-		container.New(layout.NewFormLayout(), widget.NewLabel("Net Shares:"), lblNetShares),
-		container.New(layout.NewFormLayout(), widget.NewLabel("Residual:"), lblResidual),
-	)
-
-	// Details in a 2-column grid
-	detailsLeft := widget.NewForm(
-		widget.NewFormItem("Shares Sold:", lblSharesSold),
-		widget.NewFormItem("Sale Proceeds:", lblGrossProceeds), // Clarified Label
-	)
-
-	detailsRight := widget.NewForm(
-		widget.NewFormItem("Total Taxes:", lblTaxes),
-		widget.NewFormItem("Broker Fees:", lblFees),
-		widget.NewFormItem("Total Costs:", lblTotalCost),
-	)
-
-	detailsGrid := container.NewGridWithColumns(2, detailsLeft, detailsRight)
-
-	resultCard := container.NewVBox(
-		summaryGrid,
-		widget.NewSeparator(),
-		detailsGrid,
-	)
-
 	content := container.NewVBox(
 		inputCard,
 		layout.NewSpacer(),
@@ -233,57 +583,134 @@ func makeSTCTab(win fyne.Window) fyne.CanvasObject {
 	return container.NewPadded(content)
 }
 
+// rowBufferRefund is the RSU-only row for the annualized ExtraShares refund
+const rowBufferRefund = "Buffer Refund/yr:"
+
 // --- TOOL 3: RSU Sell To Cover ---
-func makeRSUTab(win fyne.Window) fyne.CanvasObject {
+func makeRSUTab(win fyne.Window, bus *events.Bus, onKeep func(portfolio.Lot),
+	valuationOn func(time.Time) (portfolio.Valuation, bool)) fyne.CanvasObject {
 	// --- INPUT FIELDS ---
 	// RSU Specific Inputs
-	sharesReleasedEntry := NewSmartEntry("0")
-	vestPriceEntry := NewSmartEntry("0.00")
-	salePriceEntry := NewSmartEntry("0.00")
-
-	// Tax Inputs (Defaults matching existing)
-	fedTaxEntry := NewSmartEntry("0.22")
-	medTaxEntry := NewSmartEntry("0.0145")
-	ssTaxEntry := NewSmartEntry("0.062")
-	stateTaxEntry := NewSmartEntry("0.00")
-	localTaxEntry := NewSmartEntry("0.00")
+	sharesReleasedEntry := widgets.NewSmartEntry("0")
+	dividendEquivalentsEntry := widgets.NewSmartEntry("0")
+	vestPriceEntry := widgets.NewSmartEntry("0.00")
+	salePriceEntry := widgets.NewSmartEntry("0.00")
+	saleModeSelect := newSaleModeSelect()
+	valuationDateEntry := newValuationDateEntry(win, valuationOn, vestPriceEntry, salePriceEntry)
+	serviceStartEntry := widgets.NewSmartEntry("")
+	serviceEndEntry := widgets.NewSmartEntry("")
+
+	taxes := widgets.NewTaxRatesForm(defaultTaxRates)
+	taxes.Vars = variables
+	ytdWagesEntry := widgets.NewSmartEntry("0.00")
+	ytdSupplementalEntry := widgets.NewSmartEntry("0.00")
+	residencyEntry := widget.NewMultiLineEntry()
+	residencyEntry.SetPlaceHolder("CA 0.093 2025-01-01 2025-06-30\nNY 0.0685 2025-07-01 2025-12-31")
 
 	// Broker Inputs
-	commRateEntry := NewSmartEntry("0.03")
-	minFeeEntry := NewSmartEntry("25.00")
-	flatFeeEntry := NewSmartEntry("0.00")
-
-	// --- OUTPUT LABELS ---
-	lblNetShares := canvas.NewText("-", theme.PrimaryColor())
-	lblNetShares.TextSize = 24
-	lblNetShares.TextStyle = fyne.TextStyle{Bold: true}
-
-	lblResidual := canvas.NewText("-", theme.SuccessColor())
-	lblResidual.TextSize = 24
-	lblResidual.TextStyle = fyne.TextStyle{Bold: true}
-
-	lblTotalValue := widget.NewLabel("-") // New Label to show Total Grant Value
-	lblSharesSold := widget.NewLabel("-")
-	lblTotalCost := widget.NewLabel("-")
-	lblGrossProceeds := widget.NewLabel("-")
-	lblTaxes := widget.NewLabel("-")
-	lblFees := widget.NewLabel("-")
+	fees := widgets.NewBrokerFeesForm(defaultBrokerFees)
+	fees.Vars = variables
+	cashTopUpCheck := widget.NewCheck("Pay shortfall in cash", nil)
+	regFeesCheck := widget.NewCheck("Include SEC and FINRA fees", nil)
+	regFeesCheck.SetChecked(true)
+	sharePolicySelect := newSharePolicySelect()
+	haircutEntry := widgets.NewSmartEntry("0")
+	hold := newHoldInputs()
+	vestsPerYearEntry := widgets.NewSmartEntry("4")
+
+	// --- OUTPUT ---
+	rows := widgets.RSURows
+	rows[1] = append(rows[1][:len(rows[1]):len(rows[1])], rowBufferRefund)
+	residualHelp := widget.NewButtonWithIcon("", theme.QuestionIcon(), func() { showHelpTopic(win, "residual") })
+	residualHelp.Importance = widget.LowImportance
+	resultCard := widgets.NewResultCard(rows, residualHelp)
+
+	// Retained shares can be added to the portfolio once a result exists
+	var keepLot portfolio.Lot
+	var entry *stc.SessionEntry
+	keepBtn := widget.NewButtonWithIcon("Keep in Portfolio", theme.ContentAddIcon(), func() {
+		onKeep(keepLot)
+		entry.Reconciled = true
+		dialog.ShowInformation("Portfolio", fmt.Sprintf("Added %s shares to the portfolio.", displayLocale.Shares(keepLot.Shares)), win)
+	})
+	keepBtn.Disable()
+	// A broker confirmation can be checked against the latest result
+	var reconcile func(stc.Confirmation) stc.Reconciliation
+	var salePrice float64
+	reconcileBtn := widget.NewButtonWithIcon("Reconcile Confirmation...", theme.SearchIcon(), func() {
+		showReconcileDialog(win, salePrice, reconcile)
+	})
+	reconcileBtn.Disable()
+	resultCard.Append(newLayoutToggle(resultCard))
+	copyBtn, setCopyText := newCopyResultButton()
+	speakBtn, setSpokenText := newSpeakResultButton(func(err error) { dialog.ShowError(err, win) })
+	resultCard.Append(keepBtn)
+	resultCard.Append(reconcileBtn)
+	resultCard.Append(copyBtn)
+	resultCard.Append(speakBtn)
+	traceView, showTrace := newSolverTrace()
+	resultCard.Append(traceView)
+	explainView, showExplain := newExplainView()
+	resultCard.Append(explainView)
+
+	lblWarnings := widget.NewLabel("")
+	lblWarnings.Importance = widget.WarningImportance
+	lblWarnings.Wrapping = fyne.TextWrapWord
+	lblWarnings.Hide()
 
 	// --- LOGIC ---
 	calculateFunc := func() {
 		sharesReleased, err1 := parseFloat(sharesReleasedEntry.Text)
 		vestPrice, err2 := parseFloat(vestPriceEntry.Text)
 		salePrice, err3 := parseFloat(salePriceEntry.Text)
-
-		fed, _ := parseFloat(fedTaxEntry.Text)
-		med, _ := parseFloat(medTaxEntry.Text)
-		ss, _ := parseFloat(ssTaxEntry.Text)
-		state, _ := parseFloat(stateTaxEntry.Text)
-		local, _ := parseFloat(localTaxEntry.Text)
-
-		comm, _ := parseFloat(commRateEntry.Text)
-		minFee, _ := parseFloat(minFeeEntry.Text)
-		flatFee, _ := parseFloat(flatFeeEntry.Text)
+		if valuationMode == events.ModePrivate {
+			// Private shares are withheld at the valuation rather than sold on a market
+			salePrice, err3 = vestPrice, err2
+		}
+		vestsPerYear, _ := parseFloat(vestsPerYearEntry.Text)
+		ytdWages, errWages := parseFloat(ytdWagesEntry.Text)
+		ytdSupplemental, errSupplemental := parseFloat(ytdSupplementalEntry.Text)
+		dividendEquivalents, errDividends := parseFloat(dividendEquivalentsEntry.Text)
+
+		serviceStart, errStart := parseDate(serviceStartEntry.Text)
+		serviceEnd, errEnd := parseDate(serviceEndEntry.Text)
+		residency, errRes := parseResidency(residencyEntry.Text)
+		if errStart != nil || errEnd != nil || errRes != nil {
+			dialog.ShowError(fmt.Errorf("Service dates must be YYYY-MM-DD and residency lines \"ST rate start end\""), win)
+			return
+		}
+		if errWages != nil {
+			dialog.ShowError(fmt.Errorf("Please enter a valid amount for YTD Wages"), win)
+			return
+		}
+		if errSupplemental != nil {
+			dialog.ShowError(fmt.Errorf("Please enter a valid amount for YTD Supplemental"), win)
+			return
+		}
+		if errDividends != nil || dividendEquivalents < 0 {
+			dialog.ShowError(fmt.Errorf("Please enter 0 or more Dividend Equivalent shares"), win)
+			return
+		}
+		haircut, errHaircut := parseFloat(haircutEntry.Text)
+		if errHaircut != nil || haircut < 0 || haircut >= 1 {
+			dialog.ShowError(fmt.Errorf("Price Haircut must be a fraction from 0 to below 1, e.g. 0.05"), win)
+			return
+		}
+		rates, errRates := taxes.Rates()
+		if errRates != nil {
+			dialog.ShowError(errRates, win)
+			return
+		}
+		brokerFees, errFees := fees.Fees()
+		if errFees != nil {
+			dialog.ShowError(errFees, win)
+			return
+		}
+		plan, errHold := hold.Plan(rates)
+		if errHold != nil {
+			dialog.ShowError(errHold, win)
+			return
+		}
 
 		if err1 != nil || err2 != nil || err3 != nil {
 			dialog.ShowError(fmt.Errorf("Please enter valid numbers"), win)
@@ -296,77 +723,202 @@ func makeRSUTab(win fyne.Window) fyne.CanvasObject {
 		}
 
 		config := stc.Config{
-			TaxRates: stc.TaxRates{
-				Federal:   fed,
-				Medicare:  med,
-				SocialSec: ss,
-				State:     state,
-				LocalSDI:  local,
-			},
-			BrokerFees: stc.BrokerFees{
-				CommissionRate: comm,
-				MinimumFee:     minFee,
-				FlatFee:        flatFee,
-			},
+			TaxRates:   rates,
+			BrokerFees: brokerFees,
+			CashTopUp:  cashTopUpCheck.Checked,
+			Residency:  residency,
+			TimeZone:   taxHomeZone,
+
+			RegulatoryFees: regulatoryFees(regFeesCheck.Checked),
+
+			SharePolicy:  stc.SharePolicies[sharePolicySelect.SelectedIndex()],
+			PriceHaircut: haircut,
+
+			// YTD Wages are this employer's, so the wage base restarts after a job change
+			SocialSecWageBase: stc.SocialSecWageBase,
 		}
+		showConfigWarnings(lblWarnings, config)
 
 		calculator := stc.NewCalculator(config)
 		input := stc.RSUInput{
-			SharesReleased: sharesReleased,
-			VestPrice:      vestPrice,
-			SalePrice:      salePrice,
+			SharesReleased:       sharesReleased,
+			VestPrice:            vestPrice,
+			SalePrice:            salePrice,
+			ServiceStart:         serviceStart,
+			ServiceEnd:           serviceEnd,
+			YTDWages:             ytdWages,
+			YTDSupplementalWages: ytdSupplemental,
+			BrokerYTD:            brokerYTD(),
+			Mode:                 saleModes[saleModeSelect.SelectedIndex()],
+
+			DividendEquivalentShares: dividendEquivalents,
 		}
 
-		result := calculator.CalculateRSU(input)
+		result, err := calculator.CalculateRSUChecked(input)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		h := viewmodel.RSUHeadline(result, displayLocale)
+		entry = recordHistory(stc.SessionEntry{
+			Title:     fmt.Sprintf("Release of %s shares @ %s", h.Shares, h.Price),
+			Config:    config,
+			RSU:       &input,
+			RSUResult: &result,
+		})
+		vm := viewmodel.FromRSUResultIn(result, displayLocale)
+		if b, ok := calculator.BreakEvenRSU(input); ok {
+			vm.Notes = append(vm.Notes, viewmodel.BreakEvenNote(b))
+		}
 
-		lblNetShares.Text = fmt.Sprintf("%.0f", result.NetShares)
-		lblNetShares.Refresh()
-		lblResidual.Text = fmt.Sprintf("$%.2f", result.Residual)
-		lblResidual.Refresh()
+		// Flat withholding rarely matches the marginal brackets. The release
+		// form has no filing status, so single-filer brackets apply on top
+		// of the wages so far.
+		if t := calculator.TrueUp(result.TaxableGain, result.FederalTax, input.YTDWages, ""); t.Income > 0 {
+			vm.Notes = append(vm.Notes, viewmodel.TrueUpNote(t))
+		}
+
+		// Weigh holding the shares kept against selling them at the vest price
+		if plan != nil && result.NetShares > 0 {
+			vm.Notes = append(vm.Notes, plan.note(result.NetShares, vestPrice))
+		}
+
+		// Compare against every other way of settling the same release
+		for i, m := range saleModes {
+			if m == input.Mode {
+				continue
+			}
+			alt := input
+			alt.Mode = m
+			r := calculator.CalculateRSU(alt)
+			vm.Notes = append(vm.Notes, viewmodel.SaleOutcome(saleModeLabels[i], r.NetShares, salePrice, r.NetCash))
+		}
+		resultCard.ShowView(vm)
+		setCopyText(vm.Snapshot())
+		setSpokenText(vm.Spoken())
+		resultCard.ShowPayslip(viewmodel.PayslipFromRSUResult(result))
+		showTrace(result.Trace)
+		showExplain(result.Explain())
+		bus.Publish(events.InputChanged, config)
+		bus.Publish(events.ResultReady, vm)
+
+		keepLot = portfolio.Lot{
+			Shares:    result.NetShares,
+			CostBasis: vestPrice,
+			Acquired:  time.Now(),
+			Source:    "RSU",
+			Income:    result.TaxableGain,
+			Tax:       result.TotalTax,
+			Cash:      result.NetCash,
+		}
+		// A sell-all leaves nothing to keep
+		if keepLot.Shares > 0 {
+			keepBtn.Enable()
+		} else {
+			keepBtn.Disable()
+		}
 
-		// Show Taxable Gain as "Total Value" to clarify what the user likely expects
-		lblTotalValue.SetText(fmt.Sprintf("$%.2f", result.TaxableGain))
+		reconcile = func(conf stc.Confirmation) stc.Reconciliation { return calculator.ReconcileRSU(input, conf) }
+		salePrice = input.SalePrice
+		reconcileBtn.Enable()
 
-		lblSharesSold.SetText(fmt.Sprintf("%.0f", result.SharesToSell))
-		lblTotalCost.SetText(fmt.Sprintf("$%.2f", result.TotalCosts))
-		lblGrossProceeds.SetText(fmt.Sprintf("$%.2f", result.EstGrossProceeds))
-		lblTaxes.SetText(fmt.Sprintf("$%.2f", result.TotalTax))
-		lblFees.SetText(fmt.Sprintf("$%.2f", result.TotalFees))
+		// Annualize the buffer refund over a year of identical vests
+		var vests []stc.Vest
+		for i := 0; i < int(vestsPerYear); i++ {
+			vests = append(vests, stc.Vest{Shares: sharesReleased})
+		}
+		buffer := calculator.BufferImpact(vests, vestPrice, salePrice)
+		resultCard.SetValue(rowBufferRefund, displayLocale.Money(buffer.AverageAnnual))
 	}
 
 	// Attach Enter key handler
-	inputs := []*SmartEntry{
-		sharesReleasedEntry, vestPriceEntry, salePriceEntry,
-		fedTaxEntry, medTaxEntry, ssTaxEntry, stateTaxEntry, localTaxEntry,
-		commRateEntry, minFeeEntry, flatFeeEntry,
-	}
+	inputs := []*widgets.SmartEntry{sharesReleasedEntry, dividendEquivalentsEntry, vestPriceEntry, salePriceEntry, vestsPerYearEntry, ytdWagesEntry, ytdSupplementalEntry}
+	inputs = append(inputs, taxes.Entries()...)
+	inputs = append(inputs, fees.Entries()...)
+	inputs = append(inputs, haircutEntry)
+	inputs = append(inputs, hold.Entries()...)
 	for _, e := range inputs {
 		e.SetOnEnter(calculateFunc)
 	}
 
+	// Profile switches load their rates into the form; price updates refresh the sale price
+	loadConfig := func(cfg stc.Config) {
+		taxes.SetRates(cfg.TaxRates)
+		fees.SetFees(cfg.BrokerFees)
+		cashTopUpCheck.SetChecked(cfg.CashTopUp)
+		regFeesCheck.SetChecked(cfg.RegulatoryFees != stc.RegulatoryFees{})
+		if cfg.SharePolicy != "" {
+			sharePolicySelect.SetSelected(sharePolicyLabels[cfg.SharePolicy])
+		}
+		haircutEntry.SetText(viewmodel.Entry(cfg.PriceHaircut, -1))
+	}
+	bus.Subscribe(events.ProfileSwitched, func(e events.Event) {
+		loadConfig(e.Payload.(stc.Config))
+	})
+	// Opened releases fill the form and are recalculated under their own config
+	bus.Subscribe(events.EntryOpened, func(e events.Event) {
+		entry := e.Payload.(stc.SessionEntry)
+		if entry.RSU == nil {
+			return
+		}
+		in := *entry.RSU
+		loadConfig(entry.Config)
+		residencyEntry.SetText(formatResidency(entry.Config.Residency))
+		sharesReleasedEntry.SetText(viewmodel.Entry(in.SharesReleased, -1))
+		dividendEquivalentsEntry.SetText(viewmodel.Entry(in.DividendEquivalentShares, -1))
+		vestPriceEntry.SetText(viewmodel.Entry(in.VestPrice, -1))
+		salePriceEntry.SetText(viewmodel.Entry(in.SalePrice, -1))
+		saleModeSelect.SetSelectedIndex(saleModeIndex(in.Mode))
+		ytdWagesEntry.SetText(viewmodel.Entry(in.YTDWages, -1))
+		ytdSupplementalEntry.SetText(viewmodel.Entry(in.YTDSupplementalWages, -1))
+		serviceStartEntry.SetText(formatDate(in.ServiceStart))
+		serviceEndEntry.SetText(formatDate(in.ServiceEnd))
+		calculateFunc()
+	})
+	bus.Subscribe(events.PriceFetched, func(e events.Event) {
+		salePriceEntry.SetText(viewmodel.Entry(e.Payload.(events.Price).Price, 2))
+	})
+
 	// --- LAYOUT ---
 	calcBtn := widget.NewButtonWithIcon("CALCULATE", theme.ConfirmIcon(), calculateFunc)
 	calcBtn.Importance = widget.HighImportance
 
-	rsuForm := widget.NewForm(
-		widget.NewFormItem("Shares Released", sharesReleasedEntry),
-		widget.NewFormItem("Vest Price (FMV) $", vestPriceEntry),
-		widget.NewFormItem("Est. Sale Price $", salePriceEntry),
-	)
-
-	taxForm := widget.NewForm(
-		widget.NewFormItem("Federal", fedTaxEntry),
-		widget.NewFormItem("Medicare", medTaxEntry),
-		widget.NewFormItem("Social Sec", ssTaxEntry),
-		widget.NewFormItem("State", stateTaxEntry),
-		widget.NewFormItem("Local/SDI", localTaxEntry),
-	)
-
-	brokerForm := widget.NewForm(
-		widget.NewFormItem("Commission Rate", commRateEntry),
-		widget.NewFormItem("Minimum Fee ($)", minFeeEntry),
-		widget.NewFormItem("Processing Fee ($)", flatFeeEntry),
-	)
+	rsuForm := widgets.NewFieldSet()
+	rsuForm.Append("Shares Released", withHelp(win, "rsu", sharesReleasedEntry))
+	rsuForm.Append("Dividend Equivalents", dividendEquivalentsEntry)
+	rsuForm.Append("Vest Price (FMV) $", withHelp(win, "fmv", vestPriceEntry))
+	rsuForm.Append(fieldValuationDate, valuationDateEntry)
+	rsuForm.Append(fieldSalePrice, salePriceEntry)
+	rsuForm.Append("Sale", saleModeSelect)
+	rsuForm.Append("Service Start", serviceStartEntry)
+	rsuForm.Append("Service End", serviceEndEntry)
+	rsuForm.Append("Hold Estimate", hold.Content)
+
+	taxForm := widgets.NewFieldSet()
+	taxForm.Append("Federal", withHelp(win, "supplemental-withholding", taxes.Federal))
+	taxForm.Append("Medicare", taxes.Medicare)
+	taxForm.Append("Medicare Surtax", taxes.Surtax)
+	taxForm.Append("YTD Wages", ytdWagesEntry)
+	taxForm.Append("YTD Supplemental ($)", ytdSupplementalEntry)
+	taxForm.Append("Social Sec", taxes.SocialSec)
+	taxForm.Append("State", taxes.State)
+	taxForm.Append("Local/SDI", taxes.LocalSDI)
+	taxForm.Append("Jurisdictions", taxes.Jurisdictions.Content)
+	taxForm.Append("Residency", residencyEntry)
+
+	brokerForm := widgets.NewFieldSet()
+	brokerForm.Append("Commission Rate", withHelp(win, "broker-fees", fees.CommissionRate))
+	brokerForm.Append("Minimum Fee ($)", fees.MinimumFee)
+	brokerForm.Append("Commission Tiers", fees.Tiers.Content)
+	brokerForm.Append("Annual Fee Cap ($)", fees.AnnualCap)
+	brokerForm.Append("Volume Tiers", fees.VolumeTiers.Content)
+	brokerForm.Append("Processing Fee ($)", fees.FlatFee)
+	brokerForm.Append("Extra Shares", fees.ExtraShares)
+	brokerForm.Append("Share Policy", sharePolicySelect)
+	brokerForm.Append("Price Haircut", haircutEntry)
+	brokerForm.AppendWithID(fieldCashTopUp, "", cashTopUpCheck)
+	brokerForm.AppendWithID(fieldRegFees, "", regFeesCheck)
+	brokerForm.Append("Vests / Year", vestsPerYearEntry)
 
 	inputTabs := container.NewAppTabs(
 		container.NewTabItem("Equity", rsuForm),
@@ -374,38 +926,21 @@ func makeRSUTab(win fyne.Window) fyne.CanvasObject {
 		container.NewTabItem("Service", brokerForm),
 	)
 
+	bus.Subscribe(events.FieldsHidden, func(e events.Event) {
+		hidden := e.Payload.(map[string]bool)
+		for _, f := range []*widgets.FieldSet{rsuForm, taxForm, brokerForm} {
+			f.SetHidden(hidden)
+		}
+		resultCard.SetHidden(hidden)
+	})
+
 	inputCard := widget.NewCard("Restricted Stock", "", container.NewVBox(
 		inputTabs,
 		layout.NewSpacer(),
 		calcBtn,
+		lblWarnings,
 	))
 
-	// Result Layout using Grid
-	summaryGrid := container.NewGridWithColumns(2,
-		container.New(layout.NewFormLayout(), widget.NewLabel("Net Shares:"), lblNetShares),
-		container.New(layout.NewFormLayout(), widget.NewLabel("Residual:"), lblResidual),
-	)
-
-	detailsLeft := widget.NewForm(
-		widget.NewFormItem("Total Grant Value:", lblTotalValue), // Renamed from Taxable Gain to be clearer
-		widget.NewFormItem("Shares Sold:", lblSharesSold),
-		widget.NewFormItem("Sale Proceeds:", lblGrossProceeds), // Clarified label (was Gross Proceeds)
-	)
-
-	detailsRight := widget.NewForm(
-		widget.NewFormItem("Total Taxes:", lblTaxes),
-		widget.NewFormItem("Total Fees:", lblFees),
-		widget.NewFormItem("Total Costs:", lblTotalCost),
-	)
-
-	detailsGrid := container.NewGridWithColumns(2, detailsLeft, detailsRight)
-
-	resultCard := container.NewVBox(
-		summaryGrid,
-		widget.NewSeparator(),
-		detailsGrid,
-	)
-
 	content := container.NewVBox(
 		inputCard,
 		layout.NewSpacer(),
@@ -415,6 +950,52 @@ func makeRSUTab(win fyne.Window) fyne.CanvasObject {
 	return container.NewPadded(content)
 }
 
+// newSolverTrace is a collapsed debug section listing each solver iteration.
+// The returned function replaces the table rows.
+func newSolverTrace() (fyne.CanvasObject, func([]stc.SolverStep)) {
+	table := container.NewGridWithColumns(5)
+	accordion := widget.NewAccordion(widget.NewAccordionItem("Solver Trace", table))
+
+	show := func(steps []stc.SolverStep) {
+		table.RemoveAll()
+		for _, h := range []string{"#", "Guess", "Fees", "Required", "Needs"} {
+			table.Add(widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		}
+		for _, row := range viewmodel.SolverRows(steps, displayLocale) {
+			for _, cell := range row {
+				table.Add(widget.NewLabel(cell))
+			}
+		}
+	}
+	return accordion, show
+}
+
+// newExplainView is a collapsed section listing each step of the
+// calculation. The returned function replaces the steps.
+func newExplainView() (fyne.CanvasObject, func([]string)) {
+	steps := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	steps.Wrapping = fyne.TextWrapWord
+	accordion := widget.NewAccordion(widget.NewAccordionItem("How was this calculated?", steps))
+
+	show := func(lines []string) { steps.SetText(strings.Join(lines, "\n")) }
+	return accordion, show
+}
+
+// showConfigWarnings lists ConfigLint findings below the inputs without blocking the calculation
+func showConfigWarnings(lbl *widget.Label, cfg stc.Config) {
+	warnings := stc.ConfigLint(cfg)
+	if len(warnings) == 0 {
+		lbl.Hide()
+		return
+	}
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = "⚠ " + w.String()
+	}
+	lbl.SetText(strings.Join(lines, "\n"))
+	lbl.Show()
+}
+
 // --- TOOL 2: Standard Calculator ---
 
 func makeCalculatorTab() fyne.CanvasObject {
@@ -558,11 +1139,233 @@ func makeCalculatorTab() fyne.CanvasObject {
 	return screen
 }
 
+// newLayoutToggle switches a tab's result card between the summary and payslip layouts
+func newLayoutToggle(card *widgets.ResultCard) fyne.CanvasObject {
+	toggle := widget.NewRadioGroup([]string{"Summary", "Payslip"}, func(choice string) {
+		card.SetPayslipLayout(choice == "Payslip")
+	})
+	toggle.Horizontal = true
+	toggle.Required = true
+	toggle.SetSelected("Summary")
+	return toggle
+}
+
+// filingStatusLabels names each filing status in the AMT section
+var filingStatusLabels = map[amt.FilingStatus]string{
+	amt.Single:          "Single",
+	amt.MarriedJoint:    "Married Filing Jointly",
+	amt.MarriedSeparate: "Married Filing Separately",
+	amt.HeadOfHousehold: "Head of Household",
+}
+
+// amtInputs is the AMT section shown for ISO exercises: the filing status,
+// the year's ordinary income, and any minimum tax credit carried in
+type amtInputs struct {
+	Status  *widget.Select
+	Income  *widgets.SmartEntry
+	Credit  *widgets.SmartEntry
+	Content fyne.CanvasObject
+}
+
+func newAMTInputs() *amtInputs {
+	options := make([]string, 0, len(amt.FilingStatuses))
+	for _, s := range amt.FilingStatuses {
+		options = append(options, filingStatusLabels[s])
+	}
+	a := &amtInputs{
+		Status: widget.NewSelect(options, nil),
+		Income: widgets.NewSmartEntry(""),
+		Credit: widgets.NewSmartEntry(""),
+	}
+	a.Status.SetSelectedIndex(0)
+	a.Income.SetPlaceHolder("Defaults to YTD Wages")
+	a.Credit.SetPlaceHolder("0.00")
+	form := widgets.NewFieldSet()
+	form.Append("Filing Status", a.Status)
+	form.Append("Ordinary Income ($)", a.Income)
+	form.Append("Prior AMT Credit ($)", a.Credit)
+	a.Content = container.NewVBox(
+		widget.NewLabelWithStyle("AMT", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		form,
+	)
+	return a
+}
+
+// Entries lists the inputs for Enter-to-calculate
+func (a *amtInputs) Entries() []*widgets.SmartEntry {
+	return []*widgets.SmartEntry{a.Income, a.Credit}
+}
+
+// Read returns the filing status, ordinary income, and prior credit;
+// a blank ordinary income falls back to wages
+func (a *amtInputs) Read(wages float64) (amt.FilingStatus, float64, float64, error) {
+	income, errIncome := parseFloat(a.Income.Text)
+	credit, errCredit := parseFloat(a.Credit.Text)
+	if errIncome != nil || errCredit != nil || income < 0 || credit < 0 {
+		return "", 0, 0, fmt.Errorf("Please enter valid amounts for Ordinary Income and Prior AMT Credit")
+	}
+	if strings.TrimSpace(a.Income.Text) == "" {
+		income = wages
+	}
+	return amt.FilingStatuses[a.Status.SelectedIndex()], income, credit, nil
+}
+
+// SetInput fills the section from a saved exercise
+func (a *amtInputs) SetInput(in stc.Input) {
+	status := in.FilingStatus
+	if status == "" {
+		status = amt.Single
+	}
+	a.Status.SetSelected(filingStatusLabels[status])
+	a.Income.SetText("")
+	if in.YTDIncome != in.YTDWages {
+		a.Income.SetText(viewmodel.Entry(in.YTDIncome, -1))
+	}
+	a.Credit.SetText("")
+	if in.PriorAMTCredit > 0 {
+		a.Credit.SetText(viewmodel.Entry(in.PriorAMTCredit, -1))
+	}
+}
+
+// holdInputs is the optional Hold Estimate field: months to hold the shares
+// kept, the price to sell them at, and the long-term capital gains rate
+type holdInputs struct {
+	Months  *widgets.SmartEntry
+	Price   *widgets.SmartEntry
+	LTRate  *widgets.SmartEntry
+	Content fyne.CanvasObject
+}
+
+func newHoldInputs() *holdInputs {
+	h := &holdInputs{
+		Months: widgets.NewSmartEntry(""),
+		Price:  widgets.NewSmartEntry(""),
+		LTRate: widgets.NewSmartEntry("0.15"),
+	}
+	h.Months.SetPlaceHolder("Months")
+	h.Price.SetPlaceHolder("Future price")
+	h.LTRate.SetPlaceHolder("LT rate")
+	h.Content = container.NewGridWithColumns(3, h.Months, h.Price, h.LTRate)
+	return h
+}
+
+// Entries lists the inputs for Enter-to-calculate
+func (h *holdInputs) Entries() []*widgets.SmartEntry {
+	return []*widgets.SmartEntry{h.Months, h.Price, h.LTRate}
+}
+
+// holdPlan is a parsed Hold Estimate
+type holdPlan struct {
+	Months int
+	Price  float64
+	Rates  portfolio.CapGainsRates
+}
+
+// Plan reads the Hold Estimate, or returns nil when no holding period is
+// entered. Short-term gains are taxed at the federal and state rates on the
+// form, long-term gains at the long-term rate plus state.
+func (h *holdInputs) Plan(rates stc.TaxRates) (*holdPlan, error) {
+	if strings.TrimSpace(h.Months.Text) == "" {
+		return nil, nil
+	}
+	months, errMonths := parseFloat(h.Months.Text)
+	price, errPrice := parseFloat(h.Price.Text)
+	lt, errRate := parseFloat(h.LTRate.Text)
+	if errMonths != nil || errPrice != nil || errRate != nil || months < 1 || months != math.Trunc(months) || price <= 0 || lt < 0 || lt > 1 {
+		return nil, fmt.Errorf("Hold Estimate needs whole months of 1 or more, a future price greater than 0, and a long-term rate from 0 to 1")
+	}
+	return &holdPlan{
+		Months: int(months),
+		Price:  price,
+		Rates: portfolio.CapGainsRates{
+			ShortTerm: rates.Federal + rates.State,
+			LongTerm:  lt + rates.State,
+		},
+	}, nil
+}
+
+// note estimates the capital gains on shares kept at basis today and held
+// as planned
+func (p *holdPlan) note(shares, basis float64) string {
+	lot := portfolio.Lot{Shares: shares, CostBasis: basis, Acquired: time.Now()}
+	return viewmodel.HoldNote(lot.EstimateHold(p.Months, p.Price, p.Rates))
+}
+
 // --- SHARED HELPERS ---
 
+// parseFloat reads a number or a formula over the named variables, e.g. "price*0.95"
 func parseFloat(s string) (float64, error) {
-	if s == "" {
+	if strings.TrimSpace(s) == "" {
 		return 0, nil
 	}
-	return strconv.ParseFloat(s, 64)
+	return expr.Eval(s, variables)
+}
+
+// parseDate reads a YYYY-MM-DD date at the tax home; blank input yields the zero time
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, taxHome())
+}
+
+// formatDate writes a date as parseDate reads it; the zero time is blank
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(taxHome()).Format("2006-01-02")
+}
+
+// formatResidency writes periods in the format parseResidency reads
+func formatResidency(periods []stc.ResidencyPeriod) string {
+	lines := make([]string, len(periods))
+	for i, p := range periods {
+		lines[i] = fmt.Sprintf("%s %g %s %s", p.State, p.Rate, formatDate(p.Start), formatDate(p.End))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// saleModeIndex finds mode among the Sale select's choices, defaulting to sell-to-cover
+func saleModeIndex(mode stc.SaleMode) int {
+	for i, m := range saleModes {
+		if m == mode {
+			return i
+		}
+	}
+	return 0
+}
+
+// parseResidency reads one residency period per line: "CA 0.093 2025-01-01 2025-06-30"
+func parseResidency(text string) ([]stc.ResidencyPeriod, error) {
+	var periods []stc.ResidencyPeriod
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid residency line %q", line)
+		}
+		rate, err := parseFloat(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		start, err := parseDate(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseDate(fields[3])
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, stc.ResidencyPeriod{
+			State: strings.ToUpper(fields[0]),
+			Rate:  rate,
+			Start: start,
+			End:   end,
+		})
+	}
+	return periods, nil
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/widgets"
+)
+
+// Fields that only apply in one valuation mode
+const (
+	fieldCurrentPrice  = "Current Price ($)"
+	fieldSalePrice     = "Est. Sale Price $"
+	fieldValuation     = "Valuation"
+	fieldValuationDate = "Valuation Date"
+)
+
+// modeFields are hidden while the app is in the given mode. A private company
+// has no market quote or open-market sale, so prices come from valuations.
+var modeFields = map[events.Mode][]string{
+	events.ModePublic:  {fieldValuation, fieldValuationDate},
+	events.ModePrivate: {fieldCurrentPrice, fieldSalePrice},
+}
+
+// hiddenFields combines the fields the user hid with those the valuation mode hides
+func hiddenFields(a fyne.App) map[string]bool {
+	hidden := loadHiddenFields(a)
+	for _, id := range modeFields[valuationMode] {
+		hidden[id] = true
+	}
+	return hidden
+}
+
+// newValuationDateEntry returns an entry that fills targets with the valuation
+// in effect on the typed date when Enter is pressed
+func newValuationDateEntry(win fyne.Window, valuationOn func(time.Time) (portfolio.Valuation, bool),
+	targets ...*widgets.SmartEntry) *widgets.SmartEntry {
+	entry := widgets.NewSmartEntry("YYYY-MM-DD")
+	entry.SetOnEnter(func() {
+		date, err := parseDate(entry.Text)
+		if err != nil || date.IsZero() {
+			dialog.ShowError(fmt.Errorf("Valuation date must be YYYY-MM-DD"), win)
+			return
+		}
+		v, ok := valuationOn(date)
+		if !ok {
+			dialog.ShowError(fmt.Errorf("No valuation is in effect on %s", date.Format("2006-01-02")), win)
+			return
+		}
+		for _, t := range targets {
+			t.SetText(fmt.Sprintf("%.2f", v.Price))
+		}
+	})
+	return entry
+}
+
+// showValuationDialog records a private-company valuation against the portfolio
+func showValuationDialog(win fyne.Window, pf *portfolio.Portfolio, onChange func()) {
+	dateEntry := widget.NewEntry()
+	dateEntry.SetText(time.Now().In(taxHome()).Format("2006-01-02"))
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("0.00")
+	sourceEntry := widget.NewEntry()
+	sourceEntry.SetText("409A")
+
+	var history strings.Builder
+	for _, v := range pf.AdjustedValuations() {
+		fmt.Fprintf(&history, "%s  $%.2f  %s\n", v.Effective.Format("2006-01-02"), v.Price, v.Source)
+	}
+	if history.Len() == 0 {
+		history.WriteString("No valuations recorded")
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Effective", dateEntry),
+		widget.NewFormItem("Price ($)", priceEntry),
+		widget.NewFormItem("Source", sourceEntry),
+		widget.NewFormItem("Recorded", widget.NewLabel(strings.TrimSpace(history.String()))),
+	}
+	dialog.ShowForm("Record Valuation", "Record", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		date, errDate := parseDate(dateEntry.Text)
+		price, errPrice := parseFloat(priceEntry.Text)
+		if errDate != nil || date.IsZero() || errPrice != nil || price <= 0 {
+			dialog.ShowError(fmt.Errorf("Please enter a YYYY-MM-DD date and a price greater than 0"), win)
+			return
+		}
+		pf.AddValuation(portfolio.Valuation{Effective: date, Price: price, Source: strings.TrimSpace(sourceEntry.Text)})
+		onChange()
+	}, win)
+}
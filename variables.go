@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+)
+
+const variablesKey = "variables"
+
+// variables holds the user's named values. Numeric fields may use them in
+// formulas, e.g. "price*0.95". The map is updated in place so forms holding
+// a reference see changes.
+var variables = expr.Vars{}
+
+// loadVariables reads the saved definitions from preferences
+func loadVariables(a fyne.App) {
+	vars, err := expr.ParseDefinitions(a.Preferences().String(variablesKey))
+	if err != nil {
+		fyne.LogError("Failed to load variables", err)
+		return
+	}
+	setVariables(vars)
+}
+
+// setVariables replaces the contents of the shared variables map
+func setVariables(vars expr.Vars) {
+	for name := range variables {
+		delete(variables, name)
+	}
+	for name, v := range vars {
+		variables[name] = v
+	}
+}
+
+// showVariablesDialog edits the named variables, one "name = formula" per line
+func showVariablesDialog(a fyne.App, win fyne.Window) {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("price = 61.10\nsale = price * 0.95")
+	entry.SetText(a.Preferences().String(variablesKey))
+	entry.SetMinRowsVisible(6)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Variables", entry),
+	}
+	dialog.ShowForm("Named Variables", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		vars, err := expr.ParseDefinitions(entry.Text)
+		if err != nil {
+			dialog.ShowError(err, win)
+			return
+		}
+		a.Preferences().SetString(variablesKey, entry.Text)
+		setVariables(vars)
+	}, win)
+}
@@ -0,0 +1,97 @@
+package viewmodel
+
+import (
+	"strconv"
+
+	"fynance/report"
+	"fynance/stc"
+)
+
+// Headline is a result's headline figures written for a locale, with its
+// thousands separators and currency symbol, e.g. "1.234,56 $". Shares show a
+// fractional part only when they have one.
+type Headline struct {
+	Shares       string // Shares exercised or released
+	Price        string // FMV of an exercise, sale price of a release
+	NetShares    string
+	SharesToSell string
+	GrantValue   string // Releases only: the value released, before any gross-up
+	Proceeds     string
+	GainLoss     string
+	TotalTax     string
+	Surtax       string
+	BrokerFees   string // Exercises only; releases report TotalFees
+	TotalFees    string
+	RegFees      string
+	TotalCosts   string
+	CashTopUp    string
+	Residual     string
+
+	// Headline ratios as percentages, e.g. "29.7%"
+	WithheldRate string
+	FeeRate      string
+	SoldRate     string
+}
+
+// ResultHeadline writes the exercise's headline figures for loc
+func ResultHeadline(r stc.Result, loc report.Locale) Headline {
+	return Headline{
+		Shares:       loc.Shares(r.ExercisedShares),
+		Price:        loc.Money(r.FMV),
+		NetShares:    loc.Shares(r.NetShares),
+		SharesToSell: loc.Shares(r.SharesToSell),
+		Proceeds:     loc.Money(r.EstGrossProceeds),
+		GainLoss:     loc.Money(r.STCGainLoss),
+		TotalTax:     loc.Money(r.TotalTax),
+		Surtax:       loc.Money(r.MedicareSurtax),
+		BrokerFees:   loc.Money(r.BrokerFees),
+		RegFees:      loc.Money(r.SECFee + r.TAF),
+		TotalCosts:   loc.Money(r.TotalCosts),
+		CashTopUp:    loc.Money(r.CashTopUp),
+		Residual:     loc.Money(r.Residual),
+		WithheldRate: loc.Percent(r.EffectiveWithholdingRate, 1),
+		FeeRate:      loc.Percent(r.FeesAsPercentOfProceeds, 1),
+		SoldRate:     loc.Percent(r.PercentOfSharesSold, 1),
+	}
+}
+
+// RSUHeadline writes the release's headline figures for loc
+func RSUHeadline(r stc.RSUResult, loc report.Locale) Headline {
+	return Headline{
+		Shares:       loc.Shares(r.SharesReleased),
+		Price:        loc.Money(r.SalePrice),
+		NetShares:    loc.Shares(r.NetShares),
+		SharesToSell: loc.Shares(r.SharesToSell),
+		GrantValue:   loc.Money(r.TaxableGain - r.GrossUp),
+		Proceeds:     loc.Money(r.EstGrossProceeds),
+		GainLoss:     loc.Money(r.STCGainLoss),
+		TotalTax:     loc.Money(r.TotalTax),
+		Surtax:       loc.Money(r.MedicareSurtax),
+		TotalFees:    loc.Money(r.TotalFees),
+		RegFees:      loc.Money(r.SECFee + r.TAF),
+		TotalCosts:   loc.Money(r.TotalCosts),
+		CashTopUp:    loc.Money(r.CashTopUp),
+		Residual:     loc.Money(r.Residual),
+		WithheldRate: loc.Percent(r.EffectiveWithholdingRate, 1),
+		FeeRate:      loc.Percent(r.FeesAsPercentOfProceeds, 1),
+		SoldRate:     loc.Percent(r.PercentOfSharesSold, 1),
+	}
+}
+
+// SolverRows writes each solver iteration for loc: the iteration, the shares
+// tried, their fees, the amount they must cover, and the shares that covers
+func SolverRows(steps []stc.SolverStep, loc report.Locale) [][]string {
+	rows := make([][]string, len(steps))
+	for i, st := range steps {
+		rows[i] = []string{strconv.Itoa(st.Iteration), loc.Shares(st.SharesToSell), loc.Money(st.Fees),
+			loc.Money(st.TotalRequired), loc.Shares(st.NextShares)}
+	}
+	return rows
+}
+
+// Entry writes an amount into an editable field. Fields are parsed as
+// expressions, so the text has no separators or symbols in any locale;
+// places is the decimals to keep, or -1 for as many as the value needs.
+func Entry(v float64, places int) string {
+	return strconv.FormatFloat(v, 'f', places, 64)
+}
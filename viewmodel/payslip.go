@@ -0,0 +1,179 @@
+package viewmodel
+
+import (
+	"fmt"
+	"strings"
+
+	"fynance/stc"
+)
+
+// payslipWidth is the character width of a rendered payslip
+const payslipWidth = 44
+
+// Payslip lays a result out like a pay stub: the equity income as earnings,
+// each withholding as a deduction, and the sell-to-cover trade below, so the
+// numbers can be checked line by line against the employer's statement
+type Payslip struct {
+	Title      string
+	Earnings   []Row
+	Gross      string
+	Deductions []Row
+	Total      string // Total deductions
+	Net        string // Earnings less deductions, received as shares
+	Settlement []Row  // How the deductions were paid
+	Cash       bool   // Net pay is cash, as for a cash-settled award, not shares
+}
+
+// deductions lists every non-zero tax, itemizing state and local lines when present
+func deductions(federal, medicare, surtax, socialSec, state, local float64, stateLines, localLines []stc.TaxLine) []Row {
+	var rows []Row
+	add := func(label string, v float64) {
+		if v != 0 {
+			rows = append(rows, Row{label, money(v)})
+		}
+	}
+	add("Federal Income Tax", federal)
+	add("Medicare", medicare)
+	add("Addl. Medicare", surtax)
+	add("Social Security", socialSec)
+	if len(stateLines) == 0 {
+		add("State Income Tax", state)
+	}
+	for _, l := range stateLines {
+		add(l.Name+" Income Tax", l.Amount)
+	}
+	if len(localLines) == 0 {
+		add("Local/SDI", local)
+	}
+	for _, l := range localLines {
+		add(l.Name, l.Amount)
+	}
+	return rows
+}
+
+// soldRow is the settlement line for the shares sold at salePrice, or at the
+// FMV when none is set. Shares withheld by the employer are valued at the FMV.
+func soldRow(mode stc.SaleMode, n, fmv, salePrice, proceeds float64) Row {
+	if mode == stc.WithholdToCover {
+		return Row{fmt.Sprintf("Withheld %s sh @ %s", shares(n), money(fmv)), money(n * fmv)}
+	}
+	price := fmv
+	if salePrice > 0 {
+		price = salePrice
+	}
+	return Row{fmt.Sprintf("Sold %s sh @ %s", shares(n), money(price)), money(proceeds)}
+}
+
+// PayslipFromResult lays out an options exercise
+func PayslipFromResult(r stc.Result) Payslip {
+	earning := "NSO Exercise Spread"
+	if r.AMT != nil {
+		earning = "ISO Exercise (not wages)"
+	}
+	p := Payslip{
+		Title:      "Stock Option Exercise",
+		Earnings:   []Row{{earning, money(r.TaxableGain)}},
+		Gross:      money(r.TaxableGain),
+		Deductions: deductions(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax, r.StateLines, r.LocalLines),
+		Total:      money(r.TotalTax),
+		Net:        money(r.TaxableGain - r.TotalTax),
+		Settlement: []Row{
+			soldRow(r.Mode, r.SharesToSell, r.FMV, r.SalePrice, r.EstGrossProceeds),
+			{"Option Cost", "-" + money(r.OptionCost)},
+			{"Taxes Withheld", "-" + money(r.TotalTax)},
+			{"Broker Fees", "-" + money(r.BrokerFees)},
+		},
+	}
+	if r.SECFee+r.TAF > 0 {
+		p.Settlement = append(p.Settlement, Row{"SEC/FINRA Fees", "-" + money(r.SECFee+r.TAF)})
+	}
+	if r.CashTopUp > 0 {
+		p.Settlement = append(p.Settlement, Row{"Cash Paid", money(r.CashTopUp)})
+	}
+	p.Settlement = append(p.Settlement, Row{"Refund to You", money(r.Residual)})
+	return p
+}
+
+// PayslipFromRSUResult lays out an RSU release
+func PayslipFromRSUResult(r stc.RSUResult) Payslip {
+	earning := fmt.Sprintf("RSU %s sh @ %s", shares(r.SharesReleased), money(r.VestPrice))
+	if r.DividendEquivalentShares > 0 {
+		earning = fmt.Sprintf("RSU %s sh + %s div. equiv. @ %s", shares(r.SharesReleased), shares(r.DividendEquivalentShares), money(r.VestPrice))
+	}
+	p := Payslip{
+		Title:      "Restricted Stock Release",
+		Earnings:   []Row{{earning, money(r.TaxableGain)}},
+		Gross:      money(r.TaxableGain),
+		Deductions: deductions(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax, r.StateLines, r.LocalLines),
+		Total:      money(r.TotalTax),
+		Net:        money(r.TaxableGain - r.TotalTax),
+		Settlement: []Row{
+			soldRow(r.Mode, r.SharesToSell, r.VestPrice, r.SalePrice, r.SharesToSell*r.SalePrice),
+			{"Taxes Withheld", "-" + money(r.TotalTax)},
+			{"Fees", "-" + money(r.TotalFees)},
+		},
+	}
+	if r.CashTopUp > 0 {
+		p.Settlement = append(p.Settlement, Row{"Cash Paid", money(r.CashTopUp)})
+	}
+	p.Settlement = append(p.Settlement, Row{"Refund to You", money(r.Residual)})
+	return p
+}
+
+// PayslipFromCashAwardResult lays out a cash-settled award, paid like a bonus
+func PayslipFromCashAwardResult(r stc.CashAwardResult) Payslip {
+	var earnings []Row
+	if r.Units > 0 {
+		earnings = append(earnings, Row{fmt.Sprintf("Phantom %s units @ %s", shares(r.Units), money(r.UnitPrice)), money(r.Units * r.UnitPrice)})
+	}
+	if r.Amount > 0 {
+		earnings = append(earnings, Row{"Cash Award", money(r.Amount)})
+	}
+	return Payslip{
+		Title:      "Cash-Settled Award",
+		Earnings:   earnings,
+		Gross:      money(r.Payout),
+		Deductions: deductions(r.FederalTax, r.MedicareTax, r.MedicareSurtax, r.SocialSecTax, r.StateTax, r.LocalSDITax, r.StateLines, r.LocalLines),
+		Total:      money(r.TotalTax),
+		Net:        money(r.NetCash),
+		Cash:       true,
+	}
+}
+
+// Text renders the payslip in fixed-width columns
+func (p Payslip) Text() string {
+	var b strings.Builder
+	line := func(label, value string) {
+		fmt.Fprintf(&b, "%-*s%s\n", payslipWidth-len(value), label, value)
+	}
+	rule := func() { b.WriteString(strings.Repeat("-", payslipWidth) + "\n") }
+
+	b.WriteString(strings.ToUpper(p.Title) + "\n")
+	rule()
+	b.WriteString("EARNINGS\n")
+	for _, r := range p.Earnings {
+		line("  "+r.Label, r.Value)
+	}
+	line("Gross Pay", p.Gross)
+	rule()
+	b.WriteString("DEDUCTIONS\n")
+	for _, r := range p.Deductions {
+		line("  "+r.Label, r.Value)
+	}
+	line("Total Deductions", p.Total)
+	rule()
+	if p.Cash {
+		line("NET PAY (cash)", p.Net)
+	} else {
+		line("NET PAY (in shares)", p.Net)
+	}
+	if len(p.Settlement) == 0 {
+		return b.String()
+	}
+	rule()
+	b.WriteString("SELL TO COVER\n")
+	for _, r := range p.Settlement {
+		line("  "+r.Label, r.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,163 @@
+package viewmodel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"fynance/stc"
+)
+
+// PaystubLine is one line of a paystub, without and with the release
+type PaystubLine struct {
+	Label   string
+	Without string
+	With    string
+	Change  string // Blank when the release leaves the line alone
+}
+
+// Paystub lays a pay period out twice, without and with an RSU release, so
+// each line shows what the release changed: the gross, every withholding,
+// the stock offsets, and the net pay
+type Paystub struct {
+	Earnings    []PaystubLine
+	Gross       PaystubLine
+	Deductions  []PaystubLine
+	TotalTax    PaystubLine
+	Adjustments []PaystubLine // Stock offset and the tax the shares paid
+	Net         PaystubLine
+	Notes       []string
+
+	Next *Paystub // The next pay period, when the release's withholding is split
+}
+
+// paystubLine formats a line, leaving out lines that are zero in both columns
+func paystubLine(lines []PaystubLine, label string, without, with float64) []PaystubLine {
+	if without == 0 && with == 0 {
+		return lines
+	}
+	return append(lines, newPaystubLine(label, without, with))
+}
+
+func newPaystubLine(label string, without, with float64) PaystubLine {
+	l := PaystubLine{Label: label, Without: money(without), With: money(with)}
+	switch d := math.Round((with-without)*100) / 100; {
+	case d > 0:
+		l.Change = "+" + money(d)
+	case d < 0:
+		l.Change = "-" + money(-d)
+	}
+	return l
+}
+
+// ExplainPaystub lays out a paystub comparison. Deductions and offsets
+// are shown as positive amounts taken from the gross.
+func ExplainPaystub(r stc.PaystubResult) Paystub {
+	rel := r.Release
+	if r.SecondWithoutRelease == nil || r.SecondWithRelease == nil {
+		p := explainPeriod(r.WithoutRelease, r.WithRelease, rel, false)
+		if rel.Residual > 0 {
+			p.Notes = append(p.Notes, fmt.Sprintf("The %s left from the share sale goes to your brokerage account, not your pay.", money(rel.Residual)))
+		}
+		return p
+	}
+
+	p := explainPeriod(r.WithoutRelease, r.WithRelease, rel, true)
+	next := explainPeriod(*r.SecondWithoutRelease, *r.SecondWithRelease, rel, true)
+	p.Notes = append(p.Notes, fmt.Sprintf("Payroll splits the release over two paychecks: %s of it is on this one and %s on the next, each withheld in its own period.",
+		money(r.WithRelease.Gross-r.WithoutRelease.Gross), money(r.SecondWithRelease.Gross-r.SecondWithoutRelease.Gross)))
+	if rel.Residual > 0 {
+		next.Notes = append(next.Notes, fmt.Sprintf("The %s left from the share sale goes to your brokerage account, not your pay.", money(rel.Residual)))
+	}
+	p.Next = &next
+	return p
+}
+
+// explainPeriod compares one pay period without and with its part of the
+// release; split is set when the release spans two periods
+func explainPeriod(a, b stc.PaystubColumn, rel stc.RSUResult, split bool) Paystub {
+	p := Paystub{}
+	p.Earnings = paystubLine(p.Earnings, "Regular Pay", a.RegularWages, b.RegularWages)
+	p.Earnings = paystubLine(p.Earnings, fmt.Sprintf("RSU %g sh @ %s", b.EquityShares, money(rel.VestPrice)),
+		a.EquityIncome, b.EquityIncome)
+	p.Earnings = paystubLine(p.Earnings, "Tax Gross-Up", a.GrossUp, b.GrossUp)
+	p.Gross = newPaystubLine("Gross Pay", a.Gross, b.Gross)
+
+	p.Deductions = paystubLine(p.Deductions, "Federal Income Tax", a.FederalTax, b.FederalTax)
+	p.Deductions = paystubLine(p.Deductions, "Social Security", a.SocialSecTax, b.SocialSecTax)
+	p.Deductions = paystubLine(p.Deductions, "Medicare", a.MedicareTax, b.MedicareTax)
+	p.Deductions = paystubLine(p.Deductions, "Addl. Medicare", a.MedicareSurtax, b.MedicareSurtax)
+	p.Deductions = paystubLine(p.Deductions, "State Income Tax", a.StateTax, b.StateTax)
+	p.Deductions = paystubLine(p.Deductions, "Local/SDI", a.LocalSDITax, b.LocalSDITax)
+	p.TotalTax = newPaystubLine("Total Taxes", a.TotalTax, b.TotalTax)
+
+	p.Adjustments = paystubLine(p.Adjustments, "RSU Offset (paid in shares)", a.StockOffset, b.StockOffset)
+	p.Adjustments = paystubLine(p.Adjustments, "Tax Paid by Shares (credit)", a.SharesCredit, b.SharesCredit)
+	p.Adjustments = paystubLine(p.Adjustments, "Cash Top-Up", a.CashTopUp, b.CashTopUp)
+	p.Net = newPaystubLine("NET PAY", a.NetPay, b.NetPay)
+
+	if b.EquityIncome > 0 && rel.TaxableGain > 0 {
+		p.Notes = append(p.Notes, fmt.Sprintf("The release is supplemental wages: federal tax is withheld at %.1f%% of it, not at your W-4 rate.",
+			rel.FederalTax/rel.TaxableGain*100))
+	}
+	if b.SharesCredit > 0 && split {
+		p.Notes = append(p.Notes, fmt.Sprintf("%s of the tax withheld on this part is paid from the %g shares sold or withheld for the release.",
+			money(b.SharesCredit), rel.SharesToSell))
+	} else if b.SharesCredit > 0 {
+		p.Notes = append(p.Notes, fmt.Sprintf("%g shares were sold or withheld to pay %s of the release's tax, so it does not come out of your pay.",
+			rel.SharesToSell, money(b.SharesCredit)))
+	}
+	if b.GrossUp > 0 {
+		p.Notes = append(p.Notes, fmt.Sprintf("Your employer added a %s gross-up to pay the release's tax; it is taxed as income too.", money(b.GrossUp)))
+	}
+	if b.CashTopUp > 0 {
+		p.Notes = append(p.Notes, fmt.Sprintf("%s of the release's tax was not covered by shares and comes out of this paycheck.", money(b.CashTopUp)))
+	}
+	return p
+}
+
+// Text renders the comparison in fixed-width columns, with the next pay
+// period below when the release is split
+func (p Paystub) Text() string {
+	if p.Next == nil {
+		return p.period()
+	}
+	return "PAY PERIOD 1\n" + p.period() + "\nPAY PERIOD 2\n" + p.Next.period()
+}
+
+func (p Paystub) period() string {
+	const labelWidth, valueWidth = 30, 13
+	var b strings.Builder
+	line := func(l PaystubLine) {
+		fmt.Fprintf(&b, "%-*s%*s%*s%*s\n", labelWidth, l.Label, valueWidth, l.Without, valueWidth, l.With, valueWidth, l.Change)
+	}
+	rule := func() { b.WriteString(strings.Repeat("-", labelWidth+3*valueWidth) + "\n") }
+	section := func(title string, lines []PaystubLine) {
+		b.WriteString(title + "\n")
+		for _, l := range lines {
+			l.Label = "  " + l.Label
+			line(l)
+		}
+	}
+
+	line(PaystubLine{"", "Without RSU", "With RSU", "Change"})
+	rule()
+	section("EARNINGS", p.Earnings)
+	line(p.Gross)
+	rule()
+	section("TAXES", p.Deductions)
+	line(p.TotalTax)
+	rule()
+	if len(p.Adjustments) > 0 {
+		section("STOCK ADJUSTMENTS", p.Adjustments)
+		rule()
+	}
+	line(p.Net)
+	if len(p.Notes) > 0 {
+		b.WriteString("\n")
+		for _, n := range p.Notes {
+			b.WriteString("• " + n + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,383 @@
+// Package viewmodel turns calculation results into the labelled, formatted
+// text the UI shows, independent of Fyne, so the same output can be scripted
+// or compared against golden files.
+package viewmodel
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fynance/portfolio"
+	"fynance/report"
+	"fynance/stc"
+)
+
+// Row labels shown by the result card
+const (
+	RowGrantValue = "Total Grant Value:"
+	RowSharesSold = "Shares Sold:"
+	RowProceeds   = "Sale Proceeds:"
+	RowGainLoss   = "ST Gain/Loss:"
+	RowTaxes      = "Total Taxes:"
+	RowSurtax     = "Medicare Surtax:"
+	RowFees       = "Broker Fees:"
+	RowTotalFees  = "Total Fees:"
+	RowRegFees    = "SEC/FINRA Fees:"
+	RowTotalCosts = "Total Costs:"
+	RowCashTopUp  = "Cash Top-Up:"
+	RowWithheld   = "Withholding Rate:"
+	RowFeeRate    = "Fees / Proceeds:"
+	RowSoldRate   = "Shares Sold %:"
+)
+
+// Row is one labelled value
+type Row struct {
+	Label string
+	Value string
+}
+
+// ViewModel is everything a result card displays, already formatted
+type ViewModel struct {
+	Title     string
+	NetShares string
+	Residual  string
+	Rows      []Row
+	Notes     []string // Itemized state and local tax lines
+	Warnings  []string // Shown as a banner above the result
+}
+
+// withheldNote explains the sold-share rows of a net share settlement
+const withheldNote = "Shares withheld by the employer at FMV; no broker fees"
+
+// grossUpNote explains an employer gross-up, which is taxed as income too
+func grossUpNote(grossUp float64) string {
+	return fmt.Sprintf("Employer gross-up: %s pays the tax on the award and on itself", money(grossUp))
+}
+
+// FromResult formats an options calculation
+func FromResult(r stc.Result) ViewModel {
+	vm := ViewModel{
+		Title:     "Stock Options",
+		NetShares: shares(r.NetShares),
+		Residual:  money(r.Residual),
+		Rows: []Row{
+			{RowSharesSold, shares(r.SharesToSell)},
+			{RowSoldRate, percent(r.PercentOfSharesSold)},
+			{RowProceeds, money(r.EstGrossProceeds)},
+			{RowGainLoss, money(r.STCGainLoss)},
+			{RowTaxes, money(r.TotalTax)},
+			{RowWithheld, percent(r.EffectiveWithholdingRate)},
+			{RowSurtax, money(r.MedicareSurtax)},
+			{RowFees, money(r.BrokerFees)},
+			{RowRegFees, money(r.SECFee + r.TAF)},
+			{RowFeeRate, percent(r.FeesAsPercentOfProceeds)},
+			{RowTotalCosts, money(r.TotalCosts)},
+			{RowCashTopUp, money(r.CashTopUp)},
+		},
+		Notes:    TaxLines(append(r.StateLines, r.LocalLines...)),
+		Warnings: Warnings(r.Warnings),
+	}
+	if r.Mode == stc.WithholdToCover {
+		vm.Notes = append(vm.Notes, withheldNote)
+	}
+	if r.GrossUp > 0 {
+		vm.Notes = append(vm.Notes, grossUpNote(r.GrossUp))
+	}
+	if r.TargetCash > 0 {
+		if r.Residual < r.TargetCash {
+			vm.Notes = append(vm.Notes, fmt.Sprintf("Selling every share leaves %s, short of the %s target",
+				money(r.Residual), money(r.TargetCash)))
+		} else {
+			vm.Notes = append(vm.Notes, fmt.Sprintf("Shares sold to keep %s in cash after costs", money(r.TargetCash)))
+		}
+	}
+	if r.AMT != nil {
+		vm.Notes = append(vm.Notes,
+			fmt.Sprintf("ISO: nothing withheld; AMT preference %s", money(r.AMT.Preference)),
+			fmt.Sprintf("Est. AMT due at filing: %s (TMT %s − regular %s)",
+				money(r.AMT.Liability), money(r.AMT.TentativeMinimumTax), money(r.AMT.RegularTax)))
+		if r.AMT.CreditUsed > 0 {
+			vm.Notes = append(vm.Notes, fmt.Sprintf("Prior AMT credit used this year: %s", money(r.AMT.CreditUsed)))
+		}
+		if r.AMT.CreditCarryforward > 0 {
+			vm.Notes = append(vm.Notes, fmt.Sprintf("AMT credit carried forward: %s", money(r.AMT.CreditCarryforward)))
+		}
+	}
+	return vm
+}
+
+// FromMultiLotResult formats a multi-lot calculation: the combined result,
+// then each lot's share of it
+func FromMultiLotResult(r stc.MultiLotResult) ViewModel {
+	vm := FromResult(r.Result)
+	vm.Title = "Multiple Lots"
+	lots := make([]string, 0, len(r.Lots))
+	for i, l := range r.Lots {
+		label := l.Label
+		if label == "" {
+			label = fmt.Sprintf("Lot %d", i+1)
+		}
+		line := fmt.Sprintf("%s: sell %s of %s sh, tax %s, fees %s",
+			label, shares(l.SharesSold), shares(l.Shares), money(l.Tax), money(l.Fees))
+		if l.GrossUp > 0 {
+			line += ", grossed up " + money(l.GrossUp)
+		}
+		lots = append(lots, line)
+	}
+	vm.Notes = append(lots, vm.Notes...)
+	return vm
+}
+
+// FromRSUResult formats an RSU calculation
+func FromRSUResult(r stc.RSUResult) ViewModel {
+	vm := ViewModel{
+		Title:     "Restricted Stock",
+		NetShares: shares(r.NetShares),
+		Residual:  money(r.Residual),
+		Rows: []Row{
+			// Show Taxable Gain as "Total Value" to clarify what the user likely expects
+			{RowGrantValue, money(r.TaxableGain - r.GrossUp)},
+			{RowSharesSold, shares(r.SharesToSell)},
+			{RowSoldRate, percent(r.PercentOfSharesSold)},
+			{RowProceeds, money(r.EstGrossProceeds)},
+			{RowGainLoss, money(r.STCGainLoss)},
+			{RowTaxes, money(r.TotalTax)},
+			{RowWithheld, percent(r.EffectiveWithholdingRate)},
+			{RowSurtax, money(r.MedicareSurtax)},
+			{RowTotalFees, money(r.TotalFees)},
+			{RowRegFees, money(r.SECFee + r.TAF)},
+			{RowFeeRate, percent(r.FeesAsPercentOfProceeds)},
+			{RowTotalCosts, money(r.TotalCosts)},
+			{RowCashTopUp, money(r.CashTopUp)},
+		},
+		Notes:    TaxLines(append(r.StateLines, r.LocalLines...)),
+		Warnings: Warnings(r.Warnings),
+	}
+	if r.Mode == stc.WithholdToCover {
+		vm.Notes = append(vm.Notes, withheldNote)
+	}
+	if r.GrossUp > 0 {
+		vm.Notes = append(vm.Notes, grossUpNote(r.GrossUp))
+	}
+	return vm
+}
+
+// FromCashAwardResult formats a cash-settled award, which pays cash rather than shares
+func FromCashAwardResult(r stc.CashAwardResult) ViewModel {
+	vm := ViewModel{
+		Title:     "Cash-Settled Award",
+		NetShares: "0",
+		Residual:  money(r.NetCash),
+		Rows: []Row{
+			{RowGrantValue, money(r.Payout)},
+			{RowTaxes, money(r.TotalTax)},
+			{RowSurtax, money(r.MedicareSurtax)},
+		},
+		Notes: TaxLines(append(r.StateLines, r.LocalLines...)),
+	}
+	vm.Notes = append(vm.Notes, "Paid in cash through payroll as supplemental wages; nothing is sold")
+	return vm
+}
+
+// FromResultIn formats an options calculation with the headline figures
+// written for loc; notes keep the plain format
+func FromResultIn(r stc.Result, loc report.Locale) ViewModel {
+	return FromResult(r).localized(ResultHeadline(r, loc))
+}
+
+// FromMultiLotResultIn formats a multi-lot calculation for loc
+func FromMultiLotResultIn(r stc.MultiLotResult, loc report.Locale) ViewModel {
+	return FromMultiLotResult(r).localized(ResultHeadline(r.Result, loc))
+}
+
+// FromRSUResultIn formats an RSU calculation for loc
+func FromRSUResultIn(r stc.RSUResult, loc report.Locale) ViewModel {
+	return FromRSUResult(r).localized(RSUHeadline(r, loc))
+}
+
+// localized replaces the headline values and rows with f's
+func (vm ViewModel) localized(f Headline) ViewModel {
+	values := map[string]string{
+		RowGrantValue: f.GrantValue,
+		RowSharesSold: f.SharesToSell,
+		RowProceeds:   f.Proceeds,
+		RowGainLoss:   f.GainLoss,
+		RowTaxes:      f.TotalTax,
+		RowSurtax:     f.Surtax,
+		RowFees:       f.BrokerFees,
+		RowTotalFees:  f.TotalFees,
+		RowRegFees:    f.RegFees,
+		RowTotalCosts: f.TotalCosts,
+		RowCashTopUp:  f.CashTopUp,
+		RowWithheld:   f.WithheldRate,
+		RowFeeRate:    f.FeeRate,
+		RowSoldRate:   f.SoldRate,
+	}
+	vm.NetShares, vm.Residual = f.NetShares, f.Residual
+	vm.Rows = slices.Clone(vm.Rows)
+	for i, row := range vm.Rows {
+		if v := values[row.Label]; v != "" {
+			vm.Rows[i].Value = v
+		}
+	}
+	return vm
+}
+
+// SaleOutcome summarizes one way of settling a transaction, so the
+// alternatives can be compared: the shares kept, valued at price, and the net cash
+func SaleOutcome(mode string, keptShares, price, netCash float64) string {
+	return fmt.Sprintf("%s: keep %s shares (%s), net cash %s",
+		mode, shares(keptShares), money(keptShares*price), money(netCash))
+}
+
+// BreakEvenNote says how far the price can move before the shares sold change
+func BreakEvenNote(b stc.BreakEven) string {
+	shares := strconv.FormatFloat(b.Shares, 'f', -1, 64)
+	if b.Ceiling == 0 {
+		return fmt.Sprintf("Break-even: %s shares cover the costs down to %s", shares, money(b.Floor))
+	}
+	return fmt.Sprintf("Break-even: %s shares cover the costs down to %s; from %s one fewer would",
+		shares, money(b.Floor), money(b.Ceiling))
+}
+
+// TrueUpNote says what is left to settle at filing once withholding is
+// compared with the marginal brackets
+func TrueUpNote(t stc.TrueUp) string {
+	withheld := t.Withheld / t.Income * 100
+	switch {
+	case math.Abs(t.Shortfall) < 1:
+		return fmt.Sprintf("True-up: %.1f%% withheld matches the %.0f%% bracket", withheld, t.MarginalRate*100)
+	case t.Shortfall > 0:
+		return fmt.Sprintf("True-up: about %s more due at filing; %.1f%% withheld, top bracket %.0f%%",
+			money(t.Shortfall), withheld, t.MarginalRate*100)
+	}
+	return fmt.Sprintf("True-up: about %s refunded at filing; %.1f%% withheld, top bracket %.0f%%",
+		money(-t.Shortfall), withheld, t.MarginalRate*100)
+}
+
+// HoldNote compares holding the shares kept with selling them now
+func HoldNote(h portfolio.HoldEstimate) string {
+	term := "short-term"
+	if h.LongTerm {
+		term = "long-term"
+	}
+	return fmt.Sprintf("Hold %d mo to %s (%s): gain %s, tax %s, %s after tax vs %s selling now",
+		h.Months, money(h.Price), term, money(h.Gain), money(h.Tax), money(h.AfterTax), money(h.SellNow))
+}
+
+// Value looks up a row by label
+func (vm ViewModel) Value(label string) (string, bool) {
+	for _, r := range vm.Rows {
+		if r.Label == label {
+			return r.Value, true
+		}
+	}
+	return "", false
+}
+
+// Snapshot renders the view model as stable plain text, one value per line
+func (vm ViewModel) Snapshot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", vm.Title)
+	fmt.Fprintf(&b, "Net Shares: %s\n", vm.NetShares)
+	fmt.Fprintf(&b, "Residual: %s\n", vm.Residual)
+	for _, r := range vm.Rows {
+		fmt.Fprintf(&b, "%s %s\n", r.Label, r.Value)
+	}
+	for _, n := range vm.Notes {
+		fmt.Fprintf(&b, "  %s\n", n)
+	}
+	for _, w := range vm.Warnings {
+		fmt.Fprintf(&b, "⚠ %s\n", w)
+	}
+	return b.String()
+}
+
+// spokenRows are the rows read aloud by Spoken, in order
+var spokenRows = []string{RowGrantValue, RowSharesSold, RowTaxes, RowTotalCosts, RowCashTopUp}
+
+// Spoken renders the key results as sentences for a text-to-speech engine:
+// the shares kept, the cash left over, the main rows, and any warnings.
+// Amounts are spelled as dollars and cents so every engine reads them alike.
+func (vm ViewModel) Spoken() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s result. ", vm.Title)
+	fmt.Fprintf(&b, "You keep %s shares. ", vm.NetShares)
+	fmt.Fprintf(&b, "Residual cash: %s. ", spokenValue(vm.Residual))
+	for _, label := range spokenRows {
+		v, ok := vm.Value(label)
+		if !ok || (label == RowCashTopUp && !strings.ContainsAny(v, "123456789")) {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s. ", label, spokenValue(v))
+	}
+	switch len(vm.Warnings) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&b, "One warning: %s", vm.Warnings[0])
+	default:
+		fmt.Fprintf(&b, "%d warnings: %s", len(vm.Warnings), strings.Join(vm.Warnings, ". "))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// spokenValue spells a formatted dollar amount as words, e.g. "$-25.06" or
+// "-$25.06" as "minus 25 dollars and 6 cents"; other values, including
+// amounts written for other locales, are returned as they are
+func spokenValue(v string) string {
+	digits, negative := strings.CutPrefix(v, "-")
+	digits, ok := strings.CutPrefix(digits, "$")
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(digits, ",", ""), 64)
+	if !ok || err != nil {
+		return v
+	}
+	if negative {
+		amount = -amount
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "minus "
+	}
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	if cents%100 == 0 {
+		return fmt.Sprintf("%s%d dollars", sign, cents/100)
+	}
+	return fmt.Sprintf("%s%d dollars and %d cents", sign, cents/100, cents%100)
+}
+
+// Warnings formats result warnings, one string per warning
+func Warnings(warnings []stc.Warning) []string {
+	out := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		out = append(out, w.String())
+	}
+	return out
+}
+
+// TaxLines formats itemized tax lines, one string per line
+func TaxLines(lines []stc.TaxLine) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, fmt.Sprintf("%s: %s on %s @ %s",
+			l.Name, money(l.Amount), money(l.Income), strconv.FormatFloat(l.Rate, 'f', -1, 64)))
+	}
+	return out
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+// shares writes a share count with as many decimals as it has, up to the
+// millionth a fractional share policy sells, e.g. "120" or "12.5"
+func shares(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
+
+// percent writes a fraction as a percentage, e.g. 0.2965 as "29.7%"
+func percent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v*100)
+}
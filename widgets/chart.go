@@ -0,0 +1,164 @@
+package widgets
+
+import (
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Point is one (x, y) sample on a chart
+type Point struct {
+	X float64
+	Y float64
+}
+
+// LineChart draws a single series, e.g. residual cash against sale price
+type LineChart struct {
+	widget.BaseWidget
+
+	XLabel string
+	YLabel string
+	points []Point
+}
+
+// NewLineChart creates an empty chart with the given axis labels
+func NewLineChart(xLabel, yLabel string) *LineChart {
+	c := &LineChart{XLabel: xLabel, YLabel: yLabel}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetPoints replaces the plotted series
+func (c *LineChart) SetPoints(points []Point) {
+	c.points = points
+	c.Refresh()
+}
+
+// MinSize keeps the chart legible inside tight layouts
+func (c *LineChart) MinSize() fyne.Size {
+	c.ExtendBaseWidget(c)
+	return fyne.NewSize(240, 140)
+}
+
+// CreateRenderer implements fyne.Widget
+func (c *LineChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &lineChartRenderer{chart: c}
+	r.rebuild()
+	return r
+}
+
+type lineChartRenderer struct {
+	chart    *LineChart
+	xAxis    *canvas.Line
+	yAxis    *canvas.Line
+	segments []*canvas.Line
+	labels   []*canvas.Text // xMin, xMax, yMin, yMax, xLabel, yLabel
+	objects  []fyne.CanvasObject
+}
+
+func (r *lineChartRenderer) rebuild() {
+	fg := theme.Color(theme.ColorNameForeground)
+	r.xAxis = canvas.NewLine(fg)
+	r.yAxis = canvas.NewLine(fg)
+
+	r.segments = nil
+	for i := 1; i < len(r.chart.points); i++ {
+		seg := canvas.NewLine(theme.Color(theme.ColorNamePrimary))
+		seg.StrokeWidth = 2
+		r.segments = append(r.segments, seg)
+	}
+
+	xMin, xMax, yMin, yMax := r.bounds()
+	r.labels = []*canvas.Text{
+		canvas.NewText(fmt.Sprintf("%.2f", xMin), fg),
+		canvas.NewText(fmt.Sprintf("%.2f", xMax), fg),
+		canvas.NewText(fmt.Sprintf("%.2f", yMin), fg),
+		canvas.NewText(fmt.Sprintf("%.2f", yMax), fg),
+		canvas.NewText(r.chart.XLabel, fg),
+		canvas.NewText(r.chart.YLabel, fg),
+	}
+	for _, l := range r.labels {
+		l.TextSize = theme.CaptionTextSize()
+	}
+
+	r.objects = []fyne.CanvasObject{r.xAxis, r.yAxis}
+	for _, s := range r.segments {
+		r.objects = append(r.objects, s)
+	}
+	for _, l := range r.labels {
+		r.objects = append(r.objects, l)
+	}
+}
+
+// bounds returns the data range, padded so flat series still render
+func (r *lineChartRenderer) bounds() (xMin, xMax, yMin, yMax float64) {
+	if len(r.chart.points) == 0 {
+		return 0, 1, 0, 1
+	}
+	xMin, yMin = math.Inf(1), math.Inf(1)
+	xMax, yMax = math.Inf(-1), math.Inf(-1)
+	for _, p := range r.chart.points {
+		xMin, xMax = math.Min(xMin, p.X), math.Max(xMax, p.X)
+		yMin, yMax = math.Min(yMin, p.Y), math.Max(yMax, p.Y)
+	}
+	if xMax == xMin {
+		xMax = xMin + 1
+	}
+	if yMax == yMin {
+		yMax = yMin + 1
+	}
+	return xMin, xMax, yMin, yMax
+}
+
+func (r *lineChartRenderer) Layout(size fyne.Size) {
+	pad := theme.Padding()
+	labelW := float32(56)
+	labelH := theme.CaptionTextSize() + pad
+
+	left, top := labelW, labelH
+	right, bottom := size.Width-pad, size.Height-labelH*2
+	plotW, plotH := right-left, bottom-top
+
+	r.yAxis.Position1 = fyne.NewPos(left, top)
+	r.yAxis.Position2 = fyne.NewPos(left, bottom)
+	r.xAxis.Position1 = fyne.NewPos(left, bottom)
+	r.xAxis.Position2 = fyne.NewPos(right, bottom)
+
+	xMin, xMax, yMin, yMax := r.bounds()
+	toPos := func(p Point) fyne.Position {
+		x := left + float32((p.X-xMin)/(xMax-xMin))*plotW
+		y := bottom - float32((p.Y-yMin)/(yMax-yMin))*plotH
+		return fyne.NewPos(x, y)
+	}
+	for i, seg := range r.segments {
+		seg.Position1 = toPos(r.chart.points[i])
+		seg.Position2 = toPos(r.chart.points[i+1])
+	}
+
+	r.labels[0].Move(fyne.NewPos(left, bottom+pad/2))
+	r.labels[1].Move(fyne.NewPos(right-r.labels[1].MinSize().Width, bottom+pad/2))
+	r.labels[2].Move(fyne.NewPos(0, bottom-labelH))
+	r.labels[3].Move(fyne.NewPos(0, top))
+	r.labels[4].Move(fyne.NewPos(left+(plotW-r.labels[4].MinSize().Width)/2, bottom+labelH))
+	r.labels[5].Move(fyne.NewPos(left, 0))
+}
+
+func (r *lineChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(240, 140)
+}
+
+func (r *lineChartRenderer) Refresh() {
+	r.rebuild()
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *lineChartRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *lineChartRenderer) Destroy() {}
@@ -0,0 +1,4 @@
+// Package widgets provides the Fynance calculator's Fyne components — input
+// forms, the result card, and charts — so other Fyne applications can embed
+// the sell-to-cover UI without depending on the fynance main package.
+package widgets
@@ -0,0 +1,59 @@
+package widgets
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// FieldSet is a labelled two-column form whose rows can be hidden.
+// Hidden rows take no space, so the remaining fields close up.
+type FieldSet struct {
+	widget.BaseWidget
+
+	grid *fyne.Container
+	rows map[string][2]fyne.CanvasObject // id → label, field
+}
+
+// NewFieldSet creates an empty field set
+func NewFieldSet() *FieldSet {
+	f := &FieldSet{
+		grid: container.New(layout.NewFormLayout()),
+		rows: make(map[string][2]fyne.CanvasObject),
+	}
+	f.ExtendBaseWidget(f)
+	return f
+}
+
+// Append adds a row identified by its label
+func (f *FieldSet) Append(label string, field fyne.CanvasObject) {
+	f.AppendWithID(label, label, field)
+}
+
+// AppendWithID adds a row whose id differs from its label, e.g. an unlabelled checkbox
+func (f *FieldSet) AppendWithID(id, label string, field fyne.CanvasObject) {
+	lbl := widget.NewLabelWithStyle(label, fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+	f.rows[id] = [2]fyne.CanvasObject{lbl, field}
+	f.grid.Add(lbl)
+	f.grid.Add(field)
+}
+
+// SetHidden hides every row whose id is in hidden and shows the rest
+func (f *FieldSet) SetHidden(hidden map[string]bool) {
+	for id, row := range f.rows {
+		for _, o := range row {
+			if hidden[id] {
+				o.Hide()
+			} else {
+				o.Show()
+			}
+		}
+	}
+	f.grid.Refresh()
+}
+
+// CreateRenderer implements fyne.Widget
+func (f *FieldSet) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(f.grid)
+}
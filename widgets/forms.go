@@ -0,0 +1,209 @@
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+	"fynance/stc"
+)
+
+// TaxRatesForm edits an stc.TaxRates. Entries are exported so callers can lay
+// them out with their own labels; Items provides a ready-made layout.
+type TaxRatesForm struct {
+	Federal       *SmartEntry
+	Medicare      *SmartEntry
+	Surtax        *SmartEntry // Additional Medicare Tax
+	SocialSec     *SmartEntry
+	State         *SmartEntry
+	LocalSDI      *SmartEntry
+	Jurisdictions *JurisdictionEditor
+
+	Vars expr.Vars // Named variables entries may refer to, e.g. "rate*2"
+}
+
+// NewTaxRatesForm creates a form pre-filled with the given rates
+func NewTaxRatesForm(rates stc.TaxRates) *TaxRatesForm {
+	f := &TaxRatesForm{
+		Federal:       NewSmartEntry(formatRate(rates.Federal)),
+		Medicare:      NewSmartEntry(formatRate(rates.Medicare)),
+		Surtax:        NewSmartEntry(formatRate(rates.MedicareSurtax)),
+		SocialSec:     NewSmartEntry(formatRate(rates.SocialSec)),
+		State:         NewSmartEntry(fmt.Sprintf("%.2f", rates.State)),
+		LocalSDI:      NewSmartEntry(fmt.Sprintf("%.2f", rates.LocalSDI)),
+		Jurisdictions: NewJurisdictionEditor(),
+	}
+	f.Jurisdictions.SetJurisdictions(rates.Jurisdictions)
+	return f
+}
+
+// Entries returns every single-line entry, e.g. for attaching an Enter handler
+func (f *TaxRatesForm) Entries() []*SmartEntry {
+	return []*SmartEntry{f.Federal, f.Medicare, f.Surtax, f.SocialSec, f.State, f.LocalSDI}
+}
+
+// Items returns the default form layout
+func (f *TaxRatesForm) Items() []*widget.FormItem {
+	return []*widget.FormItem{
+		widget.NewFormItem("Federal", f.Federal),
+		widget.NewFormItem("Medicare", f.Medicare),
+		widget.NewFormItem("Medicare Surtax", f.Surtax),
+		widget.NewFormItem("Social Sec", f.SocialSec),
+		widget.NewFormItem("State", f.State),
+		widget.NewFormItem("Local/SDI", f.LocalSDI),
+		widget.NewFormItem("Jurisdictions", f.Jurisdictions.Content),
+	}
+}
+
+// Rates reads the entered rates. Blank entries count as zero.
+func (f *TaxRatesForm) Rates() (stc.TaxRates, error) {
+	var rates stc.TaxRates
+	var err error
+	fields := []struct {
+		name  string
+		entry *SmartEntry
+		dst   *float64
+	}{
+		{"Federal", f.Federal, &rates.Federal},
+		{"Medicare", f.Medicare, &rates.Medicare},
+		{"Medicare Surtax", f.Surtax, &rates.MedicareSurtax},
+		{"Social Sec", f.SocialSec, &rates.SocialSec},
+		{"State", f.State, &rates.State},
+		{"Local/SDI", f.LocalSDI, &rates.LocalSDI},
+	}
+	for _, fld := range fields {
+		if *fld.dst, err = parseFloat(fld.entry.Text, f.Vars); err != nil {
+			return stc.TaxRates{}, fmt.Errorf("invalid %s rate: %w", fld.name, err)
+		}
+	}
+	f.Jurisdictions.Vars = f.Vars
+	if rates.Jurisdictions, err = f.Jurisdictions.Jurisdictions(); err != nil {
+		return stc.TaxRates{}, err
+	}
+	return rates, nil
+}
+
+// SetRates loads rates into the form
+func (f *TaxRatesForm) SetRates(rates stc.TaxRates) {
+	f.Federal.SetText(formatRate(rates.Federal))
+	f.Medicare.SetText(formatRate(rates.Medicare))
+	f.Surtax.SetText(formatRate(rates.MedicareSurtax))
+	f.SocialSec.SetText(formatRate(rates.SocialSec))
+	f.State.SetText(formatRate(rates.State))
+	f.LocalSDI.SetText(formatRate(rates.LocalSDI))
+	f.Jurisdictions.SetJurisdictions(rates.Jurisdictions)
+}
+
+// BrokerFeesForm edits an stc.BrokerFees
+type BrokerFeesForm struct {
+	CommissionRate *SmartEntry
+	MinimumFee     *SmartEntry
+	FlatFee        *SmartEntry
+	ExtraShares    *SmartEntry
+	Tiers          *TierEditor
+	AnnualCap      *SmartEntry
+	VolumeTiers    *VolumeTierEditor
+
+	Vars expr.Vars // Named variables entries may refer to
+}
+
+// NewBrokerFeesForm creates a form pre-filled with the given fees
+func NewBrokerFeesForm(fees stc.BrokerFees) *BrokerFeesForm {
+	f := &BrokerFeesForm{
+		CommissionRate: NewSmartEntry(formatRate(fees.CommissionRate)),
+		MinimumFee:     NewSmartEntry(fmt.Sprintf("%.2f", fees.MinimumFee)),
+		FlatFee:        NewSmartEntry(fmt.Sprintf("%.2f", fees.FlatFee)),
+		ExtraShares:    NewSmartEntry(formatRate(fees.ExtraShares)),
+		Tiers:          NewTierEditor(),
+		AnnualCap:      NewSmartEntry(formatCap(fees.AnnualCap)),
+		VolumeTiers:    NewVolumeTierEditor(),
+	}
+	f.AnnualCap.SetPlaceHolder("No cap")
+	f.Tiers.SetTiers(fees.CommissionTiers)
+	f.VolumeTiers.SetTiers(fees.VolumeTiers)
+	return f
+}
+
+// Entries returns every entry, e.g. for attaching an Enter handler
+func (f *BrokerFeesForm) Entries() []*SmartEntry {
+	return []*SmartEntry{f.CommissionRate, f.MinimumFee, f.FlatFee, f.ExtraShares, f.AnnualCap}
+}
+
+// Items returns the default form layout
+func (f *BrokerFeesForm) Items() []*widget.FormItem {
+	return []*widget.FormItem{
+		widget.NewFormItem("Commission Rate", f.CommissionRate),
+		widget.NewFormItem("Minimum Fee ($)", f.MinimumFee),
+		widget.NewFormItem("Processing Fee ($)", f.FlatFee),
+		widget.NewFormItem("Extra Shares", f.ExtraShares),
+		widget.NewFormItem("Commission Tiers", f.Tiers.Content),
+		widget.NewFormItem("Annual Fee Cap ($)", f.AnnualCap),
+		widget.NewFormItem("Volume Tiers", f.VolumeTiers.Content),
+	}
+}
+
+// Fees reads the entered fees. Blank entries count as zero.
+func (f *BrokerFeesForm) Fees() (stc.BrokerFees, error) {
+	var fees stc.BrokerFees
+	var err error
+	fields := []struct {
+		name  string
+		entry *SmartEntry
+		dst   *float64
+	}{
+		{"commission rate", f.CommissionRate, &fees.CommissionRate},
+		{"minimum fee", f.MinimumFee, &fees.MinimumFee},
+		{"processing fee", f.FlatFee, &fees.FlatFee},
+		{"extra shares", f.ExtraShares, &fees.ExtraShares},
+		{"annual fee cap", f.AnnualCap, &fees.AnnualCap},
+	}
+	for _, fld := range fields {
+		if *fld.dst, err = parseFloat(fld.entry.Text, f.Vars); err != nil {
+			return stc.BrokerFees{}, fmt.Errorf("invalid %s: %w", fld.name, err)
+		}
+	}
+	f.Tiers.Vars = f.Vars
+	if fees.CommissionTiers, err = f.Tiers.Tiers(); err != nil {
+		return stc.BrokerFees{}, err
+	}
+	f.VolumeTiers.Vars = f.Vars
+	if fees.VolumeTiers, err = f.VolumeTiers.Tiers(); err != nil {
+		return stc.BrokerFees{}, err
+	}
+	return fees, nil
+}
+
+// SetFees loads fees into the form
+func (f *BrokerFeesForm) SetFees(fees stc.BrokerFees) {
+	f.CommissionRate.SetText(formatRate(fees.CommissionRate))
+	f.MinimumFee.SetText(fmt.Sprintf("%.2f", fees.MinimumFee))
+	f.FlatFee.SetText(fmt.Sprintf("%.2f", fees.FlatFee))
+	f.ExtraShares.SetText(formatRate(fees.ExtraShares))
+	f.Tiers.SetTiers(fees.CommissionTiers)
+	f.AnnualCap.SetText(formatCap(fees.AnnualCap))
+	f.VolumeTiers.SetTiers(fees.VolumeTiers)
+}
+
+// parseFloat evaluates a number or formula, treating blank input as zero
+func parseFloat(s string, vars expr.Vars) (float64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	return expr.Eval(s, vars)
+}
+
+// formatCap renders an annual fee cap, leaving no cap blank
+func formatCap(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// formatRate renders a rate without trailing zeros (0.0145, not 0.014500)
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
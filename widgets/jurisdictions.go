@@ -0,0 +1,109 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+	"fynance/stc"
+)
+
+// jurisdictionRow is one editable line of the jurisdiction editor
+type jurisdictionRow struct {
+	name *widget.Entry
+	kind *widget.Select
+	rate *widget.Entry
+	box  fyne.CanvasObject
+}
+
+// JurisdictionEditor is a repeating-row editor for named state and local tax rates
+type JurisdictionEditor struct {
+	rows    []*jurisdictionRow
+	list    *fyne.Container
+	Content fyne.CanvasObject
+
+	Vars expr.Vars // Named variables rates may refer to
+}
+
+// NewJurisdictionEditor creates an empty editor with an "Add Jurisdiction" button
+func NewJurisdictionEditor() *JurisdictionEditor {
+	e := &JurisdictionEditor{list: container.NewVBox()}
+	addBtn := widget.NewButtonWithIcon("Add Jurisdiction", theme.ContentAddIcon(), func() {
+		e.addRow(stc.Jurisdiction{Kind: stc.JurisdictionLocal})
+	})
+	addBtn.Importance = widget.LowImportance
+	e.Content = container.NewVBox(e.list, addBtn)
+	return e
+}
+
+// addRow appends an editable row for the given jurisdiction
+func (e *JurisdictionEditor) addRow(j stc.Jurisdiction) {
+	row := &jurisdictionRow{
+		name: widget.NewEntry(),
+		kind: widget.NewSelect([]string{string(stc.JurisdictionState), string(stc.JurisdictionLocal)}, nil),
+		rate: widget.NewEntry(),
+	}
+	row.name.SetPlaceHolder("NYC")
+	row.name.SetText(j.Name)
+	row.kind.SetSelected(string(j.Kind))
+	row.rate.SetPlaceHolder("0.03876")
+	if j.Rate != 0 {
+		row.rate.SetText(formatRate(j.Rate))
+	}
+
+	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		e.removeRow(row)
+	})
+	removeBtn.Importance = widget.LowImportance
+
+	row.box = container.NewBorder(nil, nil, nil, removeBtn,
+		container.NewGridWithColumns(3, row.name, row.kind, row.rate))
+	e.rows = append(e.rows, row)
+	e.list.Add(row.box)
+}
+
+// removeRow deletes a row from the editor
+func (e *JurisdictionEditor) removeRow(row *jurisdictionRow) {
+	for i, r := range e.rows {
+		if r == row {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			break
+		}
+	}
+	e.list.Remove(row.box)
+}
+
+// Jurisdictions returns the configured rows, skipping blank ones
+func (e *JurisdictionEditor) Jurisdictions() ([]stc.Jurisdiction, error) {
+	var out []stc.Jurisdiction
+	for _, r := range e.rows {
+		name := strings.TrimSpace(r.name.Text)
+		if name == "" && strings.TrimSpace(r.rate.Text) == "" {
+			continue
+		}
+		rate, err := parseFloat(r.rate.Text, e.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s", name)
+		}
+		kind := stc.JurisdictionKind(r.kind.Selected)
+		if kind == "" {
+			kind = stc.JurisdictionLocal
+		}
+		out = append(out, stc.Jurisdiction{Name: name, Kind: kind, Rate: rate})
+	}
+	return out, nil
+}
+
+// SetJurisdictions replaces every row with the given jurisdictions
+func (e *JurisdictionEditor) SetJurisdictions(js []stc.Jurisdiction) {
+	e.rows = nil
+	e.list.RemoveAll()
+	for _, j := range js {
+		e.addRow(j)
+	}
+}
@@ -0,0 +1,128 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+	"fynance/stc"
+)
+
+// lotKindLabels are the lot kinds the editor offers, by stc.LotKind
+var lotKindLabels = map[stc.LotKind]string{stc.LotOption: "NSO", stc.LotRSU: "RSU"}
+
+// lotRow is one editable line of the lot editor
+type lotRow struct {
+	label  *widget.Entry
+	kind   *widget.Select
+	shares *widget.Entry
+	strike *widget.Entry
+	gross  *widget.Check
+	box    fyne.CanvasObject
+}
+
+// LotEditor is a repeating-row editor for the lots of a multi-lot event
+type LotEditor struct {
+	rows    []*lotRow
+	list    *fyne.Container
+	Content fyne.CanvasObject
+
+	Vars expr.Vars // Named variables amounts may refer to
+}
+
+// NewLotEditor creates an empty editor with an "Add Lot" button
+func NewLotEditor() *LotEditor {
+	e := &LotEditor{list: container.NewVBox()}
+	addBtn := widget.NewButtonWithIcon("Add Lot", theme.ContentAddIcon(), func() {
+		e.addRow(stc.EventLot{Kind: stc.LotOption})
+	})
+	addBtn.Importance = widget.LowImportance
+	e.Content = container.NewVBox(e.list, addBtn)
+	return e
+}
+
+// addRow appends an editable row for the given lot
+func (e *LotEditor) addRow(l stc.EventLot) {
+	row := &lotRow{
+		label:  widget.NewEntry(),
+		kind:   widget.NewSelect([]string{lotKindLabels[stc.LotOption], lotKindLabels[stc.LotRSU]}, nil),
+		shares: widget.NewEntry(),
+		strike: widget.NewEntry(),
+		gross:  widget.NewCheck("Gross-up", nil),
+	}
+	row.label.SetPlaceHolder("2021 grant")
+	row.label.SetText(l.Label)
+	row.kind.SetSelected(lotKindLabels[l.Kind])
+	row.shares.SetPlaceHolder("Shares")
+	if l.Shares != 0 {
+		row.shares.SetText(formatRate(l.Shares))
+	}
+	row.strike.SetPlaceHolder("Strike $")
+	if l.ExercisePrice != 0 {
+		row.strike.SetText(fmt.Sprintf("%.2f", l.ExercisePrice))
+	}
+
+	// The employer may gross up the tax on some tranches only
+	row.gross.SetChecked(l.GrossUp)
+
+	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		e.removeRow(row)
+	})
+	removeBtn.Importance = widget.LowImportance
+
+	row.box = container.NewBorder(nil, nil, nil, container.NewHBox(row.gross, removeBtn),
+		container.NewGridWithColumns(4, row.label, row.kind, row.shares, row.strike))
+	e.rows = append(e.rows, row)
+	e.list.Add(row.box)
+}
+
+// removeRow deletes a row from the editor
+func (e *LotEditor) removeRow(row *lotRow) {
+	for i, r := range e.rows {
+		if r == row {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			break
+		}
+	}
+	e.list.Remove(row.box)
+}
+
+// Lots returns the configured rows in order, skipping ones without shares
+func (e *LotEditor) Lots() ([]stc.EventLot, error) {
+	var out []stc.EventLot
+	for i, r := range e.rows {
+		if strings.TrimSpace(r.shares.Text) == "" {
+			continue
+		}
+		kind := stc.LotOption
+		if r.kind.Selected == lotKindLabels[stc.LotRSU] {
+			kind = stc.LotRSU
+		}
+		shares, err := parseFloat(r.shares.Text, e.Vars)
+		if err != nil || shares <= 0 {
+			return nil, fmt.Errorf("invalid shares for lot %d", i+1)
+		}
+		lot := stc.EventLot{Label: strings.TrimSpace(r.label.Text), Kind: kind, Shares: shares, GrossUp: r.gross.Checked}
+		if kind == stc.LotOption {
+			if lot.ExercisePrice, err = parseFloat(r.strike.Text, e.Vars); err != nil || lot.ExercisePrice <= 0 {
+				return nil, fmt.Errorf("invalid strike for lot %d", i+1)
+			}
+		}
+		out = append(out, lot)
+	}
+	return out, nil
+}
+
+// SetLots replaces every row with the given lots
+func (e *LotEditor) SetLots(lots []stc.EventLot) {
+	e.rows = nil
+	e.list.RemoveAll()
+	for _, l := range lots {
+		e.addRow(l)
+	}
+}
@@ -0,0 +1,208 @@
+package widgets
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/stc"
+	"fynance/viewmodel"
+)
+
+// Row labels shared by ShowResult and ShowRSUResult
+const (
+	RowGrantValue = viewmodel.RowGrantValue
+	RowSharesSold = viewmodel.RowSharesSold
+	RowProceeds   = viewmodel.RowProceeds
+	RowGainLoss   = viewmodel.RowGainLoss
+	RowTaxes      = viewmodel.RowTaxes
+	RowSurtax     = viewmodel.RowSurtax
+	RowFees       = viewmodel.RowFees
+	RowTotalFees  = viewmodel.RowTotalFees
+	RowRegFees    = viewmodel.RowRegFees
+	RowTotalCosts = viewmodel.RowTotalCosts
+	RowCashTopUp  = viewmodel.RowCashTopUp
+	RowWithheld   = viewmodel.RowWithheld
+	RowFeeRate    = viewmodel.RowFeeRate
+	RowSoldRate   = viewmodel.RowSoldRate
+)
+
+// OptionRows and RSURows are the default detail layouts for each calculation
+var (
+	OptionRows = [2][]string{
+		{RowSharesSold, RowSoldRate, RowProceeds, RowGainLoss},
+		{RowTaxes, RowWithheld, RowSurtax, RowFees, RowRegFees, RowFeeRate, RowTotalCosts, RowCashTopUp},
+	}
+	RSURows = [2][]string{
+		{RowGrantValue, RowSharesSold, RowSoldRate, RowProceeds, RowGainLoss},
+		{RowTaxes, RowWithheld, RowSurtax, RowTotalFees, RowRegFees, RowFeeRate, RowTotalCosts, RowCashTopUp},
+	}
+)
+
+// ResultCard shows the headline Net Shares and Residual figures above a
+// two-column grid of detail rows and an optional note, with a banner of
+// any warnings on top.
+type ResultCard struct {
+	widget.BaseWidget
+
+	netShares *canvas.Text
+	residual  *canvas.Text
+	rows      map[string]*widget.Label
+	columns   []*FieldSet
+	note      *widget.Label
+	banner    *widget.Label   // Result warnings; hidden when there are none
+	details   *fyne.Container // Headline and detail rows
+	payslip   *widget.Label   // Alternative pay-stub layout
+	content   *fyne.Container
+}
+
+// NewResultCard creates a card with the given left and right detail row labels.
+// accessory, if non-nil, is shown beside the residual (e.g. a help button).
+func NewResultCard(rows [2][]string, accessory fyne.CanvasObject) *ResultCard {
+	c := &ResultCard{rows: make(map[string]*widget.Label)}
+	c.ExtendBaseWidget(c)
+
+	c.netShares = canvas.NewText("-", theme.PrimaryColor())
+	c.netShares.TextSize = 24
+	c.netShares.TextStyle = fyne.TextStyle{Bold: true}
+
+	c.residual = canvas.NewText("-", theme.SuccessColor())
+	c.residual.TextSize = 24
+	c.residual.TextStyle = fyne.TextStyle{Bold: true}
+
+	var residual fyne.CanvasObject = c.residual
+	if accessory != nil {
+		residual = container.NewBorder(nil, nil, nil, accessory, c.residual)
+	}
+
+	summaryGrid := container.NewGridWithColumns(2,
+		container.New(layout.NewFormLayout(), widget.NewLabel("Net Shares:"), c.netShares),
+		container.New(layout.NewFormLayout(), widget.NewLabel("Residual:"), residual),
+	)
+
+	columns := make([]fyne.CanvasObject, 2)
+	for i, labels := range rows {
+		fields := NewFieldSet()
+		for _, label := range labels {
+			lbl := widget.NewLabel("-")
+			c.rows[label] = lbl
+			fields.Append(label, lbl)
+		}
+		c.columns = append(c.columns, fields)
+		columns[i] = fields
+	}
+
+	c.note = widget.NewLabel("")
+	c.details = container.NewVBox(
+		summaryGrid,
+		widget.NewSeparator(),
+		container.NewGridWithColumns(2, columns...),
+		c.note,
+	)
+	c.payslip = widget.NewLabel("")
+	c.payslip.TextStyle = fyne.TextStyle{Monospace: true}
+	c.payslip.Hide()
+	c.banner = widget.NewLabel("")
+	c.banner.Importance = widget.WarningImportance
+	c.banner.Wrapping = fyne.TextWrapWord
+	c.banner.Hide()
+	c.content = container.NewVBox(c.banner, c.details, c.payslip)
+	return c
+}
+
+// CreateRenderer implements fyne.Widget
+func (c *ResultCard) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.content)
+}
+
+// Append adds a widget (e.g. an action button) below the card
+func (c *ResultCard) Append(obj fyne.CanvasObject) {
+	c.content.Add(obj)
+}
+
+// SetHeadline sets the large Net Shares and Residual figures
+func (c *ResultCard) SetHeadline(netShares, residual string) {
+	c.netShares.Text = netShares
+	c.netShares.Refresh()
+	c.residual.Text = residual
+	c.residual.Refresh()
+}
+
+// SetValue sets a detail row's value; unknown rows are ignored
+func (c *ResultCard) SetValue(row, value string) {
+	if lbl, ok := c.rows[row]; ok {
+		lbl.SetText(value)
+	}
+}
+
+// SetHidden hides the detail rows whose labels are in hidden
+func (c *ResultCard) SetHidden(hidden map[string]bool) {
+	for _, col := range c.columns {
+		col.SetHidden(hidden)
+	}
+}
+
+// SetPayslipLayout switches between the summary layout and the payslip layout
+func (c *ResultCard) SetPayslipLayout(on bool) {
+	if on {
+		c.details.Hide()
+		c.payslip.Show()
+	} else {
+		c.payslip.Hide()
+		c.details.Show()
+	}
+}
+
+// ShowPayslip fills the payslip layout
+func (c *ResultCard) ShowPayslip(p viewmodel.Payslip) {
+	c.payslip.SetText(p.Text())
+}
+
+// SetNote sets the free-form text below the details
+func (c *ResultCard) SetNote(text string) {
+	c.note.SetText(text)
+}
+
+// ShowView fills the card from a formatted view model
+func (c *ResultCard) ShowView(vm viewmodel.ViewModel) {
+	c.SetHeadline(vm.NetShares, vm.Residual)
+	for _, r := range vm.Rows {
+		c.SetValue(r.Label, r.Value)
+	}
+	c.SetNote(strings.Join(vm.Notes, "\n"))
+	c.SetWarnings(vm.Warnings)
+}
+
+// SetWarnings fills the warning banner, hiding it when warnings is empty
+func (c *ResultCard) SetWarnings(warnings []string) {
+	if len(warnings) == 0 {
+		c.banner.Hide()
+		return
+	}
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = "⚠ " + w
+	}
+	c.banner.SetText(strings.Join(lines, "\n"))
+	c.banner.Show()
+}
+
+// ShowResult fills the card from an options calculation
+func (c *ResultCard) ShowResult(r stc.Result) {
+	c.ShowView(viewmodel.FromResult(r))
+}
+
+// ShowRSUResult fills the card from an RSU calculation
+func (c *ResultCard) ShowRSUResult(r stc.RSUResult) {
+	c.ShowView(viewmodel.FromRSUResult(r))
+}
+
+// FormatTaxLines renders itemized tax lines, one per row
+func FormatTaxLines(lines []stc.TaxLine) string {
+	return strings.Join(viewmodel.TaxLines(lines), "\n")
+}
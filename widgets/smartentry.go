@@ -0,0 +1,53 @@
+package widgets
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SmartEntry extends widget.Entry to handle Enter/Return keys for submission
+// while preserving standard shortcuts (Ctrl+A, Tab, etc.)
+type SmartEntry struct {
+	widget.Entry
+	onEnter func()
+}
+
+// NewSmartEntry creates an entry pre-filled with (and hinting) the given value
+func NewSmartEntry(placeholder string) *SmartEntry {
+	e := &SmartEntry{}
+	e.ExtendBaseWidget(e)
+	e.SetPlaceHolder(placeholder)
+	e.Text = placeholder
+	return e
+}
+
+// SetOnEnter sets the function called when Enter/Return is pressed
+func (e *SmartEntry) SetOnEnter(f func()) {
+	e.onEnter = f
+}
+
+// TypedKey intercepts key presses to handle Enter/Return
+func (e *SmartEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyReturn || key.Name == fyne.KeyEnter {
+		if e.onEnter != nil {
+			e.onEnter()
+		}
+		// We consume the event so it doesn't add a newline
+		return
+	}
+	// Delegate to base implementation for all other keys (navigation, typing)
+	e.Entry.TypedKey(key)
+}
+
+// TypedShortcut ensures standard shortcuts (Copy/Paste/SelectAll) work
+func (e *SmartEntry) TypedShortcut(shortcut fyne.Shortcut) {
+	// Fyne's base Entry handles Cut/Copy/Paste/SelectAll.
+	// We strictly pass it through to ensure native OS behavior (Cmd+A / Ctrl+A).
+	e.Entry.TypedShortcut(shortcut)
+}
+
+// Ensure interface compliance
+var _ fyne.Focusable = (*SmartEntry)(nil)
+var _ fyne.Widget = (*SmartEntry)(nil)
+var _ desktop.Keyable = (*SmartEntry)(nil)
@@ -0,0 +1,121 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+	"fynance/stc"
+)
+
+// tierRow is one editable line of the commission tier editor
+type tierRow struct {
+	upTo *widget.Entry
+	flat *widget.Entry
+	rate *widget.Entry
+	box  fyne.CanvasObject
+}
+
+// TierEditor is a repeating-row editor for a tiered commission schedule
+type TierEditor struct {
+	rows    []*tierRow
+	list    *fyne.Container
+	Content fyne.CanvasObject
+
+	Vars expr.Vars // Named variables amounts may refer to
+}
+
+// NewTierEditor creates an empty editor with an "Add Tier" button
+func NewTierEditor() *TierEditor {
+	e := &TierEditor{list: container.NewVBox()}
+	addBtn := widget.NewButtonWithIcon("Add Tier", theme.ContentAddIcon(), func() {
+		e.addRow(stc.Tier{})
+	})
+	addBtn.Importance = widget.LowImportance
+	e.Content = container.NewVBox(e.list, addBtn)
+	return e
+}
+
+// addRow appends an editable row for the given tier
+func (e *TierEditor) addRow(t stc.Tier) {
+	row := &tierRow{
+		upTo: widget.NewEntry(),
+		flat: widget.NewEntry(),
+		rate: widget.NewEntry(),
+	}
+	row.upTo.SetPlaceHolder("Up to $ (blank: no limit)")
+	row.flat.SetPlaceHolder("Flat $")
+	row.rate.SetPlaceHolder("Rate (0-1)")
+	if t.UpTo != 0 {
+		row.upTo.SetText(fmt.Sprintf("%.2f", t.UpTo))
+	}
+	if t.Flat != 0 {
+		row.flat.SetText(fmt.Sprintf("%.2f", t.Flat))
+	}
+	if t.Rate != 0 {
+		row.rate.SetText(formatRate(t.Rate))
+	}
+
+	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		e.removeRow(row)
+	})
+	removeBtn.Importance = widget.LowImportance
+
+	row.box = container.NewBorder(nil, nil, nil, removeBtn,
+		container.NewGridWithColumns(3, row.upTo, row.flat, row.rate))
+	e.rows = append(e.rows, row)
+	e.list.Add(row.box)
+}
+
+// removeRow deletes a row from the editor
+func (e *TierEditor) removeRow(row *tierRow) {
+	for i, r := range e.rows {
+		if r == row {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			break
+		}
+	}
+	e.list.Remove(row.box)
+}
+
+// Tiers returns the configured rows in order, skipping blank ones
+func (e *TierEditor) Tiers() ([]stc.Tier, error) {
+	var out []stc.Tier
+	for i, r := range e.rows {
+		if strings.TrimSpace(r.upTo.Text+r.flat.Text+r.rate.Text) == "" {
+			continue
+		}
+		var t stc.Tier
+		var err error
+		fields := []struct {
+			name  string
+			entry *widget.Entry
+			dst   *float64
+		}{
+			{"limit", r.upTo, &t.UpTo},
+			{"flat fee", r.flat, &t.Flat},
+			{"rate", r.rate, &t.Rate},
+		}
+		for _, fld := range fields {
+			if *fld.dst, err = parseFloat(fld.entry.Text, e.Vars); err != nil {
+				return nil, fmt.Errorf("invalid %s for tier %d", fld.name, i+1)
+			}
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// SetTiers replaces every row with the given tiers
+func (e *TierEditor) SetTiers(tiers []stc.Tier) {
+	e.rows = nil
+	e.list.RemoveAll()
+	for _, t := range tiers {
+		e.addRow(t)
+	}
+}
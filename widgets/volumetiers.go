@@ -0,0 +1,117 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/expr"
+	"fynance/stc"
+)
+
+// volumeTierRow is one editable line of the volume tier editor
+type volumeTierRow struct {
+	after   *widget.Entry
+	rate    *widget.Entry
+	minimum *widget.Entry
+	box     fyne.CanvasObject
+}
+
+// VolumeTierEditor is a repeating-row editor for negotiated rates that
+// apply after a number of trades in the year
+type VolumeTierEditor struct {
+	rows    []*volumeTierRow
+	list    *fyne.Container
+	Content fyne.CanvasObject
+
+	Vars expr.Vars // Named variables amounts may refer to
+}
+
+// NewVolumeTierEditor creates an empty editor with an "Add Volume Tier" button
+func NewVolumeTierEditor() *VolumeTierEditor {
+	e := &VolumeTierEditor{list: container.NewVBox()}
+	addBtn := widget.NewButtonWithIcon("Add Volume Tier", theme.ContentAddIcon(), func() {
+		e.addRow(stc.VolumeTier{})
+	})
+	addBtn.Importance = widget.LowImportance
+	e.Content = container.NewVBox(e.list, addBtn)
+	return e
+}
+
+// addRow appends an editable row for the given tier
+func (e *VolumeTierEditor) addRow(t stc.VolumeTier) {
+	row := &volumeTierRow{
+		after:   widget.NewEntry(),
+		rate:    widget.NewEntry(),
+		minimum: widget.NewEntry(),
+	}
+	row.after.SetPlaceHolder("After N trades")
+	row.rate.SetPlaceHolder("Rate per share")
+	row.minimum.SetPlaceHolder("Minimum $")
+	if t.After != 0 {
+		row.after.SetText(fmt.Sprintf("%d", t.After))
+	}
+	if t.CommissionRate != 0 {
+		row.rate.SetText(formatRate(t.CommissionRate))
+	}
+	if t.MinimumFee != 0 {
+		row.minimum.SetText(fmt.Sprintf("%.2f", t.MinimumFee))
+	}
+
+	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		e.removeRow(row)
+	})
+	removeBtn.Importance = widget.LowImportance
+
+	row.box = container.NewBorder(nil, nil, nil, removeBtn,
+		container.NewGridWithColumns(3, row.after, row.rate, row.minimum))
+	e.rows = append(e.rows, row)
+	e.list.Add(row.box)
+}
+
+// removeRow deletes a row from the editor
+func (e *VolumeTierEditor) removeRow(row *volumeTierRow) {
+	for i, r := range e.rows {
+		if r == row {
+			e.rows = append(e.rows[:i], e.rows[i+1:]...)
+			break
+		}
+	}
+	e.list.Remove(row.box)
+}
+
+// Tiers returns the configured rows in order, skipping blank ones
+func (e *VolumeTierEditor) Tiers() ([]stc.VolumeTier, error) {
+	var out []stc.VolumeTier
+	for i, r := range e.rows {
+		if strings.TrimSpace(r.after.Text+r.rate.Text+r.minimum.Text) == "" {
+			continue
+		}
+		after, err := parseFloat(r.after.Text, e.Vars)
+		if err != nil || after < 0 || after != float64(int(after)) {
+			return nil, fmt.Errorf("invalid trade count for volume tier %d", i+1)
+		}
+		t := stc.VolumeTier{After: int(after)}
+		if t.CommissionRate, err = parseFloat(r.rate.Text, e.Vars); err != nil {
+			return nil, fmt.Errorf("invalid rate for volume tier %d", i+1)
+		}
+		if t.MinimumFee, err = parseFloat(r.minimum.Text, e.Vars); err != nil {
+			return nil, fmt.Errorf("invalid minimum fee for volume tier %d", i+1)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// SetTiers replaces every row with the given tiers
+func (e *VolumeTierEditor) SetTiers(tiers []stc.VolumeTier) {
+	e.rows = nil
+	e.list.RemoveAll()
+	for _, t := range tiers {
+		e.addRow(t)
+	}
+}
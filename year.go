@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"fynance/charts"
+	"fynance/events"
+	"fynance/portfolio"
+	"fynance/widgets"
+)
+
+// --- TOOL 5: YEAR Dashboard ---
+// makeYearTab shows the current year's projected cash flows from retained shares
+// and the net-worth history of the position. It re-renders whenever the
+// portfolio changes.
+func makeYearTab(pf *portfolio.Portfolio, bus *events.Bus) fyne.CanvasObject {
+	now := time.Now().In(taxHome())
+
+	// --- INPUT FIELDS ---
+	divPerShareEntry := widgets.NewSmartEntry("0.00")
+	paymentsEntry := widgets.NewSmartEntry("4")
+	firstPaymentEntry := widgets.NewSmartEntry(time.Date(now.Year(), time.March, 15, 0, 0, 0, 0, now.Location()).Format("2006-01-02"))
+	qualifiedCheck := widget.NewCheck("Qualified dividends", nil)
+	qualifiedCheck.SetChecked(true)
+	qualRateEntry := widgets.NewSmartEntry("0.15")
+	ordRateEntry := widgets.NewSmartEntry("0.22")
+	withholdEntry := widgets.NewSmartEntry("0.00")
+
+	// --- OUTPUT ---
+	lblShares := widget.NewLabel("-")
+	table := container.NewGridWithColumns(4)
+	lblTotals := widget.NewLabel("-")
+
+	refresh := func() {
+		perShare, _ := parseFloat(divPerShareEntry.Text)
+		payments, _ := parseFloat(paymentsEntry.Text)
+		qualRate, _ := parseFloat(qualRateEntry.Text)
+		ordRate, _ := parseFloat(ordRateEntry.Text)
+		withhold, _ := parseFloat(withholdEntry.Text)
+		// Years are bucketed at the tax home, which may have changed in Settings
+		loc := taxHome()
+		now := time.Now().In(loc)
+		first, err := time.ParseInLocation("2006-01-02", firstPaymentEntry.Text, loc)
+		if err != nil {
+			first = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		}
+
+		assumption := portfolio.DividendAssumption{
+			AnnualPerShare:  perShare,
+			PaymentsPerYear: int(payments),
+			FirstPayment:    first,
+			Qualified:       qualifiedCheck.Checked,
+			QualifiedRate:   qualRate,
+			OrdinaryRate:    ordRate,
+			WithholdingRate: withhold,
+		}
+
+		start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+		end := start.AddDate(1, 0, -1)
+		months := portfolio.MonthlyDividends(portfolio.ProjectDividends(pf, assumption, start, end), now.Year(), loc)
+
+		table.RemoveAll()
+		for _, h := range []string{"Month", "Gross", "Est. Tax", "Net"} {
+			table.Add(widget.NewLabelWithStyle(h, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		}
+
+		var gross, tax, net float64
+		for i, m := range months {
+			if m.Gross == 0 {
+				continue
+			}
+			table.Add(widget.NewLabel(time.Month(i + 1).String()[:3]))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", m.Gross)))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", m.EstTax)))
+			table.Add(widget.NewLabel(fmt.Sprintf("$%.2f", m.Net)))
+			gross += m.Gross
+			tax += m.EstTax
+			net += m.Net
+		}
+
+		lblShares.SetText(fmt.Sprintf("%.0f shares in %d lots", pf.TotalShares(), len(pf.Lots)))
+		lblTotals.SetText(fmt.Sprintf("Gross $%.2f  ·  Est. Tax $%.2f  ·  Net $%.2f", gross, tax, net))
+	}
+
+	inputs := []*widgets.SmartEntry{
+		divPerShareEntry, paymentsEntry, firstPaymentEntry, qualRateEntry, ordRateEntry, withholdEntry,
+	}
+	for _, e := range inputs {
+		e.SetOnEnter(refresh)
+	}
+	qualifiedCheck.OnChanged = func(bool) { refresh() }
+
+	// --- LAYOUT ---
+	divForm := widget.NewForm(
+		widget.NewFormItem("Annual Dividend ($/sh)", divPerShareEntry),
+		widget.NewFormItem("Payments / Year", paymentsEntry),
+		widget.NewFormItem("First Payment", firstPaymentEntry),
+		widget.NewFormItem("", qualifiedCheck),
+		widget.NewFormItem("Qualified Rate", qualRateEntry),
+		widget.NewFormItem("Ordinary Rate", ordRateEntry),
+		widget.NewFormItem("Withholding", withholdEntry),
+	)
+
+	inputCard := widget.NewCard(fmt.Sprintf("%d Dividends", now.Year()), "", divForm)
+	netWorth := makeNetWorthCard(pf, bus)
+
+	content := container.NewBorder(
+		container.NewVBox(inputCard, lblShares, widget.NewSeparator()),
+		container.NewVBox(widget.NewSeparator(), lblTotals),
+		nil, nil,
+		container.NewVScroll(container.NewVBox(table, widget.NewSeparator(), netWorth, makeEmployersCard(pf, bus))),
+	)
+
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) { refresh() })
+	refresh()
+	return container.NewPadded(content)
+}
+
+// makeNetWorthCard charts the recorded snapshots of the position. A snapshot
+// is taken on demand, and automatically once a month when a price arrives.
+func makeNetWorthCard(pf *portfolio.Portfolio, bus *events.Bus) fyne.CanvasObject {
+	chart := canvas.NewImageFromImage(nil)
+	chart.FillMode = canvas.ImageFillContain
+	chart.SetMinSize(fyne.NewSize(480, 280))
+	lblStatus := widget.NewLabel("")
+	lblStatus.Wrapping = fyne.TextWrapWord
+
+	// The last market price seen; private companies use the valuation instead
+	var marketPrice float64
+	price := func(at time.Time) (float64, bool) {
+		if valuationMode == events.ModePrivate {
+			v, ok := pf.ValuationOn(at)
+			return v.Price, ok
+		}
+		return marketPrice, marketPrice > 0
+	}
+
+	redraw := func() {
+		snapshots := pf.AdjustedSnapshots()
+		png, err := charts.RenderPNG(charts.FromSnapshots(snapshots), charts.TimeSeries, image.Pt(720, 420))
+		if err != nil {
+			fyne.LogError("Failed to render net worth chart", err)
+			return
+		}
+		chart.Resource = fyne.NewStaticResource("networth.png", png)
+		chart.Refresh()
+
+		if n := len(snapshots); n > 0 {
+			last := snapshots[n-1]
+			lblStatus.SetText(fmt.Sprintf("%d snapshots · last %s: $%.2f value, $%.2f taxes paid, $%.2f cash extracted",
+				n, last.Date.Format("2006-01-02"), last.Value, last.TaxesPaid, last.CashExtracted))
+		} else {
+			lblStatus.SetText("No snapshots yet")
+		}
+	}
+
+	record := func() bool {
+		now := time.Now().In(taxHome())
+		p, ok := price(now)
+		if !ok {
+			return false
+		}
+		pf.RecordSnapshot(pf.Snapshot(now, p))
+		bus.Publish(events.PortfolioChanged, nil)
+		return true
+	}
+
+	recordBtn := widget.NewButton("Record Snapshot", func() {
+		if !record() {
+			lblStatus.SetText("Enter a current price or record a valuation first")
+		}
+	})
+
+	// Scheduled snapshots piggyback on price updates rather than a timer
+	takeIfDue := func() {
+		if pf.SnapshotDue(time.Now()) {
+			record()
+		}
+	}
+	bus.Subscribe(events.PriceFetched, func(e events.Event) {
+		if p := e.Payload.(events.Price); p.Symbol == "" {
+			marketPrice = p.Price
+			takeIfDue()
+		}
+	})
+	bus.Subscribe(events.ModeChanged, func(events.Event) { takeIfDue() })
+	bus.Subscribe(events.PortfolioChanged, func(events.Event) { redraw() })
+	redraw()
+	takeIfDue()
+
+	return widget.NewCard("Net Worth", "Position value, cumulative taxes, and cash extracted",
+		container.NewVBox(chart, lblStatus, recordBtn))
+}